@@ -1,51 +1,154 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+
 	_ "github.com/lib/pq"
 	"github.com/robfig/cron/v3"
 
 	_ "backend/docs"
 	"backend/src/handler"
+	"backend/src/service"
 	"backend/src/service/domain"
+	"backend/src/storage"
 	"backend/src/storage/postgres"
 )
 
+const defaultShutdownTimeout = 25 * time.Second
+
+// Shorter than TOKEN_REFRESH_WINDOW so that a token is refreshed before it expires
+const tokenRefreshSchedule = "@every 5m"
+
+// Wraps the stored tokens with the current TOKEN_ENCRYPTION_KEY_ID then exits, e.g. "./server rotate-token-keys"
+const rotateTokenKeysCommand = "rotate-token-keys"
+
 // @title	Documentation for AREA Rest API
 // @host	localhost:8080
 func main() {
-	repositories := postgres.New()
+	repositories, err := postgres.New()
+	if err != nil {
+		log.Fatalf("Could not connect to the database: %s", err.Error())
+	}
+	if len(os.Args) > 1 && os.Args[1] == rotateTokenKeysCommand {
+		rotateTokenKeys(repositories)
+		return
+	}
 	services := domain.New(repositories)
 	handlers := handler.New(services)
 
-	cronJob := cron.New()
-	_, errCronCreationEveryMinute := cronJob.AddFunc("@every 1m", func() {
-		services.WorkflowService.CheckTimeAndDateActions()
-		services.WorkflowService.CheckNewGitlabWorkflows()
-		services.WorkflowService.CheckNewGithubWorkflows()
-	})
-	if errCronCreationEveryMinute != nil {
-		panic(errCronCreationEveryMinute)
+	errSeed := services.ServiceService.SeedServices()
+	if errSeed != nil {
+		log.Fatal(errSeed)
 	}
 
-	_, errCronCreationEvery15Minutes := cronJob.AddFunc("@every 15m", func() {
-		services.WorkflowService.CheckRedditActions()
-		services.WorkflowService.CheckGithubActions()
-	})
-	if errCronCreationEvery15Minutes != nil {
-		panic(errCronCreationEvery15Minutes)
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
+
+	workersCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+	pollsCtx, cancelPolls := context.WithCancel(context.Background())
+	defer cancelPolls()
+
+	cronJob := cron.New()
+	for _, connector := range services.ServiceService.RetrieveConnectors() {
+		for _, pollJob := range connector.PollJobs() {
+			poll := pollJob.Poll
+			_, errCronCreation := cronJob.AddFunc(pollJob.Schedule, func() {
+				if services.WorkflowService.IsSchedulerLeader(pollsCtx) {
+					poll(services.WorkflowService, pollsCtx)
+				}
+			})
+			if errCronCreation != nil {
+				log.Fatal(errCronCreation)
+			}
+		}
 	}
 
-	_, errCronCreationEvery12Hours := cronJob.AddFunc("@every 12h", func() {
-		services.WorkflowService.CheckWeatherActions()
+	_, errCronCreation := cronJob.AddFunc(tokenRefreshSchedule, func() {
+		if services.WorkflowService.IsSchedulerLeader(pollsCtx) {
+			services.UserServiceService.RefreshExpiringTokens(pollsCtx)
+		}
 	})
-	if errCronCreationEvery12Hours != nil {
-		panic(errCronCreationEvery12Hours)
+	if errCronCreation != nil {
+		log.Fatal(errCronCreation)
 	}
 
+	services.WorkflowService.StartJobWorkers(workersCtx)
 	cronJob.Start()
 
-	errHandler := handlers.Run()
-	if errHandler != nil {
-		panic(errHandler)
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- handlers.Run()
+	}()
+
+	select {
+	case errHandler := <-serverErrors:
+		if errHandler != nil {
+			log.Print(errHandler)
+		}
+	case <-signalCtx.Done():
+		log.Print("Shutting down")
+	}
+
+	shutdown(handlers, services, repositories, cronJob, cancelWorkers, cancelPolls)
+}
+
+func rotateTokenKeys(repositories *storage.Repository) {
+	rotated, err := repositories.UserServiceRepository.RotateUserServiceKeys()
+	repositories.DatabaseRepository.Close()
+	log.Printf("%d user services rotated", rotated)
+	if err != nil {
+		log.Fatalf("Could not rotate the token keys: %s", err.Error())
+	}
+}
+
+func shutdownTimeout() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("SHUTDOWN_TIMEOUT"))
+	if err != nil || seconds <= 0 {
+		return defaultShutdownTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Stops accepting requests then waits for the requests, polling passes and reaction jobs in flight until the deadline,
+// the jobs left unfinished are claimed again by another replica once their lock times out
+func shutdown(handlers *handler.Handler, services *service.Service, repositories *storage.Repository, cronJob *cron.Cron, cancelWorkers, cancelPolls context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+
+	services.HealthService.MarkShuttingDown()
+	err := handlers.Shutdown(ctx)
+	if err != nil {
+		log.Printf("Could not wait for the requests in flight: %s", err.Error())
+	}
+
+	cronDone := cronJob.Stop()
+	cancelWorkers()
+	select {
+	case <-cronDone.Done():
+	case <-ctx.Done():
+		cancelPolls()
+		log.Print("Could not wait for the polling passes in flight")
+	}
+
+	err = services.WorkflowService.WaitJobWorkers(ctx)
+	if err != nil {
+		log.Printf("Could not wait for the reaction jobs in flight: %s", err.Error())
+	}
+
+	err = services.WorkflowService.ReleaseSchedulerLock()
+	if err != nil {
+		log.Printf("Could not release the scheduler lock: %s", err.Error())
+	}
+	err = repositories.DatabaseRepository.Close()
+	if err != nil {
+		log.Printf("Could not close the database: %s", err.Error())
 	}
 }
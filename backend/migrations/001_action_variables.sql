@@ -0,0 +1,3 @@
+-- Variables exposed by each action to the parameters of its reactions, e.g. {{post.title}},
+-- written from the connector definitions at startup
+ALTER TABLE actions ADD COLUMN IF NOT EXISTS variables jsonb;
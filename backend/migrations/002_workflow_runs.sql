@@ -0,0 +1,13 @@
+-- History of every reaction run triggered by a workflow
+CREATE TABLE IF NOT EXISTS workflow_runs (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    workflowid uuid NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    reactionid uuid NOT NULL,
+    triggeredat timestamp with time zone NOT NULL DEFAULT now(),
+    actionpayload jsonb,
+    status text NOT NULL,
+    httpstatus integer NOT NULL DEFAULT 0,
+    errormessage text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS workflow_runs_workflowid_triggeredat_idx ON workflow_runs (workflowid, triggeredat DESC);
@@ -0,0 +1,15 @@
+-- Ordered reaction steps run by a workflow when its action triggers
+CREATE TABLE IF NOT EXISTS workflow_reactions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    workflowid uuid NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    position integer NOT NULL,
+    reactionid uuid NOT NULL,
+    reactionparam jsonb,
+    continueonerror boolean NOT NULL DEFAULT false,
+    UNIQUE (workflowid, position)
+);
+
+-- Existing workflows become single step workflows
+INSERT INTO workflow_reactions (workflowid, position, reactionid, reactionparam)
+SELECT id, 0, reactionid, reactionparam FROM workflows
+WHERE NOT EXISTS (SELECT 1 FROM workflow_reactions WHERE workflow_reactions.workflowid = workflows.id);
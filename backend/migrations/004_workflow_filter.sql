@@ -0,0 +1,2 @@
+-- Optional condition on the action event deciding whether the reactions run, e.g. title contains "release"
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS filter text NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- Last minute evaluated by each scheduler, the minutes missed while the server was down are evaluated on the next tick
+CREATE TABLE IF NOT EXISTS scheduler_ticks (
+    name text PRIMARY KEY,
+    lasttick timestamptz NOT NULL
+);
+
+-- What a workflow does with the ticks it missed: fire once, fire for every missed tick, or skip them
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS catchuppolicy text NOT NULL DEFAULT 'once';
@@ -0,0 +1,2 @@
+-- Each workflow on the "Incoming webhook" action gets a secret URL /hooks/in/:token, the token being kept in its action data
+CREATE UNIQUE INDEX IF NOT EXISTS workflows_webhook_token_index ON workflows ((actiondata->>'webhooktoken'));
@@ -0,0 +1,19 @@
+-- Secret of the hook registered on each repository or project, GitHub signs its deliveries with it (X-Hub-Signature-256)
+-- and GitLab sends it back as is (X-Gitlab-Token)
+CREATE TABLE IF NOT EXISTS service_webhooks (
+    servicename text NOT NULL,
+    resource text NOT NULL,
+    secret text NOT NULL,
+    createdat timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (servicename, resource)
+);
+
+-- Deliveries refused because their signature or token did not match
+CREATE TABLE IF NOT EXISTS webhook_rejections (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    servicename text NOT NULL,
+    resource text NOT NULL,
+    reason text NOT NULL,
+    remoteaddr text NOT NULL,
+    receivedat timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,12 @@
+-- Verified deliveries of the service webhooks, a delivery id received again within the duplicate window is ignored,
+-- one received after it replaces the stored delivery, and a stored delivery can be replayed by the owner of the workflows it triggers
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    servicename text NOT NULL,
+    deliveryid text NOT NULL,
+    headers jsonb NOT NULL,
+    body bytea NOT NULL,
+    receivedat timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_delivery_index ON webhook_deliveries (servicename, deliveryid);
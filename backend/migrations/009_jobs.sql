@@ -0,0 +1,18 @@
+-- Reaction steps waiting to be executed by the job workers, claimed with FOR UPDATE SKIP LOCKED so a job runs once
+-- even with several workers per service. A running job locked for too long is claimed again.
+CREATE TABLE IF NOT EXISTS jobs (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    workflowid uuid NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    servicename text NOT NULL,
+    workflow jsonb NOT NULL,
+    event jsonb NOT NULL,
+    steps jsonb NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    lasterror text NOT NULL DEFAULT '',
+    runat timestamptz NOT NULL DEFAULT now(),
+    lockedat timestamptz,
+    createdat timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS jobs_claim_index ON jobs (servicename, status, runat);
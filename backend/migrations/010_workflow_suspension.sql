@@ -0,0 +1,4 @@
+-- Failed runs of a workflow since its last successful run, the workflow is deactivated past the failure threshold
+-- with the reason kept until it is activated again
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS consecutivefailures integer NOT NULL DEFAULT 0;
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS suspendedreason text NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- Intervals of the polled actions in seconds, written from the connector definitions at startup,
+-- an action without a minimum interval is not polled
+ALTER TABLE actions ADD COLUMN IF NOT EXISTS minimuminterval integer NOT NULL DEFAULT 0;
+ALTER TABLE actions ADD COLUMN IF NOT EXISTS defaultinterval integer NOT NULL DEFAULT 0;
+
+-- Interval chosen by a workflow in seconds, 0 uses the default interval of its action
+-- and the next time the workflow is due to be checked
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS pollinterval integer NOT NULL DEFAULT 0;
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS nextcheckat timestamptz NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS workflows_due_index ON workflows (actionid, nextcheckat) WHERE isactivated;
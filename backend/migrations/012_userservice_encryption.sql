@@ -0,0 +1,6 @@
+-- Tokens of the user services are encrypted with a data key per row, the data key is wrapped
+-- with the master key "keyid", a row without key id still holds its tokens in plaintext
+ALTER TABLE userservices ALTER COLUMN token TYPE text;
+ALTER TABLE userservices ALTER COLUMN tokenrefresh TYPE text;
+ALTER TABLE userservices ADD COLUMN IF NOT EXISTS keyid text NOT NULL DEFAULT '';
+ALTER TABLE userservices ADD COLUMN IF NOT EXISTS datakey text NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- States issued with the OAuth2 authorization URLs, deleted when their callback is received so a state is used once,
+-- along with the PKCE code verifier of the flow
+CREATE TABLE IF NOT EXISTS oauth_states (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    codeverifier text NOT NULL DEFAULT '',
+    expiresat timestamptz NOT NULL,
+    createdat timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS oauth_states_expiry_index ON oauth_states (expiresat);
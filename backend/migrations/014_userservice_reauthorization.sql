@@ -0,0 +1,3 @@
+-- A connection whose refresh token was refused by its service must be authorized again by its user,
+-- it is left aside by the background refresh until a new token is stored
+ALTER TABLE userservices ADD COLUMN IF NOT EXISTS needsreauth boolean NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+-- Health of a connection as shown to its user: the scopes granted by the service,
+-- the last successful call made with the token and the last error reported
+ALTER TABLE userservices ADD COLUMN IF NOT EXISTS scopes text NOT NULL DEFAULT '';
+ALTER TABLE userservices ADD COLUMN IF NOT EXISTS lastsuccessat timestamptz NULL;
+ALTER TABLE userservices ADD COLUMN IF NOT EXISTS lasterror text NOT NULL DEFAULT '';
+ALTER TABLE userservices ADD COLUMN IF NOT EXISTS lasterrorat timestamptz NULL;
@@ -0,0 +1,5 @@
+-- Failed checks of the action count towards the suspension of a workflow apart from its failed runs,
+-- a successful check only resets its own count
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS consecutivepollfailures integer NOT NULL DEFAULT 0;
+-- Set on the jobs of a run which already failed, the run is counted once towards the suspension
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS runfailed boolean NOT NULL DEFAULT false;
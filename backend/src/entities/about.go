@@ -23,12 +23,21 @@ type AboutService struct {
 	Reactions []AboutReaction `json:"reactions"`
 }
 
+// The parameters, variables and intervals of an action are seeded in the actions table but not listed in about.json
 type AboutAction struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+
+	Parameters      []map[string]interface{} `json:"-"`
+	Variables       []string                 `json:"-"`
+	MinimumInterval int                      `json:"-"`
+	DefaultInterval int                      `json:"-"`
 }
 
+// The parameters of a reaction are seeded in the reactions table but not listed in about.json
 type AboutReaction struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+
+	Parameters []map[string]interface{} `json:"-"`
 }
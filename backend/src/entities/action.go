@@ -7,4 +7,9 @@ type Action struct {
 	ServiceId   string                   `json:"serviceid"`
 	NbParam     int                      `json:"nbparam"`
 	Parameters  []map[string]interface{} `json:"parameters"`
+	Variables   []string                 `json:"variables"`
+
+	// Polling intervals in seconds, 0 for the actions which are not polled
+	MinimumInterval int `json:"minimuminterval"`
+	DefaultInterval int `json:"defaultinterval"`
 }
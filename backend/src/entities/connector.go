@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"net/http"
+	"time"
+)
+
+type ServiceDefinition struct {
+	Name         string          `json:"name"`
+	Color        string          `json:"color"`
+	Logo         string          `json:"logo"`
+	Description  string          `json:"description"`
+	IsAuthNeeded bool            `json:"isauthneeded"`
+	Actions      []AboutAction   `json:"actions"`
+	Reactions    []AboutReaction `json:"reactions"`
+}
+
+type OAuthConfig struct {
+	TokenUrl             string `json:"tokenurl"`
+	UserInfoUrl          string `json:"userinfourl"`
+	CanRefreshToken      bool   `json:"canrefreshtoken"`
+	DefaultTokenLifetime int    `json:"defaulttokenlifetime"`
+	SupportsPKCE         bool   `json:"supportspkce"`
+	RevokeUrl            string `json:"revokeurl"`
+}
+
+type WebhookEvent struct {
+	ActionName string      `json:"actionname"`
+	ParamKey   string      `json:"paramkey"`
+	ParamValue string      `json:"paramvalue"`
+	DeliveryId string      `json:"deliveryid"`
+	Event      ActionEvent `json:"event"`
+}
+
+type WebhookRejection struct {
+	Id          string    `json:"id"`
+	ServiceName string    `json:"servicename"`
+	Resource    string    `json:"resource"`
+	Reason      string    `json:"reason"`
+	RemoteAddr  string    `json:"remoteaddr"`
+	ReceivedAt  time.Time `json:"receivedat"`
+}
+
+type WebhookDelivery struct {
+	Id          string      `json:"id"`
+	ServiceName string      `json:"servicename"`
+	DeliveryId  string      `json:"deliveryid"`
+	Headers     http.Header `json:"headers"`
+	Body        []byte      `json:"body"`
+	ReceivedAt  time.Time   `json:"receivedat"`
+}
@@ -0,0 +1,18 @@
+package entities
+
+// Action event, the variables exposed by a triggered action to its reactions
+type ActionEvent map[string]interface{}
+
+// Weather
+type WeatherEvent struct {
+	City           string  `json:"city"`
+	Temperature    float64 `json:"temp_c"`
+	MaxTemperature float64 `json:"maxtemp_c"`
+	MinTemperature float64 `json:"mintemp_c"`
+}
+
+// Github
+type GithubCountEvent struct {
+	Repository string `json:"repository"`
+	Count      int    `json:"count"`
+}
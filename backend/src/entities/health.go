@@ -0,0 +1,16 @@
+package entities
+
+const DatabaseUp = "up"
+const DatabaseDown = "down"
+
+type SchedulerState struct {
+	IsLeader  bool          `json:"isleader"`
+	LastPolls []PollSummary `json:"lastpolls"`
+}
+
+type Health struct {
+	IsReady        bool           `json:"isready"`
+	IsShuttingDown bool           `json:"isshuttingdown"`
+	Database       string         `json:"database"`
+	Scheduler      SchedulerState `json:"scheduler"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+const JobPending = "pending"
+const JobRunning = "running"
+const JobFailed = "failed"
+
+// A reaction job runs the first of its steps, the following steps are enqueued once it is done
+type ReactionJob struct {
+	Id          string             `json:"id"`
+	WorkflowId  string             `json:"workflowid"`
+	ServiceName string             `json:"servicename"`
+	Workflow    Workflow           `json:"workflow"`
+	Event       ActionEvent        `json:"event"`
+	Steps       []WorkflowReaction `json:"steps"`
+	Status      string             `json:"status"`
+	Attempts    int                `json:"attempts"`
+	LastError   string             `json:"lasterror"`
+	RunAt       time.Time          `json:"runat"`
+	CreatedAt   time.Time          `json:"createdat"`
+	RunFailed   bool               `json:"runfailed"`
+}
@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// Outcome of one polling pass over the activated workflows of a service
+type PollSummary struct {
+	Service   string        `json:"service"`
+	StartedAt time.Time     `json:"startedat"`
+	Duration  time.Duration `json:"duration"`
+	Checked   int           `json:"checked"`
+	Failed    int           `json:"failed"`
+	Skipped   int           `json:"skipped"`
+	Errors    []string      `json:"errors"`
+}
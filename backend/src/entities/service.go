@@ -1,5 +1,7 @@
 package entities
 
+import "time"
+
 type Service struct {
 	Id           string `json:"id"`
 	Name         string `json:"name"`
@@ -16,6 +18,7 @@ type ResultToken struct {
 	TokenType    string `json:"token_type"`
 	ExpiresIn    int    `json:"expires_in"`
 	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
 }
 
 type UserInfo struct {
@@ -41,15 +44,23 @@ type TimeResponse struct {
 }
 
 // Reddit
+type RedditPost struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	URL    string `json:"url"`
+}
+
+type RedditComment struct {
+	ID     string `json:"id"`
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
 type RedditPostResponse struct {
 	Data struct {
 		Children []struct {
-			Data struct {
-				ID     string `json:"id"`
-				Title  string `json:"title"`
-				Author string `json:"author"`
-				URL    string `json:"url"`
-			} `json:"data"`
+			Data RedditPost `json:"data"`
 		} `json:"children"`
 	} `json:"data"`
 }
@@ -57,21 +68,16 @@ type RedditPostResponse struct {
 type RedditCommentResponse struct {
 	Data struct {
 		Children []struct {
-			Data struct {
-				ID     string `json:"id"`
-				Author string `json:"author"`
-				Body   string `json:"body"`
-			} `json:"data"`
+			Data RedditComment `json:"data"`
 		} `json:"children"`
 	} `json:"data"`
 }
 
+// The voted and saved listings hold the posts themselves
 type RedditVoteResponse struct {
 	Data struct {
 		Children []struct {
-			Data struct {
-				ID string `json:"id"`
-			} `json:"data"`
+			Data RedditPost `json:"data"`
 		} `json:"children"`
 	} `json:"data"`
 }
@@ -103,11 +109,13 @@ type GithubWebhookTriggeredResponse struct {
 }
 
 type GithubWebhookResponse struct {
+	Id     int    `json:"id,omitempty"`
 	Name   string `json:"name"`
 	Active bool   `json:"active"`
 	Config struct {
 		Url         string `json:"url"`
 		ContentType string `json:"content_type"`
+		Secret      string `json:"secret,omitempty"`
 	} `json:"config"`
 	Events []string `json:"events"`
 }
@@ -168,3 +176,16 @@ type SpotifyRequestParameters struct {
 	EndUrl        string
 	Method        string
 }
+
+// API call
+// RetryAfter is the delay asked by the Retry-After header of the response, zero without it
+// Body is the start of the body of the response, e.g. the error code of an OAuth token endpoint
+type ApiCallError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (self ApiCallError) Error() string {
+	return "API call failed"
+}
@@ -25,3 +25,7 @@ type UserModifyPassword struct {
 	OldPassword string `json:"oldpassword"`
 	Password    string `json:"password"`
 }
+
+type UserTimezone struct {
+	Timezone string `json:"timezone"`
+}
@@ -1,10 +1,36 @@
 package entities
 
+import "time"
+
 type UserService struct {
-	Id           string
-	UserId       string
-	AccessToken  string
-	RefreshToken string
-	ExpiryDate   string
-	ServiceId    string
+	Id            string
+	UserId        string
+	AccessToken   string
+	RefreshToken  string
+	ExpiryDate    string
+	ServiceId     string
+	NeedsReauth   bool
+	Scopes        string
+	LastSuccessAt time.Time
+	LastError     string
+	LastErrorAt   time.Time
+}
+
+// Outcome of the disconnection of a service, the revocation error is empty when the service does not revoke tokens
+type ServiceDisconnection struct {
+	DeactivatedWorkflows int    `json:"deactivatedworkflows"`
+	TokenRevoked         bool   `json:"tokenrevoked"`
+	RevocationError      string `json:"revocationerror,omitempty"`
+}
+
+// Health of the connection of a user to a service, the dates are omitted when the event never happened
+type ServiceConnection struct {
+	Service                 string     `json:"service"`
+	Linked                  bool       `json:"linked"`
+	Scopes                  []string   `json:"scopes"`
+	ExpiresAt               *time.Time `json:"expiresat,omitempty"`
+	LastSuccessAt           *time.Time `json:"lastsuccessat,omitempty"`
+	LastError               string     `json:"lasterror"`
+	LastErrorAt             *time.Time `json:"lasterrorat,omitempty"`
+	ReauthorizationRequired bool       `json:"reauthorizationrequired"`
 }
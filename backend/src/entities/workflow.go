@@ -1,5 +1,10 @@
 package entities
 
+// What a scheduled workflow does with the ticks missed while the server was down
+const CatchUpPolicyOnce = "once"
+const CatchUpPolicyAll = "all"
+const CatchUpPolicySkip = "skip"
+
 type Workflow struct {
 	Id            string                 `json:"id"`
 	Name          string                 `json:"name"`
@@ -11,6 +16,39 @@ type Workflow struct {
 	ActionParam   map[string]interface{} `json:"actionparam"`
 	ReactionParam map[string]interface{} `json:"reactionparam"`
 	ActionData    map[string]interface{} `json:"actiondata"`
+	Filter        string                 `json:"filter"`
+	CatchUpPolicy string                 `json:"catchuppolicy"`
+	Reactions     []WorkflowReaction     `json:"reactions"`
+
+	ConsecutiveFailures int    `json:"consecutivefailures"`
+	SuspendedReason     string `json:"suspendedreason"`
+
+	PollInterval int    `json:"pollinterval"`
+	NextCheckAt  string `json:"nextcheckat"`
+
+	ConsecutivePollFailures int `json:"consecutivepollfailures"`
+}
+
+// A workflow which does not exist or belongs to another user
+type WorkflowNotFoundError struct{}
+
+func (self WorkflowNotFoundError) Error() string {
+	return "Workflow not found"
+}
+
+type WorkflowReaction struct {
+	Id              string                 `json:"id"`
+	WorkflowId      string                 `json:"workflowid"`
+	Position        int                    `json:"position"`
+	ReactionId      string                 `json:"reactionid"`
+	ReactionParam   map[string]interface{} `json:"reactionparam"`
+	ContinueOnError bool                   `json:"continueonerror"`
+}
+
+type NewWorkflowReaction struct {
+	ReactionId      string                 `json:"reactionid"`
+	ReactionParam   map[string]interface{} `json:"reactionparam"`
+	ContinueOnError bool                   `json:"continueonerror"`
 }
 
 type NewWorkflow struct {
@@ -20,6 +58,10 @@ type NewWorkflow struct {
 	ActionParam   map[string]interface{} `json:"actionparam"`
 	ReactionParam map[string]interface{} `json:"reactionparam"`
 	ActionData    map[string]interface{} `json:"actiondata"`
+	Filter        string                 `json:"filter"`
+	CatchUpPolicy string                 `json:"catchuppolicy"`
+	PollInterval  int                    `json:"pollinterval"`
+	Reactions     []NewWorkflowReaction  `json:"reactions"`
 }
 
 type UpdatedWorkflow struct {
@@ -29,4 +71,16 @@ type UpdatedWorkflow struct {
 	IsActivated   *bool                   `json:"isactivated"`
 	ActionParam   *map[string]interface{} `json:"actionparam"`
 	ReactionParam *map[string]interface{} `json:"reactionparam"`
+	Filter        *string                 `json:"filter"`
+	CatchUpPolicy *string                 `json:"catchuppolicy"`
+	PollInterval  *int                    `json:"pollinterval"`
+	Reactions     *[]NewWorkflowReaction  `json:"reactions"`
+}
+
+type WorkflowValidationError struct {
+	Message string
+}
+
+func (self WorkflowValidationError) Error() string {
+	return self.Message
 }
@@ -0,0 +1,31 @@
+package entities
+
+const WorkflowRunSuccess = "success"
+const WorkflowRunFailure = "failure"
+
+type WorkflowRun struct {
+	Id            string                 `json:"id"`
+	WorkflowId    string                 `json:"workflowid"`
+	ReactionId    string                 `json:"reactionid"`
+	TriggeredAt   string                 `json:"triggeredat"`
+	ActionPayload map[string]interface{} `json:"actionpayload"`
+	Status        string                 `json:"status"`
+	HttpStatus    int                    `json:"httpstatus"`
+	ErrorMessage  string                 `json:"errormessage"`
+}
+
+type WorkflowRunsPage struct {
+	Runs     []WorkflowRun `json:"runs"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"pagesize"`
+}
+
+type ReactionResult struct {
+	ReactionId   string `json:"reactionid"`
+	ReactionName string `json:"reactionname"`
+	ServiceName  string `json:"servicename"`
+	Status       string `json:"status"`
+	HttpStatus   int    `json:"httpstatus"`
+	ErrorMessage string `json:"errormessage"`
+}
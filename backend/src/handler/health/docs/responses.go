@@ -0,0 +1,17 @@
+package docs_health
+
+import "backend/src/entities"
+
+// Get Health Responses
+type HealthGetHealthSuccessResponse struct {
+	Health entities.Health
+}
+
+// Get Readiness Responses
+type HealthGetReadinessSuccessResponse struct {
+	Health entities.Health
+}
+
+type HealthGetReadinessServiceUnavailableResponse struct {
+	Health entities.Health
+}
@@ -0,0 +1,58 @@
+package health_handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	_ "backend/src/handler/health/docs"
+	"backend/src/service"
+)
+
+type HealthHandler struct {
+	HealthService service.HealthService
+}
+
+func NewHealthHandler(HealthService service.HealthService, router *gin.Engine) *HealthHandler {
+	handler := &HealthHandler{HealthService: HealthService}
+	handler.setupRoutes(router)
+	return handler
+}
+
+func (self *HealthHandler) setupRoutes(router *gin.Engine) {
+	self.publicRoutes(router)
+}
+
+func (self *HealthHandler) publicRoutes(router *gin.Engine) {
+	router.GET("/healthz", self.getHealth)
+	router.GET("/readyz", self.getReadiness)
+}
+
+// @Summary		Health
+// @Description	Get the state of the database connection and of the scheduler, answers as long as the server is alive
+// @Tags			Health
+// @Produce		json
+// @Success		200		{object}	docs_health.HealthGetHealthSuccessResponse
+// @Router			/healthz [get]
+func (self *HealthHandler) getHealth(context *gin.Context) {
+	health := self.HealthService.GetHealth(context.Request.Context())
+
+	context.IndentedJSON(http.StatusOK, health)
+}
+
+// @Summary		Readiness
+// @Description	Check if the server can receive requests, it is not ready once its database is down or it is shutting down
+// @Tags			Health
+// @Produce		json
+// @Success		200		{object}	docs_health.HealthGetReadinessSuccessResponse
+// @Failure		503		{object}	docs_health.HealthGetReadinessServiceUnavailableResponse
+// @Router			/readyz [get]
+func (self *HealthHandler) getReadiness(context *gin.Context) {
+	health := self.HealthService.GetHealth(context.Request.Context())
+	if !health.IsReady {
+		context.IndentedJSON(http.StatusServiceUnavailable, health)
+		return
+	}
+
+	context.IndentedJSON(http.StatusOK, health)
+}
@@ -0,0 +1,80 @@
+package health_handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+type MockHealthService struct {
+	mock.Mock
+}
+
+func (m *MockHealthService) GetHealth(ctx context.Context) entities.Health {
+	args := m.Called(ctx)
+	return args.Get(0).(entities.Health)
+}
+
+func (m *MockHealthService) MarkShuttingDown() {
+	m.Called()
+}
+
+func newHealthRouter(health entities.Health) *gin.Engine {
+	mockHealth := new(MockHealthService)
+	mockHealth.On("GetHealth", mock.Anything).
+		Return(health)
+
+	router := gin.Default()
+	NewHealthHandler(mockHealth, router)
+	return router
+}
+
+func TestGetHealth(test *testing.T) {
+	test.Run("Database Down", func(test *testing.T) {
+		router := newHealthRouter(entities.Health{Database: entities.DatabaseDown})
+
+		req, _ := http.NewRequest("GET", "/healthz", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+		require.JSONEq(test, `{
+			"isready": false,
+			"isshuttingdown": false,
+			"database": "down",
+			"scheduler": {"isleader": false, "lastpolls": null}
+		}`, w.Body.String())
+	})
+}
+
+func TestGetReadiness(test *testing.T) {
+	test.Run("Ready", func(test *testing.T) {
+		router := newHealthRouter(entities.Health{IsReady: true, Database: entities.DatabaseUp})
+
+		req, _ := http.NewRequest("GET", "/readyz", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+	})
+
+	test.Run("Shutting Down", func(test *testing.T) {
+		router := newHealthRouter(entities.Health{IsShuttingDown: true, Database: entities.DatabaseUp})
+
+		req, _ := http.NewRequest("GET", "/readyz", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusServiceUnavailable, w.Code)
+	})
+}
@@ -147,3 +147,39 @@ func TestVerifyJWTCookie(test *testing.T) {
 		require.JSONEq(test, `{"error": "Invalid token"}`, w.Body.String())
 	})
 }
+
+func TestOAuthSession(test *testing.T) {
+	test.Run("New Session", func(test *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/authentication", nil)
+
+		session, err := OAuthSession(c)
+
+		require.NoError(test, err)
+		require.NotEmpty(test, session)
+		require.Contains(test, w.Header().Get("Set-Cookie"), oauthSessionCookie+"="+session)
+	})
+
+	test.Run("Existing Session", func(test *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/authentication", nil)
+		c.Request.AddCookie(&http.Cookie{Name: oauthSessionCookie, Value: "session"})
+
+		session, err := OAuthSession(c)
+
+		require.NoError(test, err)
+		require.Equal(test, "session", session)
+		require.Equal(test, "session", FindOAuthSession(c))
+	})
+
+	test.Run("Mobile Session", func(test *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/login-callback", nil)
+		c.Request.Header.Set(oauthSessionHeader, "mobile")
+
+		require.Equal(test, "mobile", FindOAuthSession(c))
+	})
+}
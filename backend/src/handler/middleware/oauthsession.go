@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const oauthSessionCookie = "OAuthSession"
+const oauthSessionMaxAge = 3600
+
+const invalidOAuthStateMessage = "Invalid OAuth state"
+const expiredOAuthStateMessage = "OAuth state expired or already used"
+
+// A mobile client has no cookie jar, it keeps the session returned with the authorization URL and sends it back in this header
+const oauthSessionHeader = "X-OAuth-Session"
+
+func NewOAuthSession() (string, error) {
+	sessionBytes := make([]byte, 32)
+	_, err := rand.Read(sessionBytes)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sessionBytes), nil
+}
+
+// Returns the OAuth session of the client, the states of its authorization URLs are bound to it
+// A client without session is given a new one in an HTTP only cookie
+func OAuthSession(context *gin.Context) (string, error) {
+	session, err := context.Cookie(oauthSessionCookie)
+	if err != nil || session == "" {
+		session, err = NewOAuthSession()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	context.SetSameSite(http.SameSiteNoneMode)
+	context.SetCookie(oauthSessionCookie, session, oauthSessionMaxAge, "", "", true, true)
+	return session, nil
+}
+
+// The OAuth session sent with a callback, from the header of a mobile client or the cookie of a browser, empty when the client has none
+func FindOAuthSession(context *gin.Context) string {
+	session := context.GetHeader(oauthSessionHeader)
+	if session != "" {
+		return session
+	}
+	session, _ = context.Cookie(oauthSessionCookie)
+	return session
+}
+
+func IsOAuthStateError(err error) bool {
+	return err.Error() == invalidOAuthStateMessage || err.Error() == expiredOAuthStateMessage
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"os"
 
@@ -10,6 +12,7 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	about_handler "backend/src/handler/about"
+	health_handler "backend/src/handler/health"
 	service_handler "backend/src/handler/service"
 	user_handler "backend/src/handler/user"
 	user_service_handler "backend/src/handler/userservice"
@@ -32,40 +35,50 @@ type WorkflowHandler interface {
 type AboutHandler interface {
 }
 
+type HealthHandler interface {
+}
+
 type Handler struct {
 	UserHandler        UserHandler
 	ServiceHandler     ServiceHandler
 	UserServiceHandler UserServiceHandler
 	WorkflowHandler    WorkflowHandler
 	AboutHandler       AboutHandler
-	router             *gin.Engine
+	HealthHandler      HealthHandler
+	server             *http.Server
 }
 
 func New(services *service.Service) *Handler {
 	router := gin.Default()
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	corsAllow := cors.New(cors.Options{
+		AllowedOrigins:   []string{"http://localhost:8081"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+	})
+
 	return &Handler{
 		UserHandler:        user_handler.NewUserHandler(services.UserService, router),
 		UserServiceHandler: user_service_handler.NewUserServiceHandler(services.UserServiceService, router),
 		ServiceHandler:     service_handler.NewServiceHandler(services.ServiceService, services.UserService, router),
 		WorkflowHandler:    workflow_handler.NewWorkflowHandler(services.WorkflowService, services.UserService, router),
 		AboutHandler:       about_handler.NewAboutHandler(services.AboutService, router),
-		router:             router,
+		HealthHandler:      health_handler.NewHealthHandler(services.HealthService, router),
+		server:             &http.Server{Addr: ":8080", Handler: corsAllow.Handler(router)},
 	}
 }
 
 func (self *Handler) Run() error {
-	corsAllow := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:8081"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Content-Type"},
-		AllowCredentials: true,
-	})
-
-	handler := corsAllow.Handler(self.router)
-	err := http.ListenAndServeTLS(":8080", os.Getenv("CERTIFICATE"), os.Getenv("KEY"), handler)
-	if err != nil {
+	err := self.server.ListenAndServeTLS(os.Getenv("CERTIFICATE"), os.Getenv("KEY"))
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 	return nil
 }
+
+// Stops accepting connections and waits for the requests in flight until ctx is done
+func (self *Handler) Shutdown(ctx context.Context) error {
+	return self.server.Shutdown(ctx)
+}
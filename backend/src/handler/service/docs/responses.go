@@ -37,7 +37,8 @@ type ServiceInternalServerErrorResponse struct {
 
 // OAuth2 Responses
 type ServiceOAuth2SuccessResponse struct {
-	Msg string `json:"auth-url"example:"url-example.com"`
+	Msg     string `json:"auth-url"example:"url-example.com"`
+	Session string `json:"session,omitempty"example:"session-example"`
 }
 
 type ServiceOAuth2BadRequestResponse struct {
@@ -57,7 +57,7 @@ func (self *ServiceHandler) privateRoutes(router *gin.Engine) {
 }
 
 // @Summary		Service OAuth2
-// @Description	Get Service OAuth2 URL
+// @Description	Get Service OAuth2 URL, its state is bound to the OAuthSession cookie and can be used once within 10 minutes. A mobile app is given the session instead, to send back in the X-OAuth-Session header of its callback
 // @Tags			Authentication
 // @Produce		json
 // @Param        service  query      string  true  "Service name (Github, Spotify, Discord..)"
@@ -65,6 +65,7 @@ func (self *ServiceHandler) privateRoutes(router *gin.Engine) {
 // @Param        apptype  query      string  true  "App type (web or mobile)"
 // @Success		200		{object}	docs_service.ServiceOAuth2SuccessResponse
 // @Failure		400		{object}	docs_service.ServiceOAuth2BadRequestResponse
+// @Failure		500		{object}	docs_service.ServiceInternalServerErrorResponse
 // @Router			/authentication [get]
 func (self *ServiceHandler) oauth2Service(context *gin.Context) {
 	serviceName := context.Query("service")
@@ -84,7 +85,21 @@ func (self *ServiceHandler) oauth2Service(context *gin.Context) {
 		return
 	}
 
-	authUrl, err := self.ServiceService.OAuth2Service(serviceName, callbackType, appType)
+	var session string
+	var err error
+	if appType == "mobile" {
+		session, err = middleware.NewOAuthSession()
+	} else {
+		session, err = middleware.OAuthSession(context)
+	}
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{
+			"error": internalServerErrorMessage,
+		})
+		return
+	}
+
+	authUrl, err := self.ServiceService.OAuth2Service(serviceName, callbackType, appType, session)
 	if err != nil {
 		context.IndentedJSON(http.StatusBadRequest, gin.H{
 			"error": unknownServiceMessage,
@@ -92,6 +107,13 @@ func (self *ServiceHandler) oauth2Service(context *gin.Context) {
 		return
 	}
 
+	if appType == "mobile" {
+		context.IndentedJSON(http.StatusOK, gin.H{
+			"auth-url": authUrl,
+			"session":  session,
+		})
+		return
+	}
 	context.IndentedJSON(http.StatusOK, gin.H{
 		"auth-url": authUrl,
 	})
@@ -1,6 +1,7 @@
 package service_handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -17,14 +18,20 @@ import (
 
 	"backend/src/entities"
 	"backend/src/handler/middleware"
+	"backend/src/service"
 )
 
 type MockServiceService struct {
 	mock.Mock
 }
 
-func (m *MockServiceService) OAuth2Service(serviceName, callbackType, appType string) (string, error) {
-	args := m.Called(serviceName, callbackType, appType)
+func (m *MockServiceService) OAuth2Service(serviceName, callbackType, appType, session string) (string, error) {
+	args := m.Called(serviceName, callbackType, appType, session)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockServiceService) ConsumeOAuthState(state, session, serviceName, callbackType, appType string) (string, error) {
+	args := m.Called(state, session, serviceName, callbackType, appType)
 	return args.String(0), args.Error(1)
 }
 
@@ -68,43 +75,35 @@ func (m *MockServiceService) RetrieveReactionsFromService(serviceName string) ([
 	return args.Get(0).([]entities.Reaction), args.Error(1)
 }
 
-func (m *MockServiceService) GetGoogleRefreshTokenRequest(refreshToken string) (*http.Request, error) {
+func (m *MockServiceService) FindConnector(serviceName string) (service.Connector, error) {
 	return nil, nil
 }
 
-func (m *MockServiceService) GetSpotifyRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
+func (m *MockServiceService) RetrieveConnectors() []service.Connector {
+	return nil
 }
 
-func (m *MockServiceService) GetDiscordRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
+func (m *MockServiceService) SeedServices() error {
+	return nil
 }
 
-func (m *MockServiceService) GetRedditRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
-}
-
-func (m *MockServiceService) GetAsanaRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
-}
-
-func (m *MockServiceService) GetDropboxRefreshTokenRequest(refreshToken string) (*http.Request, error) {
+func (m *MockServiceService) ExecuteRequest(request *http.Request) (*http.Response, error) {
 	return nil, nil
 }
 
-func (m *MockServiceService) GetGitlabRefreshTokenRequest(refreshToken string) (*http.Request, error) {
+func (m *MockServiceService) ExecuteRequestWithClient(client *http.Client, request *http.Request) (*http.Response, error) {
 	return nil, nil
 }
 
-func (m *MockServiceService) ExecuteRequest(request *http.Request) (*http.Response, error) {
+func (m *MockServiceService) ExecuteApiRequest(url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
 	return nil, nil
 }
 
-func (m *MockServiceService) ExecuteApiRequest(url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
+func (m *MockServiceService) ExecuteApiRequestWithContext(ctx context.Context, url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
 	return nil, nil
 }
 
-func (m *MockServiceService) GetResultTokenFromCode(code, serviceName, callbackType, appType string) (entities.ResultToken, error) {
+func (m *MockServiceService) GetResultTokenFromCode(code, serviceName, callbackType, appType, codeVerifier string) (entities.ResultToken, error) {
 	var test entities.ResultToken
 	return test, nil
 }
@@ -114,12 +113,7 @@ func (m *MockServiceService) GetUserInfoFromService(accessToken, serviceName str
 	return test, nil
 }
 
-func (m *MockServiceService) RequestToTimeApi() (entities.TimeResponse, error) {
-	var test entities.TimeResponse
-	return test, nil
-}
-
-func (m *MockServiceService) RequestGithubUserRepositories(accessToken string) ([]entities.GithubRepository, error) {
+func (m *MockServiceService) RequestGithubUserRepositories(ctx context.Context, accessToken string) ([]entities.GithubRepository, error) {
 	return nil, nil
 }
 
@@ -174,7 +168,7 @@ func TestOAuth2Service(test *testing.T) {
 	router.GET("/authentication", handler.oauth2Service)
 
 	test.Run("Successful", func(test *testing.T) {
-		mockService.On("OAuth2Service", "Github", "service", "web").
+		mockService.On("OAuth2Service", "Github", "service", "web", mock.Anything).
 			Return("https://localhost:8080/authentication/Github/service/web", nil)
 
 		req, _ := http.NewRequest("GET", "/authentication?service=Github&callbacktype=service&apptype=web", nil)
@@ -183,8 +177,27 @@ func TestOAuth2Service(test *testing.T) {
 		router.ServeHTTP(w, req)
 
 		require.Equal(test, http.StatusOK, w.Code)
+		require.Contains(test, w.Header().Get("Set-Cookie"), "OAuthSession=")
+		require.Contains(test, w.Header().Get("Set-Cookie"), "HttpOnly")
+
+		mockService.AssertCalled(test, "OAuth2Service", "Github", "service", "web", mock.Anything)
+	})
 
-		mockService.AssertCalled(test, "OAuth2Service", "Github", "service", "web")
+	test.Run("Mobile session", func(test *testing.T) {
+		var session string
+		mockService.On("OAuth2Service", "Github", "login", "mobile", mock.Anything).
+			Run(func(args mock.Arguments) { session = args.String(3) }).
+			Return("https://localhost:8080/authentication/Github/login/mobile", nil)
+
+		req, _ := http.NewRequest("GET", "/authentication?service=Github&callbacktype=login&apptype=mobile", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+		require.NotEmpty(test, session)
+		require.JSONEq(test, `{"auth-url": "https://localhost:8080/authentication/Github/login/mobile", "session": "`+session+`"}`, w.Body.String())
+		require.Empty(test, w.Header().Get("Set-Cookie"))
 	})
 
 	test.Run("Invalid app type", func(test *testing.T) {
@@ -208,7 +221,7 @@ func TestOAuth2Service(test *testing.T) {
 	})
 
 	test.Run("Service error", func(test *testing.T) {
-		mockService.On("OAuth2Service", "false", "service", "web").
+		mockService.On("OAuth2Service", "false", "service", "web", mock.Anything).
 			Return("", fmt.Errorf("service error"))
 
 		req, _ := http.NewRequest("GET", "/authentication?service=false&callbacktype=service&apptype=web", nil)
@@ -219,7 +232,7 @@ func TestOAuth2Service(test *testing.T) {
 		require.Equal(test, http.StatusBadRequest, w.Code)
 		require.JSONEq(test, `{"error": "Unknown service"}`, w.Body.String())
 
-		mockService.AssertCalled(test, "OAuth2Service", "false", "service", "web")
+		mockService.AssertCalled(test, "OAuth2Service", "false", "service", "web", mock.Anything)
 	})
 }
 
@@ -47,7 +47,11 @@ type UserLoginCallbackSuccessResponse struct {
 }
 
 type UserLoginCallbackBadRequestResponse struct {
-	Msg string `json:"error"example:"Invalid request body-Invalid code authorization"`
+	Msg string `json:"error"example:"Invalid request body-Invalid code authorization-Invalid state"`
+}
+
+type UserLoginCallbackForbiddenResponse struct {
+	Msg string `json:"error"example:"Invalid OAuth state-OAuth state expired or already used"`
 }
 
 type UserLoginCallbackInternalServerErrorResponse struct {
@@ -97,6 +101,23 @@ type UserModifyPasswordBadRequestResponse struct {
 	Msg string `json:"error"example:"Invalid request body-Could not find requested user"`
 }
 
+// Modify Timezone Responses
+type UserModifyTimezoneSuccessResponse struct {
+	Msg string `json:"success"example:"Timezone modified"`
+}
+
+type UserModifyTimezoneBadRequestResponse struct {
+	Msg string `json:"error"example:"Invalid request body-Invalid timezone"`
+}
+
+type UserModifyTimezoneUnauthorizedResponse struct {
+	Msg string `json:"error"example:"Email not found in token-Email is not a valid string-Connection type not found in token-Connection type is not a valid string"`
+}
+
+type UserModifyTimezoneInternalServerErrorResponse struct {
+	Msg string `json:"error"example:"Could not modify the timezone"`
+}
+
 // Delete Account Responses
 type UserDeleteAccountSuccessResponse struct {
 	Msg string `json:"success"example:"Account deleted"`
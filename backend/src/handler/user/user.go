@@ -42,6 +42,7 @@ func (self *UserHandler) privateRoutes(router *gin.Engine) {
 	{
 		user.GET("", self.getUser)
 		user.PUT("/modify-password", self.modifyPassword)
+		user.PUT("/timezone", self.modifyTimezone)
 		user.DELETE("", self.deleteAccount)
 	}
 }
@@ -138,14 +139,18 @@ func (self *UserHandler) loginAuthentication(context *gin.Context) {
 // @Tags         Callbacks
 // @Produce      json
 // @Param        code     query     string  true  "Authorization code given by the service"
+// @Param        state    query     string  true  "State given by the service"
+// @Param        X-OAuth-Session  header  string  false  "OAuth session given to a mobile app with the authorization URL"
 // @Param		callback-informations	body		entities.CallbackInformations true	"Callback informations"
 // @Failure		200		{object}	docs_user.UserLoginCallbackSuccessResponse
 // @Failure		400		{object}	docs_user.UserLoginCallbackBadRequestResponse
+// @Failure		403		{object}	docs_user.UserLoginCallbackForbiddenResponse
 // @Failure		500		{object}	docs_user.UserLoginCallbackInternalServerErrorResponse
 // @Router       /login-callback [post]
 func (self *UserHandler) loginCallback(context *gin.Context) {
 	var callbackInformations entities.CallbackInformations
 	code := context.Query("code")
+	state := context.Query("state")
 
 	errorBody := context.ShouldBindJSON(&callbackInformations)
 	if errorBody != nil {
@@ -169,7 +174,20 @@ func (self *UserHandler) loginCallback(context *gin.Context) {
 		return
 	}
 
-	token, err := self.UserService.LoginWithService(code, callbackInformations.Service, callbackInformations.AppType)
+	if state == "" {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid state",
+		})
+		return
+	}
+
+	token, err := self.UserService.LoginWithService(code, state, middleware.FindOAuthSession(context), callbackInformations.Service, callbackInformations.AppType)
+	if err != nil && middleware.IsOAuthStateError(err) {
+		context.IndentedJSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		context.IndentedJSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to connect with requested service",
@@ -280,6 +298,48 @@ func (self *UserHandler) modifyPassword(context *gin.Context) {
 	}
 }
 
+// @Summary		Modify Timezone
+// @Description	Modify the timezone, an IANA name such as "America/New_York", in which the time triggers of the user are evaluated
+// @Tags			Users
+// @Accept			json
+// @Produce		json
+// @Param			user	body		entities.UserTimezone true	"New timezone of the user"
+// @Success		200		{object}	docs_user.UserModifyTimezoneSuccessResponse
+// @Failure		400		{object}	docs_user.UserModifyTimezoneBadRequestResponse
+// @Failure		401		{object}	docs_user.UserModifyTimezoneUnauthorizedResponse
+// @Failure		500		{object}	docs_user.UserModifyTimezoneInternalServerErrorResponse
+// @Router			/user/timezone [put]
+func (self *UserHandler) modifyTimezone(context *gin.Context) {
+	var newTimezone entities.UserTimezone
+	email := context.GetString("email")
+	connectionType := context.GetString("connectionType")
+
+	errorBody := context.ShouldBindJSON(&newTimezone)
+	if errorBody != nil {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{
+			"error": invalidRequestBodyMessage,
+		})
+		return
+	}
+
+	errorModifyTimezone := self.UserService.ModifyTimezone(email, connectionType, newTimezone.Timezone)
+	if errorModifyTimezone != nil {
+		if errorModifyTimezone.Error() == "Invalid timezone" {
+			context.IndentedJSON(http.StatusBadRequest, gin.H{
+				"error": errorModifyTimezone.Error(),
+			})
+		} else {
+			context.IndentedJSON(http.StatusInternalServerError, gin.H{
+				"error": errorModifyTimezone.Error(),
+			})
+		}
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{
+		"success": "Timezone modified",
+	})
+}
+
 // @Summary		Delete Account
 // @Description	Delete the user account
 // @Tags			Users
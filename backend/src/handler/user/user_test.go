@@ -34,8 +34,8 @@ func (m *MockUserService) LoginAuthentication(userEmail, userPassword, userConne
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUserService) LoginWithService(code, serviceName, appType string) (string, error) {
-	args := m.Called(code, serviceName, appType)
+func (m *MockUserService) LoginWithService(code, state, session, serviceName, appType string) (string, error) {
+	args := m.Called(code, state, session, serviceName, appType)
 	return args.String(0), args.Error(1)
 }
 
@@ -49,6 +49,11 @@ func (m *MockUserService) ModifyPassword(userEmail, userConnectionType string, n
 	return args.Error(0)
 }
 
+func (m *MockUserService) ModifyTimezone(userEmail, userConnectionType, timezone string) error {
+	args := m.Called(userEmail, userConnectionType, timezone)
+	return args.Error(0)
+}
+
 func (m *MockUserService) DeleteAccount(userEmail, userConnectionType string) error {
 	args := m.Called(userEmail, userConnectionType)
 	return args.Error(0)
@@ -268,7 +273,7 @@ func TestLoginCallback(test *testing.T) {
 	router.POST("/login-callback", handler.loginCallback)
 
 	test.Run("Successful", func(test *testing.T) {
-		mockUserService.On("LoginWithService", "code", "github", "web").
+		mockUserService.On("LoginWithService", "code", "state", "session", "github", "web").
 			Return("token", nil)
 
 		body := `{
@@ -276,7 +281,8 @@ func TestLoginCallback(test *testing.T) {
 			"apptype": "web"
 		}`
 
-		req, _ := http.NewRequest("POST", "/login-callback?code=code", strings.NewReader(body))
+		req, _ := http.NewRequest("POST", "/login-callback?code=code&state=state", strings.NewReader(body))
+		req.AddCookie(&http.Cookie{Name: "OAuthSession", Value: "session"})
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -285,6 +291,25 @@ func TestLoginCallback(test *testing.T) {
 		require.JSONEq(test, `{"success": "Connection successful"}`, w.Body.String())
 	})
 
+	test.Run("Mobile session", func(test *testing.T) {
+		mockUserService.On("LoginWithService", "code", "state", "mobile-session", "github", "mobile").
+			Return("token", nil).Once()
+
+		body := `{
+			"service": "github",
+			"apptype": "mobile"
+		}`
+
+		req, _ := http.NewRequest("POST", "/login-callback?code=code&state=state", strings.NewReader(body))
+		req.Header.Set("X-OAuth-Session", "mobile-session")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+		mockUserService.AssertCalled(test, "LoginWithService", "code", "state", "mobile-session", "github", "mobile")
+	})
+
 	test.Run("Invalid code authorization", func(t *testing.T) {
 		body := `{
 			"service": "Github",
@@ -315,8 +340,42 @@ func TestLoginCallback(test *testing.T) {
 		require.JSONEq(test, `{"error": "Invalid app type"}`, w.Body.String())
 	})
 
+	test.Run("Invalid state", func(test *testing.T) {
+		body := `{
+			"service": "Github",
+			"apptype": "web"
+		}`
+
+		req, _ := http.NewRequest("POST", "/login-callback?code=code", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Invalid state"}`, w.Body.String())
+	})
+
+	test.Run("Forbidden state", func(test *testing.T) {
+		mockUserService.On("LoginWithService", "code", "replayed", "session", "Github", "web").
+			Return("", fmt.Errorf("OAuth state expired or already used"))
+
+		body := `{
+			"service": "Github",
+			"apptype": "web"
+		}`
+
+		req, _ := http.NewRequest("POST", "/login-callback?code=code&state=replayed", strings.NewReader(body))
+		req.AddCookie(&http.Cookie{Name: "OAuthSession", Value: "session"})
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusForbidden, w.Code)
+		require.JSONEq(test, `{"error": "OAuth state expired or already used"}`, w.Body.String())
+	})
+
 	test.Run("Failed to connect", func(test *testing.T) {
-		mockUserService.On("LoginWithService", "code", "Github", "web").
+		mockUserService.On("LoginWithService", "code", "state", "", "Github", "web").
 			Return("", fmt.Errorf("service error"))
 
 		body := `{
@@ -324,7 +383,7 @@ func TestLoginCallback(test *testing.T) {
 			"apptype": "web"
 		}`
 
-		req, _ := http.NewRequest("POST", "/login-callback?code=code", strings.NewReader(body))
+		req, _ := http.NewRequest("POST", "/login-callback?code=code&state=state", strings.NewReader(body))
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -517,6 +576,72 @@ func TestModifyPassword(test *testing.T) {
 	})
 }
 
+func TestModifyTimezone(test *testing.T) {
+	handler, router, mockUserService := createMockAndRoute(false)
+
+	token := createToken(test)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("email", "email")
+		c.Set("connectionType", "basic")
+	})
+	router.PUT("/user/timezone", handler.modifyTimezone)
+
+	test.Run("Successful", func(test *testing.T) {
+		mockUserService.On("ModifyTimezone", "email", "basic", "America/New_York").Return(nil).Once()
+
+		body := `{"timezone": "America/New_York"}`
+
+		req := requestForProtected("PUT", "/user/timezone", token, strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+		require.JSONEq(test, `{"success": "Timezone modified"}`, w.Body.String())
+	})
+
+	test.Run("Invalid Timezone", func(test *testing.T) {
+		mockUserService.On("ModifyTimezone", "email", "basic", "Europe/Atlantis").
+			Return(errors.New("Invalid timezone")).Once()
+
+		body := `{"timezone": "Europe/Atlantis"}`
+
+		req := requestForProtected("PUT", "/user/timezone", token, strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Invalid timezone"}`, w.Body.String())
+	})
+
+	test.Run("Fail Modify Timezone", func(test *testing.T) {
+		mockUserService.On("ModifyTimezone", "email", "basic", "Asia/Tokyo").
+			Return(errors.New("Could not modify the timezone")).Once()
+
+		body := `{"timezone": "Asia/Tokyo"}`
+
+		req := requestForProtected("PUT", "/user/timezone", token, strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusInternalServerError, w.Code)
+		require.JSONEq(test, `{"error": "Could not modify the timezone"}`, w.Body.String())
+	})
+
+	test.Run("Invalid Body", func(test *testing.T) {
+		req := requestForProtected("PUT", "/user/timezone", token, strings.NewReader("{"))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Invalid request body"}`, w.Body.String())
+	})
+}
+
 func TestDeleteAccount(test *testing.T) {
 	handler, router, mockUserService := createMockAndRoute(false)
 
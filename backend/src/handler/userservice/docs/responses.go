@@ -18,7 +18,11 @@ type UserServiceServiceCallbackSuccessResponse struct {
 }
 
 type UserServiceServiceCallbackBadRequestResponse struct {
-	Msg string `json:"error"example:"Invalid request body-Invalid code authorization-Invalid app type"`
+	Msg string `json:"error"example:"Invalid request body-Invalid code authorization-Invalid app type-Invalid state"`
+}
+
+type UserServiceServiceCallbackForbiddenResponse struct {
+	Msg string `json:"error"example:"Invalid OAuth state-OAuth state expired or already used"`
 }
 
 type UserServiceServiceCallbackInternalServerErrorResponse struct {
@@ -34,6 +38,39 @@ type UserServiceGetUserServiceAuthenticationStatusBadRequestResponse struct {
 	Msg string `json:"error"example:"Could not find requested user-Unknown service"`
 }
 
+// Get Service Connections Responses
+type UserServiceGetServiceConnectionsSuccessResponse struct {
+	Connections []entities.ServiceConnection `json:"connections"`
+}
+
+type UserServiceGetServiceConnectionsBadRequestResponse struct {
+	Msg string `json:"error"example:"Could not find requested user"`
+}
+
+type UserServiceGetServiceConnectionsInternalServerErrorResponse struct {
+	Msg string `json:"error"example:"Could not retrieve the service connections"`
+}
+
+// Disconnect Service Responses
+type UserServiceDisconnectServiceSuccessResponse struct {
+	Msg                  string `json:"success"example:"Service disconnected"`
+	DeactivatedWorkflows int    `json:"deactivatedworkflows"example:"2"`
+	TokenRevoked         bool   `json:"tokenrevoked"example:"false"`
+	RevocationError      string `json:"revocationerror"example:"API call failed (HTTP 400)"`
+}
+
+type UserServiceDisconnectServiceBadRequestResponse struct {
+	Msg string `json:"error"example:"Could not find requested user-Unknown service"`
+}
+
+type UserServiceDisconnectServiceNotFoundResponse struct {
+	Msg string `json:"error"example:"Service not connected"`
+}
+
+type UserServiceDisconnectServiceInternalServerErrorResponse struct {
+	Msg string `json:"error"example:"Could not disconnect the service"`
+}
+
 // Github Get User Repositories
 type UserServiceGetGithubUserRepositoriesSuccessResponse struct {
 	Repositories []entities.GithubRepository
@@ -30,6 +30,8 @@ func (self *UserServiceHandler) privateRoutes(router *gin.Engine) {
 	private := router.Group("", middleware.VerifyJWTCookie, middleware.VerifyEmailFromContext, middleware.VerifyConnectionTypeFromContext)
 	private.POST("/service-callback", self.serviceCallback)
 	private.GET("/service-authentication-status", self.getUserServiceAuthenticationStatus)
+	private.GET("/services/connections", self.getServiceConnections)
+	private.DELETE("/services/:service/connection", self.disconnectService)
 	private.GET("/github/user/repositories", self.getGithubUserRepositories)
 	private.GET("/gitlab/user/projects", self.getGitlabUserProjects)
 	private.GET("/discord/user/servers", self.getDiscordUserServers)
@@ -47,14 +49,18 @@ func (self *UserServiceHandler) privateRoutes(router *gin.Engine) {
 // @Tags         Callbacks
 // @Produce      json
 // @Param        code     query     string  true  "Authorization code given by the service"
+// @Param        state    query     string  true  "State given by the service"
+// @Param        X-OAuth-Session  header  string  false  "OAuth session given to a mobile app with the authorization URL"
 // @Param		callback-informations	body		entities.CallbackInformations true	"Callback informations"
 // @Failure		200		{object}	docs_userservice.UserServiceServiceCallbackSuccessResponse
 // @Failure		400		{object}	docs_userservice.UserServiceServiceCallbackBadRequestResponse
+// @Failure		403		{object}	docs_userservice.UserServiceServiceCallbackForbiddenResponse
 // @Failure		500		{object}	docs_userservice.UserServiceServiceCallbackInternalServerErrorResponse
 // @Router       /service-callback [post]
 func (self *UserServiceHandler) serviceCallback(context *gin.Context) {
 	var callbackInformations entities.CallbackInformations
 	code := context.Query("code")
+	state := context.Query("state")
 	email := context.GetString("email")
 	connectionType := context.GetString("connectionType")
 
@@ -80,7 +86,21 @@ func (self *UserServiceHandler) serviceCallback(context *gin.Context) {
 		return
 	}
 
-	errUpdate := self.UserServiceService.UpdateTokenForService(code, callbackInformations.Service, callbackInformations.AppType, email, connectionType)
+	if state == "" {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid state",
+		})
+		return
+	}
+
+	errUpdate := self.UserServiceService.UpdateTokenForService(code, state, middleware.FindOAuthSession(context),
+		callbackInformations.Service, callbackInformations.AppType, email, connectionType)
+	if errUpdate != nil && middleware.IsOAuthStateError(errUpdate) {
+		context.IndentedJSON(http.StatusForbidden, gin.H{
+			"error": errUpdate.Error(),
+		})
+		return
+	}
 	if errUpdate != nil {
 		context.IndentedJSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update token",
@@ -118,6 +138,83 @@ func (self *UserServiceHandler) getUserServiceAuthenticationStatus(context *gin.
 	})
 }
 
+// @Summary      Disconnect Service
+// @Description  Revokes the token of the connected user at the service when it allows it, forgets it and deactivates the workflows relying on the service. A failed revocation does not keep the service connected, it is reported in revocationerror
+// @Tags         Authentication
+// @Produce      json
+// @Param        service     path     string  true  "Service name (Github, Spotify, Discord..)"
+// @Success		200		{object}	docs_userservice.UserServiceDisconnectServiceSuccessResponse
+// @Failure		400		{object}	docs_userservice.UserServiceDisconnectServiceBadRequestResponse
+// @Failure		404		{object}	docs_userservice.UserServiceDisconnectServiceNotFoundResponse
+// @Failure		500		{object}	docs_userservice.UserServiceDisconnectServiceInternalServerErrorResponse
+// @Router       /services/{service}/connection [delete]
+func (self *UserServiceHandler) disconnectService(context *gin.Context) {
+	serviceName := context.Param("service")
+	email := context.GetString("email")
+	connectionType := context.GetString("connectionType")
+
+	disconnection, err := self.UserServiceService.DisconnectService(email, connectionType, serviceName)
+	if err != nil && err.Error() == "Service not connected" {
+		context.IndentedJSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil && (err.Error() == "Unknown service" || err.Error() == "Could not find requested user") {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not disconnect the service",
+		})
+		return
+	}
+
+	response := gin.H{
+		"success":              "Service disconnected",
+		"deactivatedworkflows": disconnection.DeactivatedWorkflows,
+		"tokenrevoked":         disconnection.TokenRevoked,
+	}
+	if disconnection.RevocationError != "" {
+		response["revocationerror"] = disconnection.RevocationError
+	}
+	context.IndentedJSON(http.StatusOK, response)
+}
+
+// @Summary      Service Connections
+// @Description  Health of the connections of the user to each service requiring an authorization: granted scopes, token expiry, last successful call, last error and whether the service must be authorized again
+// @Tags         Authentication
+// @Produce      json
+// @Success		200		{object}	docs_userservice.UserServiceGetServiceConnectionsSuccessResponse
+// @Failure		400		{object}	docs_userservice.UserServiceGetServiceConnectionsBadRequestResponse
+// @Failure		500		{object}	docs_userservice.UserServiceGetServiceConnectionsInternalServerErrorResponse
+// @Router       /services/connections [get]
+func (self *UserServiceHandler) getServiceConnections(context *gin.Context) {
+	email := context.GetString("email")
+	connectionType := context.GetString("connectionType")
+
+	connections, err := self.UserServiceService.RetrieveServiceConnections(email, connectionType)
+	if err != nil && err.Error() == "Could not find requested user" {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not retrieve the service connections",
+		})
+		return
+	}
+
+	context.IndentedJSON(http.StatusOK, gin.H{
+		"connections": connections,
+	})
+}
+
 // @Summary      Retrieve User Repositories
 // @Description  Retrieve the repositories of the authenticated user
 // @Tags         Github
@@ -1,6 +1,7 @@
 package userservice_handler
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -33,11 +34,31 @@ func (m *MockUserServiceService) CallApiAndRefresh(email, connectionType, servic
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUserServiceService) UpdateTokenForService(code, serviceName, appType, email, connectionType string) error {
-	args := m.Called(code, serviceName, appType, email, connectionType)
+func (m *MockUserServiceService) UpdateTokenForService(code, state, session, serviceName, appType, email, connectionType string) error {
+	args := m.Called(code, state, session, serviceName, appType, email, connectionType)
 	return args.Error(0)
 }
 
+func (m *MockUserServiceService) RecordServiceCall(userId, serviceName string, callErr error) error {
+	args := m.Called(userId, serviceName, callErr)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceService) RetrieveServiceConnections(email, connectionType string) ([]entities.ServiceConnection, error) {
+	args := m.Called(email, connectionType)
+	return args.Get(0).([]entities.ServiceConnection), args.Error(1)
+}
+
+func (m *MockUserServiceService) RefreshExpiringTokens(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserServiceService) DisconnectService(email, connectionType, serviceName string) (entities.ServiceDisconnection, error) {
+	args := m.Called(email, connectionType, serviceName)
+	return args.Get(0).(entities.ServiceDisconnection), args.Error(1)
+}
+
 func (m *MockUserServiceService) RetrieveGithubUserRepositories(email, connectionType string) ([]entities.GithubRepository, error) {
 	args := m.Called(email, connectionType)
 	return args.Get(0).([]entities.GithubRepository), args.Error(1)
@@ -117,7 +138,7 @@ func TestServiceCallback(test *testing.T) {
 	router.POST("/service-callback", handler.serviceCallback)
 
 	test.Run("Successful", func(test *testing.T) {
-		mockUserServiceService.On("UpdateTokenForService", "code", "github", "web", "email", "basic").
+		mockUserServiceService.On("UpdateTokenForService", "code", "state", "", "github", "web", "email", "basic").
 			Return(nil).Once()
 
 		body := `{
@@ -125,7 +146,7 @@ func TestServiceCallback(test *testing.T) {
 			"apptype": "web"
 		}`
 
-		req := requestForProtected("POST", "/service-callback?code=code", token, strings.NewReader(body))
+		req := requestForProtected("POST", "/service-callback?code=code&state=state", token, strings.NewReader(body))
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -136,7 +157,7 @@ func TestServiceCallback(test *testing.T) {
 	})
 
 	test.Run("Invalid code", func(test *testing.T) {
-		mockUserServiceService.On("UpdateTokenForService", "code", "github", "web", "email", "basic").
+		mockUserServiceService.On("UpdateTokenForService", "code", "state", "", "github", "web", "email", "basic").
 			Return(nil).Once()
 
 		body := `{
@@ -155,7 +176,7 @@ func TestServiceCallback(test *testing.T) {
 	})
 
 	test.Run("Invalid app", func(test *testing.T) {
-		mockUserServiceService.On("UpdateTokenForService", "code", "github", "fail", "email", "basic").
+		mockUserServiceService.On("UpdateTokenForService", "code", "state", "", "github", "fail", "email", "basic").
 			Return(nil).Once()
 
 		body := `{
@@ -163,7 +184,7 @@ func TestServiceCallback(test *testing.T) {
 			"apptype": "fail"
 		}`
 
-		req := requestForProtected("POST", "/service-callback?code=code", token, strings.NewReader(body))
+		req := requestForProtected("POST", "/service-callback?code=code&state=state", token, strings.NewReader(body))
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -173,6 +194,40 @@ func TestServiceCallback(test *testing.T) {
 
 	})
 
+	test.Run("Invalid state", func(test *testing.T) {
+		body := `{
+			"service": "github",
+			"apptype": "web"
+		}`
+
+		req := requestForProtected("POST", "/service-callback?code=code", token, strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Invalid state"}`, w.Body.String())
+	})
+
+	test.Run("Forbidden state", func(test *testing.T) {
+		mockUserServiceService.On("UpdateTokenForService", "code", "forged", "session", "github", "web", "email", "basic").
+			Return(errors.New("Invalid OAuth state")).Once()
+
+		body := `{
+			"service": "github",
+			"apptype": "web"
+		}`
+
+		req := requestForProtected("POST", "/service-callback?code=code&state=forged", token, strings.NewReader(body))
+		req.AddCookie(&http.Cookie{Name: "OAuthSession", Value: "session"})
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusForbidden, w.Code)
+		require.JSONEq(test, `{"error": "Invalid OAuth state"}`, w.Body.String())
+	})
+
 	test.Run("Fail update token", func(test *testing.T) {
 		handler, router, mockUserServiceService := createMockAndRoute(true)
 
@@ -184,7 +239,7 @@ func TestServiceCallback(test *testing.T) {
 		})
 		router.POST("/service-callback", handler.serviceCallback)
 
-		mockUserServiceService.On("UpdateTokenForService", "code", "github", "web", "email", "basic").
+		mockUserServiceService.On("UpdateTokenForService", "code", "state", "", "github", "web", "email", "basic").
 			Return(errors.New("Failed to update token")).Once()
 
 		body := `{
@@ -192,7 +247,7 @@ func TestServiceCallback(test *testing.T) {
 			"apptype": "web"
 		}`
 
-		req := requestForProtected("POST", "/service-callback?code=code", token, strings.NewReader(body))
+		req := requestForProtected("POST", "/service-callback?code=code&state=state", token, strings.NewReader(body))
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -202,10 +257,10 @@ func TestServiceCallback(test *testing.T) {
 	})
 
 	test.Run("Fail JSON Bind", func(test *testing.T) {
-		mockUserServiceService.On("UpdateTokenForService", "code", "github", "web", "email", "basic").
+		mockUserServiceService.On("UpdateTokenForService", "code", "state", "", "github", "web", "email", "basic").
 			Return(nil).Once()
 
-		req := requestForProtected("POST", "/service-callback?code=code", token, nil)
+		req := requestForProtected("POST", "/service-callback?code=code&state=state", token, nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -267,6 +322,141 @@ func TestGetUserServiceAuthenticationStatus(test *testing.T) {
 	})
 }
 
+func TestGetServiceConnections(test *testing.T) {
+	handler, router, mockUserServiceService := createMockAndRoute(true)
+
+	token := createToken(test)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("email", "email")
+		c.Set("connectionType", "basic")
+	})
+	router.GET("/services/connections", handler.getServiceConnections)
+
+	test.Run("Successful", func(test *testing.T) {
+		lastSuccessAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		mockUserServiceService.On("RetrieveServiceConnections", "email", "basic").
+			Return([]entities.ServiceConnection{
+				{Service: "Github", Linked: true, Scopes: []string{"repo"}, LastSuccessAt: &lastSuccessAt, LastError: "API call failed (HTTP 401)", ReauthorizationRequired: true},
+				{Service: "Spotify", Scopes: []string{}},
+			}, nil).Once()
+
+		req := requestForProtected("GET", "/services/connections", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+		require.JSONEq(test, `{"connections": [
+			{"service": "Github", "linked": true, "scopes": ["repo"], "lastsuccessat": "2024-01-01T12:00:00Z", "lasterror": "API call failed (HTTP 401)", "reauthorizationrequired": true},
+			{"service": "Spotify", "linked": false, "scopes": [], "lasterror": "", "reauthorizationrequired": false}
+		]}`, w.Body.String())
+	})
+
+	test.Run("Unknown user", func(test *testing.T) {
+		mockUserServiceService.On("RetrieveServiceConnections", "email", "basic").
+			Return([]entities.ServiceConnection(nil), errors.New("Could not find requested user")).Once()
+
+		req := requestForProtected("GET", "/services/connections", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Could not find requested user"}`, w.Body.String())
+	})
+
+	test.Run("Fail retrieve", func(test *testing.T) {
+		mockUserServiceService.On("RetrieveServiceConnections", "email", "basic").
+			Return([]entities.ServiceConnection(nil), errors.New("Query error")).Once()
+
+		req := requestForProtected("GET", "/services/connections", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusInternalServerError, w.Code)
+		require.JSONEq(test, `{"error": "Could not retrieve the service connections"}`, w.Body.String())
+	})
+}
+
+func TestDisconnectService(test *testing.T) {
+	handler, router, mockUserServiceService := createMockAndRoute(true)
+
+	token := createToken(test)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("email", "email")
+		c.Set("connectionType", "basic")
+	})
+	router.DELETE("/services/:service/connection", handler.disconnectService)
+
+	test.Run("Successful", func(test *testing.T) {
+		mockUserServiceService.On("DisconnectService", "email", "basic", "Github").
+			Return(entities.ServiceDisconnection{DeactivatedWorkflows: 2, TokenRevoked: true}, nil).Once()
+
+		req := requestForProtected("DELETE", "/services/Github/connection", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+		require.JSONEq(test, `{"success": "Service disconnected", "deactivatedworkflows": 2, "tokenrevoked": true}`, w.Body.String())
+	})
+
+	test.Run("Revocation failed", func(test *testing.T) {
+		mockUserServiceService.On("DisconnectService", "email", "basic", "Github").
+			Return(entities.ServiceDisconnection{DeactivatedWorkflows: 1, RevocationError: "API call failed (HTTP 400)"}, nil).Once()
+
+		req := requestForProtected("DELETE", "/services/Github/connection", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+		require.JSONEq(test, `{"success": "Service disconnected", "deactivatedworkflows": 1, "tokenrevoked": false, "revocationerror": "API call failed (HTTP 400)"}`, w.Body.String())
+	})
+
+	test.Run("Service not connected", func(test *testing.T) {
+		mockUserServiceService.On("DisconnectService", "email", "basic", "Github").
+			Return(entities.ServiceDisconnection{}, errors.New("Service not connected")).Once()
+
+		req := requestForProtected("DELETE", "/services/Github/connection", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusNotFound, w.Code)
+		require.JSONEq(test, `{"error": "Service not connected"}`, w.Body.String())
+	})
+
+	test.Run("Unknown service", func(test *testing.T) {
+		mockUserServiceService.On("DisconnectService", "email", "basic", "Unknown").
+			Return(entities.ServiceDisconnection{}, errors.New("Unknown service")).Once()
+
+		req := requestForProtected("DELETE", "/services/Unknown/connection", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Unknown service"}`, w.Body.String())
+	})
+
+	test.Run("Fail disconnect", func(test *testing.T) {
+		mockUserServiceService.On("DisconnectService", "email", "basic", "Github").
+			Return(entities.ServiceDisconnection{}, errors.New("Fail delete")).Once()
+
+		req := requestForProtected("DELETE", "/services/Github/connection", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusInternalServerError, w.Code)
+		require.JSONEq(test, `{"error": "Could not disconnect the service"}`, w.Body.String())
+	})
+}
+
 func TestGetGithubUserRepositories(test *testing.T) {
 	handler, router, mockUserServiceService := createMockAndRoute(true)
 
@@ -11,13 +11,38 @@ type WorkflowReceiveServiceWebhookBadRequestResponse struct {
 	Msg string `json:"error"example:"Invalid request body"`
 }
 
+type WorkflowReceiveServiceWebhookUnauthorizedResponse struct {
+	Msg string `json:"error"example:"Invalid webhook signature"`
+}
+
+// Receive Incoming Webhook Responses
+type WorkflowReceiveIncomingWebhookSuccessResponse struct {
+	Msg string `json:"success"example:"Webhook received"`
+}
+
+type WorkflowReceiveIncomingWebhookBadRequestResponse struct {
+	Msg string `json:"error"example:"Invalid webhook body"`
+}
+
+type WorkflowReceiveIncomingWebhookUnauthorizedResponse struct {
+	Msg string `json:"error"example:"Invalid webhook signature"`
+}
+
+type WorkflowReceiveIncomingWebhookNotFoundResponse struct {
+	Msg string `json:"error"example:"Webhook not found"`
+}
+
+type WorkflowReceiveIncomingWebhookTooLargeResponse struct {
+	Msg string `json:"error"example:"Webhook body too large"`
+}
+
 // Create Workflow Responses
 type WorkflowCreateWorkflowSuccessResponse struct {
 	Msg string `json:"success"example:"Successful workflow creation"`
 }
 
 type WorkflowCreateWorkflowBadRequestResponse struct {
-	Msg string `json:"error"example:"Invalid request body"`
+	Msg string `json:"error"example:"Invalid request body-Invalid filter: unexpected end of filter-Unknown filter field \"author\", available fields are: post.id, post.title-Invalid cron expression, expected 5 fields: minute hour day-of-month month day-of-week-Invalid catch-up policy, expected once, all or skip"`
 }
 
 type WorkflowCreateWorkflowUnauthorizedResponse struct {
@@ -47,7 +72,7 @@ type WorkflowUpdateWorkflowSuccessResponse struct {
 }
 
 type WorkflowUpdateWorkflowBadRequestResponse struct {
-	Msg string `json:"error"example:"Invalid request body"`
+	Msg string `json:"error"example:"Invalid request body-Invalid filter: unexpected end of filter-Unknown filter field \"author\", available fields are: post.id, post.title-Invalid cron expression, expected 5 fields: minute hour day-of-month month day-of-week-Invalid catch-up policy, expected once, all or skip"`
 }
 
 type WorkflowUpdateWorkflowInternalServerErrorResponse struct {
@@ -66,3 +91,76 @@ type WorkflowDeleteWorkflowUnauthorizedResponse struct {
 type WorkflowDeleteWorkflowInternalServerErrorResponse struct {
 	Msg string `json:"error"example:"Could not delete workflow"`
 }
+
+// Retrieve Workflow Runs Responses
+type WorkflowRetrieveWorkflowRunsSuccessResponse struct {
+	entities.WorkflowRunsPage
+}
+
+type WorkflowRetrieveWorkflowRunsBadRequestResponse struct {
+	Msg string `json:"error"example:"Invalid query parameters"`
+}
+
+type WorkflowRetrieveWorkflowRunsUnauthorizedResponse struct {
+	Msg string `json:"error"example:"Email not found in token-Email is not a valid string-Connection type not found in token-Connection type is not a valid string"`
+}
+
+type WorkflowRetrieveWorkflowRunsNotFoundResponse struct {
+	Msg string `json:"error"example:"Workflow not found"`
+}
+
+type WorkflowRetrieveWorkflowRunsInternalServerErrorResponse struct {
+	Msg string `json:"error"example:"Could not retrieve workflow runs"`
+}
+
+// Replay Webhook Delivery Responses
+type WorkflowReplayWebhookDeliverySuccessResponse struct {
+	Msg string `json:"success"example:"Webhook delivery replayed"`
+}
+
+type WorkflowReplayWebhookDeliveryUnauthorizedResponse struct {
+	Msg string `json:"error"example:"Email not found in token-Email is not a valid string-Connection type not found in token-Connection type is not a valid string"`
+}
+
+type WorkflowReplayWebhookDeliveryNotFoundResponse struct {
+	Msg string `json:"error"example:"Webhook delivery not found"`
+}
+
+type WorkflowReplayWebhookDeliveryInternalServerErrorResponse struct {
+	Msg string `json:"error"example:"Could not replay webhook delivery"`
+}
+
+// Retrieve Workflow Dead Letters Responses
+type WorkflowRetrieveDeadLettersSuccessResponse struct {
+	DeadLetters []entities.ReactionJob `json:"deadletters"`
+}
+
+type WorkflowRetrieveDeadLettersUnauthorizedResponse struct {
+	Msg string `json:"error"example:"Email not found in token-Email is not a valid string-Connection type not found in token-Connection type is not a valid string"`
+}
+
+type WorkflowRetrieveDeadLettersNotFoundResponse struct {
+	Msg string `json:"error"example:"Workflow not found"`
+}
+
+type WorkflowRetrieveDeadLettersInternalServerErrorResponse struct {
+	Msg string `json:"error"example:"Could not retrieve dead letters"`
+}
+
+// Retrigger Workflow Dead Letters Responses
+type WorkflowRetriggerDeadLettersSuccessResponse struct {
+	Msg         string `json:"success"example:"Dead letters retriggered"`
+	Retriggered int    `json:"retriggered"example:"2"`
+}
+
+type WorkflowRetriggerDeadLettersUnauthorizedResponse struct {
+	Msg string `json:"error"example:"Email not found in token-Email is not a valid string-Connection type not found in token-Connection type is not a valid string"`
+}
+
+type WorkflowRetriggerDeadLettersNotFoundResponse struct {
+	Msg string `json:"error"example:"Workflow not found"`
+}
+
+type WorkflowRetriggerDeadLettersInternalServerErrorResponse struct {
+	Msg string `json:"error"example:"Could not retrigger dead letters"`
+}
@@ -1,7 +1,10 @@
 package workflow_handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -17,6 +20,13 @@ type WorkflowHandler struct {
 }
 
 const invalidRequestBodyMessage = "Invalid request body"
+const invalidQueryParametersMessage = "Invalid query parameters"
+
+// Bodies of the incoming webhooks are read before their token is checked, larger ones are refused
+const maxIncomingWebhookBodySize = 1 << 20
+
+const defaultRunsPageSize = 20
+const maxRunsPageSize = 100
 
 func NewWorkflowHandler(WorkflowService service.WorkflowService, UserService service.UserService, router *gin.Engine) *WorkflowHandler {
 	handler := &WorkflowHandler{
@@ -37,6 +47,10 @@ func (self *WorkflowHandler) publicRoutes(router *gin.Engine) {
 	{
 		webhook.POST("/:service", self.receiveServiceWebhook)
 	}
+	hook := router.Group("/hooks")
+	{
+		hook.POST("/in/:token", self.receiveIncomingWebhook)
+	}
 }
 
 func (self *WorkflowHandler) privateRoutes(router *gin.Engine) {
@@ -47,20 +61,35 @@ func (self *WorkflowHandler) privateRoutes(router *gin.Engine) {
 		workflow.GET("", self.getUserWorkflows)
 		workflow.PUT("/:id", self.updateWorkflow)
 		workflow.DELETE("/:id", self.deleteWorkflow)
+		workflow.GET("/:id/runs", self.getWorkflowRuns)
+		workflow.GET("/:id/dead-letters", self.getWorkflowDeadLetters)
+		workflow.POST("/:id/dead-letters", self.retriggerWorkflowDeadLetters)
+	}
+	delivery := private.Group("/webhooks/deliveries")
+	{
+		delivery.POST("/:id/replay", self.replayWebhookDelivery)
 	}
 }
 
-// // @Summary		Receive Service Webhook
-// // @Description	Receive webhook from a service
-// // @Tags			Webhooks
-// // @Produce		json
+// @Summary		Receive Service Webhook
+// @Description	Receive webhook from a service, signed in X-Hub-Signature-256 for GitHub or carrying X-Gitlab-Token for GitLab
+// @Tags			Webhooks
+// @Produce		json
+// @Param			service	path		string	true	"Name of the service sending the webhook"
 // @Success		200		{object}	docs_workflow.WorkflowReceiveServiceWebhookSuccessResponse
-// @Success		400		{object}	docs_workflow.WorkflowReceiveServiceWebhookBadRequestResponse
-// // @Router		/webhooks/{service} [post]
+// @Failure		400		{object}	docs_workflow.WorkflowReceiveServiceWebhookBadRequestResponse
+// @Failure		401		{object}	docs_workflow.WorkflowReceiveServiceWebhookUnauthorizedResponse
+// @Router			/webhooks/{service} [post]
 func (self *WorkflowHandler) receiveServiceWebhook(context *gin.Context) {
 	serviceName := context.Param("service")
 
 	err := self.WorkflowService.CheckWebhooksWorkflows(serviceName, context.Request)
+	if err != nil && err.Error() == "Invalid webhook signature" {
+		context.IndentedJSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		context.IndentedJSON(http.StatusBadRequest, gin.H{
 			"error": invalidRequestBodyMessage,
@@ -73,6 +102,50 @@ func (self *WorkflowHandler) receiveServiceWebhook(context *gin.Context) {
 	})
 }
 
+// @Summary		Receive Incoming Webhook
+// @Description	Trigger the workflow owning the token with any JSON body, signed in the X-Webhook-Signature-256 header when the workflow has a secret
+// @Tags			Webhooks
+// @Accept			json
+// @Produce		json
+// @Param			token	path		string	true	"Webhook token of the workflow"
+// @Success		200		{object}	docs_workflow.WorkflowReceiveIncomingWebhookSuccessResponse
+// @Failure		400		{object}	docs_workflow.WorkflowReceiveIncomingWebhookBadRequestResponse
+// @Failure		401		{object}	docs_workflow.WorkflowReceiveIncomingWebhookUnauthorizedResponse
+// @Failure		404		{object}	docs_workflow.WorkflowReceiveIncomingWebhookNotFoundResponse
+// @Failure		413		{object}	docs_workflow.WorkflowReceiveIncomingWebhookTooLargeResponse
+// @Router			/hooks/in/{token} [post]
+func (self *WorkflowHandler) receiveIncomingWebhook(context *gin.Context) {
+	token := context.Param("token")
+
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, maxIncomingWebhookBodySize)
+	err := self.WorkflowService.CheckIncomingWebhook(token, context.Request)
+	if err != nil {
+		switch err.Error() {
+		case "Webhook not found":
+			context.IndentedJSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case "Invalid webhook signature":
+			context.IndentedJSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+			})
+		case "Webhook body too large":
+			context.IndentedJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			context.IndentedJSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	context.IndentedJSON(http.StatusOK, gin.H{
+		"success": "Webhook received",
+	})
+}
+
 // @Summary		Create Workflow
 // @Description	Create a workflow
 // @Tags			Workflows
@@ -85,6 +158,7 @@ func (self *WorkflowHandler) receiveServiceWebhook(context *gin.Context) {
 // @Router			/workflows [post]
 func (self *WorkflowHandler) createWorkflow(context *gin.Context) {
 	var newWorkflow entities.NewWorkflow
+	var validationError entities.WorkflowValidationError
 	email := context.GetString("email")
 	connectionType := context.GetString("connectionType")
 
@@ -97,6 +171,12 @@ func (self *WorkflowHandler) createWorkflow(context *gin.Context) {
 	}
 
 	errCreationWorkflow := self.WorkflowService.CreateWorkflow(email, connectionType, newWorkflow)
+	if errors.As(errCreationWorkflow, &validationError) {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{
+			"error": validationError.Error(),
+		})
+		return
+	}
 	if errCreationWorkflow != nil {
 		context.IndentedJSON(http.StatusInternalServerError, gin.H{
 			"error": "Could not create workflow",
@@ -145,6 +225,7 @@ func (self *WorkflowHandler) getUserWorkflows(context *gin.Context) {
 // @Router			/workflows/{id} [put]
 func (self *WorkflowHandler) updateWorkflow(context *gin.Context) {
 	var workflow entities.UpdatedWorkflow
+	var validationError entities.WorkflowValidationError
 	workflowId := context.Param("id")
 
 	err := context.ShouldBindJSON(&workflow)
@@ -156,6 +237,12 @@ func (self *WorkflowHandler) updateWorkflow(context *gin.Context) {
 	}
 
 	err = self.WorkflowService.UpdateWorkflow(workflowId, workflow)
+	if errors.As(err, &validationError) {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{
+			"error": validationError.Error(),
+		})
+		return
+	}
 	if err != nil {
 		context.IndentedJSON(http.StatusInternalServerError, gin.H{
 			"error": "Could not update workflow",
@@ -193,3 +280,163 @@ func (self *WorkflowHandler) deleteWorkflow(context *gin.Context) {
 		"success": "Workflow deleted",
 	})
 }
+
+func parseRunsPagination(context *gin.Context) (int, int, error) {
+	page, err := strconv.Atoi(context.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, fmt.Errorf(invalidQueryParametersMessage)
+	}
+
+	pageSize, err := strconv.Atoi(context.DefaultQuery("limit", strconv.Itoa(defaultRunsPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxRunsPageSize {
+		return 0, 0, fmt.Errorf(invalidQueryParametersMessage)
+	}
+	return page, pageSize, nil
+}
+
+// @Summary		Retrieve Workflow Runs
+// @Description	Retrieve the execution history of a user's workflow, most recent first
+// @Tags			Workflows
+// @Produce		json
+// @Param        id     path     string  true  "Workflow id"
+// @Param        page   query    int     false "Page number, starting at 1"
+// @Param        limit  query    int     false "Number of runs per page, 100 at most"
+// @Param        status query    string  false "Filter by run status (success, failure)"
+// @Success		200		{object}	docs_workflow.WorkflowRetrieveWorkflowRunsSuccessResponse
+// @Failure		400		{object}	docs_workflow.WorkflowRetrieveWorkflowRunsBadRequestResponse
+// @Failure		401		{object}	docs_workflow.WorkflowRetrieveWorkflowRunsUnauthorizedResponse
+// @Failure		404		{object}	docs_workflow.WorkflowRetrieveWorkflowRunsNotFoundResponse
+// @Failure		500		{object}	docs_workflow.WorkflowRetrieveWorkflowRunsInternalServerErrorResponse
+// @Router			/workflows/{id}/runs [get]
+func (self *WorkflowHandler) getWorkflowRuns(context *gin.Context) {
+	email := context.GetString("email")
+	connectionType := context.GetString("connectionType")
+	workflowId := context.Param("id")
+	status := context.Query("status")
+
+	page, pageSize, err := parseRunsPagination(context)
+	if err != nil || (status != "" && status != entities.WorkflowRunSuccess && status != entities.WorkflowRunFailure) {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{
+			"error": invalidQueryParametersMessage,
+		})
+		return
+	}
+
+	workflowRuns, err := self.WorkflowService.GetWorkflowRuns(email, connectionType, workflowId, status, page, pageSize)
+	if errors.As(err, &entities.WorkflowNotFoundError{}) {
+		context.IndentedJSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not retrieve workflow runs",
+		})
+		return
+	}
+
+	context.IndentedJSON(http.StatusOK, workflowRuns)
+}
+
+// @Summary		Retrieve Workflow Dead Letters
+// @Description	Retrieve the reactions of a user's workflow which failed for good, after a permanent error or too many attempts
+// @Tags			Workflows
+// @Produce		json
+// @Param        id     path     string  true  "Workflow id"
+// @Success		200		{object}	docs_workflow.WorkflowRetrieveDeadLettersSuccessResponse
+// @Failure		401		{object}	docs_workflow.WorkflowRetrieveDeadLettersUnauthorizedResponse
+// @Failure		404		{object}	docs_workflow.WorkflowRetrieveDeadLettersNotFoundResponse
+// @Failure		500		{object}	docs_workflow.WorkflowRetrieveDeadLettersInternalServerErrorResponse
+// @Router			/workflows/{id}/dead-letters [get]
+func (self *WorkflowHandler) getWorkflowDeadLetters(context *gin.Context) {
+	email := context.GetString("email")
+	connectionType := context.GetString("connectionType")
+	workflowId := context.Param("id")
+
+	deadLetters, err := self.WorkflowService.GetWorkflowDeadLetters(email, connectionType, workflowId)
+	if errors.As(err, &entities.WorkflowNotFoundError{}) {
+		context.IndentedJSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not retrieve dead letters",
+		})
+		return
+	}
+
+	context.IndentedJSON(http.StatusOK, gin.H{
+		"deadletters": deadLetters,
+	})
+}
+
+// @Summary		Retrigger Workflow Dead Letters
+// @Description	Enqueue the dead letters of a user's workflow again, each with all its attempts
+// @Tags			Workflows
+// @Produce		json
+// @Param        id     path     string  true  "Workflow id"
+// @Success		200		{object}	docs_workflow.WorkflowRetriggerDeadLettersSuccessResponse
+// @Failure		401		{object}	docs_workflow.WorkflowRetriggerDeadLettersUnauthorizedResponse
+// @Failure		404		{object}	docs_workflow.WorkflowRetriggerDeadLettersNotFoundResponse
+// @Failure		500		{object}	docs_workflow.WorkflowRetriggerDeadLettersInternalServerErrorResponse
+// @Router			/workflows/{id}/dead-letters [post]
+func (self *WorkflowHandler) retriggerWorkflowDeadLetters(context *gin.Context) {
+	email := context.GetString("email")
+	connectionType := context.GetString("connectionType")
+	workflowId := context.Param("id")
+
+	retriggered, err := self.WorkflowService.RetriggerWorkflowDeadLetters(email, connectionType, workflowId)
+	if errors.As(err, &entities.WorkflowNotFoundError{}) {
+		context.IndentedJSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not retrigger dead letters",
+		})
+		return
+	}
+
+	context.IndentedJSON(http.StatusOK, gin.H{
+		"success":     "Dead letters retriggered",
+		"retriggered": retriggered,
+	})
+}
+
+// @Summary		Replay Webhook Delivery
+// @Description	Run a stored service webhook delivery again through the user's workflows it triggers, its id is the "delivery_id" of the runs
+// @Tags			Webhooks
+// @Produce		json
+// @Param        id     path     string  true  "Webhook delivery id"
+// @Success		200		{object}	docs_workflow.WorkflowReplayWebhookDeliverySuccessResponse
+// @Failure		401 	{object}	docs_workflow.WorkflowReplayWebhookDeliveryUnauthorizedResponse
+// @Failure		404 	{object}	docs_workflow.WorkflowReplayWebhookDeliveryNotFoundResponse
+// @Failure		500		{object}	docs_workflow.WorkflowReplayWebhookDeliveryInternalServerErrorResponse
+// @Router			/webhooks/deliveries/{id}/replay [post]
+func (self *WorkflowHandler) replayWebhookDelivery(context *gin.Context) {
+	email := context.GetString("email")
+	connectionType := context.GetString("connectionType")
+	deliveryId := context.Param("id")
+
+	err := self.WorkflowService.ReplayWebhookDelivery(email, connectionType, deliveryId)
+	if err != nil && err.Error() == "Webhook delivery not found" {
+		context.IndentedJSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not replay webhook delivery",
+		})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{
+		"success": "Webhook delivery replayed",
+	})
+}
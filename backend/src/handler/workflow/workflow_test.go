@@ -1,6 +1,7 @@
 package workflow_handler
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -43,33 +44,38 @@ func (m *MockWorkflowService) DeleteWorkflow(email, connectionType, workflowId s
 	return args.Error(0)
 }
 
-func (m *MockWorkflowService) CheckTimeAndDateActions() error {
-	args := m.Called()
+func (m *MockWorkflowService) GetWorkflowRuns(email, connectionType, workflowId, status string, page, pageSize int) (entities.WorkflowRunsPage, error) {
+	args := m.Called(email, connectionType, workflowId, status, page, pageSize)
+	return args.Get(0).(entities.WorkflowRunsPage), args.Error(1)
+}
+
+func (m *MockWorkflowService) CheckTimeAndDateActions(ctx context.Context) error {
+	args := m.Called(ctx)
 	return args.Error(0)
 }
 
-func (m *MockWorkflowService) CheckGithubActions() error {
-	args := m.Called()
+func (m *MockWorkflowService) CheckGithubActions(ctx context.Context) error {
+	args := m.Called(ctx)
 	return args.Error(0)
 }
 
-func (m *MockWorkflowService) CheckRedditActions() error {
-	args := m.Called()
+func (m *MockWorkflowService) CheckRedditActions(ctx context.Context) error {
+	args := m.Called(ctx)
 	return args.Error(0)
 }
 
-func (m *MockWorkflowService) CheckWeatherActions() error {
-	args := m.Called()
+func (m *MockWorkflowService) CheckWeatherActions(ctx context.Context) error {
+	args := m.Called(ctx)
 	return args.Error(0)
 }
 
-func (m *MockWorkflowService) CheckNewGitlabWorkflows() error {
-	args := m.Called()
+func (m *MockWorkflowService) CheckNewGitlabWorkflows(ctx context.Context) error {
+	args := m.Called(ctx)
 	return args.Error(0)
 }
 
-func (m *MockWorkflowService) CheckNewGithubWorkflows() error {
-	args := m.Called()
+func (m *MockWorkflowService) CheckNewGithubWorkflows(ctx context.Context) error {
+	args := m.Called(ctx)
 	return args.Error(0)
 }
 
@@ -78,6 +84,95 @@ func (m *MockWorkflowService) CheckWebhooksWorkflows(serviceName string, request
 	return args.Error(0)
 }
 
+func (m *MockWorkflowService) CheckIncomingWebhook(token string, request *http.Request) error {
+	args := m.Called(token, request)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) ReplayWebhookDelivery(email, connectionType, deliveryId string) error {
+	args := m.Called(email, connectionType, deliveryId)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) StartJobWorkers(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockWorkflowService) WaitJobWorkers(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) IsSchedulerLeader(ctx context.Context) bool {
+	args := m.Called(ctx)
+	return args.Bool(0)
+}
+
+func (m *MockWorkflowService) ReleaseSchedulerLock() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) GetSchedulerState() entities.SchedulerState {
+	args := m.Called()
+	return args.Get(0).(entities.SchedulerState)
+}
+
+func (m *MockWorkflowService) GetWorkflowDeadLetters(email, connectionType, workflowId string) ([]entities.ReactionJob, error) {
+	args := m.Called(email, connectionType, workflowId)
+	return args.Get(0).([]entities.ReactionJob), args.Error(1)
+}
+
+func (m *MockWorkflowService) RetriggerWorkflowDeadLetters(email, connectionType, workflowId string) (int, error) {
+	args := m.Called(email, connectionType, workflowId)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWorkflowService) CheckSpotifyReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) CheckDiscordReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) CheckLinkedinReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) CheckAsanaReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) CheckSMSReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) CheckSendEmailReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) CheckDropboxReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) CheckRedditReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowService) CheckHttpRequestReactions(workflow entities.Workflow, reaction entities.Reaction) error {
+	args := m.Called(workflow, reaction)
+	return args.Error(0)
+}
+
 func requestForProtected(method, url, token string, body io.Reader) *http.Request {
 	req, _ := http.NewRequest(method, url, body)
 	req.AddCookie(&http.Cookie{Name: "JWToken", Value: token})
@@ -110,6 +205,87 @@ func createMockAndRoute(isProtected bool) (*WorkflowHandler, *gin.Engine, *MockW
 	return handler, router, MockWorkflowService
 }
 
+func TestReceiveServiceWebhook(test *testing.T) {
+	handler, router, mockService := createMockAndRoute(false)
+
+	router.POST("/webhooks/:service", handler.receiveServiceWebhook)
+
+	responses := []struct {
+		name string
+		err  error
+		code int
+		body string
+	}{
+		{"Successful", nil, http.StatusOK, `{"success": "Webhook received"}`},
+		{"Invalid signature", errors.New("Invalid webhook signature"), http.StatusUnauthorized, `{"error": "Invalid webhook signature"}`},
+		{"Invalid body", errors.New("Incorrect repository name"), http.StatusBadRequest, `{"error": "Invalid request body"}`},
+	}
+
+	for _, response := range responses {
+		test.Run(response.name, func(test *testing.T) {
+			mockService.On("CheckWebhooksWorkflows", "Github", mock.Anything).
+				Return(response.err).Once()
+
+			req, _ := http.NewRequest("POST", "/webhooks/Github", strings.NewReader(`{"repository": {"full_name": "owner/repo"}}`))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(test, response.code, w.Code)
+			require.JSONEq(test, response.body, w.Body.String())
+		})
+	}
+}
+
+func TestReceiveIncomingWebhook(test *testing.T) {
+	handler, router, mockService := createMockAndRoute(false)
+
+	router.POST("/hooks/in/:token", handler.receiveIncomingWebhook)
+
+	responses := []struct {
+		name string
+		err  error
+		code int
+		body string
+	}{
+		{"Successful", nil, http.StatusOK, `{"success": "Webhook received"}`},
+		{"Unknown token", errors.New("Webhook not found"), http.StatusNotFound, `{"error": "Webhook not found"}`},
+		{"Invalid signature", errors.New("Invalid webhook signature"), http.StatusUnauthorized, `{"error": "Invalid webhook signature"}`},
+		{"Invalid body", errors.New("Invalid webhook body"), http.StatusBadRequest, `{"error": "Invalid webhook body"}`},
+		{"Body too large", errors.New("Webhook body too large"), http.StatusRequestEntityTooLarge, `{"error": "Webhook body too large"}`},
+	}
+
+	for _, response := range responses {
+		test.Run(response.name, func(test *testing.T) {
+			mockService.On("CheckIncomingWebhook", "token", mock.Anything).
+				Return(response.err).Once()
+
+			req, _ := http.NewRequest("POST", "/hooks/in/token", strings.NewReader(`{"status": "failed"}`))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(test, response.code, w.Code)
+			require.JSONEq(test, response.body, w.Body.String())
+		})
+	}
+
+	test.Run("Body limited", func(test *testing.T) {
+		mockService.On("CheckIncomingWebhook", "token", mock.MatchedBy(func(request *http.Request) bool {
+			_, err := io.ReadAll(request.Body)
+			var maxBytesError *http.MaxBytesError
+			return errors.As(err, &maxBytesError)
+		})).Return(errors.New("Webhook body too large")).Once()
+
+		req, _ := http.NewRequest("POST", "/hooks/in/token", strings.NewReader(strings.Repeat("a", maxIncomingWebhookBodySize+1)))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
+
 func TestCreateWorkflow(test *testing.T) {
 	handler, router, mock := createMockAndRoute(true)
 
@@ -163,6 +339,25 @@ func TestCreateWorkflow(test *testing.T) {
 
 	})
 
+	test.Run("Invalid filter", func(test *testing.T) {
+		newWorkflow := entities.NewWorkflow{Filter: "author == 1"}
+
+		mock.On("CreateWorkflow", "email", "basic", newWorkflow).
+			Return(entities.WorkflowValidationError{Message: "Unknown filter field \"author\", available fields are: post.title"}).Once()
+
+		body := `{
+			"filter": "author == 1"
+		}`
+
+		req := requestForProtected("POST", "/workflows", token, strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Unknown filter field \"author\", available fields are: post.title"}`, w.Body.String())
+	})
+
 	test.Run("Fail JSON Bind", func(test *testing.T) {
 		var newWorkflow entities.NewWorkflow
 
@@ -268,6 +463,26 @@ func TestUpdateWorkflows(test *testing.T) {
 		require.JSONEq(test, `{"error": "Could not update workflow"}`, w.Body.String())
 	})
 
+	test.Run("Invalid filter", func(test *testing.T) {
+		filter := "temp_c >"
+		workflow := entities.UpdatedWorkflow{Filter: &filter}
+
+		mock.On("UpdateWorkflow", "1", workflow).
+			Return(entities.WorkflowValidationError{Message: "Invalid filter: unexpected end of filter"}).Once()
+
+		body := `{
+			"filter": "temp_c >"
+		}`
+
+		req := requestForProtected("PUT", "/workflows/1", token, strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Invalid filter: unexpected end of filter"}`, w.Body.String())
+	})
+
 	test.Run("Fail JSON Bind", func(test *testing.T) {
 		var workflow entities.UpdatedWorkflow
 
@@ -321,3 +536,207 @@ func TestDeleteWorkflow(test *testing.T) {
 		require.JSONEq(test, `{"error": "Could not delete workflow"}`, w.Body.String())
 	})
 }
+
+func TestGetWorkflowRuns(test *testing.T) {
+	handler, router, mock := createMockAndRoute(true)
+
+	token := createToken(test)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("email", "email")
+		c.Set("connectionType", "basic")
+	})
+	router.GET("/workflows/:id/runs", handler.getWorkflowRuns)
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.On("GetWorkflowRuns", "email", "basic", "1", "", 1, 20).
+			Return(entities.WorkflowRunsPage{Runs: []entities.WorkflowRun{}, Page: 1, PageSize: 20}, nil).Once()
+
+		req := requestForProtected("GET", "/workflows/1/runs", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+		require.JSONEq(test, `{"runs": [], "total": 0, "page": 1, "pagesize": 20}`, w.Body.String())
+	})
+
+	test.Run("Successful with filter", func(test *testing.T) {
+		mock.On("GetWorkflowRuns", "email", "basic", "1", "failure", 2, 5).
+			Return(entities.WorkflowRunsPage{Runs: []entities.WorkflowRun{}, Page: 2, PageSize: 5}, nil).Once()
+
+		req := requestForProtected("GET", "/workflows/1/runs?status=failure&page=2&limit=5", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusOK, w.Code)
+	})
+
+	test.Run("Invalid status", func(test *testing.T) {
+		req := requestForProtected("GET", "/workflows/1/runs?status=unknown", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+		require.JSONEq(test, `{"error": "Invalid query parameters"}`, w.Body.String())
+	})
+
+	test.Run("Invalid limit", func(test *testing.T) {
+		req := requestForProtected("GET", "/workflows/1/runs?limit=1000", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusBadRequest, w.Code)
+	})
+
+	test.Run("Unknown workflow", func(test *testing.T) {
+		mock.On("GetWorkflowRuns", "email", "basic", "1", "", 1, 20).
+			Return(entities.WorkflowRunsPage{}, entities.WorkflowNotFoundError{}).Once()
+
+		req := requestForProtected("GET", "/workflows/1/runs", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusNotFound, w.Code)
+		require.JSONEq(test, `{"error": "Workflow not found"}`, w.Body.String())
+	})
+
+	test.Run("Fail retrieve runs", func(test *testing.T) {
+		mock.On("GetWorkflowRuns", "email", "basic", "1", "", 1, 20).
+			Return(entities.WorkflowRunsPage{}, errors.New("Fail retrieve runs")).Once()
+
+		req := requestForProtected("GET", "/workflows/1/runs", token, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(test, http.StatusInternalServerError, w.Code)
+		require.JSONEq(test, `{"error": "Could not retrieve workflow runs"}`, w.Body.String())
+	})
+}
+
+func TestReplayWebhookDelivery(test *testing.T) {
+	handler, router, mock := createMockAndRoute(true)
+
+	token := createToken(test)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("email", "email")
+		c.Set("connectionType", "basic")
+	})
+	router.POST("/webhooks/deliveries/:id/replay", handler.replayWebhookDelivery)
+
+	test.Run("Routes", func(test *testing.T) {
+		require.NotPanics(test, func() {
+			NewWorkflowHandler(mock, nil, gin.New())
+		})
+	})
+
+	responses := []struct {
+		name string
+		err  error
+		code int
+		body string
+	}{
+		{"Successful", nil, http.StatusOK, `{"success": "Webhook delivery replayed"}`},
+		{"Unknown delivery", errors.New("Webhook delivery not found"), http.StatusNotFound, `{"error": "Webhook delivery not found"}`},
+		{"Fail replay", errors.New("Fail find service"), http.StatusInternalServerError, `{"error": "Could not replay webhook delivery"}`},
+	}
+
+	for _, response := range responses {
+		test.Run(response.name, func(test *testing.T) {
+			mock.On("ReplayWebhookDelivery", "email", "basic", "1").
+				Return(response.err).Once()
+
+			req := requestForProtected("POST", "/webhooks/deliveries/1/replay", token, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(test, response.code, w.Code)
+			require.JSONEq(test, response.body, w.Body.String())
+		})
+	}
+}
+
+func TestGetWorkflowDeadLetters(test *testing.T) {
+	handler, router, mock := createMockAndRoute(true)
+
+	token := createToken(test)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("email", "email")
+		c.Set("connectionType", "basic")
+	})
+	router.GET("/workflows/:id/dead-letters", handler.getWorkflowDeadLetters)
+
+	responses := []struct {
+		name        string
+		deadLetters []entities.ReactionJob
+		err         error
+		code        int
+		body        string
+	}{
+		{"Successful", []entities.ReactionJob{}, nil, http.StatusOK, `{"deadletters": []}`},
+		{"Unknown workflow", nil, entities.WorkflowNotFoundError{}, http.StatusNotFound, `{"error": "Workflow not found"}`},
+		{"Fail retrieve", nil, errors.New("Fail find jobs"), http.StatusInternalServerError, `{"error": "Could not retrieve dead letters"}`},
+	}
+
+	for _, response := range responses {
+		test.Run(response.name, func(test *testing.T) {
+			mock.On("GetWorkflowDeadLetters", "email", "basic", "1").
+				Return(response.deadLetters, response.err).Once()
+
+			req := requestForProtected("GET", "/workflows/1/dead-letters", token, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(test, response.code, w.Code)
+			require.JSONEq(test, response.body, w.Body.String())
+		})
+	}
+}
+
+func TestRetriggerWorkflowDeadLetters(test *testing.T) {
+	handler, router, mock := createMockAndRoute(true)
+
+	token := createToken(test)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("email", "email")
+		c.Set("connectionType", "basic")
+	})
+	router.POST("/workflows/:id/dead-letters", handler.retriggerWorkflowDeadLetters)
+
+	responses := []struct {
+		name        string
+		retriggered int
+		err         error
+		code        int
+		body        string
+	}{
+		{"Successful", 2, nil, http.StatusOK, `{"success": "Dead letters retriggered", "retriggered": 2}`},
+		{"Unknown workflow", 0, entities.WorkflowNotFoundError{}, http.StatusNotFound, `{"error": "Workflow not found"}`},
+		{"Fail retrigger", 0, errors.New("Fail requeue jobs"), http.StatusInternalServerError, `{"error": "Could not retrigger dead letters"}`},
+	}
+
+	for _, response := range responses {
+		test.Run(response.name, func(test *testing.T) {
+			mock.On("RetriggerWorkflowDeadLetters", "email", "basic", "1").
+				Return(response.retriggered, response.err).Once()
+
+			req := requestForProtected("POST", "/workflows/1/dead-letters", token, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(test, response.code, w.Code)
+			require.JSONEq(test, response.body, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"backend/src/entities"
+)
+
+type PollJob struct {
+	Schedule string
+	Poll     func(workflowService WorkflowService, ctx context.Context) error
+}
+
+// Executes a reaction of the service for the workflow
+type ReactionHandler func(workflowService WorkflowService, workflow entities.Workflow, reaction entities.Reaction) error
+
+type Connector interface {
+	Definition() entities.ServiceDefinition
+	OAuthConfig() entities.OAuthConfig
+	AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error)
+	AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error)
+	RefreshTokenRequest(refreshToken string) (*http.Request, error)
+	RevokeTokenRequest(accessToken, refreshToken string) (*http.Request, error)
+	UserInfoRequest(accessToken string) (*http.Request, error)
+	DecodeUserInfo(res *http.Response) (entities.UserInfo, error)
+	ParseWebhook(headers http.Header, body []byte) (entities.WebhookEvent, error)
+	VerifyWebhook(headers http.Header, body []byte, secret string) bool
+	PollJobs() []PollJob
+	ReactionHandler() ReactionHandler
+}
+
+type ConnectorRegistry interface {
+	FindConnector(serviceName string) (Connector, error)
+	RetrieveConnectors() []Connector
+}
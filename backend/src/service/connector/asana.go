@@ -0,0 +1,101 @@
+package connector
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type asanaConnector struct {
+	baseConnector
+}
+
+func newAsanaConnector() *asanaConnector {
+	return &asanaConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Asana",
+				Color:        "#F06A6A",
+				Logo:         "/logos/asana.svg",
+				Description:  "Create tasks and projects in your Asana workspaces",
+				IsAuthNeeded: true,
+				Actions:      []entities.AboutAction{},
+				Reactions: []entities.AboutReaction{
+					{Name: "Create task", Description: "Create a task in a project of a workspace"},
+					{Name: "Create project", Description: "Create a project in a workspace"},
+				},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl:        "https://app.asana.com/-/oauth_token",
+				CanRefreshToken: true,
+				SupportsPKCE:    true,
+			},
+		},
+	}
+}
+
+func oauth2Asana(callbackType string) string {
+	callbackLink, _ := getCallbackAndClientId(callbackType, "ASANA", false)
+
+	return ("https://app.asana.com/-/oauth_authorize?" +
+		clientIdParam + os.Getenv("ASANA_CLIENT_ID") +
+		redirectUriParam + callbackLink +
+		codeResponseType +
+		"&scope=default email profile")
+}
+
+func (self *asanaConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Asana(callbackType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *asanaConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var asanaCallback string
+
+	if callbackType == "service" {
+		asanaCallback = os.Getenv("ASANA_SERVICE_CALLBACK")
+	} else if callbackType == "login" {
+		asanaCallback = os.Getenv("ASANA_LOGIN_CALLBACK")
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	jsonBody := fmt.Sprintf(
+		"client_id=%s&client_secret=%s&code=%s&redirect_uri=%s&grant_type=%s",
+		os.Getenv("ASANA_CLIENT_ID"),
+		os.Getenv("ASANA_CLIENT_SECRET"),
+		code,
+		asanaCallback,
+		grantTypeAuthorization,
+	)
+	jsonBody += codeVerifierBody(codeVerifier)
+
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.TokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *asanaConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	jsonBody := genericJsonBodyRefreshToken(os.Getenv("ASANA_CLIENT_ID"), os.Getenv("ASANA_CLIENT_SECRET"), refreshToken)
+
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.TokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *asanaConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckAsanaReactions
+}
@@ -0,0 +1,49 @@
+package connector
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Asana(test *testing.T) {
+	callbackLink := os.Getenv("ASANA_LOGIN_CALLBACK")
+
+	expectedRes := "https://app.asana.com/-/oauth_authorize?" +
+		clientIdParam + os.Getenv("ASANA_CLIENT_ID") +
+		redirectUriParam + callbackLink +
+		codeResponseType +
+		"&scope=default email profile"
+
+	res := oauth2Asana("login")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestAsanaAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service", func(test *testing.T) {
+		_, err := newAsanaConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login", func(test *testing.T) {
+		_, err := newAsanaConnector().AccessTokenRequest("code", "login", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newAsanaConnector().AccessTokenRequest("code", "invalid", "web", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+}
+
+func TestAsanaRefreshTokenRequest(test *testing.T) {
+	_, err := newAsanaConnector().RefreshTokenRequest("refreshToken")
+
+	require.NoError(test, err)
+}
@@ -0,0 +1,244 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+const bearerType = "Bearer "
+const contentType = "Content-Type"
+const contentTypeUrlEncoded = "application/x-www-form-urlencoded"
+
+const invalidCallbackTypeMessage = "Invalid callback type"
+const unknownServiceMessage = "Unknown service"
+const unsupportedOperationMessage = "Operation not supported by this service"
+
+const grantTypeRefreshToken = "refresh_token"
+const grantTypeAuthorization = "authorization_code"
+const oneYearSecond = 31536000
+
+const clientIdParam = "client_id="
+const clientSecretParam = "&client_secret="
+const codeParam = "&code="
+const grantTypeParam = "&grant_type="
+const redirectUriParam = "&redirect_uri="
+const stateParam = "&state="
+const codeChallengeParam = "&code_challenge="
+const codeChallengeMethodParam = "&code_challenge_method=S256"
+const codeVerifierParam = "&code_verifier="
+const codeResponseType = "&response_type=code"
+
+type baseConnector struct {
+	definition  entities.ServiceDefinition
+	oauthConfig entities.OAuthConfig
+}
+
+func (self *baseConnector) Definition() entities.ServiceDefinition {
+	return self.definition
+}
+
+func (self *baseConnector) OAuthConfig() entities.OAuthConfig {
+	return self.oauthConfig
+}
+
+func (self *baseConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return "", fmt.Errorf(unsupportedOperationMessage)
+}
+
+func (self *baseConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	return nil, fmt.Errorf(unsupportedOperationMessage)
+}
+
+func (self *baseConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	return nil, fmt.Errorf(unsupportedOperationMessage)
+}
+
+func (self *baseConnector) RevokeTokenRequest(accessToken, refreshToken string) (*http.Request, error) {
+	if self.oauthConfig.RevokeUrl == "" {
+		return nil, fmt.Errorf(unsupportedOperationMessage)
+	}
+	return genericRevokeTokenRequest(self.oauthConfig.RevokeUrl, accessToken, refreshToken)
+}
+
+func (self *baseConnector) UserInfoRequest(accessToken string) (*http.Request, error) {
+	if self.oauthConfig.UserInfoUrl == "" {
+		return nil, fmt.Errorf(unsupportedOperationMessage)
+	}
+	return getOAuth2UserEmailRequest(self.oauthConfig.UserInfoUrl, accessToken)
+}
+
+func (self *baseConnector) DecodeUserInfo(res *http.Response) (entities.UserInfo, error) {
+	var userInfo entities.UserInfo
+
+	err := json.NewDecoder(res.Body).Decode(&userInfo)
+	if err != nil {
+		return userInfo, err
+	}
+	return userInfo, nil
+}
+
+func (self *baseConnector) ParseWebhook(headers http.Header, body []byte) (entities.WebhookEvent, error) {
+	return entities.WebhookEvent{}, fmt.Errorf(unsupportedOperationMessage)
+}
+
+func (self *baseConnector) VerifyWebhook(headers http.Header, body []byte, secret string) bool {
+	return false
+}
+
+func (self *baseConnector) PollJobs() []service.PollJob {
+	return nil
+}
+
+func (self *baseConnector) ReactionHandler() service.ReactionHandler {
+	return nil
+}
+
+// Parameter of an action or a reaction as the clients render it, an exhaustive parameter only accepts its values
+func newParameter(name, parameterType string, values []string, isExhaustive bool) map[string]interface{} {
+	if values == nil {
+		values = []string{}
+	}
+	return map[string]interface{}{
+		"name":         name,
+		"type":         parameterType,
+		"route":        nil,
+		"values":       values,
+		"isexhaustive": isExhaustive,
+	}
+}
+
+func getCallbackAndClientId(callbackType, serviceName string, isIdNecessary bool) (string, string) {
+	var callbackLink, clientID string
+
+	if callbackType == "login" {
+		if isIdNecessary {
+			clientID = os.Getenv(serviceName + "_LOGIN_CLIENT_ID")
+		}
+		callbackLink = os.Getenv(serviceName + "_LOGIN_CALLBACK")
+	} else if callbackType == "service" {
+		if isIdNecessary {
+			clientID = os.Getenv(serviceName + "_SERVICE_CLIENT_ID")
+		}
+		callbackLink = os.Getenv(serviceName + "_SERVICE_CALLBACK")
+	}
+	return callbackLink, clientID
+}
+
+// The state is appended to every authorization URL, the code challenge only when the service supports PKCE
+func authorizationParams(state, codeChallenge string) string {
+	params := stateParam + url.QueryEscape(state)
+	if codeChallenge != "" {
+		params += codeChallengeParam + codeChallenge + codeChallengeMethodParam
+	}
+	return params
+}
+
+func codeVerifierBody(codeVerifier string) string {
+	if codeVerifier == "" {
+		return ""
+	}
+	return codeVerifierParam + codeVerifier
+}
+
+func genericAccessTokenRequest(tokenUrl, code, callbackUrl, codeVerifier string) (*http.Request, error) {
+	jsonBody := fmt.Sprintf(
+		"grant_type=%s&code=%s&redirect_uri=%s",
+		grantTypeAuthorization,
+		code,
+		callbackUrl,
+	) + codeVerifierBody(codeVerifier)
+
+	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+	return request, nil
+}
+
+func genericRefreshTokenRequest(tokenUrl, refreshToken string) (*http.Request, error) {
+	jsonBody := fmt.Sprintf(
+		"grant_type=%s&refresh_token=%s",
+		grantTypeRefreshToken,
+		refreshToken,
+	)
+
+	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+	return request, nil
+}
+
+// Token revocation of RFC 7009, revoking the refresh token also revokes the access tokens it issued
+func genericRevokeTokenRequest(revokeUrl, accessToken, refreshToken string) (*http.Request, error) {
+	token, tokenTypeHint := accessToken, "access_token"
+	if refreshToken != "" {
+		token, tokenTypeHint = refreshToken, grantTypeRefreshToken
+	}
+	jsonBody := fmt.Sprintf(
+		"token=%s&token_type_hint=%s",
+		url.QueryEscape(token),
+		tokenTypeHint,
+	)
+
+	request, errRequest := http.NewRequest("POST", revokeUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+	return request, nil
+}
+
+func genericJsonBodyRefreshToken(clientId, secretId, refreshToken string) string {
+	return fmt.Sprintf(
+		"client_id=%s&client_secret=%s&grant_type=%s&refresh_token=%s",
+		clientId,
+		secretId,
+		grantTypeRefreshToken,
+		refreshToken,
+	)
+}
+
+func getOAuth2UserEmailRequest(userInfoUrl, accessToken string) (*http.Request, error) {
+	request, errRequest := http.NewRequest("GET", userInfoUrl, nil)
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set("Authorization", bearerType+accessToken)
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+	return request, nil
+}
+
+func decodeUserInfoWithMultipleResults(res *http.Response) (entities.UserInfo, error) {
+	var userInfos []entities.UserInfo
+
+	err := json.NewDecoder(res.Body).Decode(&userInfos)
+	if err != nil {
+		return entities.UserInfo{}, err
+	}
+	if len(userInfos) == 0 {
+		return entities.UserInfo{}, fmt.Errorf("No user information found")
+	}
+	return userInfos[0], nil
+}
+
+func newWebhookActionEvent(eventName string, webhookJsonDataBytes []byte) entities.ActionEvent {
+	var webhookJsonData map[string]interface{}
+
+	if len(webhookJsonDataBytes) > 0 {
+		json.Unmarshal(webhookJsonDataBytes, &webhookJsonData)
+	}
+	return entities.ActionEvent{
+		"event_name": eventName,
+		"event":      webhookJsonData,
+	}
+}
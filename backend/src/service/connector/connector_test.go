@@ -0,0 +1,213 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func TestBaseConnector(test *testing.T) {
+	test.Run("Unsupported operations", func(test *testing.T) {
+		connector := &baseConnector{}
+
+		_, err := connector.AuthorizationUrl("login", "web", "state", "")
+		require.EqualError(test, err, unsupportedOperationMessage)
+
+		_, err = connector.AccessTokenRequest("code", "login", "web", "")
+		require.EqualError(test, err, unsupportedOperationMessage)
+
+		_, err = connector.RefreshTokenRequest("refreshToken")
+		require.EqualError(test, err, unsupportedOperationMessage)
+
+		_, err = connector.RevokeTokenRequest("accessToken", "refreshToken")
+		require.EqualError(test, err, unsupportedOperationMessage)
+
+		_, err = connector.UserInfoRequest("accessToken")
+		require.EqualError(test, err, unsupportedOperationMessage)
+
+		_, err = connector.ParseWebhook(http.Header{}, nil)
+		require.EqualError(test, err, unsupportedOperationMessage)
+
+		require.False(test, connector.VerifyWebhook(http.Header{}, nil, "secret"))
+		require.Empty(test, connector.PollJobs())
+	})
+
+	test.Run("User info request", func(test *testing.T) {
+		connector := &baseConnector{
+			oauthConfig: entities.OAuthConfig{UserInfoUrl: "https://discord.com/api/users/@me"},
+		}
+
+		request, err := connector.UserInfoRequest("accessToken")
+
+		require.NoError(test, err)
+		assert.Equal(test, bearerType+"accessToken", request.Header.Get("Authorization"))
+	})
+
+	test.Run("Decode user info", func(test *testing.T) {
+		connector := &baseConnector{}
+
+		res := &http.Response{
+			Body: io.NopCloser(bytes.NewReader([]byte(`{"email": "test@test.com"}`))),
+		}
+
+		userInfo, err := connector.DecodeUserInfo(res)
+
+		require.NoError(test, err)
+		assert.Equal(test, "test@test.com", userInfo.Email)
+	})
+}
+
+func TestGetCallbackAndClientId(test *testing.T) {
+	test.Run("Login", func(test *testing.T) {
+
+		serviceName := "SPOTIFY"
+
+		callback, clientId := getCallbackAndClientId("login", "SPOTIFY", true)
+
+		resCallback := serviceName + "_LOGIN_CALLBACK"
+		resClientId := serviceName + "_LOGIN_CLIENT_ID"
+
+		assert.Equal(test, os.Getenv(resCallback), callback)
+		assert.Equal(test, os.Getenv(resClientId), clientId)
+	})
+
+	test.Run("Service", func(test *testing.T) {
+
+		serviceName := "SPOTIFY"
+
+		callback, clientId := getCallbackAndClientId("service", "SPOTIFY", true)
+
+		resCallback := serviceName + "_SERVICE_CALLBACK"
+		resClientId := serviceName + "_SERVICE_CLIENT_ID"
+
+		assert.Equal(test, os.Getenv(resCallback), callback)
+		assert.Equal(test, os.Getenv(resClientId), clientId)
+	})
+}
+
+func TestGenericAccessTokenRequest(test *testing.T) {
+	test.Run("Success", func(test *testing.T) {
+		_, err := genericAccessTokenRequest("url", "code", "callback", "")
+
+		assert.NoError(test, err, "Error should be nil")
+	})
+
+	test.Run("Code Verifier", func(test *testing.T) {
+		request, err := genericAccessTokenRequest("url", "code", "callback", "verifier")
+		require.NoError(test, err)
+
+		body, _ := io.ReadAll(request.Body)
+		assert.Equal(test, "grant_type=authorization_code&code=code&redirect_uri=callback&code_verifier=verifier", string(body))
+	})
+
+	test.Run("Failure", func(test *testing.T) {
+		_, err := genericAccessTokenRequest(":", "code", "callback", "")
+
+		require.Error(test, err)
+	})
+}
+
+func TestAuthorizationParams(test *testing.T) {
+	assert.Equal(test, stateParam+"a.b%2Bc", authorizationParams("a.b+c", ""))
+	assert.Equal(test, stateParam+"state"+codeChallengeParam+"challenge"+codeChallengeMethodParam, authorizationParams("state", "challenge"))
+}
+
+func TestGenericRefreshTokenRequest(test *testing.T) {
+	test.Run("Success", func(test *testing.T) {
+		_, err := genericRefreshTokenRequest("tokenurl", "refreshToken")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Failure", func(test *testing.T) {
+		_, err := genericRefreshTokenRequest(":", "refreshToken")
+
+		require.Error(test, err)
+	})
+}
+
+func TestGenericRevokeTokenRequest(test *testing.T) {
+	test.Run("Refresh token", func(test *testing.T) {
+		request, err := genericRevokeTokenRequest("revokeurl", "accessToken", "refresh/Token")
+		require.NoError(test, err)
+
+		body, _ := io.ReadAll(request.Body)
+		assert.Equal(test, "token=refresh%2FToken&token_type_hint=refresh_token", string(body))
+		assert.Equal(test, contentTypeUrlEncoded, request.Header.Get(contentType))
+	})
+
+	test.Run("Access token", func(test *testing.T) {
+		request, err := genericRevokeTokenRequest("revokeurl", "accessToken", "")
+		require.NoError(test, err)
+
+		body, _ := io.ReadAll(request.Body)
+		assert.Equal(test, "token=accessToken&token_type_hint=access_token", string(body))
+	})
+
+	test.Run("Failure", func(test *testing.T) {
+		_, err := genericRevokeTokenRequest(":", "accessToken", "")
+
+		require.Error(test, err)
+	})
+}
+
+func TestGenericJsonBodyRefreshToken(test *testing.T) {
+	expectedRes := fmt.Sprintf(
+		"client_id=%s&client_secret=%s&grant_type=%s&refresh_token=%s",
+		"clientId",
+		"secretId",
+		grantTypeRefreshToken,
+		"refreshToken",
+	)
+
+	res := genericJsonBodyRefreshToken("clientId", "secretId", "refreshToken")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestGetOAuth2UserEmailRequest(test *testing.T) {
+	test.Run("Success", func(test *testing.T) {
+		_, err := getOAuth2UserEmailRequest("https://tools.aimylogic.com/api/now?tz=Europe/Paris", "accessToken")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Failure", func(test *testing.T) {
+		_, err := getOAuth2UserEmailRequest(":", "accessToken")
+
+		require.Error(test, err)
+	})
+}
+
+func TestDecodeUserInfoWithMultipleResults(test *testing.T) {
+	userInfos := []entities.UserInfo{
+		{Email: "test@test.com"},
+	}
+
+	responseBody, _ := json.Marshal(userInfos)
+
+	res := &http.Response{
+		Body:       io.NopCloser(bytes.NewReader(responseBody)),
+		StatusCode: http.StatusOK,
+	}
+
+	_, err := decodeUserInfoWithMultipleResults(res)
+
+	require.NoError(test, err)
+}
+
+func TestNewWebhookActionEvent(test *testing.T) {
+	event := newWebhookActionEvent("watch", []byte(`{"action": "started"}`))
+
+	assert.Equal(test, "watch", event["event_name"])
+	assert.Equal(test, map[string]interface{}{"action": "started"}, event["event"])
+}
@@ -0,0 +1,110 @@
+package connector
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type discordConnector struct {
+	baseConnector
+}
+
+func newDiscordConnector() *discordConnector {
+	return &discordConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Discord",
+				Color:        "#5865F2",
+				Logo:         "/logos/discord.svg",
+				Description:  "Post messages and threads in your Discord servers",
+				IsAuthNeeded: true,
+				Actions:      []entities.AboutAction{},
+				Reactions: []entities.AboutReaction{
+					{Name: "Post a message to a channel", Description: "Post a message to a channel of a server"},
+					{Name: "Create a thread in a channel", Description: "Create a new thread in a channel of a server"},
+				},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl:        "https://discord.com/api/v10/oauth2/token",
+				UserInfoUrl:     "https://discord.com/api/users/@me",
+				CanRefreshToken: true,
+				RevokeUrl:       "https://discord.com/api/v10/oauth2/token/revoke",
+			},
+		},
+	}
+}
+
+func oauth2Discord(callbackType string) string {
+	callbackLink, _ := getCallbackAndClientId(callbackType, "DISCORD", false)
+	var scope string
+
+	if callbackType == "login" {
+		scope = "identify+email"
+	} else if callbackType == "service" {
+		scope = "identify+email+guilds+bot"
+	}
+
+	return ("https://discord.com/oauth2/authorize?" +
+		clientIdParam + os.Getenv("DISCORD_CLIENT_ID") +
+		"&permissions=141376" +
+		codeResponseType +
+		redirectUriParam + callbackLink +
+		"&scope=" + scope)
+}
+
+func (self *discordConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Discord(callbackType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *discordConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var discordCallback string
+
+	if callbackType == "service" {
+		discordCallback = os.Getenv("DISCORD_SERVICE_CALLBACK")
+	} else if callbackType == "login" {
+		discordCallback = os.Getenv("DISCORD_LOGIN_CALLBACK")
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	request, err := genericAccessTokenRequest(self.oauthConfig.TokenUrl, code, discordCallback, codeVerifier)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(os.Getenv("DISCORD_CLIENT_ID"), os.Getenv("DISCORD_CLIENT_SECRET"))
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *discordConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	request, err := genericRefreshTokenRequest(self.oauthConfig.TokenUrl, refreshToken)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(os.Getenv("DISCORD_CLIENT_ID"), os.Getenv("DISCORD_CLIENT_SECRET"))
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *discordConnector) RevokeTokenRequest(accessToken, refreshToken string) (*http.Request, error) {
+	request, err := genericRevokeTokenRequest(self.oauthConfig.RevokeUrl, accessToken, refreshToken)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(os.Getenv("DISCORD_CLIENT_ID"), os.Getenv("DISCORD_CLIENT_SECRET"))
+
+	return request, nil
+}
+
+func (self *discordConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckDiscordReactions
+}
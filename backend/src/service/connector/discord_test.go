@@ -0,0 +1,76 @@
+package connector
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Discord(test *testing.T) {
+	test.Run("Login", func(test *testing.T) {
+		scope := "identify+email"
+
+		expectedRes := "https://discord.com/oauth2/authorize?" +
+			clientIdParam + os.Getenv("DISCORD_CLIENT_ID") +
+			"&permissions=141376" +
+			codeResponseType +
+			redirectUriParam + os.Getenv("DISCORD_LOGIN_CALLBACK") +
+			"&scope=" + scope
+
+		res := oauth2Discord("login")
+
+		assert.Equal(test, res, expectedRes)
+	})
+
+	test.Run("Service", func(test *testing.T) {
+		scope := "identify+email+guilds+bot"
+
+		expectedRes := "https://discord.com/oauth2/authorize?" +
+			clientIdParam + os.Getenv("DISCORD_CLIENT_ID") +
+			"&permissions=141376" +
+			codeResponseType +
+			redirectUriParam + os.Getenv("DISCORD_LOGIN_CALLBACK") +
+			"&scope=" + scope
+
+		res := oauth2Discord("service")
+
+		assert.Equal(test, res, expectedRes)
+	})
+}
+
+func TestDiscordAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service", func(test *testing.T) {
+		_, err := newDiscordConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login", func(test *testing.T) {
+		_, err := newDiscordConnector().AccessTokenRequest("code", "login", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newDiscordConnector().AccessTokenRequest("code", "invalid", "web", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+}
+
+func TestDiscordRefreshTokenRequest(test *testing.T) {
+	_, err := newDiscordConnector().RefreshTokenRequest("refreshToken")
+
+	require.NoError(test, err)
+}
+
+func TestDiscordRevokeTokenRequest(test *testing.T) {
+	request, err := newDiscordConnector().RevokeTokenRequest("accessToken", "refreshToken")
+
+	require.NoError(test, err)
+	_, _, hasBasicAuth := request.BasicAuth()
+	assert.True(test, hasBasicAuth)
+	assert.Equal(test, contentTypeUrlEncoded, request.Header.Get(contentType))
+}
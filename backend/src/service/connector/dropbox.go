@@ -0,0 +1,107 @@
+package connector
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type dropboxConnector struct {
+	baseConnector
+}
+
+func newDropboxConnector() *dropboxConnector {
+	return &dropboxConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Dropbox",
+				Color:        "#0061FF",
+				Logo:         "/logos/dropbox.svg",
+				Description:  "Manage files and folders in your Dropbox",
+				IsAuthNeeded: true,
+				Actions:      []entities.AboutAction{},
+				Reactions: []entities.AboutReaction{
+					{Name: "Move file or folder", Description: "Move a file or a folder to another path"},
+					{Name: "Create a text file", Description: "Create a text file with the given content"},
+					{Name: "Append to a text file", Description: "Append content to an existing text file"},
+				},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl:        "https://api.dropboxapi.com/oauth2/token",
+				CanRefreshToken: true,
+				SupportsPKCE:    true,
+				RevokeUrl:       "https://api.dropboxapi.com/2/auth/token/revoke",
+			},
+		},
+	}
+}
+
+func oauth2Dropbox(callbackType string) string {
+	callbackLink, _ := getCallbackAndClientId(callbackType, "DROPBOX", false)
+
+	return ("https://www.dropbox.com/oauth2/authorize?" +
+		clientIdParam + url.QueryEscape(os.Getenv("DROPBOX_CLIENT_ID")) +
+		redirectUriParam + callbackLink +
+		codeResponseType + "&token_access_type=offline")
+}
+
+func (self *dropboxConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Dropbox(callbackType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *dropboxConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var dropboxCallback string
+
+	if callbackType == "service" {
+		dropboxCallback = os.Getenv("DROPBOX_SERVICE_CALLBACK")
+	} else if callbackType == "login" {
+		dropboxCallback = os.Getenv("DROPBOX_LOGIN_CALLBACK")
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	request, err := genericAccessTokenRequest(self.oauthConfig.TokenUrl, code, dropboxCallback, codeVerifier)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(os.Getenv("DROPBOX_CLIENT_ID"), os.Getenv("DROPBOX_CLIENT_SECRET"))
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *dropboxConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	tokenUrl := "https://api.dropbox.com/oauth2/token"
+	jsonBody := genericJsonBodyRefreshToken(os.Getenv("DROPBOX_CLIENT_ID"), os.Getenv("DROPBOX_CLIENT_SECRET"), refreshToken)
+
+	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+// Dropbox revokes the token used to authenticate the request, it takes no body
+func (self *dropboxConnector) RevokeTokenRequest(accessToken, refreshToken string) (*http.Request, error) {
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.RevokeUrl, nil)
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set("Authorization", bearerType+accessToken)
+
+	return request, nil
+}
+
+func (self *dropboxConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckDropboxReactions
+}
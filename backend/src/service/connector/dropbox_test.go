@@ -0,0 +1,57 @@
+package connector
+
+import (
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Dropbox(test *testing.T) {
+	callbackLink := os.Getenv("DROPBOX_LOGIN_CALLBACK")
+
+	expectedRes := "https://www.dropbox.com/oauth2/authorize?" +
+		clientIdParam + url.QueryEscape(os.Getenv("DROPBOX_CLIENT_ID")) +
+		redirectUriParam + callbackLink +
+		codeResponseType + "&token_access_type=offline"
+
+	res := oauth2Dropbox("login")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestDropboxAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service", func(test *testing.T) {
+		_, err := newDropboxConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login", func(test *testing.T) {
+		_, err := newDropboxConnector().AccessTokenRequest("code", "login", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newDropboxConnector().AccessTokenRequest("code", "invalid", "web", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+}
+
+func TestDropboxRefreshTokenRequest(test *testing.T) {
+	_, err := newDropboxConnector().RefreshTokenRequest("refreshToken")
+
+	require.NoError(test, err)
+}
+
+func TestDropboxRevokeTokenRequest(test *testing.T) {
+	request, err := newDropboxConnector().RevokeTokenRequest("accessToken", "refreshToken")
+
+	require.NoError(test, err)
+	assert.Equal(test, bearerType+"accessToken", request.Header.Get("Authorization"))
+	assert.Nil(test, request.Body)
+}
@@ -0,0 +1,31 @@
+package connector
+
+import (
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type emailConnector struct {
+	baseConnector
+}
+
+func newEmailConnector() *emailConnector {
+	return &emailConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:        "Email",
+				Color:       "#1A82E2",
+				Logo:        "/logos/email.svg",
+				Description: "Send emails",
+				Actions:     []entities.AboutAction{},
+				Reactions: []entities.AboutReaction{
+					{Name: "Send me an email", Description: "Send an email to your account address"},
+				},
+			},
+		},
+	}
+}
+
+func (self *emailConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckSendEmailReactions
+}
@@ -0,0 +1,203 @@
+package connector
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+var githubPollVariables = []string{"github.repository", "github.count"}
+var githubWebhookVariables = []string{"repository.name", "event_name", "event"}
+
+type githubConnector struct {
+	baseConnector
+}
+
+func newGithubConnector() *githubConnector {
+	return &githubConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Github",
+				Color:        "#24292F",
+				Logo:         "/logos/github.svg",
+				Description:  "Follow the activity of your GitHub repositories",
+				IsAuthNeeded: true,
+				Actions: []entities.AboutAction{
+					{Name: "New repository", Description: "A new repository is created on your account", Variables: githubPollVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New issue assignated", Description: "A new issue is assigned to you", Variables: githubPollVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New pull request", Description: "A new pull request is opened on a repository", Variables: githubPollVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New branch", Description: "A new branch is created on a repository", Variables: githubPollVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New push", Description: "A new commit is pushed on a repository", Variables: githubPollVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New star", Description: "A repository is starred", Variables: githubWebhookVariables},
+					{Name: "Visibility update", Description: "A repository is made public", Variables: githubWebhookVariables},
+					{Name: "Milestone update", Description: "A milestone of a repository is updated", Variables: githubWebhookVariables},
+					{Name: "Release update", Description: "A release of a repository is updated", Variables: githubWebhookVariables},
+					{Name: "Wiki update", Description: "A wiki page of a repository is updated", Variables: githubWebhookVariables},
+					{Name: "Workflow job update", Description: "A workflow job of a repository is updated", Variables: githubWebhookVariables},
+					{Name: "Workflow run update", Description: "A workflow run of a repository is updated", Variables: githubWebhookVariables},
+					{Name: "Fork update", Description: "A repository is forked", Variables: githubWebhookVariables},
+				},
+				Reactions: []entities.AboutReaction{},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl:             "https://github.com/login/oauth/access_token",
+				UserInfoUrl:          "https://api.github.com/user/emails",
+				DefaultTokenLifetime: oneYearSecond,
+				SupportsPKCE:         true,
+				RevokeUrl:            "https://api.github.com/applications/%s/grant",
+			},
+		},
+	}
+}
+
+func githubWebhooksEventsToActions() map[string]string {
+	return map[string]string{
+		"watch":        "New star",
+		"public":       "Visibility update",
+		"milestone":    "Milestone update",
+		"release":      "Release update",
+		"gollum":       "Wiki update",
+		"workflow_job": "Workflow job update",
+		"workflow_run": "Workflow run update",
+		"fork":         "Fork update",
+	}
+}
+
+func oauth2Github(callbackType string) string {
+	callbackLink, clientID := getCallbackAndClientId(callbackType, "GITHUB", true)
+
+	return ("https://github.com/login/oauth/authorize?" +
+		clientIdParam + clientID +
+		redirectUriParam + callbackLink +
+		"&scope=repo admin:org user")
+}
+
+func (self *githubConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Github(callbackType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *githubConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var githubCallback, githubClientID, githubClientSecret string
+
+	if callbackType == "service" {
+		githubCallback = os.Getenv("GITHUB_SERVICE_CALLBACK")
+		githubClientID = os.Getenv("GITHUB_SERVICE_CLIENT_ID")
+		githubClientSecret = os.Getenv("GITHUB_SERVICE_CLIENT_SECRET")
+	} else if callbackType == "login" {
+		githubCallback = os.Getenv("GITHUB_LOGIN_CALLBACK")
+		githubClientID = os.Getenv("GITHUB_LOGIN_CLIENT_ID")
+		githubClientSecret = os.Getenv("GITHUB_LOGIN_CLIENT_SECRET")
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	jsonBody := fmt.Sprintf(
+		"client_id=%s&client_secret=%s&code=%s&redirect_uri=%s",
+		githubClientID,
+		githubClientSecret,
+		code,
+		githubCallback,
+	)
+	jsonBody += codeVerifierBody(codeVerifier)
+
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.TokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+	request.Header.Set("Accept", "application/json")
+
+	return request, nil
+}
+
+// Deletes the authorization of the OAuth app, the linked tokens were issued to its service client
+func (self *githubConnector) RevokeTokenRequest(accessToken, refreshToken string) (*http.Request, error) {
+	jsonBody, err := json.Marshal(map[string]string{"access_token": accessToken})
+	if err != nil {
+		return nil, err
+	}
+
+	revokeUrl := fmt.Sprintf(self.oauthConfig.RevokeUrl, os.Getenv("GITHUB_SERVICE_CLIENT_ID"))
+	request, errRequest := http.NewRequest("DELETE", revokeUrl, bytes.NewBuffer(jsonBody))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.SetBasicAuth(os.Getenv("GITHUB_SERVICE_CLIENT_ID"), os.Getenv("GITHUB_SERVICE_CLIENT_SECRET"))
+	request.Header.Set(contentType, "application/json")
+	request.Header.Set("Accept", "application/vnd.github+json")
+
+	return request, nil
+}
+
+func (self *githubConnector) DecodeUserInfo(res *http.Response) (entities.UserInfo, error) {
+	return decodeUserInfoWithMultipleResults(res)
+}
+
+func (self *githubConnector) ParseWebhook(headers http.Header, body []byte) (entities.WebhookEvent, error) {
+	var webhookResponse entities.GithubWebhookTriggeredResponse
+	if len(body) > 0 {
+		err := json.Unmarshal(body, &webhookResponse)
+		if err != nil {
+			return entities.WebhookEvent{}, err
+		}
+	}
+	if webhookResponse.Repository.Name == "" {
+		return entities.WebhookEvent{}, fmt.Errorf("Incorrect repository name")
+	}
+
+	eventName := headers.Get("X-Github-Event")
+	if eventName == "ping" {
+		return entities.WebhookEvent{}, nil
+	}
+
+	actionName, actionNameExists := githubWebhooksEventsToActions()[eventName]
+	if !actionNameExists {
+		return entities.WebhookEvent{}, fmt.Errorf("No action mapped with this event")
+	}
+
+	event := newWebhookActionEvent(eventName, body)
+	event["repository"] = map[string]interface{}{"name": webhookResponse.Repository.Name}
+
+	return entities.WebhookEvent{
+		ActionName: actionName,
+		ParamKey:   "repository",
+		ParamValue: webhookResponse.Repository.Name,
+		DeliveryId: headers.Get("X-GitHub-Delivery"),
+		Event:      event,
+	}, nil
+}
+
+// GitHub signs each delivery with the secret of the hook: "sha256=" followed by the hex HMAC-SHA256 of the body
+func (self *githubConnector) VerifyWebhook(headers http.Header, body []byte, secret string) bool {
+	signature, hasPrefix := strings.CutPrefix(headers.Get("X-Hub-Signature-256"), "sha256=")
+	if !hasPrefix || secret == "" {
+		return false
+	}
+
+	signatureBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(signatureBytes, mac.Sum(nil))
+}
+
+func (self *githubConnector) PollJobs() []service.PollJob {
+	return []service.PollJob{
+		{Schedule: "@every 1m", Poll: service.WorkflowService.CheckNewGithubWorkflows},
+		{Schedule: "@every 1m", Poll: service.WorkflowService.CheckGithubActions},
+	}
+}
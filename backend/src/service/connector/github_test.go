@@ -0,0 +1,142 @@
+package connector
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Github(test *testing.T) {
+	clientID := os.Getenv("GITHUB_LOGIN_CLIENT_ID")
+	callbackLink := os.Getenv("GITHUB_LOGIN_CALLBACK")
+
+	expectedRes := "https://github.com/login/oauth/authorize?" +
+		clientIdParam + clientID +
+		redirectUriParam + callbackLink +
+		"&scope=repo admin:org user"
+
+	res := oauth2Github("login")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestGithubAuthorizationUrl(test *testing.T) {
+	res, err := newGithubConnector().AuthorizationUrl("login", "web", "state", "challenge")
+
+	require.NoError(test, err)
+	assert.Equal(test, oauth2Github("login")+stateParam+"state"+codeChallengeParam+"challenge"+codeChallengeMethodParam, res)
+}
+
+func TestGithubAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service", func(test *testing.T) {
+		_, err := newGithubConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login", func(test *testing.T) {
+		_, err := newGithubConnector().AccessTokenRequest("code", "login", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newGithubConnector().AccessTokenRequest("code", "invalid", "web", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+}
+
+func TestGithubParseWebhook(test *testing.T) {
+	connector := newGithubConnector()
+
+	test.Run("Success", func(test *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-GitHub-Event", "watch")
+		headers.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+
+		webhookEvent, err := connector.ParseWebhook(headers, []byte(`{"repository": {"full_name": "owner/repo"}}`))
+
+		require.NoError(test, err)
+		assert.Equal(test, "New star", webhookEvent.ActionName)
+		assert.Equal(test, "72d3162e-cc78-11e3-81ab-4c9367dc0958", webhookEvent.DeliveryId)
+		assert.Equal(test, "repository", webhookEvent.ParamKey)
+		assert.Equal(test, "owner/repo", webhookEvent.ParamValue)
+		assert.Equal(test, "watch", webhookEvent.Event["event_name"])
+	})
+
+	test.Run("Ping", func(test *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-GitHub-Event", "ping")
+
+		webhookEvent, err := connector.ParseWebhook(headers, []byte(`{"repository": {"full_name": "owner/repo"}}`))
+
+		require.NoError(test, err)
+		assert.Empty(test, webhookEvent.ActionName)
+	})
+
+	test.Run("Incorrect repository", func(test *testing.T) {
+		_, err := connector.ParseWebhook(http.Header{}, []byte(`{}`))
+
+		require.EqualError(test, err, "Incorrect repository name")
+	})
+
+	test.Run("Unknown event", func(test *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-GitHub-Event", "unknown")
+
+		_, err := connector.ParseWebhook(headers, []byte(`{"repository": {"full_name": "owner/repo"}}`))
+
+		require.EqualError(test, err, "No action mapped with this event")
+	})
+}
+
+func TestGithubVerifyWebhook(test *testing.T) {
+	connector := newGithubConnector()
+	body := []byte(`{"repository": {"full_name": "owner/repo"}}`)
+	signature := "sha256=" + hex.EncodeToString(hmacSha256([]byte("secret"), body))
+
+	newHeaders := func(signature string) http.Header {
+		headers := http.Header{}
+		headers.Set("X-Hub-Signature-256", signature)
+		return headers
+	}
+
+	test.Run("Valid Signature", func(test *testing.T) {
+		require.True(test, connector.VerifyWebhook(newHeaders(signature), body, "secret"))
+	})
+
+	test.Run("Invalid Signatures", func(test *testing.T) {
+		require.False(test, connector.VerifyWebhook(newHeaders(signature), body, "other"))
+		require.False(test, connector.VerifyWebhook(newHeaders(signature), []byte(`{}`), "secret"))
+		require.False(test, connector.VerifyWebhook(newHeaders(signature[len("sha256="):]), body, "secret"))
+		require.False(test, connector.VerifyWebhook(newHeaders("sha256=zz"), body, "secret"))
+		require.False(test, connector.VerifyWebhook(http.Header{}, body, "secret"))
+		require.False(test, connector.VerifyWebhook(newHeaders(signature), body, ""))
+	})
+}
+
+func hmacSha256(key, body []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func TestGithubRevokeTokenRequest(test *testing.T) {
+	test.Setenv("GITHUB_SERVICE_CLIENT_ID", "clientId")
+
+	request, err := newGithubConnector().RevokeTokenRequest("accessToken", "")
+	require.NoError(test, err)
+
+	body, _ := io.ReadAll(request.Body)
+	assert.Equal(test, "DELETE", request.Method)
+	assert.Equal(test, "https://api.github.com/applications/clientId/grant", request.URL.String())
+	assert.JSONEq(test, `{"access_token": "accessToken"}`, string(body))
+}
@@ -0,0 +1,177 @@
+package connector
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+var gitlabVariables = []string{"project.id", "event_name", "event"}
+
+type gitlabConnector struct {
+	baseConnector
+}
+
+func newGitlabConnector() *gitlabConnector {
+	return &gitlabConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Gitlab",
+				Color:        "#FC6D26",
+				Logo:         "/logos/gitlab.svg",
+				Description:  "Follow the activity of your GitLab projects",
+				IsAuthNeeded: true,
+				Actions: []entities.AboutAction{
+					{Name: "New push", Description: "A new commit is pushed on a project", Variables: gitlabVariables},
+					{Name: "Merge request update", Description: "A merge request of a project is updated", Variables: gitlabVariables},
+					{Name: "Issue update", Description: "An issue of a project is updated", Variables: gitlabVariables},
+					{Name: "Comment update", Description: "A comment is posted on a project", Variables: gitlabVariables},
+					{Name: "New tag push", Description: "A new tag is pushed on a project", Variables: gitlabVariables},
+					{Name: "Wiki page update", Description: "A wiki page of a project is updated", Variables: gitlabVariables},
+					{Name: "Release update", Description: "A release of a project is updated", Variables: gitlabVariables},
+					{Name: "Feature flag update", Description: "A feature flag of a project is updated", Variables: gitlabVariables},
+					{Name: "Pipeline update", Description: "A pipeline of a project is updated", Variables: gitlabVariables},
+					{Name: "Job update", Description: "A job of a project is updated", Variables: gitlabVariables},
+					{Name: "Deployment update", Description: "A deployment of a project is updated", Variables: gitlabVariables},
+					{Name: "Emoji update", Description: "An emoji reaction is added on a project", Variables: gitlabVariables},
+				},
+				Reactions: []entities.AboutReaction{},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl:        "https://gitlab.com/oauth/token",
+				UserInfoUrl:     "https://gitlab.com/api/v4/user/emails",
+				CanRefreshToken: true,
+				SupportsPKCE:    true,
+			},
+		},
+	}
+}
+
+func gitlabWebhooksEventsToActions() map[string]string {
+	return map[string]string{
+		"Push Hook":          "New push",
+		"Merge Request Hook": "Merge request update",
+		"Issue Hook":         "Issue update",
+		"Note Hook":          "Comment update",
+		"Tag Push Hook":      "New tag push",
+		"Wiki Page Hook":     "Wiki page update",
+		"Release Hook":       "Release update",
+		"Feature Flag Hook":  "Feature flag update",
+		"Pipeline Hook":      "Pipeline update",
+		"Job Hook":           "Job update",
+		"Deployment Hook":    "Deployment update",
+		"Emoji Hook":         "Emoji update",
+	}
+}
+
+func oauth2Gitlab(callbackType string) string {
+	callbackLink, _ := getCallbackAndClientId(callbackType, "GITLAB", false)
+
+	return ("https://gitlab.com/oauth/authorize?" +
+		clientIdParam + os.Getenv("GITLAB_CLIENT_ID") +
+		codeResponseType +
+		redirectUriParam + callbackLink +
+		"&scope=api read_api read_user read_repository write_repository")
+}
+
+func (self *gitlabConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Gitlab(callbackType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *gitlabConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var gitlabCallback string
+
+	if callbackType == "service" {
+		gitlabCallback = os.Getenv("GITLAB_SERVICE_CALLBACK")
+	} else if callbackType == "login" {
+		gitlabCallback = os.Getenv("GITLAB_LOGIN_CALLBACK")
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	jsonBody := clientIdParam + os.Getenv("GITLAB_CLIENT_ID") +
+		clientSecretParam + os.Getenv("GITLAB_CLIENT_SECRET") +
+		codeParam + code +
+		redirectUriParam + gitlabCallback +
+		grantTypeParam + grantTypeAuthorization
+	jsonBody += codeVerifierBody(codeVerifier)
+
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.TokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *gitlabConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	jsonBody := genericJsonBodyRefreshToken(os.Getenv("GITLAB_CLIENT_ID"), os.Getenv("GITLAB_CLIENT_SECRET"), refreshToken)
+	jsonBody += "&redirect_uri=" + os.Getenv("GITLAB_SERVICE_CALLBACK")
+
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.TokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *gitlabConnector) DecodeUserInfo(res *http.Response) (entities.UserInfo, error) {
+	return decodeUserInfoWithMultipleResults(res)
+}
+
+func (self *gitlabConnector) ParseWebhook(headers http.Header, body []byte) (entities.WebhookEvent, error) {
+	var webhookResponse entities.GitlabWebhookTriggeredResponse
+	if len(body) > 0 {
+		err := json.Unmarshal(body, &webhookResponse)
+		if err != nil {
+			return entities.WebhookEvent{}, err
+		}
+	}
+	if webhookResponse.Project.Id == 0 {
+		return entities.WebhookEvent{}, fmt.Errorf("Incorrect project id")
+	}
+
+	eventName := headers.Get("X-Gitlab-Event")
+	actionName, actionNameExists := gitlabWebhooksEventsToActions()[eventName]
+	if !actionNameExists {
+		return entities.WebhookEvent{}, fmt.Errorf("No action mapped with this event")
+	}
+
+	event := newWebhookActionEvent(eventName, body)
+	event["project"] = map[string]interface{}{"id": webhookResponse.Project.Id}
+
+	return entities.WebhookEvent{
+		ActionName: actionName,
+		ParamKey:   "project",
+		ParamValue: strconv.Itoa(webhookResponse.Project.Id),
+		DeliveryId: headers.Get("X-Gitlab-Event-UUID"),
+		Event:      event,
+	}, nil
+}
+
+// GitLab sends the secret token of the hook as is
+func (self *gitlabConnector) VerifyWebhook(headers http.Header, body []byte, secret string) bool {
+	token := headers.Get("X-Gitlab-Token")
+	if token == "" || secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+func (self *gitlabConnector) PollJobs() []service.PollJob {
+	return []service.PollJob{
+		{Schedule: "@every 1m", Poll: service.WorkflowService.CheckNewGitlabWorkflows},
+	}
+}
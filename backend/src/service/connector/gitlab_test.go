@@ -0,0 +1,95 @@
+package connector
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Gitlab(test *testing.T) {
+	callbackLink := os.Getenv("GITLAB_LOGIN_CALLBACK")
+
+	expectedRes := "https://gitlab.com/oauth/authorize?" +
+		clientIdParam + os.Getenv("GITLAB_CLIENT_ID") +
+		codeResponseType +
+		redirectUriParam + callbackLink +
+		"&scope=api read_api read_user read_repository write_repository"
+
+	res := oauth2Gitlab("login")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestGitlabAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service", func(test *testing.T) {
+		_, err := newGitlabConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login", func(test *testing.T) {
+		_, err := newGitlabConnector().AccessTokenRequest("code", "login", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newGitlabConnector().AccessTokenRequest("code", "invalid", "web", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+}
+
+func TestGitlabRefreshTokenRequest(test *testing.T) {
+	_, err := newGitlabConnector().RefreshTokenRequest("refreshToken")
+
+	require.NoError(test, err)
+}
+
+func TestGitlabParseWebhook(test *testing.T) {
+	connector := newGitlabConnector()
+
+	test.Run("Success", func(test *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Gitlab-Event", "Push Hook")
+		headers.Set("X-Gitlab-Event-UUID", "13792a34-cac6-4fda-95a8-c58e00a3954e")
+
+		webhookEvent, err := connector.ParseWebhook(headers, []byte(`{"project": {"id": 42}}`))
+
+		require.NoError(test, err)
+		assert.Equal(test, "New push", webhookEvent.ActionName)
+		assert.Equal(test, "13792a34-cac6-4fda-95a8-c58e00a3954e", webhookEvent.DeliveryId)
+		assert.Equal(test, "project", webhookEvent.ParamKey)
+		assert.Equal(test, "42", webhookEvent.ParamValue)
+	})
+
+	test.Run("Incorrect project", func(test *testing.T) {
+		_, err := connector.ParseWebhook(http.Header{}, []byte(`{}`))
+
+		require.EqualError(test, err, "Incorrect project id")
+	})
+
+	test.Run("Unknown event", func(test *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Gitlab-Event", "Unknown Hook")
+
+		_, err := connector.ParseWebhook(headers, []byte(`{"project": {"id": 42}}`))
+
+		require.EqualError(test, err, "No action mapped with this event")
+	})
+}
+
+func TestGitlabVerifyWebhook(test *testing.T) {
+	connector := newGitlabConnector()
+
+	headers := http.Header{}
+	headers.Set("X-Gitlab-Token", "secret")
+
+	require.True(test, connector.VerifyWebhook(headers, nil, "secret"))
+	require.False(test, connector.VerifyWebhook(headers, nil, "other"))
+	require.False(test, connector.VerifyWebhook(headers, nil, ""))
+	require.False(test, connector.VerifyWebhook(http.Header{}, nil, "secret"))
+}
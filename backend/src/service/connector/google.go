@@ -0,0 +1,127 @@
+package connector
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+
+	"backend/src/entities"
+)
+
+type googleConnector struct {
+	baseConnector
+}
+
+func newGoogleConnector() *googleConnector {
+	return &googleConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Google",
+				Color:        "#4285F4",
+				Logo:         "/logos/google.svg",
+				Description:  "Sign in with your Google account",
+				IsAuthNeeded: true,
+				Actions:      []entities.AboutAction{},
+				Reactions:    []entities.AboutReaction{},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl:        "https://oauth2.googleapis.com/token",
+				RevokeUrl:       "https://oauth2.googleapis.com/revoke",
+				UserInfoUrl:     "https://www.googleapis.com/oauth2/v3/userinfo",
+				CanRefreshToken: true,
+				SupportsPKCE:    true,
+			},
+		},
+	}
+}
+
+func oauth2Google(callbackType, appType string) string {
+	var callbackLink, appTypeLink string
+
+	if callbackType == "login" {
+		callbackLink = os.Getenv("GOOGLE_LOGIN_CALLBACK")
+	} else if callbackType == "service" {
+		callbackLink = os.Getenv("GOOGLE_SERVICE_CALLBACK")
+	}
+
+	if appType == "web" {
+		appTypeLink = os.Getenv("GOOGLE_WEB_CLIENT_ID")
+	} else if appType == "mobile" {
+		appTypeLink = os.Getenv("GOOGLE_MOBILE_CLIENT_ID")
+	}
+
+	return ("https://accounts.google.com/o/oauth2/auth?" +
+		clientIdParam + appTypeLink +
+		codeResponseType +
+		"&access_type=offline" +
+		redirectUriParam + callbackLink +
+		"&scope=email")
+}
+
+func getGoogleOAuth2AccessTokenWebRequestBody(code, callback string) string {
+	return fmt.Sprintf(
+		"code=%s&client_id=%s&client_secret=%s&redirect_uri=%s&grant_type=%s",
+		code,
+		os.Getenv("GOOGLE_WEB_CLIENT_ID"),
+		os.Getenv("GOOGLE_CLIENT_SECRET"),
+		callback,
+		grantTypeAuthorization,
+	)
+}
+
+func getGoogleOAuth2AccessTokenMobileRequestBody(code, callback string) string {
+	return fmt.Sprintf(
+		"code=%s&client_id=%s&redirect_uri=%s&grant_type=%s",
+		code,
+		os.Getenv("GOOGLE_MOBILE_CLIENT_ID"),
+		callback,
+		grantTypeAuthorization,
+	)
+}
+
+func (self *googleConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Google(callbackType, appType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *googleConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var googleCallback, jsonBody string
+
+	if callbackType == "service" {
+		googleCallback = os.Getenv("GOOGLE_SERVICE_CALLBACK")
+	} else if callbackType == "login" {
+		googleCallback = os.Getenv("GOOGLE_LOGIN_CALLBACK")
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	if appType == "web" {
+		jsonBody = getGoogleOAuth2AccessTokenWebRequestBody(code, googleCallback)
+	} else if appType == "mobile" {
+		jsonBody = getGoogleOAuth2AccessTokenMobileRequestBody(code, googleCallback)
+	} else {
+		return nil, fmt.Errorf("Invalid app type")
+	}
+	jsonBody += codeVerifierBody(codeVerifier)
+
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.TokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+	return request, nil
+}
+
+func (self *googleConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	jsonBody := genericJsonBodyRefreshToken(os.Getenv("GOOGLE_WEB_CLIENT_ID"), os.Getenv("GOOGLE_CLIENT_SECRET"), refreshToken)
+
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.TokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
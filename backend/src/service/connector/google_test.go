@@ -0,0 +1,110 @@
+package connector
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Google(test *testing.T) {
+	test.Run("Login web", func(test *testing.T) {
+		callbackLink := os.Getenv("GOOGLE_LOGIN_CALLBACK")
+		appTypeLink := os.Getenv("GOOGLE_WEB_CLIENT_ID")
+
+		res := oauth2Google("login", "web")
+
+		expectedRes := "https://accounts.google.com/o/oauth2/auth?" +
+			clientIdParam + appTypeLink +
+			codeResponseType +
+			"&access_type=offline" +
+			redirectUriParam + callbackLink +
+			"&scope=email"
+
+		assert.Equal(test, res, expectedRes)
+	})
+
+	test.Run("Service mobile", func(test *testing.T) {
+		callbackLink := os.Getenv("GOOGLE_WEB_CLIENT_ID")
+		appTypeLink := os.Getenv("GOOGLE_MOBILE_CLIENT_ID")
+
+		res := oauth2Google("service", "mobile")
+
+		expectedRes := "https://accounts.google.com/o/oauth2/auth?" +
+			clientIdParam + appTypeLink +
+			codeResponseType +
+			"&access_type=offline" +
+			redirectUriParam + callbackLink +
+			"&scope=email"
+
+		assert.Equal(test, res, expectedRes)
+	})
+}
+
+func TestGetGoogleOAuth2AccessTokenWebRequestBody(test *testing.T) {
+	expectedRes := fmt.Sprintf(
+		"code=%s&client_id=%s&client_secret=%s&redirect_uri=%s&grant_type=%s",
+		"code",
+		os.Getenv("GOOGLE_WEB_CLIENT_ID"),
+		os.Getenv("GOOGLE_CLIENT_SECRET"),
+		"callback",
+		grantTypeAuthorization,
+	)
+	res := getGoogleOAuth2AccessTokenWebRequestBody("code", "callback")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestGetGoogleOAuth2AccessTokenMobileRequestBody(test *testing.T) {
+	expectedRes := fmt.Sprintf(
+		"code=%s&client_id=%s&redirect_uri=%s&grant_type=%s",
+		"code",
+		os.Getenv("GOOGLE_MOBILE_CLIENT_ID"),
+		"callback",
+		grantTypeAuthorization,
+	)
+	res := getGoogleOAuth2AccessTokenMobileRequestBody("code", "callback")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestGoogleAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service Web", func(test *testing.T) {
+		_, err := newGoogleConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login Mobile", func(test *testing.T) {
+		_, err := newGoogleConnector().AccessTokenRequest("code", "login", "mobile", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newGoogleConnector().AccessTokenRequest("code", "invalid", "mobile", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+
+	test.Run("Invalid App Type", func(test *testing.T) {
+		_, err := newGoogleConnector().AccessTokenRequest("code", "service", "invalid", "")
+
+		require.EqualError(test, err, "Invalid app type")
+	})
+}
+
+func TestGoogleRefreshTokenRequest(test *testing.T) {
+	_, err := newGoogleConnector().RefreshTokenRequest("refreshToken")
+
+	require.NoError(test, err)
+}
+
+func TestGoogleRevokeTokenRequest(test *testing.T) {
+	request, err := newGoogleConnector().RevokeTokenRequest("accessToken", "refreshToken")
+
+	require.NoError(test, err)
+	assert.Equal(test, "https://oauth2.googleapis.com/revoke", request.URL.String())
+}
@@ -0,0 +1,44 @@
+package connector
+
+import (
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type httpRequestConnector struct {
+	baseConnector
+}
+
+func newHttpRequestConnector() *httpRequestConnector {
+	return &httpRequestConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:        "HTTP",
+				Color:       "#0984E3",
+				Logo:        "/logos/http.svg",
+				Description: "Call any tool exposing an HTTP API",
+				Actions:     []entities.AboutAction{},
+				Reactions: []entities.AboutReaction{
+					{
+						Name:        "HTTP request",
+						Description: "Send a request with a custom method, URL, headers (JSON object) and JSON or form body. Private and loopback addresses are refused",
+						Parameters: []map[string]interface{}{
+							newParameter("method", "string", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}, true),
+							newParameter("url", "string", nil, false),
+							newParameter("headers", "string", nil, false),
+							newParameter("bodytype", "string", []string{"json", "form"}, true),
+							newParameter("body", "string", nil, false),
+							newParameter("timeout", "int", nil, false),
+							newParameter("expectedstatus", "string", nil, false),
+							newParameter("retries", "int", nil, false),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (self *httpRequestConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckHttpRequestReactions
+}
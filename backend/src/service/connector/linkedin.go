@@ -0,0 +1,87 @@
+package connector
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type linkedinConnector struct {
+	baseConnector
+}
+
+func newLinkedinConnector() *linkedinConnector {
+	return &linkedinConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Linkedin",
+				Color:        "#0A66C2",
+				Logo:         "/logos/linkedin.svg",
+				Description:  "Share updates on your LinkedIn profile",
+				IsAuthNeeded: true,
+				Actions:      []entities.AboutAction{},
+				Reactions: []entities.AboutReaction{
+					{Name: "Share an update", Description: "Share a text update on your profile"},
+					{Name: "Share a link", Description: "Share a link with a comment on your profile"},
+				},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl: "https://www.linkedin.com/oauth/v2/accessToken",
+			},
+		},
+	}
+}
+
+func oauth2Linkedin(callbackType string) string {
+	callbackLink, _ := getCallbackAndClientId(callbackType, "LINKEDIN", false)
+
+	return ("https://www.linkedin.com/oauth/v2/authorization?" +
+		clientIdParam + url.QueryEscape(os.Getenv("LINKEDIN_CLIENT_ID")) +
+		redirectUriParam + callbackLink +
+		codeResponseType +
+		"&scope=openid email profile w_member_social")
+}
+
+func (self *linkedinConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Linkedin(callbackType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *linkedinConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var callback string
+
+	if callbackType == "service" {
+		callback = os.Getenv("LINKEDIN_SERVICE_CALLBACK")
+	} else if callbackType == "login" {
+		callback = os.Getenv("LINKEDIN_LOGIN_CALLBACK")
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	jsonBody := fmt.Sprintf(
+		"client_id=%s&client_secret=%s&code=%s&redirect_uri=%s&grant_type=%s",
+		url.QueryEscape(os.Getenv("LINKEDIN_CLIENT_ID")),
+		url.QueryEscape(os.Getenv("LINKEDIN_CLIENT_SECRET")),
+		code,
+		callback,
+		grantTypeAuthorization,
+	)
+	jsonBody += codeVerifierBody(codeVerifier)
+
+	request, errRequest := http.NewRequest("POST", self.oauthConfig.TokenUrl, bytes.NewBuffer([]byte(jsonBody)))
+	if errRequest != nil {
+		return request, errRequest
+	}
+
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *linkedinConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckLinkedinReactions
+}
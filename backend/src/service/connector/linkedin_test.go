@@ -0,0 +1,44 @@
+package connector
+
+import (
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Linkedin(test *testing.T) {
+	callbackLink := os.Getenv("LINKEDIN_LOGIN_CALLBACK")
+
+	expectedRes := "https://www.linkedin.com/oauth/v2/authorization?" +
+		clientIdParam + url.QueryEscape(os.Getenv("LINKEDIN_CLIENT_ID")) +
+		redirectUriParam + callbackLink +
+		codeResponseType +
+		"&scope=openid email profile w_member_social"
+
+	res := oauth2Linkedin("login")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestLinkedinAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service", func(test *testing.T) {
+		_, err := newLinkedinConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login", func(test *testing.T) {
+		_, err := newLinkedinConnector().AccessTokenRequest("code", "login", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newLinkedinConnector().AccessTokenRequest("code", "invalid", "web", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+}
@@ -0,0 +1,131 @@
+package connector
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+var redditPostVariables = []string{"post.id", "post.title", "post.author", "post.url"}
+var redditCommentVariables = []string{"comment.id", "comment.author", "comment.body"}
+
+type redditConnector struct {
+	baseConnector
+}
+
+func newRedditConnector() *redditConnector {
+	return &redditConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Reddit",
+				Color:        "#FF4500",
+				Logo:         "/logos/reddit.svg",
+				Description:  "Follow subreddits and interact with posts on Reddit",
+				IsAuthNeeded: true,
+				Actions: []entities.AboutAction{
+					{Name: "Any new post in subreddit", Description: "A new post is submitted in a subreddit", Variables: redditPostVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New post by you", Description: "You submit a new post", Variables: redditPostVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New comment by you", Description: "You submit a new comment", Variables: redditCommentVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New downvoted post by you", Description: "You downvote a post", Variables: redditPostVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New upvoted post by you", Description: "You upvote a post", Variables: redditPostVariables, MinimumInterval: 300, DefaultInterval: 900},
+					{Name: "New post saved by you", Description: "You save a post", Variables: redditPostVariables, MinimumInterval: 300, DefaultInterval: 900},
+				},
+				Reactions: []entities.AboutReaction{
+					{Name: "Submit a comment on a post ", Description: "Submit a comment on a post"},
+					{Name: "Downvote a post", Description: "Downvote a post"},
+					{Name: "Upvote a post", Description: "Upvote a post"},
+					{Name: "Submit a post", Description: "Submit a text post in a subreddit"},
+					{Name: "Submit a post with a link", Description: "Submit a link post in a subreddit"},
+				},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl:        "https://www.reddit.com/api/v1/access_token",
+				CanRefreshToken: true,
+				RevokeUrl:       "https://www.reddit.com/api/v1/revoke_token",
+			},
+		},
+	}
+}
+
+func oauth2Reddit(callbackType string) string {
+	callbackLink, clientID := getCallbackAndClientId(callbackType, "REDDIT", true)
+
+	return ("https://www.reddit.com/api/v1/authorize?" +
+		clientIdParam + clientID +
+		codeResponseType +
+		redirectUriParam + callbackLink +
+		"&duration=permanent" +
+		"&scope=identity read submit vote mysubreddits history account edit privatemessages")
+}
+
+func (self *redditConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Reddit(callbackType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *redditConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var redditCallback, redditClientID, redditClientSecret string
+
+	if callbackType == "service" {
+		redditCallback = os.Getenv("REDDIT_SERVICE_CALLBACK")
+		redditClientID = url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_ID"))
+		redditClientSecret = url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_SECRET"))
+	} else if callbackType == "login" {
+		redditCallback = os.Getenv("REDDIT_LOGIN_CALLBACK")
+		redditClientID = url.QueryEscape(os.Getenv("REDDIT_LOGIN_CLIENT_ID"))
+		redditClientSecret = url.QueryEscape(os.Getenv("REDDIT_LOGIN_CLIENT_SECRET"))
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	codeStripped := strings.TrimSuffix(code, "#_")
+	request, err := genericAccessTokenRequest(self.oauthConfig.TokenUrl, codeStripped, redditCallback, codeVerifier)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(redditClientID, redditClientSecret)
+	request.Header.Set("User-Agent", os.Getenv("REDDIT_SERVICE_USER_AGENT"))
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *redditConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	request, err := genericRefreshTokenRequest(self.oauthConfig.TokenUrl, refreshToken)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_ID")), url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_SECRET")))
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+	request.Header.Set("User-Agent", os.Getenv("REDDIT_SERVICE_USER_AGENT"))
+
+	return request, nil
+}
+
+func (self *redditConnector) RevokeTokenRequest(accessToken, refreshToken string) (*http.Request, error) {
+	request, err := genericRevokeTokenRequest(self.oauthConfig.RevokeUrl, accessToken, refreshToken)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_ID")), url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_SECRET")))
+	request.Header.Set("User-Agent", os.Getenv("REDDIT_SERVICE_USER_AGENT"))
+
+	return request, nil
+}
+
+func (self *redditConnector) PollJobs() []service.PollJob {
+	return []service.PollJob{
+		{Schedule: "@every 1m", Poll: service.WorkflowService.CheckRedditActions},
+	}
+}
+
+func (self *redditConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckRedditReactions
+}
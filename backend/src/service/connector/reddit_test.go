@@ -0,0 +1,60 @@
+package connector
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Reddit(test *testing.T) {
+	clientID := os.Getenv("REDDIT_LOGIN_CLIENT_ID")
+	callbackLink := os.Getenv("REDDIT_LOGIN_CALLBACK")
+
+	expectedRes := "https://www.reddit.com/api/v1/authorize?" +
+		clientIdParam + clientID +
+		codeResponseType +
+		redirectUriParam + callbackLink +
+		"&duration=permanent" +
+		"&scope=identity read submit vote mysubreddits history account edit privatemessages"
+
+	res := oauth2Reddit("login")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestRedditAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service", func(test *testing.T) {
+		_, err := newRedditConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login", func(test *testing.T) {
+		_, err := newRedditConnector().AccessTokenRequest("code", "login", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newRedditConnector().AccessTokenRequest("code", "invalid", "web", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+}
+
+func TestRedditRefreshTokenRequest(test *testing.T) {
+	_, err := newRedditConnector().RefreshTokenRequest("refreshToken")
+
+	require.NoError(test, err)
+}
+
+func TestRedditRevokeTokenRequest(test *testing.T) {
+	request, err := newRedditConnector().RevokeTokenRequest("accessToken", "refreshToken")
+
+	require.NoError(test, err)
+	_, _, hasBasicAuth := request.BasicAuth()
+	assert.True(test, hasBasicAuth)
+	assert.Equal(test, os.Getenv("REDDIT_SERVICE_USER_AGENT"), request.Header.Get("User-Agent"))
+}
@@ -0,0 +1,46 @@
+package connector
+
+import (
+	"fmt"
+
+	"backend/src/service"
+)
+
+type Registry struct {
+	connectors []service.Connector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		connectors: []service.Connector{
+			newSpotifyConnector(),
+			newDiscordConnector(),
+			newLinkedinConnector(),
+			newAsanaConnector(),
+			newSMSConnector(),
+			newEmailConnector(),
+			newDropboxConnector(),
+			newRedditConnector(),
+			newGithubConnector(),
+			newGitlabConnector(),
+			newGoogleConnector(),
+			newTimeAndDateConnector(),
+			newWeatherConnector(),
+			newWebhookConnector(),
+			newHttpRequestConnector(),
+		},
+	}
+}
+
+func (self *Registry) FindConnector(serviceName string) (service.Connector, error) {
+	for _, connector := range self.connectors {
+		if connector.Definition().Name == serviceName {
+			return connector, nil
+		}
+	}
+	return nil, fmt.Errorf(unknownServiceMessage)
+}
+
+func (self *Registry) RetrieveConnectors() []service.Connector {
+	return self.connectors
+}
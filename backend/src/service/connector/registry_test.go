@@ -0,0 +1,41 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindConnector(test *testing.T) {
+	registry := NewRegistry()
+
+	test.Run("Success", func(test *testing.T) {
+		connector, err := registry.FindConnector("Github")
+
+		require.NoError(test, err)
+		assert.Equal(test, "Github", connector.Definition().Name)
+	})
+
+	test.Run("Unknown", func(test *testing.T) {
+		_, err := registry.FindConnector("Unknown")
+
+		require.EqualError(test, err, unknownServiceMessage)
+	})
+}
+
+func TestRetrieveConnectors(test *testing.T) {
+	names := map[string]bool{}
+
+	for _, connector := range NewRegistry().RetrieveConnectors() {
+		definition := connector.Definition()
+
+		assert.False(test, names[definition.Name], "duplicated connector "+definition.Name)
+		names[definition.Name] = true
+
+		for _, pollJob := range connector.PollJobs() {
+			assert.NotEmpty(test, pollJob.Schedule)
+			assert.NotNil(test, pollJob.Poll)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package connector
+
+import (
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type smsConnector struct {
+	baseConnector
+}
+
+func newSMSConnector() *smsConnector {
+	return &smsConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:        "SMS",
+				Color:       "#F22F46",
+				Logo:        "/logos/sms.svg",
+				Description: "Send text messages",
+				Actions:     []entities.AboutAction{},
+				Reactions: []entities.AboutReaction{
+					{Name: "Send an SMS", Description: "Send a text message to a phone number"},
+				},
+			},
+		},
+	}
+}
+
+func (self *smsConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckSMSReactions
+}
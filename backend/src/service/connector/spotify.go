@@ -0,0 +1,104 @@
+package connector
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type spotifyConnector struct {
+	baseConnector
+}
+
+func newSpotifyConnector() *spotifyConnector {
+	return &spotifyConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:         "Spotify",
+				Color:        "#1DB954",
+				Logo:         "/logos/spotify.svg",
+				Description:  "Control your Spotify playback and library",
+				IsAuthNeeded: true,
+				Actions:      []entities.AboutAction{},
+				Reactions: []entities.AboutReaction{
+					{Name: "Start playback", Description: "Start or resume playback on the active device"},
+					{Name: "Pause playback", Description: "Pause playback on the active device"},
+					{Name: "Activate playback shuffle", Description: "Turn on shuffle on the active device"},
+					{Name: "Deactivate playback shuffle", Description: "Turn off shuffle on the active device"},
+					{Name: "Skip to next track", Description: "Skip to the next track in the queue"},
+					{Name: "Skip to previous track", Description: "Skip to the previous track"},
+					{Name: "Add track to playback queue", Description: "Add a track to the end of the playback queue"},
+					{Name: "Save a track", Description: "Save a track to your library"},
+					{Name: "Save an album", Description: "Save an album to your library"},
+					{Name: "Save an audiobook", Description: "Save an audiobook to your library"},
+					{Name: "Save an episode", Description: "Save an episode to your library"},
+					{Name: "Save a show", Description: "Save a show to your library"},
+					{Name: "Set playback volume", Description: "Set the volume of the active device"},
+					{Name: "Follow a playlist", Description: "Follow a playlist"},
+					{Name: "Unfollow a playlist", Description: "Unfollow a playlist"},
+				},
+			},
+			oauthConfig: entities.OAuthConfig{
+				TokenUrl:        "https://accounts.spotify.com/api/token",
+				UserInfoUrl:     "https://api.spotify.com/v1/me",
+				CanRefreshToken: true,
+				SupportsPKCE:    true,
+			},
+		},
+	}
+}
+
+func oauth2Spotify(callbackType string) string {
+	callbackLink, _ := getCallbackAndClientId(callbackType, "SPOTIFY", false)
+
+	return ("https://accounts.spotify.com/authorize?" +
+		clientIdParam + os.Getenv("SPOTIFY_CLIENT_ID") +
+		codeResponseType +
+		redirectUriParam + callbackLink +
+		"&scope=user-read-private user-read-email user-modify-playback-state user-read-playback-state user-library-modify playlist-modify-public")
+}
+
+func (self *spotifyConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	return oauth2Spotify(callbackType) + authorizationParams(state, codeChallenge), nil
+}
+
+func (self *spotifyConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	var spotifyCallback string
+
+	if callbackType == "service" {
+		spotifyCallback = os.Getenv("SPOTIFY_SERVICE_CALLBACK")
+	} else if callbackType == "login" {
+		spotifyCallback = os.Getenv("SPOTIFY_LOGIN_CALLBACK")
+	} else {
+		return nil, fmt.Errorf(invalidCallbackTypeMessage)
+	}
+
+	request, err := genericAccessTokenRequest(self.oauthConfig.TokenUrl, code, spotifyCallback, codeVerifier)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(os.Getenv("SPOTIFY_CLIENT_ID"), os.Getenv("SPOTIFY_CLIENT_SECRET"))
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *spotifyConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	request, err := genericRefreshTokenRequest(self.oauthConfig.TokenUrl, refreshToken)
+	if err != nil {
+		return request, err
+	}
+
+	request.SetBasicAuth(os.Getenv("SPOTIFY_CLIENT_ID"), os.Getenv("SPOTIFY_CLIENT_SECRET"))
+	request.Header.Set(contentType, contentTypeUrlEncoded)
+
+	return request, nil
+}
+
+func (self *spotifyConnector) ReactionHandler() service.ReactionHandler {
+	return service.WorkflowService.CheckSpotifyReactions
+}
@@ -0,0 +1,53 @@
+package connector
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauth2Spotify(test *testing.T) {
+	expectedRes := "https://accounts.spotify.com/authorize?" +
+		clientIdParam + os.Getenv("SPOTIFY_CLIENT_ID") +
+		codeResponseType +
+		redirectUriParam + os.Getenv("SPOTIFY_LOGIN_CALLBACK") +
+		"&scope=user-read-private user-read-email user-modify-playback-state user-read-playback-state user-library-modify playlist-modify-public"
+
+	res := oauth2Spotify("login")
+
+	assert.Equal(test, res, expectedRes)
+}
+
+func TestSpotifyAccessTokenRequest(test *testing.T) {
+	test.Run("Success Service", func(test *testing.T) {
+		_, err := newSpotifyConnector().AccessTokenRequest("code", "service", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Success Login", func(test *testing.T) {
+		_, err := newSpotifyConnector().AccessTokenRequest("code", "login", "web", "")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid Callback Type", func(test *testing.T) {
+		_, err := newSpotifyConnector().AccessTokenRequest("code", "invalid", "web", "")
+
+		require.EqualError(test, err, invalidCallbackTypeMessage)
+	})
+}
+
+func TestSpotifyRefreshTokenRequest(test *testing.T) {
+	_, err := newSpotifyConnector().RefreshTokenRequest("refreshToken")
+
+	require.NoError(test, err)
+}
+
+func TestSpotifyRevokeTokenRequest(test *testing.T) {
+	_, err := newSpotifyConnector().RevokeTokenRequest("accessToken", "refreshToken")
+
+	require.EqualError(test, err, unsupportedOperationMessage)
+}
@@ -0,0 +1,57 @@
+package connector
+
+import (
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+var timeVariables = []string{"time.timezone", "time.formatted", "time.timestamp", "time.weekDay", "time.day", "time.month", "time.year", "time.hour", "time.minute"}
+
+type timeAndDateConnector struct {
+	baseConnector
+}
+
+func newTimeAndDateConnector() *timeAndDateConnector {
+	return &timeAndDateConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:        "Time & Date",
+				Color:       "#6C5CE7",
+				Logo:        "/logos/timeanddate.svg",
+				Description: "Trigger workflows at a given time",
+				Actions: []entities.AboutAction{
+					{Name: "Every day at", Description: "Every day at the given hour and minute", Variables: timeVariables},
+					{Name: "Every hour at", Description: "Every hour at the given minute", Variables: timeVariables},
+					{Name: "Every day of the week at", Description: "Every given day of the week at the given hour and minute", Variables: timeVariables},
+					{Name: "Every month on the", Description: "Every month on the given day at the given hour and minute", Variables: timeVariables},
+					{Name: "Every year on", Description: "Every year on the given month and day at the given hour and minute", Variables: timeVariables},
+					{
+						Name:        "Cron schedule",
+						Description: "On the given cron expression (minute hour day-of-month month day-of-week)",
+						Parameters:  []map[string]interface{}{newParameter("expression", "string", nil, false)},
+						Variables:   timeVariables,
+					},
+					{
+						Name:        "Every N minutes",
+						Description: "Every given number of minutes",
+						Parameters:  []map[string]interface{}{newParameter("minutes", "int", nil, false)},
+						Variables:   timeVariables,
+					},
+					{
+						Name:        "At date and time",
+						Description: "Once at the given date and time (YYYY-MM-DD HH:MM), the workflow is deactivated afterwards",
+						Parameters:  []map[string]interface{}{newParameter("date", "string", nil, false)},
+						Variables:   timeVariables,
+					},
+				},
+				Reactions: []entities.AboutReaction{},
+			},
+		},
+	}
+}
+
+func (self *timeAndDateConnector) PollJobs() []service.PollJob {
+	return []service.PollJob{
+		{Schedule: "@every 1m", Poll: service.WorkflowService.CheckTimeAndDateActions},
+	}
+}
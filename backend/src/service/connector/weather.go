@@ -0,0 +1,38 @@
+package connector
+
+import (
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+var weatherVariables = []string{"weather.city", "weather.temp_c", "weather.maxtemp_c", "weather.mintemp_c"}
+
+type weatherConnector struct {
+	baseConnector
+}
+
+func newWeatherConnector() *weatherConnector {
+	return &weatherConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:        "FreeWeather",
+				Color:       "#00A8E8",
+				Logo:        "/logos/freeweather.svg",
+				Description: "Trigger workflows on the weather of a city",
+				Actions: []entities.AboutAction{
+					{Name: "Current temperature rises above", Description: "The current temperature of a city rises above the given value", Variables: weatherVariables, MinimumInterval: 3600, DefaultInterval: 43200},
+					{Name: "Current temperature drops below", Description: "The current temperature of a city drops below the given value", Variables: weatherVariables, MinimumInterval: 3600, DefaultInterval: 43200},
+					{Name: "Tomorrow's low drops below", Description: "Tomorrow's lowest temperature of a city drops below the given value", Variables: weatherVariables, MinimumInterval: 3600, DefaultInterval: 43200},
+					{Name: "Tomorrow's high rises above", Description: "Tomorrow's highest temperature of a city rises above the given value", Variables: weatherVariables, MinimumInterval: 3600, DefaultInterval: 43200},
+				},
+				Reactions: []entities.AboutReaction{},
+			},
+		},
+	}
+}
+
+func (self *weatherConnector) PollJobs() []service.PollJob {
+	return []service.PollJob{
+		{Schedule: "@every 1m", Poll: service.WorkflowService.CheckWeatherActions},
+	}
+}
@@ -0,0 +1,31 @@
+package connector
+
+import (
+	"backend/src/entities"
+)
+
+type webhookConnector struct {
+	baseConnector
+}
+
+func newWebhookConnector() *webhookConnector {
+	return &webhookConnector{
+		baseConnector{
+			definition: entities.ServiceDefinition{
+				Name:        "Webhook",
+				Color:       "#2D3436",
+				Logo:        "/logos/webhook.svg",
+				Description: "Trigger workflows from any tool able to send an HTTP request",
+				Actions: []entities.AboutAction{
+					{
+						Name:        "Incoming webhook",
+						Description: "When a JSON body is posted to the unique URL of the workflow, optionally signed with a secret (X-Webhook-Signature-256 header)",
+						Parameters:  []map[string]interface{}{newParameter("secret", "string", nil, false)},
+						Variables:   []string{"body"},
+					},
+				},
+				Reactions: []entities.AboutReaction{},
+			},
+		},
+	}
+}
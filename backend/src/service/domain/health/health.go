@@ -0,0 +1,50 @@
+package health_service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"backend/src/entities"
+	"backend/src/service"
+	"backend/src/storage"
+)
+
+const databasePingTimeout = 2 * time.Second
+
+type HealthService struct {
+	DatabaseRepository storage.DatabaseRepository
+	WorkflowService    service.WorkflowService
+
+	isShuttingDown atomic.Bool
+}
+
+func NewHealthService(DatabaseRepository storage.DatabaseRepository, WorkflowService service.WorkflowService) *HealthService {
+	return &HealthService{
+		DatabaseRepository: DatabaseRepository,
+		WorkflowService:    WorkflowService,
+	}
+}
+
+// The server is ready to receive requests while its database answers and it is not shutting down
+func (self *HealthService) GetHealth(ctx context.Context) entities.Health {
+	health := entities.Health{
+		IsShuttingDown: self.isShuttingDown.Load(),
+		Database:       entities.DatabaseUp,
+		Scheduler:      self.WorkflowService.GetSchedulerState(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, databasePingTimeout)
+	defer cancel()
+
+	err := self.DatabaseRepository.Ping(ctx)
+	if err != nil {
+		health.Database = entities.DatabaseDown
+	}
+	health.IsReady = err == nil && !health.IsShuttingDown
+	return health
+}
+
+func (self *HealthService) MarkShuttingDown() {
+	self.isShuttingDown.Store(true)
+}
@@ -0,0 +1,87 @@
+package health_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+type MockDatabaseRepository struct {
+	mock.Mock
+}
+
+func (m *MockDatabaseRepository) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockDatabaseRepository) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockWorkflowService struct {
+	service.WorkflowService
+	mock.Mock
+}
+
+func (m *MockWorkflowService) GetSchedulerState() entities.SchedulerState {
+	args := m.Called()
+	return args.Get(0).(entities.SchedulerState)
+}
+
+func TestGetHealth(test *testing.T) {
+	schedulerState := entities.SchedulerState{
+		IsLeader:  true,
+		LastPolls: []entities.PollSummary{{Service: "Reddit", Checked: 2}},
+	}
+
+	newHealthService := func(pingError error) *HealthService {
+		mockDatabaseRepo := new(MockDatabaseRepository)
+		mockWorkflowService := new(MockWorkflowService)
+
+		mockDatabaseRepo.On("Ping", mock.Anything).
+			Return(pingError)
+		mockWorkflowService.On("GetSchedulerState").
+			Return(schedulerState)
+		return NewHealthService(mockDatabaseRepo, mockWorkflowService)
+	}
+
+	test.Run("Ready", func(test *testing.T) {
+		service := newHealthService(nil)
+
+		health := service.GetHealth(context.Background())
+
+		require.Equal(test, entities.Health{
+			IsReady:   true,
+			Database:  entities.DatabaseUp,
+			Scheduler: schedulerState,
+		}, health)
+	})
+
+	test.Run("Database Down", func(test *testing.T) {
+		service := newHealthService(errors.New("connection refused"))
+
+		health := service.GetHealth(context.Background())
+
+		require.False(test, health.IsReady)
+		require.Equal(test, entities.DatabaseDown, health.Database)
+	})
+
+	test.Run("Shutting Down", func(test *testing.T) {
+		service := newHealthService(nil)
+		service.MarkShuttingDown()
+
+		health := service.GetHealth(context.Background())
+
+		require.False(test, health.IsReady)
+		require.True(test, health.IsShuttingDown)
+		require.Equal(test, entities.DatabaseUp, health.Database)
+	})
+}
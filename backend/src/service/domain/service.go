@@ -2,7 +2,9 @@ package domain
 
 import (
 	"backend/src/service"
+	"backend/src/service/connector"
 	about_service "backend/src/service/domain/about"
+	health_service "backend/src/service/domain/health"
 	service_service "backend/src/service/domain/service"
 	user_service "backend/src/service/domain/user"
 	user_service_service "backend/src/service/domain/userservice"
@@ -11,11 +13,13 @@ import (
 )
 
 func New(repositories *storage.Repository) *service.Service {
-	serviceService := service_service.NewServiceService(repositories.ServiceRepository, repositories.UserRepository, repositories.ActionRepository, repositories.WorkflowRepository, repositories.ReactionRepository)
+	connectors := connector.NewRegistry()
+	serviceService := service_service.NewServiceService(repositories.ServiceRepository, repositories.UserRepository, repositories.ActionRepository, repositories.WorkflowRepository, repositories.ReactionRepository, repositories.OAuthStateRepository, connectors)
 	userService := user_service.NewUserService(repositories.UserRepository, repositories.ServiceRepository, repositories.UserServiceRepository, repositories.WorkflowRepository, serviceService)
-	userServiceService := user_service_service.NewUserServiceService(repositories.ServiceRepository, repositories.UserRepository, repositories.UserServiceRepository, serviceService)
-	workflowService := workflow_service.NewWorkflowService(repositories.WorkflowRepository, repositories.UserRepository, repositories.ActionRepository, repositories.ReactionRepository, serviceService, userServiceService)
-	aboutService := about_service.NewAboutService(repositories.ServiceRepository, repositories.ActionRepository, repositories.ReactionRepository)
+	userServiceService := user_service_service.NewUserServiceService(repositories.ServiceRepository, repositories.UserRepository, repositories.UserServiceRepository, repositories.WorkflowRepository, serviceService)
+	workflowService := workflow_service.NewWorkflowService(repositories.WorkflowRepository, repositories.UserRepository, repositories.ActionRepository, repositories.ReactionRepository, repositories.WorkflowReactionRepository, repositories.WorkflowRunRepository, repositories.SchedulerTickRepository, repositories.ServiceWebhookRepository, repositories.WebhookDeliveryRepository, repositories.JobRepository, serviceService, userServiceService)
+	aboutService := about_service.NewAboutService(connectors)
+	healthService := health_service.NewHealthService(repositories.DatabaseRepository, workflowService)
 
 	return &service.Service{
 		ServiceService:     serviceService,
@@ -23,5 +27,6 @@ func New(repositories *storage.Repository) *service.Service {
 		UserServiceService: userServiceService,
 		WorkflowService:    workflowService,
 		AboutService:       aboutService,
+		HealthService:      healthService,
 	}
 }
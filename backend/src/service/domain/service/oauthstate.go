@@ -0,0 +1,149 @@
+package service_service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const oauthStateLifetime = 10 * time.Minute
+
+const errorInvalidOAuthState = "Invalid OAuth state"
+const errorExpiredOAuthState = "OAuth state expired or already used"
+
+// Payload of the state given to the service, signed so it cannot be forged and bound to the session which asked
+// for the authorization URL so it cannot be replayed from another browser or app
+type oauthState struct {
+	Id           string `json:"id"`
+	Service      string `json:"service"`
+	CallbackType string `json:"callbacktype"`
+	AppType      string `json:"apptype"`
+	Session      string `json:"session"`
+	ExpiresAt    int64  `json:"expiresat"`
+}
+
+// The session is random and kept in an HTTP only cookie, only its digest is given to the service
+func oauthSessionDigest(session string) string {
+	digest := sha256.Sum256([]byte(session))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+func oauthStateSignature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET_KEY")))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signOAuthState(state oauthState) (string, error) {
+	payloadBytes, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(payloadBytes)
+	return payload + "." + oauthStateSignature(payload), nil
+}
+
+func verifyOAuthState(signedState string) (oauthState, error) {
+	var state oauthState
+
+	payload, signature, found := strings.Cut(signedState, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(oauthStateSignature(payload))) {
+		return state, fmt.Errorf(errorInvalidOAuthState)
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return state, fmt.Errorf(errorInvalidOAuthState)
+	}
+	err = json.Unmarshal(payloadBytes, &state)
+	if err != nil {
+		return state, fmt.Errorf(errorInvalidOAuthState)
+	}
+	return state, nil
+}
+
+// PKCE code verifier of RFC 7636 and its S256 challenge
+func newCodeVerifier() (string, string, error) {
+	verifierBytes := make([]byte, 32)
+	_, err := rand.Read(verifierBytes)
+	if err != nil {
+		return "", "", err
+	}
+
+	codeVerifier := base64.RawURLEncoding.EncodeToString(verifierBytes)
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	return codeVerifier, base64.RawURLEncoding.EncodeToString(challenge[:]), nil
+}
+
+// Issues a single use state expiring after a few minutes, the code verifier stays on the server until the callback
+func (self *ServiceService) OAuth2Service(serviceName, callbackType, appType, session string) (string, error) {
+	connector, err := self.Connectors.FindConnector(serviceName)
+	if err != nil {
+		return "", err
+	}
+	if session == "" {
+		return "", fmt.Errorf(errorInvalidOAuthState)
+	}
+
+	var codeVerifier, codeChallenge string
+	if connector.OAuthConfig().SupportsPKCE {
+		codeVerifier, codeChallenge, err = newCodeVerifier()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	expiresAt := time.Now().Add(oauthStateLifetime)
+	id, err := self.OAuthStateRepository.CreateOAuthState(codeVerifier, expiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := signOAuthState(oauthState{
+		Id:           id,
+		Service:      serviceName,
+		CallbackType: callbackType,
+		AppType:      appType,
+		Session:      oauthSessionDigest(session),
+		ExpiresAt:    expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return connector.AuthorizationUrl(callbackType, appType, state, codeChallenge)
+}
+
+// Checks the state received with a callback was issued for this session and flow, then uses it up
+// Returns the code verifier to send with the access code
+func (self *ServiceService) ConsumeOAuthState(signedState, session, serviceName, callbackType, appType string) (string, error) {
+	state, err := verifyOAuthState(signedState)
+	if err != nil {
+		return "", err
+	}
+
+	isSameFlow := state.Service == serviceName && state.CallbackType == callbackType && state.AppType == appType
+	isSameSession := session != "" && hmac.Equal([]byte(state.Session), []byte(oauthSessionDigest(session)))
+	if !isSameFlow || !isSameSession {
+		return "", fmt.Errorf(errorInvalidOAuthState)
+	}
+
+	now := time.Now()
+	if now.Unix() >= state.ExpiresAt {
+		return "", fmt.Errorf(errorExpiredOAuthState)
+	}
+
+	codeVerifier, found, err := self.OAuthStateRepository.ConsumeOAuthState(state.Id, now)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf(errorExpiredOAuthState)
+	}
+	return codeVerifier, nil
+}
@@ -0,0 +1,195 @@
+package service_service
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/service/connector"
+)
+
+func authorizationUrlParameters(test *testing.T, authorizationUrl string) url.Values {
+	parsedUrl, err := url.Parse(authorizationUrl)
+	require.NoError(test, err)
+	return parsedUrl.Query()
+}
+
+func TestOAuth2ServiceState(test *testing.T) {
+	test.Run("PKCE", func(test *testing.T) {
+		var codeVerifier string
+
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
+
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { codeVerifier = args.String(0) }).
+			Return("stateId", nil)
+
+		authorizationUrl, err := serviceservice.OAuth2Service("Google", "login", "web", "session")
+		require.NoError(test, err)
+
+		parameters := authorizationUrlParameters(test, authorizationUrl)
+		challenge := sha256.Sum256([]byte(codeVerifier))
+		require.NotEmpty(test, codeVerifier)
+		require.Equal(test, base64.RawURLEncoding.EncodeToString(challenge[:]), parameters.Get("code_challenge"))
+		require.Equal(test, "S256", parameters.Get("code_challenge_method"))
+		require.NotEmpty(test, parameters.Get("state"))
+	})
+
+	test.Run("Without PKCE", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
+
+		mockOAuthStateRepo.On("CreateOAuthState", "", mock.Anything).
+			Return("stateId", nil)
+
+		authorizationUrl, err := serviceservice.OAuth2Service("Discord", "login", "web", "session")
+		require.NoError(test, err)
+
+		parameters := authorizationUrlParameters(test, authorizationUrl)
+		require.Empty(test, parameters.Get("code_challenge"))
+		require.NotEmpty(test, parameters.Get("state"))
+	})
+
+	test.Run("No session", func(test *testing.T) {
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
+
+		_, err := serviceservice.OAuth2Service("Google", "login", "web", "")
+
+		require.EqualError(test, err, errorInvalidOAuthState)
+	})
+
+	test.Run("Fail create state", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
+
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("", errors.New("Fail create state"))
+
+		_, err := serviceservice.OAuth2Service("Google", "login", "web", "session")
+
+		require.EqualError(test, err, "Fail create state")
+	})
+}
+
+func TestConsumeOAuthState(test *testing.T) {
+	test.Setenv("SECRET_KEY", "secret")
+
+	validState := oauthState{
+		Id:           "stateId",
+		Service:      "Google",
+		CallbackType: "login",
+		AppType:      "web",
+		Session:      oauthSessionDigest("session"),
+		ExpiresAt:    time.Now().Add(oauthStateLifetime).Unix(),
+	}
+
+	test.Run("Successful", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{OAuthStateRepository: mockOAuthStateRepo}
+
+		signedState, err := signOAuthState(validState)
+		require.NoError(test, err)
+
+		mockOAuthStateRepo.On("ConsumeOAuthState", "stateId", mock.Anything).
+			Return("verifier", true, nil)
+
+		codeVerifier, err := serviceservice.ConsumeOAuthState(signedState, "session", "Google", "login", "web")
+
+		require.NoError(test, err)
+		require.Equal(test, "verifier", codeVerifier)
+	})
+
+	test.Run("Forged signature", func(test *testing.T) {
+		serviceservice := &ServiceService{}
+
+		signedState, err := signOAuthState(validState)
+		require.NoError(test, err)
+
+		_, err = serviceservice.ConsumeOAuthState(signedState+"x", "session", "Google", "login", "web")
+
+		require.EqualError(test, err, errorInvalidOAuthState)
+	})
+
+	test.Run("Other secret", func(test *testing.T) {
+		serviceservice := &ServiceService{}
+
+		test.Setenv("SECRET_KEY", "other")
+		signedState, err := signOAuthState(validState)
+		require.NoError(test, err)
+		test.Setenv("SECRET_KEY", "secret")
+
+		_, err = serviceservice.ConsumeOAuthState(signedState, "session", "Google", "login", "web")
+
+		require.EqualError(test, err, errorInvalidOAuthState)
+	})
+
+	test.Run("Other session", func(test *testing.T) {
+		serviceservice := &ServiceService{}
+
+		signedState, err := signOAuthState(validState)
+		require.NoError(test, err)
+
+		_, err = serviceservice.ConsumeOAuthState(signedState, "otherSession", "Google", "login", "web")
+
+		require.EqualError(test, err, errorInvalidOAuthState)
+	})
+
+	test.Run("Other flow", func(test *testing.T) {
+		serviceservice := &ServiceService{}
+
+		signedState, err := signOAuthState(validState)
+		require.NoError(test, err)
+
+		_, err = serviceservice.ConsumeOAuthState(signedState, "session", "Github", "login", "web")
+		require.EqualError(test, err, errorInvalidOAuthState)
+
+		_, err = serviceservice.ConsumeOAuthState(signedState, "session", "Google", "service", "web")
+		require.EqualError(test, err, errorInvalidOAuthState)
+
+		_, err = serviceservice.ConsumeOAuthState(signedState, "session", "Google", "login", "mobile")
+		require.EqualError(test, err, errorInvalidOAuthState)
+	})
+
+	test.Run("Expired", func(test *testing.T) {
+		serviceservice := &ServiceService{}
+
+		expiredState := validState
+		expiredState.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+		signedState, err := signOAuthState(expiredState)
+		require.NoError(test, err)
+
+		_, err = serviceservice.ConsumeOAuthState(signedState, "session", "Google", "login", "web")
+
+		require.EqualError(test, err, errorExpiredOAuthState)
+	})
+
+	test.Run("Already used", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{OAuthStateRepository: mockOAuthStateRepo}
+
+		signedState, err := signOAuthState(validState)
+		require.NoError(test, err)
+
+		mockOAuthStateRepo.On("ConsumeOAuthState", "stateId", mock.Anything).
+			Return("", false, nil)
+
+		_, err = serviceservice.ConsumeOAuthState(signedState, "session", "Google", "login", "web")
+
+		require.EqualError(test, err, errorExpiredOAuthState)
+	})
+
+	test.Run("Malformed", func(test *testing.T) {
+		serviceservice := &ServiceService{}
+
+		_, err := serviceservice.ConsumeOAuthState("state", "session", "Google", "login", "web")
+
+		require.EqualError(test, err, errorInvalidOAuthState)
+	})
+}
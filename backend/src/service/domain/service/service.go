@@ -1,58 +1,49 @@
 package service_service
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
-	"strings"
+	"strconv"
+	"time"
 
 	"backend/src/entities"
+	"backend/src/service"
 	"backend/src/storage"
 )
 
 type ServiceService struct {
-	ServiceRepository  storage.ServiceRepository
-	UserRepository     storage.UserRepository
-	ActionRepository   storage.ActionRepository
-	WorkflowRepository storage.WorkflowRepository
-	ReactionRepository storage.ReactionRepository
+	ServiceRepository    storage.ServiceRepository
+	UserRepository       storage.UserRepository
+	ActionRepository     storage.ActionRepository
+	WorkflowRepository   storage.WorkflowRepository
+	ReactionRepository   storage.ReactionRepository
+	OAuthStateRepository storage.OAuthStateRepository
+	Connectors           service.ConnectorRegistry
 }
 
 const bearerType = "Bearer "
-const contentType = "Content-Type"
-const contentTypeUrlEncoded = "application/x-www-form-urlencoded"
 
-const invalidCallbackTypeMessage = "Invalid callback type"
 const unknownServiceMessage = "Unknown service"
-const apiCallFailedMessage = "API call failed"
 
-const grantTypeRefreshToken = "refresh_token"
-const grantTypeAuthorization = "authorization_code"
-const oneYearSecond = 31536000
-
-const clientIdParam = "client_id="
-const clientSecretParam = "&client_secret="
-const codeParam = "&code="
-const grantTypeParam = "&grant_type="
-const redirectUriParam = "&redirect_uri="
-const stateParam = "&state="
-const codeResponseType = "&response_type=code"
+const errorBodyLimit = 4096
 
 const githubBaseUrl = "https://api.github.com/"
 
 func NewServiceService(ServiceRepository storage.ServiceRepository, UserRepository storage.UserRepository,
 	ActionRepository storage.ActionRepository, WorkflowRepository storage.WorkflowRepository,
-	ReactionRepository storage.ReactionRepository) *ServiceService {
+	ReactionRepository storage.ReactionRepository, OAuthStateRepository storage.OAuthStateRepository,
+	Connectors service.ConnectorRegistry) *ServiceService {
 	return &ServiceService{
-		ServiceRepository:  ServiceRepository,
-		UserRepository:     UserRepository,
-		ActionRepository:   ActionRepository,
-		WorkflowRepository: WorkflowRepository,
-		ReactionRepository: ReactionRepository,
+		ServiceRepository:    ServiceRepository,
+		UserRepository:       UserRepository,
+		ActionRepository:     ActionRepository,
+		WorkflowRepository:   WorkflowRepository,
+		ReactionRepository:   ReactionRepository,
+		OAuthStateRepository: OAuthStateRepository,
+		Connectors:           Connectors,
 	}
 }
 
@@ -118,586 +109,144 @@ func (self *ServiceService) RetrieveReactionsServices() ([]entities.Service, err
 	return self.ServiceRepository.FindReactionsServices()
 }
 
-func getCallbackAndClientId(callbackType, serviceName string, isIdNecessary bool) (string, string) {
-	var callbackLink, clientID string
-
-	if callbackType == "login" {
-		if isIdNecessary {
-			clientID = os.Getenv(serviceName + "_LOGIN_CLIENT_ID")
-		}
-		callbackLink = os.Getenv(serviceName + "_LOGIN_CALLBACK")
-	} else if callbackType == "service" {
-		if isIdNecessary {
-			clientID = os.Getenv(serviceName + "_SERVICE_CLIENT_ID")
-		}
-		callbackLink = os.Getenv(serviceName + "_SERVICE_CALLBACK")
-	}
-	return callbackLink, clientID
-}
-
-func oauth2Google(callbackType, appType string) string {
-	var callbackLink, appTypeLink string
-
-	if callbackType == "login" {
-		callbackLink = os.Getenv("GOOGLE_LOGIN_CALLBACK")
-	} else if callbackType == "service" {
-		callbackLink = os.Getenv("GOOGLE_SERVICE_CALLBACK")
-	}
-
-	if appType == "web" {
-		appTypeLink = os.Getenv("GOOGLE_WEB_CLIENT_ID")
-	} else if appType == "mobile" {
-		appTypeLink = os.Getenv("GOOGLE_MOBILE_CLIENT_ID")
-	}
-
-	return ("https://accounts.google.com/o/oauth2/auth?" +
-		clientIdParam + appTypeLink +
-		codeResponseType +
-		"&access_type=offline" +
-		redirectUriParam + callbackLink +
-		"&scope=email")
-}
-
-func oauth2Spotify(callbackType string) string {
-	callbackLink, _ := getCallbackAndClientId(callbackType, "SPOTIFY", false)
-
-	return ("https://accounts.spotify.com/authorize?" +
-		clientIdParam + os.Getenv("SPOTIFY_CLIENT_ID") +
-		codeResponseType +
-		redirectUriParam + callbackLink +
-		"&scope=user-read-private user-read-email user-modify-playback-state user-read-playback-state user-library-modify playlist-modify-public")
-}
-
-func oauth2Discord(callbackType string) string {
-	callbackLink, _ := getCallbackAndClientId(callbackType, "DISCORD", false)
-	var scope string
-
-	if callbackType == "login" {
-		scope = "identify+email"
-	} else if callbackType == "service" {
-		scope = "identify+email+guilds+bot"
-	}
-
-	return ("https://discord.com/oauth2/authorize?" +
-		clientIdParam + os.Getenv("DISCORD_CLIENT_ID") +
-		"&permissions=141376" +
-		codeResponseType +
-		redirectUriParam + callbackLink +
-		"&scope=" + scope)
-}
-
-func oauth2Github(callbackType string) string {
-	callbackLink, clientID := getCallbackAndClientId(callbackType, "GITHUB", true)
-
-	return ("https://github.com/login/oauth/authorize?" +
-		clientIdParam + clientID +
-		redirectUriParam + callbackLink +
-		"&scope=repo admin:org user" +
-		stateParam + os.Getenv("GITHUB_STATE"))
-}
-
-func oauth2Reddit(callbackType string) string {
-	callbackLink, clientID := getCallbackAndClientId(callbackType, "REDDIT", true)
-
-	return ("https://www.reddit.com/api/v1/authorize?" +
-		clientIdParam + clientID +
-		codeResponseType +
-		stateParam + os.Getenv("REDDIT_STATE") +
-		redirectUriParam + callbackLink +
-		"&duration=permanent" +
-		"&scope=identity read submit vote mysubreddits history account edit privatemessages")
-}
-
-func oauth2Asana(callbackType string) string {
-	callbackLink, _ := getCallbackAndClientId(callbackType, "ASANA", false)
-
-	return ("https://app.asana.com/-/oauth_authorize?" +
-		clientIdParam + os.Getenv("ASANA_CLIENT_ID") +
-		redirectUriParam + callbackLink +
-		codeResponseType +
-		stateParam + os.Getenv("ASANA_STATE") +
-		"&scope=default email profile")
-}
-
-func oauth2Linkedin(callbackType string) string {
-	callbackLink, _ := getCallbackAndClientId(callbackType, "LINKEDIN", false)
-
-	return ("https://www.linkedin.com/oauth/v2/authorization?" +
-		clientIdParam + url.QueryEscape(os.Getenv("LINKEDIN_CLIENT_ID")) +
-		redirectUriParam + callbackLink +
-		codeResponseType +
-		"&scope=openid email profile w_member_social" +
-		stateParam + os.Getenv("LINKEDIN_STATE"))
-}
-
-func oauth2Dropbox(callbackType string) string {
-	callbackLink, _ := getCallbackAndClientId(callbackType, "DROPBOX", false)
-
-	return ("https://www.dropbox.com/oauth2/authorize?" +
-		clientIdParam + url.QueryEscape(os.Getenv("DROPBOX_CLIENT_ID")) +
-		redirectUriParam + callbackLink +
-		codeResponseType + "&token_access_type=offline")
-}
-
-func oauth2Gitlab(callbackType string) string {
-	callbackLink, _ := getCallbackAndClientId(callbackType, "GITLAB", false)
-
-	return ("https://gitlab.com/oauth/authorize?" +
-		clientIdParam + os.Getenv("GITLAB_CLIENT_ID") +
-		codeResponseType +
-		redirectUriParam + callbackLink +
-		stateParam + os.Getenv("GITLAB_STATE") +
-		"&scope=api read_api read_user read_repository write_repository")
-}
-
-func (self *ServiceService) OAuth2Service(serviceName, callbackType, appType string) (string, error) {
-	var authUrl string
-
-	switch serviceName {
-	case "Google":
-		authUrl = oauth2Google(callbackType, appType)
-	case "Spotify":
-		authUrl = oauth2Spotify(callbackType)
-	case "Discord":
-		authUrl = oauth2Discord(callbackType)
-	case "Github":
-		authUrl = oauth2Github(callbackType)
-	case "Reddit":
-		authUrl = oauth2Reddit(callbackType)
-	case "Asana":
-		authUrl = oauth2Asana(callbackType)
-	case "Linkedin":
-		authUrl = oauth2Linkedin(callbackType)
-	case "Dropbox":
-		authUrl = oauth2Dropbox(callbackType)
-	case "Gitlab":
-		authUrl = oauth2Gitlab(callbackType)
-	default:
-		return authUrl, fmt.Errorf(unknownServiceMessage)
-	}
-	return authUrl, nil
-}
-
-func genericAccessTokenRequest(tokenUrl, code, callbackUrl string) (*http.Request, error) {
-	jsonBody := fmt.Sprintf(
-		"grant_type=%s&code=%s&redirect_uri=%s",
-		grantTypeAuthorization,
-		code,
-		callbackUrl,
-	)
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
-	}
-	return request, nil
-}
-
-func getGoogleOAuth2AccessTokenWebRequestBody(code, callback string) string {
-	return fmt.Sprintf(
-		"code=%s&client_id=%s&client_secret=%s&redirect_uri=%s&grant_type=%s",
-		code,
-		os.Getenv("GOOGLE_WEB_CLIENT_ID"),
-		os.Getenv("GOOGLE_CLIENT_SECRET"),
-		callback,
-		grantTypeAuthorization,
-	)
-}
-
-func getGoogleOAuth2AccessTokenMobileRequestBody(code, callback string) string {
-	return fmt.Sprintf(
-		"code=%s&client_id=%s&redirect_uri=%s&grant_type=%s",
-		code,
-		os.Getenv("GOOGLE_MOBILE_CLIENT_ID"),
-		callback,
-		grantTypeAuthorization,
-	)
-}
-
-func getGoogleOAuth2AccessTokenRequest(code, callbackType, appType string) (*http.Request, error) {
-	var googleCallback, jsonBody string
-
-	if callbackType == "service" {
-		googleCallback = os.Getenv("GOOGLE_SERVICE_CALLBACK")
-	} else if callbackType == "login" {
-		googleCallback = os.Getenv("GOOGLE_LOGIN_CALLBACK")
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	if appType == "web" {
-		jsonBody = getGoogleOAuth2AccessTokenWebRequestBody(code, googleCallback)
-	} else if appType == "mobile" {
-		jsonBody = getGoogleOAuth2AccessTokenMobileRequestBody(code, googleCallback)
-	} else {
-		return nil, fmt.Errorf("Invalid app type")
-	}
-
-	tokenUrl := "https://oauth2.googleapis.com/token"
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
-	}
-
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-	return request, nil
-}
-
-func getGithubOAuth2AccessTokenRequest(code, callbackType string) (*http.Request, error) {
-	var githubCallback, githubClientID, githubClientSecret string
-
-	if callbackType == "service" {
-		githubCallback = os.Getenv("GITHUB_SERVICE_CALLBACK")
-		githubClientID = os.Getenv("GITHUB_SERVICE_CLIENT_ID")
-		githubClientSecret = os.Getenv("GITHUB_SERVICE_CLIENT_SECRET")
-	} else if callbackType == "login" {
-		githubCallback = os.Getenv("GITHUB_LOGIN_CALLBACK")
-		githubClientID = os.Getenv("GITHUB_LOGIN_CLIENT_ID")
-		githubClientSecret = os.Getenv("GITHUB_LOGIN_CLIENT_SECRET")
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	tokenUrl := "https://github.com/login/oauth/access_token"
-	jsonBody := fmt.Sprintf(
-		"client_id=%s&client_secret=%s&code=%s&redirect_uri=%s",
-		githubClientID,
-		githubClientSecret,
-		code,
-		githubCallback,
-	)
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
-	}
-
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
-}
-
-func getGitlabOAuth2AccessTokenRequest(code, callbackType string) (*http.Request, error) {
-	var gitlabCallback string
-
-	if callbackType == "service" {
-		gitlabCallback = os.Getenv("GITLAB_SERVICE_CALLBACK")
-	} else if callbackType == "login" {
-		gitlabCallback = os.Getenv("GITLAB_LOGIN_CALLBACK")
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	tokenUrl := "https://gitlab.com/oauth/token"
-	jsonBody := fmt.Sprintf(
-		clientIdParam + os.Getenv("GITLAB_CLIENT_ID") +
-			clientSecretParam + os.Getenv("GITLAB_CLIENT_SECRET") +
-			codeParam + code +
-			redirectUriParam + gitlabCallback +
-			grantTypeParam + grantTypeAuthorization,
-	)
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
-	}
-
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
-}
-
-func getAsanaOAuth2AccessTokenRequest(code, callbackType string) (*http.Request, error) {
-	var asanaCallback string
-
-	if callbackType == "service" {
-		asanaCallback = os.Getenv("ASANA_SERVICE_CALLBACK")
-	} else if callbackType == "login" {
-		asanaCallback = os.Getenv("ASANA_LOGIN_CALLBACK")
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	tokenUrl := "https://app.asana.com/-/oauth_token"
-	jsonBody := fmt.Sprintf(
-		"client_id=%s&client_secret=%s&code=%s&redirect_uri=%s&grant_type=%s",
-		os.Getenv("ASANA_CLIENT_ID"),
-		os.Getenv("ASANA_CLIENT_SECRET"),
-		code,
-		asanaCallback,
-		grantTypeAuthorization,
-	)
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
-	}
-
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
-}
-
-func getLinkedinOAuth2AccessTokenRequest(code, callbackType string) (*http.Request, error) {
-	var callback string
-
-	if callbackType == "service" {
-		callback = os.Getenv("LINKEDIN_SERVICE_CALLBACK")
-	} else if callbackType == "login" {
-		callback = os.Getenv("LINKEDIN_LOGIN_CALLBACK")
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	tokenUrl := "https://www.linkedin.com/oauth/v2/accessToken"
-	jsonBody := fmt.Sprintf(
-		"client_id=%s&client_secret=%s&code=%s&redirect_uri=%s&grant_type=%s",
-		url.QueryEscape(os.Getenv("LINKEDIN_CLIENT_ID")),
-		url.QueryEscape(os.Getenv("LINKEDIN_CLIENT_SECRET")),
-		code,
-		callback,
-		grantTypeAuthorization,
-	)
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
-	}
-
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
-}
-
-func getDropboxOAuth2AccessTokenRequest(code, callbackType string) (*http.Request, error) {
-	var dropboxCallback string
-
-	if callbackType == "service" {
-		dropboxCallback = os.Getenv("DROPBOX_SERVICE_CALLBACK")
-	} else if callbackType == "login" {
-		dropboxCallback = os.Getenv("DROPBOX_LOGIN_CALLBACK")
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	request, err := genericAccessTokenRequest("https://api.dropboxapi.com/oauth2/token", code, dropboxCallback)
-	if err != nil {
-		return request, err
-	}
-
-	request.SetBasicAuth(os.Getenv("DROPBOX_CLIENT_ID"), os.Getenv("DROPBOX_CLIENT_SECRET"))
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
+func (self *ServiceService) FindConnector(serviceName string) (service.Connector, error) {
+	return self.Connectors.FindConnector(serviceName)
 }
 
-func getSpotifyOAuth2AccessTokenRequest(code, callbackType string) (*http.Request, error) {
-	var spotifyCallback string
-
-	if callbackType == "service" {
-		spotifyCallback = os.Getenv("SPOTIFY_SERVICE_CALLBACK")
-	} else if callbackType == "login" {
-		spotifyCallback = os.Getenv("SPOTIFY_LOGIN_CALLBACK")
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	request, err := genericAccessTokenRequest("https://accounts.spotify.com/api/token", code, spotifyCallback)
-	if err != nil {
-		return request, err
-	}
-
-	request.SetBasicAuth(os.Getenv("SPOTIFY_CLIENT_ID"), os.Getenv("SPOTIFY_CLIENT_SECRET"))
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
+func (self *ServiceService) RetrieveConnectors() []service.Connector {
+	return self.Connectors.RetrieveConnectors()
 }
 
-func getDiscordOAuth2AccessTokenRequest(code, callbackType string) (*http.Request, error) {
-	var discordCallback string
-
-	if callbackType == "service" {
-		discordCallback = os.Getenv("DISCORD_SERVICE_CALLBACK")
-	} else if callbackType == "login" {
-		discordCallback = os.Getenv("DISCORD_LOGIN_CALLBACK")
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	request, err := genericAccessTokenRequest("https://discord.com/api/v10/oauth2/token", code, discordCallback)
-	if err != nil {
-		return request, err
+func newServiceFromDefinition(definition entities.ServiceDefinition) entities.Service {
+	return entities.Service{
+		Name:         definition.Name,
+		Color:        definition.Color,
+		Logo:         definition.Logo,
+		HasActions:   len(definition.Actions) > 0,
+		HasReactions: len(definition.Reactions) > 0,
+		IsAuthNeeded: definition.IsAuthNeeded,
+		Description:  definition.Description,
 	}
-
-	request.SetBasicAuth(os.Getenv("DISCORD_CLIENT_ID"), os.Getenv("DISCORD_CLIENT_SECRET"))
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
 }
 
-func getRedditOAuth2AccessTokenRequest(code, callbackType string) (*http.Request, error) {
-	var redditCallback, redditClientID, redditClientSecret string
-
-	if callbackType == "service" {
-		redditCallback = os.Getenv("REDDIT_SERVICE_CALLBACK")
-		redditClientID = url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_ID"))
-		redditClientSecret = url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_SECRET"))
-	} else if callbackType == "login" {
-		redditCallback = os.Getenv("REDDIT_LOGIN_CALLBACK")
-		redditClientID = url.QueryEscape(os.Getenv("REDDIT_LOGIN_CLIENT_ID"))
-		redditClientSecret = url.QueryEscape(os.Getenv("REDDIT_LOGIN_CLIENT_SECRET"))
-	} else {
-		return nil, fmt.Errorf(invalidCallbackTypeMessage)
-	}
-
-	request, err := genericAccessTokenRequest("https://www.reddit.com/api/v1/access_token", code, redditCallback)
-	if err != nil {
-		return request, err
+func newActionFromDefinition(serviceId string, definition entities.AboutAction) entities.Action {
+	return entities.Action{
+		Name:            definition.Name,
+		Description:     definition.Description,
+		ServiceId:       serviceId,
+		NbParam:         len(definition.Parameters),
+		Parameters:      definition.Parameters,
+		Variables:       definition.Variables,
+		MinimumInterval: definition.MinimumInterval,
+		DefaultInterval: definition.DefaultInterval,
 	}
-
-	request.SetBasicAuth(redditClientID, redditClientSecret)
-	request.Header.Set("User-Agent", os.Getenv("REDDIT_SERVICE_USER_AGENT"))
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
 }
 
-func (self *ServiceService) genericRefreshTokenRequest(tokenUrl, refreshToken string) (*http.Request, error) {
-	jsonBody := fmt.Sprintf(
-		"grant_type=%s&refresh_token=%s",
-		grantTypeRefreshToken,
-		refreshToken,
-	)
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
+func newReactionFromDefinition(serviceId string, definition entities.AboutReaction) entities.Reaction {
+	return entities.Reaction{
+		Name:        definition.Name,
+		Description: definition.Description,
+		ServiceId:   serviceId,
+		NbParam:     len(definition.Parameters),
+		Parameters:  definition.Parameters,
 	}
-	return request, nil
 }
 
-func (self *ServiceService) genericJsonBodyRefreshToken(clientId, secretId, refreshToken string) string {
-	return fmt.Sprintf(
-		"client_id=%s&client_secret=%s&grant_type=%s&refresh_token=%s",
-		clientId,
-		secretId,
-		grantTypeRefreshToken,
-		refreshToken,
-	)
-}
-
-func (self *ServiceService) GetGoogleRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	tokenUrl := "https://oauth2.googleapis.com/token"
-	jsonBody := self.genericJsonBodyRefreshToken(os.Getenv("GOOGLE_WEB_CLIENT_ID"), os.Getenv("GOOGLE_CLIENT_SECRET"), refreshToken)
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
-	}
-
-	request.Header.Set(contentType, contentTypeUrlEncoded)
+// Creates the services, actions and reactions of the connectors missing from the database.
+// The existing actions are updated from their definition, their variables and intervals are only set here.
+func (self *ServiceService) SeedServices() error {
+	for _, connector := range self.Connectors.RetrieveConnectors() {
+		definition := connector.Definition()
 
-	return request, nil
-}
+		foundService, err := self.seedService(definition)
+		if err != nil {
+			return err
+		}
 
-func (self *ServiceService) GetAsanaRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	tokenUrl := "https://app.asana.com/-/oauth_token"
-	jsonBody := self.genericJsonBodyRefreshToken(os.Getenv("ASANA_CLIENT_ID"), os.Getenv("ASANA_CLIENT_SECRET"), refreshToken)
+		for _, action := range definition.Actions {
+			foundAction, err := self.ActionRepository.FindActionByNameAndServiceId(action.Name, foundService.Id)
+			if err == nil {
+				err = self.ActionRepository.UpdateAction(foundAction.Id, newActionFromDefinition(foundService.Id, action))
+			} else {
+				err = self.ActionRepository.CreateAction(newActionFromDefinition(foundService.Id, action))
+			}
+			if err != nil {
+				return err
+			}
+		}
 
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
+		for _, reaction := range definition.Reactions {
+			_, err = self.ReactionRepository.FindReactionByNameAndServiceId(reaction.Name, foundService.Id)
+			if err == nil {
+				continue
+			}
+			err = self.ReactionRepository.CreateReaction(newReactionFromDefinition(foundService.Id, reaction))
+			if err != nil {
+				return err
+			}
+		}
 	}
-
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
+	return nil
 }
 
-func (self *ServiceService) GetDropboxRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	tokenUrl := "https://api.dropbox.com/oauth2/token"
-	jsonBody := self.genericJsonBodyRefreshToken(os.Getenv("DROPBOX_CLIENT_ID"), os.Getenv("DROPBOX_CLIENT_SECRET"), refreshToken)
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
+func (self *ServiceService) seedService(definition entities.ServiceDefinition) (entities.Service, error) {
+	foundService, err := self.ServiceRepository.FindServiceByName(definition.Name)
+	if err == nil {
+		return foundService, nil
 	}
 
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
-}
-
-func (self *ServiceService) GetSpotifyRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	tokenUrl := "https://accounts.spotify.com/api/token"
-	request, err := self.genericRefreshTokenRequest(tokenUrl, refreshToken)
+	err = self.ServiceRepository.CreateService(newServiceFromDefinition(definition))
 	if err != nil {
-		return request, err
+		return foundService, err
 	}
-
-	request.SetBasicAuth(os.Getenv("SPOTIFY_CLIENT_ID"), os.Getenv("SPOTIFY_CLIENT_SECRET"))
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
+	return self.ServiceRepository.FindServiceByName(definition.Name)
 }
 
-func (self *ServiceService) GetDiscordRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	tokenUrl := "https://discord.com/api/v10/oauth2/token"
-	request, err := self.genericRefreshTokenRequest(tokenUrl, refreshToken)
-	if err != nil {
-		return request, err
+// Retry-After is either a number of seconds or an HTTP date
+func parseRetryAfter(retryAfter string, now time.Time) time.Duration {
+	seconds, err := strconv.Atoi(retryAfter)
+	if err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
 	}
 
-	request.SetBasicAuth(os.Getenv("DISCORD_CLIENT_ID"), os.Getenv("DISCORD_CLIENT_SECRET"))
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
-}
-
-func (self *ServiceService) GetRedditRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	tokenUrl := "https://www.reddit.com/api/v1/access_token"
-	request, err := self.genericRefreshTokenRequest(tokenUrl, refreshToken)
-	if err != nil {
-		return request, err
+	date, err := http.ParseTime(retryAfter)
+	if err == nil && date.After(now) {
+		return date.Sub(now)
 	}
-
-	request.SetBasicAuth(url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_ID")), url.QueryEscape(os.Getenv("REDDIT_SERVICE_CLIENT_SECRET")))
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-	request.Header.Set("User-Agent", os.Getenv("REDDIT_SERVICE_USER_AGENT"))
-
-	return request, nil
+	return 0
 }
 
-func (self *ServiceService) GetGitlabRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	tokenUrl := "https://gitlab.com/oauth/token"
-	jsonBody := self.genericJsonBodyRefreshToken(os.Getenv("GITLAB_CLIENT_ID"), os.Getenv("GITLAB_CLIENT_SECRET"), refreshToken)
-	jsonBody += "&redirect_uri=" + os.Getenv("GITLAB_SERVICE_CALLBACK")
-
-	request, errRequest := http.NewRequest("POST", tokenUrl, bytes.NewBuffer([]byte(jsonBody)))
-	if errRequest != nil {
-		return request, errRequest
-	}
-
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-
-	return request, nil
+func (self *ServiceService) ExecuteRequest(request *http.Request) (*http.Response, error) {
+	return self.ExecuteRequestWithClient(&http.Client{}, request)
 }
 
-func (self *ServiceService) ExecuteRequest(request *http.Request) (*http.Response, error) {
-	client := &http.Client{}
+// The client decides how the request is sent, such as a transport refusing to dial some addresses
+func (self *ServiceService) ExecuteRequestWithClient(client *http.Client, request *http.Request) (*http.Response, error) {
 	res, err := client.Do(request)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusAccepted {
-		return nil, fmt.Errorf(apiCallFailedMessage)
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusAccepted &&
+		res.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, errorBodyLimit))
+		res.Body.Close()
+		return nil, entities.ApiCallError{
+			StatusCode: res.StatusCode,
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+			Body:       string(body),
+		}
 	}
 	return res, nil
 }
 
 func (self *ServiceService) ExecuteApiRequest(url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, body)
+	return self.ExecuteApiRequestWithContext(context.Background(), url, method, typeToken, accessToken, body)
+}
+
+// The request is abandoned once the context is done, such as a poll check past its deadline
+func (self *ServiceService) ExecuteApiRequestWithContext(ctx context.Context, url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -708,37 +257,15 @@ func (self *ServiceService) ExecuteApiRequest(url, method, typeToken, accessToke
 	return self.ExecuteRequest(req)
 }
 
-func (self *ServiceService) GetResultTokenFromCode(code, serviceName, callbackType, appType string) (entities.ResultToken, error) {
+func (self *ServiceService) GetResultTokenFromCode(code, serviceName, callbackType, appType, codeVerifier string) (entities.ResultToken, error) {
 	var tokenRes entities.ResultToken
-	var request *http.Request
-	var errRequest error
-
-	switch serviceName {
-	case "Google":
-		request, errRequest = getGoogleOAuth2AccessTokenRequest(code, callbackType, appType)
-	case "Spotify":
-		request, errRequest = getSpotifyOAuth2AccessTokenRequest(code, callbackType)
-	case "Discord":
-		request, errRequest = getDiscordOAuth2AccessTokenRequest(code, callbackType)
-	case "Github":
-		request, errRequest = getGithubOAuth2AccessTokenRequest(code, callbackType)
-		request.Header.Set("Accept", "application/json")
-		tokenRes.ExpiresIn = oneYearSecond
-	case "Reddit":
-		codeStripped := strings.TrimSuffix(code, "#_")
-		request, errRequest = getRedditOAuth2AccessTokenRequest(codeStripped, callbackType)
-	case "Asana":
-		request, errRequest = getAsanaOAuth2AccessTokenRequest(code, callbackType)
-	case "Linkedin":
-		request, errRequest = getLinkedinOAuth2AccessTokenRequest(code, callbackType)
-	case "Dropbox":
-		request, errRequest = getDropboxOAuth2AccessTokenRequest(code, callbackType)
-	case "Gitlab":
-		request, errRequest = getGitlabOAuth2AccessTokenRequest(code, callbackType)
-	default:
-		return tokenRes, fmt.Errorf(unknownServiceMessage)
+
+	connector, err := self.Connectors.FindConnector(serviceName)
+	if err != nil {
+		return tokenRes, err
 	}
 
+	request, errRequest := connector.AccessTokenRequest(code, callbackType, appType, codeVerifier)
 	if errRequest != nil {
 		return tokenRes, errRequest
 	}
@@ -749,6 +276,7 @@ func (self *ServiceService) GetResultTokenFromCode(code, serviceName, callbackTy
 	}
 	defer res.Body.Close()
 
+	tokenRes.ExpiresIn = connector.OAuthConfig().DefaultTokenLifetime
 	errDecoder := json.NewDecoder(res.Body).Decode(&tokenRes)
 	if errDecoder != nil {
 		return tokenRes, errDecoder
@@ -756,100 +284,33 @@ func (self *ServiceService) GetResultTokenFromCode(code, serviceName, callbackTy
 	return tokenRes, nil
 }
 
-func getOAuth2UserEmailRequest(userInfoUrl, accessToken string) (*http.Request, error) {
-	request, errRequest := http.NewRequest("GET", userInfoUrl, nil)
-	if errRequest != nil {
-		return request, errRequest
-	}
-
-	request.Header.Set("Authorization", bearerType+accessToken)
-	request.Header.Set(contentType, contentTypeUrlEncoded)
-	return request, nil
-}
-
-func decodeUserInfoWithMultipleResults(res *http.Response) (entities.UserInfo, error) {
-	var userInfos []entities.UserInfo
-
-	err := json.NewDecoder(res.Body).Decode(&userInfos)
-	if err != nil {
-		return userInfos[0], err
-	}
-	return userInfos[0], nil
-}
-
 func (self *ServiceService) GetUserInfoFromService(accessToken, serviceName string) (entities.UserInfo, error) {
 	var userInfo entities.UserInfo
-	var request *http.Request
-	var err error
-
-	switch serviceName {
-	case "Google":
-		request, err = getOAuth2UserEmailRequest("https://www.googleapis.com/oauth2/v3/userinfo", accessToken)
-	case "Spotify":
-		request, err = getOAuth2UserEmailRequest("https://api.spotify.com/v1/me", accessToken)
-	case "Discord":
-		request, err = getOAuth2UserEmailRequest("https://discord.com/api/users/@me", accessToken)
-	case "Github":
-		request, err = getOAuth2UserEmailRequest(githubBaseUrl+"user/emails", accessToken)
-	case "Gitlab":
-		request, err = getOAuth2UserEmailRequest("https://gitlab.com/api/v4/user/emails", accessToken)
-	default:
-		return userInfo, fmt.Errorf(unknownServiceMessage)
-	}
 
+	connector, err := self.Connectors.FindConnector(serviceName)
 	if err != nil {
 		return userInfo, err
 	}
 
-	res, err := self.ExecuteRequest(request)
+	request, err := connector.UserInfoRequest(accessToken)
 	if err != nil {
 		return userInfo, err
 	}
-	defer res.Body.Close()
 
-	if serviceName == "Github" || serviceName == "Gitlab" {
-		userInfo, err = decodeUserInfoWithMultipleResults(res)
-	} else {
-		err = json.NewDecoder(res.Body).Decode(&userInfo)
-	}
+	res, err := self.ExecuteRequest(request)
 	if err != nil {
 		return userInfo, err
 	}
-	return userInfo, nil
-}
-
-func (self *ServiceService) RequestToTimeApi() (entities.TimeResponse, error) {
-	timeUrl := "https://tools.aimylogic.com/api/now?tz=Europe/Paris"
-	var timeRes entities.TimeResponse
-
-	request, errRequest := http.NewRequest("GET", timeUrl, nil)
-	if errRequest != nil {
-		return timeRes, errRequest
-	}
-
-	client := &http.Client{}
-	res, err := client.Do(request)
-	if err != nil {
-		return timeRes, err
-	}
 	defer res.Body.Close()
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return timeRes, fmt.Errorf("HTTP request failed: %s", res.Status)
-	}
-
-	errDecoder := json.NewDecoder(res.Body).Decode(&timeRes)
-	if errDecoder != nil {
-		return timeRes, errDecoder
-	}
-	return timeRes, nil
+	return connector.DecodeUserInfo(res)
 }
 
-func (self *ServiceService) RequestGithubUserRepositories(accessToken string) ([]entities.GithubRepository, error) {
+func (self *ServiceService) RequestGithubUserRepositories(ctx context.Context, accessToken string) ([]entities.GithubRepository, error) {
 	var repositories []entities.GithubRepository
 	url := githubBaseUrl + "user/repos"
 
-	resp, err := self.ExecuteApiRequest(url, "GET", bearerType, accessToken, nil)
+	resp, err := self.ExecuteApiRequestWithContext(ctx, url, "GET", bearerType, accessToken, nil)
 	if err != nil {
 		return repositories, err
 	}
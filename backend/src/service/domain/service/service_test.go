@@ -1,29 +1,26 @@
 package service_service
 
 import (
-	"bytes"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"net/http"
-	"net/url"
-	"os"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"backend/src/entities"
+	"backend/src/service/connector"
 )
 
 type MockServiceRepository struct {
 	mock.Mock
 }
 
-func (m *MockServiceRepository) CreateService(name, color, logo string) error {
-	args := m.Called(name, color, logo)
+func (m *MockServiceRepository) CreateService(service entities.Service) error {
+	args := m.Called(service)
 	return args.Error(0)
 }
 
@@ -52,12 +49,31 @@ func (m *MockServiceRepository) FindReactionsServices() ([]entities.Service, err
 	return args.Get(0).([]entities.Service), args.Error(1)
 }
 
+type MockOAuthStateRepository struct {
+	mock.Mock
+}
+
+func (m *MockOAuthStateRepository) CreateOAuthState(codeVerifier string, expiresAt time.Time) (string, error) {
+	args := m.Called(codeVerifier, expiresAt)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOAuthStateRepository) ConsumeOAuthState(id string, now time.Time) (string, bool, error) {
+	args := m.Called(id, now)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
 type MockActionRepository struct {
 	mock.Mock
 }
 
-func (m *MockActionRepository) CreateAction(name, description, serviceId string, nbParam int) error {
-	args := m.Called(name, description, serviceId, nbParam)
+func (m *MockActionRepository) CreateAction(action entities.Action) error {
+	args := m.Called(action)
+	return args.Error(0)
+}
+
+func (m *MockActionRepository) UpdateAction(id string, action entities.Action) error {
+	args := m.Called(id, action)
 	return args.Error(0)
 }
 
@@ -85,11 +101,16 @@ type MockReactionRepository struct {
 	mock.Mock
 }
 
-func (m *MockReactionRepository) CreateReaction(name, description, serviceId string, nbParam int) error {
-	args := m.Called(name, description, serviceId, nbParam)
+func (m *MockReactionRepository) CreateReaction(reaction entities.Reaction) error {
+	args := m.Called(reaction)
 	return args.Error(0)
 }
 
+func (m *MockReactionRepository) FindReactionByNameAndServiceId(name, serviceId string) (entities.Reaction, error) {
+	args := m.Called(name, serviceId)
+	return args.Get(0).(entities.Reaction), args.Error(1)
+}
+
 func (m *MockReactionRepository) FindReactionById(id string) (entities.Reaction, error) {
 	args := m.Called(id)
 	return args.Get(0).(entities.Reaction), args.Error(1)
@@ -396,626 +417,350 @@ func TestRetrieveReactionsServices(test *testing.T) {
 	require.NoError(test, err)
 }
 
-func TestGetCallbackAndClientId(test *testing.T) {
-	test.Run("Login", func(test *testing.T) {
-
-		serviceName := "SPOTIFY"
-
-		callback, clientId := getCallbackAndClientId("login", "SPOTIFY", true)
-
-		resCallback := serviceName + "_LOGIN_CALLBACK"
-		resClientId := serviceName + "_LOGIN_CLIENT_ID"
-
-		assert.Equal(test, os.Getenv(resCallback), callback)
-		assert.Equal(test, os.Getenv(resClientId), clientId)
-	})
-
-	test.Run("Service", func(test *testing.T) {
-
-		serviceName := "SPOTIFY"
-
-		callback, clientId := getCallbackAndClientId("service", "SPOTIFY", true)
-
-		resCallback := serviceName + "_SERVICE_CALLBACK"
-		resClientId := serviceName + "_SERVICE_CLIENT_ID"
-
-		assert.Equal(test, os.Getenv(resCallback), callback)
-		assert.Equal(test, os.Getenv(resClientId), clientId)
-	})
-}
-
-func TestOauth2Google(test *testing.T) {
-	test.Run("Login web", func(test *testing.T) {
-		callbackLink := os.Getenv("GOOGLE_LOGIN_CALLBACK")
-		appTypeLink := os.Getenv("GOOGLE_WEB_CLIENT_ID")
-
-		res := oauth2Google("login", "web")
-
-		expectedRes := "https://accounts.google.com/o/oauth2/auth?" +
-			clientIdParam + appTypeLink +
-			codeResponseType +
-			"&access_type=offline" +
-			redirectUriParam + callbackLink +
-			"&scope=email"
-
-		assert.Equal(test, res, expectedRes)
-	})
-
-	test.Run("Service mobile", func(test *testing.T) {
-		callbackLink := os.Getenv("GOOGLE_WEB_CLIENT_ID")
-		appTypeLink := os.Getenv("GOOGLE_MOBILE_CLIENT_ID")
-
-		res := oauth2Google("service", "mobile")
-
-		expectedRes := "https://accounts.google.com/o/oauth2/auth?" +
-			clientIdParam + appTypeLink +
-			codeResponseType +
-			"&access_type=offline" +
-			redirectUriParam + callbackLink +
-			"&scope=email"
-
-		assert.Equal(test, res, expectedRes)
-	})
-}
-
-func TestOauth2Spotify(test *testing.T) {
-	expectedRes := "https://accounts.spotify.com/authorize?" +
-		clientIdParam + os.Getenv("SPOTIFY_CLIENT_ID") +
-		codeResponseType +
-		redirectUriParam + os.Getenv("SPOTIFY_LOGIN_CALLBACK") +
-		"&scope=user-read-private user-read-email user-modify-playback-state user-read-playback-state user-library-modify playlist-modify-public"
-
-	res := oauth2Spotify("login")
-
-	assert.Equal(test, res, expectedRes)
-}
-
-func TestOauth2Discord(test *testing.T) {
-	test.Run("Login", func(test *testing.T) {
-		scope := "identify+email"
-
-		expectedRes := "https://discord.com/oauth2/authorize?" +
-			clientIdParam + os.Getenv("DISCORD_CLIENT_ID") +
-			"&permissions=141376" +
-			codeResponseType +
-			redirectUriParam + os.Getenv("DISCORD_LOGIN_CALLBACK") +
-			"&scope=" + scope
-
-		res := oauth2Discord("login")
-
-		assert.Equal(test, res, expectedRes)
-	})
-
-	test.Run("Service", func(test *testing.T) {
-		scope := "identify+email+guilds+bot"
-
-		expectedRes := "https://discord.com/oauth2/authorize?" +
-			clientIdParam + os.Getenv("DISCORD_CLIENT_ID") +
-			"&permissions=141376" +
-			codeResponseType +
-			redirectUriParam + os.Getenv("DISCORD_LOGIN_CALLBACK") +
-			"&scope=" + scope
-
-		res := oauth2Discord("service")
-
-		assert.Equal(test, res, expectedRes)
-	})
-}
-
-func TestOauth2Github(test *testing.T) {
-	clientID := os.Getenv("GITHUB_LOGIN_CLIENT_ID")
-	callbackLink := os.Getenv("GITHUB_LOGIN_CALLBACK")
-
-	expectedRes := "https://github.com/login/oauth/authorize?" +
-		clientIdParam + clientID +
-		redirectUriParam + callbackLink +
-		"&scope=repo admin:org user" +
-		stateParam + os.Getenv("GITHUB_STATE")
-
-	res := oauth2Github("login")
-
-	assert.Equal(test, res, expectedRes)
-}
-
-func TestOauth2Reddit(test *testing.T) {
-	clientID := os.Getenv("REDDIT_LOGIN_CLIENT_ID")
-	callbackLink := os.Getenv("REDDIT_LOGIN_CALLBACK")
-
-	expectedRes := "https://www.reddit.com/api/v1/authorize?" +
-		clientIdParam + clientID +
-		codeResponseType +
-		stateParam + os.Getenv("REDDIT_STATE") +
-		redirectUriParam + callbackLink +
-		"&duration=permanent" +
-		"&scope=identity read submit vote mysubreddits history account edit privatemessages"
-
-	res := oauth2Reddit("login")
-
-	assert.Equal(test, res, expectedRes)
-}
-
-func TestOauth2Asana(test *testing.T) {
-	callbackLink := os.Getenv("ASANA_LOGIN_CALLBACK")
-
-	expectedRes := "https://app.asana.com/-/oauth_authorize?" +
-		clientIdParam + os.Getenv("ASANA_CLIENT_ID") +
-		redirectUriParam + callbackLink +
-		codeResponseType +
-		stateParam + os.Getenv("ASANA_STATE") +
-		"&scope=default email profile"
-
-	res := oauth2Asana("login")
-
-	assert.Equal(test, res, expectedRes)
-}
+func TestFindConnector(test *testing.T) {
+	serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
-func TestOauth2Linkedin(test *testing.T) {
-	callbackLink := os.Getenv("LINKEDIN_LOGIN_CALLBACK")
-
-	expectedRes := "https://www.linkedin.com/oauth/v2/authorization?" +
-		clientIdParam + url.QueryEscape(os.Getenv("LINKEDIN_CLIENT_ID")) +
-		redirectUriParam + callbackLink +
-		codeResponseType +
-		"&scope=openid email profile w_member_social" +
-		stateParam + os.Getenv("LINKEDIN_STATE")
-
-	res := oauth2Linkedin("login")
-
-	assert.Equal(test, res, expectedRes)
-}
-
-func TestOauth2Dropbox(test *testing.T) {
-	callbackLink := os.Getenv("DROPBOX_LOGIN_CALLBACK")
-
-	expectedRes := "https://www.dropbox.com/oauth2/authorize?" +
-		clientIdParam + url.QueryEscape(os.Getenv("DROPBOX_CLIENT_ID")) +
-		redirectUriParam + callbackLink +
-		codeResponseType + "&token_access_type=offline"
-
-	res := oauth2Dropbox("login")
-
-	assert.Equal(test, res, expectedRes)
-}
-
-func TestOauth2Gitlab(test *testing.T) {
-	callbackLink := os.Getenv("GITLAB_LOGIN_CALLBACK")
-
-	expectedRes := "https://gitlab.com/oauth/authorize?" +
-		clientIdParam + os.Getenv("GITLAB_CLIENT_ID") +
-		codeResponseType +
-		redirectUriParam + callbackLink +
-		stateParam + os.Getenv("GITLAB_STATE") +
-		"&scope=api read_api read_user read_repository write_repository"
-
-	res := oauth2Gitlab("login")
-
-	assert.Equal(test, res, expectedRes)
-}
-
-func TestOAuth2Service(test *testing.T) {
-	test.Run("Google", func(test *testing.T) {
-		serviceservice := &ServiceService{}
-
-		_, err := serviceservice.OAuth2Service("Google", "login", "web")
+	test.Run("Success", func(test *testing.T) {
+		serviceConnector, err := serviceservice.FindConnector("Reddit")
 
 		require.NoError(test, err)
+		assert.Equal(test, "Reddit", serviceConnector.Definition().Name)
 	})
 
-	test.Run("Spotify", func(test *testing.T) {
-		serviceservice := &ServiceService{}
-
-		_, err := serviceservice.OAuth2Service("Spotify", "login", "web")
+	test.Run("Unknown", func(test *testing.T) {
+		_, err := serviceservice.FindConnector("Unknown")
 
-		require.NoError(test, err)
+		require.EqualError(test, err, unknownServiceMessage)
 	})
+}
 
-	test.Run("Discord", func(test *testing.T) {
-		serviceservice := &ServiceService{}
-
-		_, err := serviceservice.OAuth2Service("Discord", "login", "web")
+func TestSeedServices(test *testing.T) {
+	registry := connector.NewRegistry()
+	connectors := registry.RetrieveConnectors()
 
-		require.NoError(test, err)
-	})
+	newSeedService := func() (*ServiceService, *MockServiceRepository, *MockActionRepository, *MockReactionRepository) {
+		mockServiceRepo := new(MockServiceRepository)
+		mockActionRepo := new(MockActionRepository)
+		mockReactionRepo := new(MockReactionRepository)
 
-	test.Run("Github", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+		mockActionRepo.On("UpdateAction", mock.Anything, mock.Anything).
+			Return(nil)
 
-		_, err := serviceservice.OAuth2Service("Github", "login", "web")
+		return &ServiceService{
+			ServiceRepository:  mockServiceRepo,
+			ActionRepository:   mockActionRepo,
+			ReactionRepository: mockReactionRepo,
+			Connectors:         registry,
+		}, mockServiceRepo, mockActionRepo, mockReactionRepo
+	}
 
-		require.NoError(test, err)
-	})
+	test.Run("Create missing services", func(test *testing.T) {
+		serviceservice, mockServiceRepo, mockActionRepo, mockReactionRepo := newSeedService()
+
+		for _, serviceConnector := range connectors {
+			definition := serviceConnector.Definition()
+			if definition.Name == "Github" {
+				mockServiceRepo.On("FindServiceByName", definition.Name).
+					Return(entities.Service{Id: definition.Name, Name: definition.Name}, nil)
+				continue
+			}
+			mockServiceRepo.On("FindServiceByName", definition.Name).
+				Return(entities.Service{}, errors.New("Service not found")).Once()
+			mockServiceRepo.On("FindServiceByName", definition.Name).
+				Return(entities.Service{Id: definition.Name, Name: definition.Name}, nil)
+		}
 
-	test.Run("Reddit", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+		mockServiceRepo.On("CreateService", mock.Anything).
+			Return(nil)
+		mockActionRepo.On("FindActionByNameAndServiceId", mock.Anything, mock.Anything).
+			Return(entities.Action{}, nil)
+		mockReactionRepo.On("FindReactionByNameAndServiceId", mock.Anything, mock.Anything).
+			Return(entities.Reaction{}, nil)
 
-		_, err := serviceservice.OAuth2Service("Reddit", "login", "web")
+		err := serviceservice.SeedServices()
 
 		require.NoError(test, err)
+		mockServiceRepo.AssertNumberOfCalls(test, "CreateService", len(connectors)-1)
+		mockServiceRepo.AssertCalled(test, "CreateService", entities.Service{
+			Name:         "SMS",
+			Color:        "#F22F46",
+			Logo:         "/logos/sms.svg",
+			HasReactions: true,
+			Description:  "Send text messages",
+		})
 	})
 
-	test.Run("Asana", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+	test.Run("Create missing actions and reactions", func(test *testing.T) {
+		serviceservice, mockServiceRepo, mockActionRepo, mockReactionRepo := newSeedService()
 
-		_, err := serviceservice.OAuth2Service("Asana", "login", "web")
+		mockServiceRepo.On("FindServiceByName", mock.Anything).
+			Return(entities.Service{Id: "serviceId"}, nil)
+		mockActionRepo.On("FindActionByNameAndServiceId", "Every N minutes", "serviceId").
+			Return(entities.Action{}, errors.New("Action not found"))
+		mockActionRepo.On("FindActionByNameAndServiceId", mock.Anything, mock.Anything).
+			Return(entities.Action{Id: "actionId"}, nil)
+		mockActionRepo.On("CreateAction", mock.Anything).
+			Return(nil)
+		mockReactionRepo.On("FindReactionByNameAndServiceId", "HTTP request", "serviceId").
+			Return(entities.Reaction{}, errors.New("Reaction not found"))
+		mockReactionRepo.On("FindReactionByNameAndServiceId", mock.Anything, mock.Anything).
+			Return(entities.Reaction{}, nil)
+		mockReactionRepo.On("CreateReaction", mock.Anything).
+			Return(nil)
 
-		require.NoError(test, err)
-	})
-
-	test.Run("Linkedin", func(test *testing.T) {
-		serviceservice := &ServiceService{}
-
-		_, err := serviceservice.OAuth2Service("Linkedin", "login", "web")
+		err := serviceservice.SeedServices()
 
 		require.NoError(test, err)
-	})
-
-	test.Run("Dropbox", func(test *testing.T) {
-		serviceservice := &ServiceService{}
-
-		_, err := serviceservice.OAuth2Service("Dropbox", "login", "web")
+		mockServiceRepo.AssertNotCalled(test, "CreateService", mock.Anything)
+		mockActionRepo.AssertNumberOfCalls(test, "CreateAction", 1)
+		mockReactionRepo.AssertNumberOfCalls(test, "CreateReaction", 1)
 
-		require.NoError(test, err)
+		mockActionRepo.AssertCalled(test, "CreateAction", mock.MatchedBy(func(action entities.Action) bool {
+			return action.Name == "Every N minutes" && action.ServiceId == "serviceId" &&
+				action.NbParam == len(action.Parameters) && len(action.Variables) > 0
+		}))
+		mockActionRepo.AssertCalled(test, "UpdateAction", "actionId", mock.MatchedBy(func(action entities.Action) bool {
+			return action.Name == "Any new post in subreddit" && action.MinimumInterval == 300 && len(action.Variables) > 0
+		}))
+		mockReactionRepo.AssertCalled(test, "CreateReaction", mock.MatchedBy(func(reaction entities.Reaction) bool {
+			return reaction.Name == "HTTP request" && reaction.NbParam == len(reaction.Parameters)
+		}))
 	})
 
-	test.Run("Gitlab", func(test *testing.T) {
-		serviceservice := &ServiceService{}
-
-		_, err := serviceservice.OAuth2Service("Gitlab", "login", "web")
-
-		require.NoError(test, err)
-	})
+	test.Run("Fail Create Service", func(test *testing.T) {
+		serviceservice, mockServiceRepo, _, _ := newSeedService()
 
-	test.Run("Unknown", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+		mockServiceRepo.On("FindServiceByName", mock.Anything).
+			Return(entities.Service{}, errors.New("Service not found"))
+		mockServiceRepo.On("CreateService", mock.Anything).
+			Return(errors.New("Fail create service"))
 
-		_, err := serviceservice.OAuth2Service("Unknown", "login", "web")
+		err := serviceservice.SeedServices()
 
-		require.EqualError(test, err, unknownServiceMessage)
+		require.EqualError(test, err, "Fail create service")
 	})
-}
 
-func TestGenericAccessTokenRequest(test *testing.T) {
-	test.Run("Success", func(test *testing.T) {
-		_, err := genericAccessTokenRequest("url", "code", "callback")
+	test.Run("Fail Create Action", func(test *testing.T) {
+		serviceservice, mockServiceRepo, mockActionRepo, mockReactionRepo := newSeedService()
 
-		assert.NoError(test, err, "Error should be nil")
-	})
+		mockServiceRepo.On("FindServiceByName", mock.Anything).
+			Return(entities.Service{Id: "serviceId"}, nil)
+		mockReactionRepo.On("FindReactionByNameAndServiceId", mock.Anything, mock.Anything).
+			Return(entities.Reaction{}, nil)
+		mockActionRepo.On("FindActionByNameAndServiceId", mock.Anything, mock.Anything).
+			Return(entities.Action{}, errors.New("Action not found"))
+		mockActionRepo.On("CreateAction", mock.Anything).
+			Return(errors.New("Fail create action"))
 
-	test.Run("Failure", func(test *testing.T) {
-		_, err := genericAccessTokenRequest(":", "code", "callback")
+		err := serviceservice.SeedServices()
 
-		require.Error(test, err)
+		require.EqualError(test, err, "Fail create action")
 	})
 }
 
-func TestGetGoogleOAuth2AccessTokenWebRequestBody(test *testing.T) {
-	expectedRes := fmt.Sprintf(
-		"code=%s&client_id=%s&client_secret=%s&redirect_uri=%s&grant_type=%s",
-		"code",
-		os.Getenv("GOOGLE_WEB_CLIENT_ID"),
-		os.Getenv("GOOGLE_CLIENT_SECRET"),
-		"callback",
-		grantTypeAuthorization,
-	)
-	res := getGoogleOAuth2AccessTokenWebRequestBody("code", "callback")
-
-	assert.Equal(test, res, expectedRes)
-}
-
-func TestGetGoogleOAuth2AccessTokenMobileRequestBody(test *testing.T) {
-	expectedRes := fmt.Sprintf(
-		"code=%s&client_id=%s&redirect_uri=%s&grant_type=%s",
-		"code",
-		os.Getenv("GOOGLE_MOBILE_CLIENT_ID"),
-		"callback",
-		grantTypeAuthorization,
-	)
-	res := getGoogleOAuth2AccessTokenMobileRequestBody("code", "callback")
-
-	assert.Equal(test, res, expectedRes)
-}
-
-func TestGetGoogleOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service Web", func(test *testing.T) {
-		_, err := getGoogleOAuth2AccessTokenRequest("code", "service", "web")
+func TestOAuth2Service(test *testing.T) {
+	test.Run("Google", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.NoError(test, err)
-	})
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-	test.Run("Success Login Mobile", func(test *testing.T) {
-		_, err := getGoogleOAuth2AccessTokenRequest("code", "login", "mobile")
+		_, err := serviceservice.OAuth2Service("Google", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getGoogleOAuth2AccessTokenRequest("code", "invalid", "mobile")
-
-		require.EqualError(test, err, invalidCallbackTypeMessage)
-	})
-
-	test.Run("Invalid App Type", func(test *testing.T) {
-		_, err := getGoogleOAuth2AccessTokenRequest("code", "service", "invalid")
-
-		require.EqualError(test, err, "Invalid app type")
-	})
-}
-
-func TestGetGithubOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service", func(test *testing.T) {
-		_, err := getGithubOAuth2AccessTokenRequest("code", "service")
+	test.Run("Spotify", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.NoError(test, err)
-	})
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-	test.Run("Success Login", func(test *testing.T) {
-		_, err := getGithubOAuth2AccessTokenRequest("code", "login")
+		_, err := serviceservice.OAuth2Service("Spotify", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getGithubOAuth2AccessTokenRequest("code", "invalid")
-
-		require.EqualError(test, err, invalidCallbackTypeMessage)
-	})
-}
-
-func TestGetGitlabOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service", func(test *testing.T) {
-		_, err := getGitlabOAuth2AccessTokenRequest("code", "service")
+	test.Run("Discord", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.NoError(test, err)
-	})
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-	test.Run("Success Login", func(test *testing.T) {
-		_, err := getGitlabOAuth2AccessTokenRequest("code", "login")
+		_, err := serviceservice.OAuth2Service("Discord", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getGitlabOAuth2AccessTokenRequest("code", "invalid")
-
-		require.EqualError(test, err, invalidCallbackTypeMessage)
-	})
-}
-
-func TestGetAsanaOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service", func(test *testing.T) {
-		_, err := getAsanaOAuth2AccessTokenRequest("code", "service")
+	test.Run("Github", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.NoError(test, err)
-	})
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-	test.Run("Success Login", func(test *testing.T) {
-		_, err := getAsanaOAuth2AccessTokenRequest("code", "login")
+		_, err := serviceservice.OAuth2Service("Github", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getAsanaOAuth2AccessTokenRequest("code", "invalid")
-
-		require.EqualError(test, err, invalidCallbackTypeMessage)
-	})
-}
-
-func TestGetLinkedinOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service", func(test *testing.T) {
-		_, err := getLinkedinOAuth2AccessTokenRequest("code", "service")
+	test.Run("Reddit", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.NoError(test, err)
-	})
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-	test.Run("Success Login", func(test *testing.T) {
-		_, err := getLinkedinOAuth2AccessTokenRequest("code", "login")
+		_, err := serviceservice.OAuth2Service("Reddit", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getLinkedinOAuth2AccessTokenRequest("code", "invalid")
+	test.Run("Asana", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.EqualError(test, err, invalidCallbackTypeMessage)
-	})
-}
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-func TestGetDropboxOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service", func(test *testing.T) {
-		_, err := getDropboxOAuth2AccessTokenRequest("code", "service")
+		_, err := serviceservice.OAuth2Service("Asana", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Success Login", func(test *testing.T) {
-		_, err := getDropboxOAuth2AccessTokenRequest("code", "login")
-
-		require.NoError(test, err)
-	})
-
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getDropboxOAuth2AccessTokenRequest("code", "invalid")
-
-		require.EqualError(test, err, invalidCallbackTypeMessage)
-	})
-}
-
-func TestGetSpotifyOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service", func(test *testing.T) {
-		_, err := getSpotifyOAuth2AccessTokenRequest("code", "service")
+	test.Run("Linkedin", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.NoError(test, err)
-	})
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-	test.Run("Success Login", func(test *testing.T) {
-		_, err := getSpotifyOAuth2AccessTokenRequest("code", "login")
+		_, err := serviceservice.OAuth2Service("Linkedin", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getSpotifyOAuth2AccessTokenRequest("code", "invalid")
-
-		require.EqualError(test, err, invalidCallbackTypeMessage)
-	})
-}
-
-func TestGetDiscordOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service", func(test *testing.T) {
-		_, err := getDiscordOAuth2AccessTokenRequest("code", "service")
+	test.Run("Dropbox", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.NoError(test, err)
-	})
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-	test.Run("Success Login", func(test *testing.T) {
-		_, err := getDiscordOAuth2AccessTokenRequest("code", "login")
+		_, err := serviceservice.OAuth2Service("Dropbox", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getDiscordOAuth2AccessTokenRequest("code", "invalid")
+	test.Run("Gitlab", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.EqualError(test, err, invalidCallbackTypeMessage)
-	})
-}
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-func TestGetRedditOAuth2AccessTokenRequest(test *testing.T) {
-	test.Run("Success Service", func(test *testing.T) {
-		_, err := getRedditOAuth2AccessTokenRequest("code", "service")
+		_, err := serviceservice.OAuth2Service("Gitlab", "login", "web", "session")
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Success Login", func(test *testing.T) {
-		_, err := getRedditOAuth2AccessTokenRequest("code", "login")
+	test.Run("Unknown", func(test *testing.T) {
+		mockOAuthStateRepo := new(MockOAuthStateRepository)
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry(), OAuthStateRepository: mockOAuthStateRepo}
 
-		require.NoError(test, err)
-	})
+		mockOAuthStateRepo.On("CreateOAuthState", mock.Anything, mock.Anything).
+			Return("stateId", nil)
 
-	test.Run("Invalid Callback Type", func(test *testing.T) {
-		_, err := getRedditOAuth2AccessTokenRequest("code", "invalid")
+		_, err := serviceservice.OAuth2Service("Unknown", "login", "web", "session")
 
-		require.EqualError(test, err, invalidCallbackTypeMessage)
+		require.EqualError(test, err, unknownServiceMessage)
 	})
 }
 
-func TestGenericRefreshTokenRequest(test *testing.T) {
+func TestExecuteRequest(test *testing.T) {
 	test.Run("Success", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
+
+		req, _ := http.NewRequest("GET", "https://tools.aimylogic.com/api/now?tz=Europe/Paris", nil)
 
-		_, err := serviceservice.genericRefreshTokenRequest("tokenurl", "refreshToken")
+		_, err := serviceservice.ExecuteRequest(req)
 
 		require.NoError(test, err)
 	})
 
 	test.Run("Failure", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
-		_, err := serviceservice.genericRefreshTokenRequest(":", "refreshToken")
+		req, _ := http.NewRequest("GET", "https://tools.aimylogic.", nil)
+
+		_, err := serviceservice.ExecuteRequest(req)
 
 		require.Error(test, err)
 	})
 }
 
-func TestGenericJsonBodyRefreshToken(test *testing.T) {
-	serviceservice := &ServiceService{}
+func TestExecuteRequestRetryAfter(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Retry-After", "120")
+		writer.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
-	expectedRes := fmt.Sprintf(
-		"client_id=%s&client_secret=%s&grant_type=%s&refresh_token=%s",
-		"clientId",
-		"secretId",
-		grantTypeRefreshToken,
-		"refreshToken",
-	)
+	req, _ := http.NewRequest("GET", server.URL, nil)
 
-	res := serviceservice.genericJsonBodyRefreshToken("clientId", "secretId", "refreshToken")
+	_, err := serviceservice.ExecuteRequest(req)
 
-	assert.Equal(test, res, expectedRes)
+	require.Equal(test, entities.ApiCallError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Minute}, err)
 }
 
-func TestGetGoogleRefreshTokenRequest(test *testing.T) {
-	serviceservice := &ServiceService{}
-
-	_, err := serviceservice.GetGoogleRefreshTokenRequest("refreshToken")
-
-	require.NoError(test, err)
-}
+func TestExecuteRequestNoContent(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
-func TestGetAsanaRefreshTokenRequest(test *testing.T) {
-	serviceservice := &ServiceService{}
+	req, _ := http.NewRequest("DELETE", server.URL, nil)
 
-	_, err := serviceservice.GetAsanaRefreshTokenRequest("refreshToken")
+	res, err := serviceservice.ExecuteRequest(req)
 
 	require.NoError(test, err)
+	require.Equal(test, http.StatusNoContent, res.StatusCode)
+	res.Body.Close()
 }
 
-func TestGetDropboxRefreshTokenRequest(test *testing.T) {
-	serviceservice := &ServiceService{}
+func TestExecuteRequestErrorBody(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(`{"error": "invalid_grant"}`))
+	}))
+	defer server.Close()
+	serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
-	_, err := serviceservice.GetDropboxRefreshTokenRequest("refreshToken")
+	req, _ := http.NewRequest("POST", server.URL, nil)
 
-	require.NoError(test, err)
-}
-
-func TestGetSpotifyRefreshTokenRequest(test *testing.T) {
-	serviceservice := &ServiceService{}
-
-	_, err := serviceservice.GetSpotifyRefreshTokenRequest("refreshToken")
+	_, err := serviceservice.ExecuteRequest(req)
 
-	require.NoError(test, err)
+	require.Equal(test, entities.ApiCallError{StatusCode: http.StatusBadRequest, Body: `{"error": "invalid_grant"}`}, err)
 }
 
-func TestGetDiscordRefreshTokenRequest(test *testing.T) {
-	serviceservice := &ServiceService{}
-
-	_, err := serviceservice.GetDiscordRefreshTokenRequest("refreshToken")
-
-	require.NoError(test, err)
-}
-
-func TestGetRedditRefreshTokenRequest(test *testing.T) {
-	serviceservice := &ServiceService{}
-
-	_, err := serviceservice.GetRedditRefreshTokenRequest("refreshToken")
-
-	require.NoError(test, err)
-}
-
-func TestGetGitlabRefreshTokenRequest(test *testing.T) {
-	serviceservice := &ServiceService{}
-
-	_, err := serviceservice.GetGitlabRefreshTokenRequest("refreshToken")
-
-	require.NoError(test, err)
-}
-
-func TestExecuteRequest(test *testing.T) {
-	test.Run("Success", func(test *testing.T) {
-		serviceservice := &ServiceService{}
-
-		req, _ := http.NewRequest("GET", "https://tools.aimylogic.com/api/now?tz=Europe/Paris", nil)
-
-		_, err := serviceservice.ExecuteRequest(req)
-
-		require.NoError(test, err)
-	})
-
-	test.Run("Failure", func(test *testing.T) {
-		serviceservice := &ServiceService{}
-
-		req, _ := http.NewRequest("GET", "https://tools.aimylogic.", nil)
+func TestParseRetryAfter(test *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
 
-		_, err := serviceservice.ExecuteRequest(req)
-
-		require.Error(test, err)
-	})
+	require.Equal(test, 30*time.Second, parseRetryAfter("30", now))
+	require.Equal(test, 90*time.Second, parseRetryAfter("Fri, 01 Mar 2024 09:01:30 GMT", now))
+	require.Zero(test, parseRetryAfter("Fri, 01 Mar 2024 08:00:00 GMT", now))
+	require.Zero(test, parseRetryAfter("", now))
+	require.Zero(test, parseRetryAfter("soon", now))
 }
 
 func TestExecuteApiRequest(test *testing.T) {
 	test.Run("Success", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
 		_, err := serviceservice.ExecuteApiRequest("https://tools.aimylogic.com/api/now?tz=Europe/Paris", "GET", "token", "accessToken", nil)
 
@@ -1023,7 +768,7 @@ func TestExecuteApiRequest(test *testing.T) {
 	})
 
 	test.Run("Failure", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
 		_, err := serviceservice.ExecuteApiRequest(":", "GET", "token", "accessToken", nil)
 
@@ -1032,47 +777,16 @@ func TestExecuteApiRequest(test *testing.T) {
 }
 
 func TestGetResultTokenFromCode(test *testing.T) {
-	serviceservice := &ServiceService{}
+	serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
-	_, err := serviceservice.GetResultTokenFromCode("code", "Unknown", "service", "web")
+	_, err := serviceservice.GetResultTokenFromCode("code", "Unknown", "service", "web", "")
 
 	require.EqualError(test, err, unknownServiceMessage)
 }
 
-func TestGetOAuth2UserEmailRequest(test *testing.T) {
-	test.Run("Success", func(test *testing.T) {
-		_, err := getOAuth2UserEmailRequest("https://tools.aimylogic.com/api/now?tz=Europe/Paris", "accessToken")
-
-		require.NoError(test, err)
-	})
-
-	test.Run("Failure", func(test *testing.T) {
-		_, err := getOAuth2UserEmailRequest(":", "accessToken")
-
-		require.Error(test, err)
-	})
-}
-
-func TestDecodeUserInfoWithMultipleResults(test *testing.T) {
-	userInfos := []entities.UserInfo{
-		{Email: "test@test.com"},
-	}
-
-	responseBody, _ := json.Marshal(userInfos)
-
-	res := &http.Response{
-		Body:       io.NopCloser(bytes.NewReader(responseBody)),
-		StatusCode: http.StatusOK,
-	}
-
-	_, err := decodeUserInfoWithMultipleResults(res)
-
-	require.NoError(test, err)
-}
-
 func TestGetUserInfoFromService(test *testing.T) {
 	test.Run("Unknown", func(test *testing.T) {
-		serviceservice := &ServiceService{}
+		serviceservice := &ServiceService{Connectors: connector.NewRegistry()}
 
 		_, err := serviceservice.GetUserInfoFromService("accessToken", "invalid")
 
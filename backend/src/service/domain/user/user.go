@@ -93,13 +93,18 @@ func (self *UserService) LoginAuthentication(userEmail, userPassword, userConnec
 	return tokenString, nil
 }
 
-func (self *UserService) LoginWithService(code, serviceName, appType string) (string, error) {
+func (self *UserService) LoginWithService(code, state, session, serviceName, appType string) (string, error) {
 	_, errorService := self.ServiceRepository.FindServiceByName(serviceName)
 	if errorService != nil {
 		return "", errorService
 	}
 
-	resultToken, errToken := self.ServiceService.GetResultTokenFromCode(code, serviceName, "login", appType)
+	codeVerifier, errState := self.ServiceService.ConsumeOAuthState(state, session, serviceName, "login", appType)
+	if errState != nil {
+		return "", errState
+	}
+
+	resultToken, errToken := self.ServiceService.GetResultTokenFromCode(code, serviceName, "login", appType, codeVerifier)
 	if errToken != nil {
 		return "", errToken
 	}
@@ -160,6 +165,31 @@ func (self *UserService) ModifyPassword(userEmail, userConnectionType string, ne
 	return nil
 }
 
+func checkTimezone(timezone string) error {
+	if timezone == "" || timezone == "Local" {
+		return fmt.Errorf("Invalid timezone")
+	}
+
+	_, err := time.LoadLocation(timezone)
+	if err != nil {
+		return fmt.Errorf("Invalid timezone")
+	}
+	return nil
+}
+
+func (self *UserService) ModifyTimezone(userEmail, userConnectionType, timezone string) error {
+	err := checkTimezone(timezone)
+	if err != nil {
+		return err
+	}
+
+	err = self.UserRepository.UpdateUserTimezone(userEmail, userConnectionType, timezone)
+	if err != nil {
+		return fmt.Errorf("Could not modify the timezone")
+	}
+	return nil
+}
+
 func (self *UserService) DeleteAccount(userEmail, userConnectionType string) error {
 	user, err := self.UserRepository.FindUserByEmail(userEmail, userConnectionType)
 	if err != nil {
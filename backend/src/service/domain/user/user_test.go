@@ -1,15 +1,18 @@
 package user_service
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"backend/src/entities"
+	"backend/src/service"
 )
 
 type MockUserRepository struct {
@@ -36,6 +39,11 @@ func (m *MockUserRepository) UpdateUser(email, password, connectionType string)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateUserTimezone(email, connectionType, timezone string) error {
+	args := m.Called(email, connectionType, timezone)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) DeleteUser(email, connectionType string) error {
 	args := m.Called(email, connectionType)
 	return args.Error(0)
@@ -45,8 +53,8 @@ type MockServiceRepository struct {
 	mock.Mock
 }
 
-func (m *MockServiceRepository) CreateService(name, color, logo string) error {
-	args := m.Called(name, color, logo)
+func (m *MockServiceRepository) CreateService(service entities.Service) error {
+	args := m.Called(service)
 	return args.Error(0)
 }
 
@@ -124,39 +132,20 @@ func (m *MockServiceServiceRepository) RetrieveReactionsFromService(serviceName
 	return args.Get(0).([]entities.Reaction), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetGoogleRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) GetSpotifyRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) GetDiscordRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) GetRedditRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) GetAsanaRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
+func (m *MockServiceServiceRepository) FindConnector(serviceName string) (service.Connector, error) {
+	args := m.Called(serviceName)
+	connector, _ := args.Get(0).(service.Connector)
+	return connector, args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetDropboxRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
+func (m *MockServiceServiceRepository) RetrieveConnectors() []service.Connector {
+	args := m.Called()
+	return args.Get(0).([]service.Connector)
 }
 
-func (m *MockServiceServiceRepository) GetGitlabRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
+func (m *MockServiceServiceRepository) SeedServices() error {
+	args := m.Called()
+	return args.Error(0)
 }
 
 func (m *MockServiceServiceRepository) ExecuteRequest(request *http.Request) (*http.Response, error) {
@@ -164,13 +153,23 @@ func (m *MockServiceServiceRepository) ExecuteRequest(request *http.Request) (*h
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
+func (m *MockServiceServiceRepository) ExecuteRequestWithClient(client *http.Client, request *http.Request) (*http.Response, error) {
+	args := m.Called(client, request)
+	return args.Get(0).(*http.Response), args.Error(1)
+}
+
 func (m *MockServiceServiceRepository) ExecuteApiRequest(url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
 	args := m.Called(url, method, typeToken, accessToken, body)
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetResultTokenFromCode(code, serviceName, callbackType, appType string) (entities.ResultToken, error) {
-	args := m.Called(code, serviceName, callbackType, appType)
+// The context is not matched, the expectations are registered on ExecuteApiRequest
+func (m *MockServiceServiceRepository) ExecuteApiRequestWithContext(ctx context.Context, url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
+	return m.ExecuteApiRequest(url, method, typeToken, accessToken, body)
+}
+
+func (m *MockServiceServiceRepository) GetResultTokenFromCode(code, serviceName, callbackType, appType, codeVerifier string) (entities.ResultToken, error) {
+	args := m.Called(code, serviceName, callbackType, appType, codeVerifier)
 	return args.Get(0).(entities.ResultToken), args.Error(1)
 }
 
@@ -179,17 +178,17 @@ func (m *MockServiceServiceRepository) GetUserInfoFromService(accessToken, servi
 	return args.Get(0).(entities.UserInfo), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) RequestToTimeApi() (entities.TimeResponse, error) {
-	args := m.Called()
-	return args.Get(0).(entities.TimeResponse), args.Error(1)
+func (m *MockServiceServiceRepository) OAuth2Service(serviceName, callbackType, appType, session string) (string, error) {
+	args := m.Called(serviceName, callbackType, appType, session)
+	return args.String(0), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) OAuth2Service(serviceName, callbackType, appType string) (string, error) {
-	args := m.Called(serviceName, callbackType, appType)
+func (m *MockServiceServiceRepository) ConsumeOAuthState(state, session, serviceName, callbackType, appType string) (string, error) {
+	args := m.Called(state, session, serviceName, callbackType, appType)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) RequestGithubUserRepositories(accessToken string) ([]entities.GithubRepository, error) {
+func (m *MockServiceServiceRepository) RequestGithubUserRepositories(ctx context.Context, accessToken string) ([]entities.GithubRepository, error) {
 	args := m.Called(accessToken)
 	return args.Get(0).([]entities.GithubRepository), args.Error(1)
 }
@@ -208,9 +207,9 @@ type MockWorkflowRepository struct {
 	mock.Mock
 }
 
-func (m *MockWorkflowRepository) CreateWorkflow(name, ownerId, actionId, reactionId string, actionParam, reactionParam, actionData map[string]interface{}) error {
-	args := m.Called(name, ownerId, actionId, reactionId, actionParam, reactionParam, actionData)
-	return args.Error(0)
+func (m *MockWorkflowRepository) CreateWorkflow(name, ownerId, actionId, reactionId, filter, catchUpPolicy string, pollInterval int, actionParam, reactionParam, actionData map[string]interface{}) (string, error) {
+	args := m.Called(name, ownerId, actionId, reactionId, filter, catchUpPolicy, pollInterval, actionParam, reactionParam, actionData)
+	return args.String(0), args.Error(1)
 }
 
 func (m *MockWorkflowRepository) FindWorkflowById(id string) (entities.Workflow, error) {
@@ -218,11 +217,21 @@ func (m *MockWorkflowRepository) FindWorkflowById(id string) (entities.Workflow,
 	return args.Get(0).(entities.Workflow), args.Error(1)
 }
 
+func (m *MockWorkflowRepository) FindWorkflowByWebhookToken(token string) (entities.Workflow, error) {
+	args := m.Called(token)
+	return args.Get(0).(entities.Workflow), args.Error(1)
+}
+
 func (m *MockWorkflowRepository) FindWorkflowsByActionId(actionId string) ([]entities.Workflow, error) {
 	args := m.Called(actionId)
 	return args.Get(0).([]entities.Workflow), args.Error(1)
 }
 
+func (m *MockWorkflowRepository) FindDueWorkflowsByActionId(actionId string, now time.Time) ([]entities.Workflow, error) {
+	args := m.Called(actionId, now)
+	return args.Get(0).([]entities.Workflow), args.Error(1)
+}
+
 func (m *MockWorkflowRepository) FindWorkflowsByOwnerId(ownerId string) ([]entities.Workflow, error) {
 	args := m.Called(ownerId)
 	return args.Get(0).([]entities.Workflow), args.Error(1)
@@ -233,6 +242,46 @@ func (m *MockWorkflowRepository) UpdateWorkflow(id string, updatedWorkflow entit
 	return args.Error(0)
 }
 
+func (m *MockWorkflowRepository) UpdateWorkflowActionData(id string, actionData map[string]interface{}) error {
+	args := m.Called(id, actionData)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) IncrementWorkflowFailures(id string) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) ResetWorkflowFailures(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) IncrementWorkflowPollFailures(id string) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) ResetWorkflowPollFailures(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) ClaimWorkflowCheck(id string, now, nextCheckAt time.Time) (bool, error) {
+	args := m.Called(id, now, nextCheckAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) SuspendWorkflow(id, reason string) (bool, error) {
+	args := m.Called(id, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) SuspendWorkflowsByOwnerIdAndServiceId(ownerId, serviceId, reason string) (int, error) {
+	args := m.Called(ownerId, serviceId, reason)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockWorkflowRepository) DeleteWorkflow(id, ownerId string) error {
 	args := m.Called(id, ownerId)
 	return args.Error(0)
@@ -262,11 +311,51 @@ func (m *MockUserServiceRepository) UpdateUserServiceByServiceIdAndUserId(userId
 	return args.Error(0)
 }
 
+func (m *MockUserServiceRepository) FindUserServicesExpiringBefore(deadline time.Time) ([]entities.UserService, error) {
+	args := m.Called(deadline)
+	return args.Get(0).([]entities.UserService), args.Error(1)
+}
+
+func (m *MockUserServiceRepository) FindUserServicesByUserId(userId string) ([]entities.UserService, error) {
+	args := m.Called(userId)
+	return args.Get(0).([]entities.UserService), args.Error(1)
+}
+
+func (m *MockUserServiceRepository) UpdateUserServiceScopes(userId, serviceId, scopes string) error {
+	args := m.Called(userId, serviceId, scopes)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) RecordUserServiceSuccess(userId, serviceId string, at time.Time) error {
+	args := m.Called(userId, serviceId, at)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) RecordUserServiceError(userId, serviceId, lastError string, at time.Time) error {
+	args := m.Called(userId, serviceId, lastError, at)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) MarkUserServiceNeedsReauth(userId, serviceId string) error {
+	args := m.Called(userId, serviceId)
+	return args.Error(0)
+}
+
 func (m *MockUserServiceRepository) DeleteUserServiceByUserId(userId string) error {
 	args := m.Called(userId)
 	return args.Error(0)
 }
 
+func (m *MockUserServiceRepository) DeleteUserServiceByServiceIdAndUserId(userId, serviceId string) error {
+	args := m.Called(userId, serviceId)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) RotateUserServiceKeys() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
 func TestCreateUser(test *testing.T) {
 	test.Run("Successful No Basic", func(test *testing.T) {
 		mockUserRepo := new(MockUserRepository)
@@ -442,7 +531,10 @@ func TestLoginWithService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "service").
 			Return(entities.Service{}, nil)
 
-		mockServiceServiceRepo.On("GetResultTokenFromCode", "code", "service", "login", "web").
+		mockServiceServiceRepo.On("ConsumeOAuthState", "state", "session", "service", "login", "web").
+			Return("verifier", nil)
+
+		mockServiceServiceRepo.On("GetResultTokenFromCode", "code", "service", "login", "web", "verifier").
 			Return(resultToken, nil)
 
 		mockServiceServiceRepo.On("GetUserInfoFromService", "accessToken", "service").
@@ -460,7 +552,7 @@ func TestLoginWithService(test *testing.T) {
 		mockUserRepo.On("FindUserByEmail", "test@test.com", "service").
 			Return(user, nil)
 
-		_, err := userService.LoginWithService("code", "service", "web")
+		_, err := userService.LoginWithService("code", "state", "session", "service", "web")
 
 		require.NoError(test, err)
 	})
@@ -475,11 +567,32 @@ func TestLoginWithService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "service").
 			Return(entities.Service{}, errors.New("Fail Find Service"))
 
-		_, err := userService.LoginWithService("code", "service", "web")
+		_, err := userService.LoginWithService("code", "state", "session", "service", "web")
 
 		require.EqualError(test, err, "Fail Find Service")
 	})
 
+	test.Run("Fail Consume OAuth State", func(test *testing.T) {
+		mockServiceRepo := new(MockServiceRepository)
+		mockServiceServiceRepo := new(MockServiceServiceRepository)
+
+		userService := &UserService{
+			ServiceRepository: mockServiceRepo,
+			ServiceService:    mockServiceServiceRepo,
+		}
+
+		mockServiceRepo.On("FindServiceByName", "service").
+			Return(entities.Service{}, nil)
+
+		mockServiceServiceRepo.On("ConsumeOAuthState", "state", "session", "service", "login", "web").
+			Return("", errors.New("OAuth state expired or already used"))
+
+		_, err := userService.LoginWithService("code", "state", "session", "service", "web")
+
+		require.EqualError(test, err, "OAuth state expired or already used")
+		mockServiceServiceRepo.AssertNotCalled(test, "GetResultTokenFromCode", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	test.Run("Fail Get Token From Code", func(test *testing.T) {
 		var resultToken entities.ResultToken
 
@@ -496,10 +609,13 @@ func TestLoginWithService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "service").
 			Return(entities.Service{}, nil)
 
-		mockServiceServiceRepo.On("GetResultTokenFromCode", "code", "service", "login", "web").
+		mockServiceServiceRepo.On("ConsumeOAuthState", "state", "session", "service", "login", "web").
+			Return("verifier", nil)
+
+		mockServiceServiceRepo.On("GetResultTokenFromCode", "code", "service", "login", "web", "verifier").
 			Return(resultToken, errors.New("Fail get token from code"))
 
-		_, err := userService.LoginWithService("code", "service", "web")
+		_, err := userService.LoginWithService("code", "state", "session", "service", "web")
 
 		require.EqualError(test, err, "Fail get token from code")
 	})
@@ -523,13 +639,16 @@ func TestLoginWithService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "service").
 			Return(entities.Service{}, nil)
 
-		mockServiceServiceRepo.On("GetResultTokenFromCode", "code", "service", "login", "web").
+		mockServiceServiceRepo.On("ConsumeOAuthState", "state", "session", "service", "login", "web").
+			Return("verifier", nil)
+
+		mockServiceServiceRepo.On("GetResultTokenFromCode", "code", "service", "login", "web", "verifier").
 			Return(resultToken, nil)
 
 		mockServiceServiceRepo.On("GetUserInfoFromService", "accessToken", "service").
 			Return(userInfo, errors.New("Fail get user info"))
 
-		_, err := userService.LoginWithService("code", "service", "web")
+		_, err := userService.LoginWithService("code", "state", "session", "service", "web")
 
 		require.EqualError(test, err, "Fail get user info")
 	})
@@ -557,7 +676,10 @@ func TestLoginWithService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "service").
 			Return(entities.Service{}, nil)
 
-		mockServiceServiceRepo.On("GetResultTokenFromCode", "code", "service", "login", "web").
+		mockServiceServiceRepo.On("ConsumeOAuthState", "state", "session", "service", "login", "web").
+			Return("verifier", nil)
+
+		mockServiceServiceRepo.On("GetResultTokenFromCode", "code", "service", "login", "web", "verifier").
 			Return(resultToken, nil)
 
 		mockServiceServiceRepo.On("GetUserInfoFromService", "accessToken", "service").
@@ -572,7 +694,7 @@ func TestLoginWithService(test *testing.T) {
 		mockUserRepo.On("CreateUser", "test@test.com", "", "service").
 			Return(errors.New("Fail create user"))
 
-		_, err := userService.LoginWithService("code", "service", "web")
+		_, err := userService.LoginWithService("code", "state", "session", "service", "web")
 
 		require.EqualError(test, err, "Email address already used")
 	})
@@ -669,6 +791,53 @@ func TestModifyPassword(test *testing.T) {
 	})
 }
 
+func TestModifyTimezone(test *testing.T) {
+	test.Run("Success", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+
+		userService := &UserService{
+			UserRepository: mockUserRepo,
+		}
+
+		mockUserRepo.On("UpdateUserTimezone", "test@test.com", "basic", "America/New_York").
+			Return(nil)
+
+		err := userService.ModifyTimezone("test@test.com", "basic", "America/New_York")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid timezone", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+
+		userService := &UserService{
+			UserRepository: mockUserRepo,
+		}
+
+		for _, timezone := range []string{"", "Local", "Europe/Atlantis", "../etc/passwd"} {
+			err := userService.ModifyTimezone("test@test.com", "basic", timezone)
+
+			require.EqualError(test, err, "Invalid timezone", timezone)
+		}
+		mockUserRepo.AssertNotCalled(test, "UpdateUserTimezone", "test@test.com", "basic", mock.Anything)
+	})
+
+	test.Run("Fail update timezone", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+
+		userService := &UserService{
+			UserRepository: mockUserRepo,
+		}
+
+		mockUserRepo.On("UpdateUserTimezone", "test@test.com", "basic", "Asia/Tokyo").
+			Return(errors.New("User doesn't exist"))
+
+		err := userService.ModifyTimezone("test@test.com", "basic", "Asia/Tokyo")
+
+		require.EqualError(test, err, "Could not modify the timezone")
+	})
+}
+
 func TestDeleteAccount(test *testing.T) {
 	test.Run("Successful", func(test *testing.T) {
 		var foundUser entities.User
@@ -0,0 +1,73 @@
+package userservice_service
+
+import (
+	"fmt"
+
+	"backend/src/entities"
+)
+
+const serviceNotConnectedMessage = "Service not connected"
+const revocationNotSupportedMessage = "Operation not supported by this service"
+
+func disconnectedServiceReason(serviceName string) string {
+	return fmt.Sprintf("Suspended because %s was disconnected, connect it again then activate the workflow", serviceName)
+}
+
+// Revokes the token at the service when it allows it, then forgets the connection
+// The revocation is best effort, a token the service already expired or revoked must not keep the service linked,
+// its failure is reported with the number of workflows deactivated because they rely on the service
+func (self *UserServiceService) DisconnectService(email, connectionType, serviceName string) (entities.ServiceDisconnection, error) {
+	var disconnection entities.ServiceDisconnection
+
+	foundUser, errGetUser := self.GetUser(email, connectionType)
+	if errGetUser != nil {
+		return disconnection, errGetUser
+	}
+
+	foundService, errService := self.ServiceRepository.FindServiceByName(serviceName)
+	if errService != nil {
+		return disconnection, fmt.Errorf("Unknown service")
+	}
+
+	foundUserService, errUserService := self.UserServiceRepository.FindUserServiceByServiceIdandUserId(foundUser.Id, foundService.Id)
+	if errUserService != nil {
+		return disconnection, fmt.Errorf(serviceNotConnectedMessage)
+	}
+
+	errRevoke := self.revokeToken(serviceName, foundUserService.AccessToken, foundUserService.RefreshToken)
+	disconnection.TokenRevoked = errRevoke == nil
+	if errRevoke != nil && errRevoke.Error() != revocationNotSupportedMessage {
+		disconnection.RevocationError = describeCallError(errRevoke)
+	}
+
+	errDelete := self.UserServiceRepository.DeleteUserServiceByServiceIdAndUserId(foundUser.Id, foundService.Id)
+	if errDelete != nil {
+		return disconnection, errDelete
+	}
+
+	deactivatedWorkflows, err := self.WorkflowRepository.SuspendWorkflowsByOwnerIdAndServiceId(foundUser.Id, foundService.Id, disconnectedServiceReason(serviceName))
+	if err != nil {
+		return disconnection, err
+	}
+	disconnection.DeactivatedWorkflows = deactivatedWorkflows
+	return disconnection, nil
+}
+
+func (self *UserServiceService) revokeToken(serviceName, accessToken, refreshToken string) error {
+	connector, err := self.ServiceService.FindConnector(serviceName)
+	if err != nil {
+		return err
+	}
+
+	request, err := connector.RevokeTokenRequest(accessToken, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	res, err := self.ServiceService.ExecuteRequest(request)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
@@ -0,0 +1,124 @@
+package userservice_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/src/entities"
+)
+
+const tokenRefreshFailedMessage = "Token refresh failed: "
+
+const callErrorMessageLimit = 200
+
+// Describes a failed call for its user, with the HTTP status and the start of the message answered by the service when there is one
+func describeCallError(err error) string {
+	var apiCallError entities.ApiCallError
+	if !errors.As(err, &apiCallError) {
+		return err.Error()
+	}
+
+	description := fmt.Sprintf("%s (HTTP %d)", err.Error(), apiCallError.StatusCode)
+	message := []rune(strings.Join(strings.Fields(apiCallError.Body), " "))
+	if len(message) > callErrorMessageLimit {
+		message = append(message[:callErrorMessageLimit], []rune("...")...)
+	}
+	if len(message) > 0 {
+		description += ": " + string(message)
+	}
+	return description
+}
+
+// The services separate the granted scopes with spaces or commas
+func splitScopes(scopes string) []string {
+	return strings.FieldsFunc(scopes, func(char rune) bool {
+		return char == ' ' || char == ','
+	})
+}
+
+// Records the outcome of a call made with the token of the user to the service, a nil error is a success
+// Nothing is recorded for a service without authorization
+func (self *UserServiceService) RecordServiceCall(userId, serviceName string, callErr error) error {
+	foundService, err := self.ServiceRepository.FindServiceByName(serviceName)
+	if err != nil {
+		return err
+	}
+	if !foundService.IsAuthNeeded {
+		return nil
+	}
+
+	if callErr == nil {
+		return self.UserServiceRepository.RecordUserServiceSuccess(userId, foundService.Id, time.Now())
+	}
+	return self.UserServiceRepository.RecordUserServiceError(userId, foundService.Id, describeCallError(callErr), time.Now())
+}
+
+func (self *UserServiceService) recordRefreshError(userId, serviceName string, err error) {
+	self.RecordServiceCall(userId, serviceName, errors.New(tokenRefreshFailedMessage+describeCallError(err)))
+}
+
+// Health of the connections of the user to each service requiring an authorization
+func (self *UserServiceService) RetrieveServiceConnections(email, connectionType string) ([]entities.ServiceConnection, error) {
+	foundUser, errGetUser := self.GetUser(email, connectionType)
+	if errGetUser != nil {
+		return nil, errGetUser
+	}
+
+	services, err := self.ServiceRepository.FindAllServices()
+	if err != nil {
+		return nil, err
+	}
+
+	userServices, err := self.UserServiceRepository.FindUserServicesByUserId(foundUser.Id)
+	if err != nil {
+		return nil, err
+	}
+	userServicesByServiceId := map[string]entities.UserService{}
+	for _, userService := range userServices {
+		userServicesByServiceId[userService.ServiceId] = userService
+	}
+
+	connections := []entities.ServiceConnection{}
+	for _, service := range services {
+		if !service.IsAuthNeeded {
+			continue
+		}
+
+		userService, linked := userServicesByServiceId[service.Id]
+		if !linked {
+			connections = append(connections, entities.ServiceConnection{Service: service.Name, Scopes: []string{}})
+			continue
+		}
+		connections = append(connections, self.describeConnection(service.Name, userService))
+	}
+	return connections, nil
+}
+
+// A connection must be authorized again once its refresh token was refused,
+// or once its token expired without a way to refresh it
+func (self *UserServiceService) describeConnection(serviceName string, userService entities.UserService) entities.ServiceConnection {
+	connection := entities.ServiceConnection{
+		Service:                 serviceName,
+		Linked:                  true,
+		Scopes:                  splitScopes(userService.Scopes),
+		LastError:               userService.LastError,
+		ReauthorizationRequired: userService.NeedsReauth,
+	}
+
+	expiryDate, err := time.Parse(formattingDate, userService.ExpiryDate)
+	if err == nil {
+		connection.ExpiresAt = &expiryDate
+		if expiryDate.Before(time.Now()) && (userService.RefreshToken == "" || !self.canRefreshToken(serviceName)) {
+			connection.ReauthorizationRequired = true
+		}
+	}
+	if !userService.LastSuccessAt.IsZero() {
+		connection.LastSuccessAt = &userService.LastSuccessAt
+	}
+	if !userService.LastErrorAt.IsZero() {
+		connection.LastErrorAt = &userService.LastErrorAt
+	}
+	return connection
+}
@@ -0,0 +1,180 @@
+package userservice_service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func TestDescribeCallError(test *testing.T) {
+	require.Equal(test, "API call failed (HTTP 401)", describeCallError(entities.ApiCallError{StatusCode: 401}))
+	require.Equal(test, `API call failed (HTTP 400): {"error": "invalid_grant"}`,
+		describeCallError(entities.ApiCallError{StatusCode: 400, Body: "{\"error\":\n \"invalid_grant\"}"}))
+	require.Equal(test, "API call failed (HTTP 500): "+strings.Repeat("é", callErrorMessageLimit)+"...",
+		describeCallError(entities.ApiCallError{StatusCode: 500, Body: strings.Repeat("é", callErrorMessageLimit+50)}))
+	require.Equal(test, "Missing field", describeCallError(errors.New("Missing field")))
+}
+
+func TestSplitScopes(test *testing.T) {
+	require.Equal(test, []string{"openid", "email"}, splitScopes("openid email"))
+	require.Equal(test, []string{"repo", "user"}, splitScopes("repo,user"))
+	require.Empty(test, splitScopes(""))
+}
+
+func TestRecordServiceCall(test *testing.T) {
+	newRecordService := func() (*UserServiceService, *MockUserServiceRepository) {
+		mockServiceRepo := new(MockServiceRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
+
+		mockServiceRepo.On("FindServiceByName", "Discord").
+			Return(entities.Service{Id: "discord", Name: "Discord", IsAuthNeeded: true}, nil)
+		mockServiceRepo.On("FindServiceByName", "SMS").
+			Return(entities.Service{Id: "sms", Name: "SMS"}, nil)
+		mockServiceRepo.On("FindServiceByName", "Unknown").
+			Return(entities.Service{}, errors.New("Unknown service"))
+
+		return &UserServiceService{
+			ServiceRepository:     mockServiceRepo,
+			UserServiceRepository: mockUserServiceRepo,
+		}, mockUserServiceRepo
+	}
+
+	test.Run("Success", func(test *testing.T) {
+		userServiceService, mockUserServiceRepo := newRecordService()
+
+		mockUserServiceRepo.On("RecordUserServiceSuccess", "1", "discord", mock.Anything).
+			Return(nil)
+
+		err := userServiceService.RecordServiceCall("1", "Discord", nil)
+
+		require.NoError(test, err)
+		mockUserServiceRepo.AssertNotCalled(test, "RecordUserServiceError", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Error", func(test *testing.T) {
+		userServiceService, mockUserServiceRepo := newRecordService()
+
+		mockUserServiceRepo.On("RecordUserServiceError", "1", "discord", "API call failed (HTTP 403)", mock.Anything).
+			Return(nil)
+
+		err := userServiceService.RecordServiceCall("1", "Discord", entities.ApiCallError{StatusCode: 403})
+
+		require.NoError(test, err)
+		mockUserServiceRepo.AssertCalled(test, "RecordUserServiceError", "1", "discord", "API call failed (HTTP 403)", mock.Anything)
+	})
+
+	test.Run("Service without authorization", func(test *testing.T) {
+		userServiceService, mockUserServiceRepo := newRecordService()
+
+		err := userServiceService.RecordServiceCall("1", "SMS", nil)
+
+		require.NoError(test, err)
+		mockUserServiceRepo.AssertNotCalled(test, "RecordUserServiceSuccess", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Unknown service", func(test *testing.T) {
+		userServiceService, _ := newRecordService()
+
+		err := userServiceService.RecordServiceCall("1", "Unknown", nil)
+
+		require.EqualError(test, err, "Unknown service")
+	})
+}
+
+func TestRetrieveServiceConnections(test *testing.T) {
+	services := []entities.Service{
+		{Id: "google", Name: "Google", IsAuthNeeded: true},
+		{Id: "github", Name: "Github", IsAuthNeeded: true},
+		{Id: "spotify", Name: "Spotify", IsAuthNeeded: true},
+		{Id: "weather", Name: "Weather"},
+	}
+	lastSuccessAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	lastErrorAt := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	expiryDate := time.Now().Add(time.Hour).Format(formattingDate)
+
+	newConnectionsService := func() (*UserServiceService, *MockUserRepository, *MockUserServiceRepository) {
+		mockUserRepo := new(MockUserRepository)
+		mockServiceRepo := new(MockServiceRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+		mockConnector := new(MockConnector)
+
+		mockServiceRepo.On("FindAllServices").
+			Return(services, nil)
+		mockConnector.On("OAuthConfig").
+			Return(entities.OAuthConfig{CanRefreshToken: false})
+		mockServiceService.On("FindConnector", "Github").
+			Return(mockConnector, nil)
+
+		return &UserServiceService{
+			UserRepository:        mockUserRepo,
+			ServiceRepository:     mockServiceRepo,
+			UserServiceRepository: mockUserServiceRepo,
+			ServiceService:        mockServiceService,
+		}, mockUserRepo, mockUserServiceRepo
+	}
+
+	test.Run("Successful", func(test *testing.T) {
+		userServiceService, mockUserRepo, mockUserServiceRepo := newConnectionsService()
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1", Email: "test@test.com"}, nil)
+		mockUserServiceRepo.On("FindUserServicesByUserId", "1").
+			Return([]entities.UserService{
+				{ServiceId: "google", RefreshToken: "refreshToken", ExpiryDate: expiryDate, Scopes: "openid email", LastSuccessAt: lastSuccessAt},
+				{ServiceId: "github", ExpiryDate: "2024-01-01 12:00:00+00:00", LastError: "API call failed (HTTP 401)", LastErrorAt: lastErrorAt},
+			}, nil)
+
+		connections, err := userServiceService.RetrieveServiceConnections("test@test.com", "basic")
+
+		require.NoError(test, err)
+		require.Len(test, connections, 3)
+
+		require.Equal(test, "Google", connections[0].Service)
+		require.True(test, connections[0].Linked)
+		require.Equal(test, []string{"openid", "email"}, connections[0].Scopes)
+		require.NotNil(test, connections[0].ExpiresAt)
+		require.Equal(test, lastSuccessAt, *connections[0].LastSuccessAt)
+		require.Nil(test, connections[0].LastErrorAt)
+		require.False(test, connections[0].ReauthorizationRequired)
+
+		require.Equal(test, "Github", connections[1].Service)
+		require.True(test, connections[1].Linked)
+		require.Equal(test, "API call failed (HTTP 401)", connections[1].LastError)
+		require.Equal(test, lastErrorAt, *connections[1].LastErrorAt)
+		require.True(test, connections[1].ReauthorizationRequired)
+
+		require.Equal(test, entities.ServiceConnection{Service: "Spotify", Scopes: []string{}}, connections[2])
+	})
+
+	test.Run("Refresh token refused", func(test *testing.T) {
+		userServiceService, mockUserRepo, mockUserServiceRepo := newConnectionsService()
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1", Email: "test@test.com"}, nil)
+		mockUserServiceRepo.On("FindUserServicesByUserId", "1").
+			Return([]entities.UserService{{ServiceId: "google", RefreshToken: "refreshToken", ExpiryDate: expiryDate, NeedsReauth: true}}, nil)
+
+		connections, err := userServiceService.RetrieveServiceConnections("test@test.com", "basic")
+
+		require.NoError(test, err)
+		require.True(test, connections[0].ReauthorizationRequired)
+	})
+
+	test.Run("Unknown user", func(test *testing.T) {
+		userServiceService, mockUserRepo, _ := newConnectionsService()
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{}, errors.New("Unknown user"))
+
+		_, err := userServiceService.RetrieveServiceConnections("test@test.com", "basic")
+
+		require.EqualError(test, err, "Could not find requested user")
+	})
+}
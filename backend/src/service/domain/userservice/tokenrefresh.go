@@ -0,0 +1,96 @@
+package userservice_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"backend/src/entities"
+)
+
+const tokenRefreshWindowEnv = "TOKEN_REFRESH_WINDOW"
+const defaultTokenRefreshWindow = 15 * time.Minute
+
+const reauthorizationRequiredMessage = "Service must be authorized again"
+
+const invalidGrantError = "invalid_grant"
+
+// Minutes before its expiry from which a token is refreshed by the background job
+func tokenRefreshWindow() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(tokenRefreshWindowEnv))
+	if err != nil || minutes <= 0 {
+		return defaultTokenRefreshWindow
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// The token endpoint answers "invalid_grant" once the refresh token was revoked or expired, the user must authorize again
+func isInvalidGrant(err error) bool {
+	var apiCallError entities.ApiCallError
+	if !errors.As(err, &apiCallError) {
+		return false
+	}
+
+	var oauthError struct {
+		Error string `json:"error"`
+	}
+	json.Unmarshal([]byte(apiCallError.Body), &oauthError)
+	return oauthError.Error == invalidGrantError
+}
+
+func (self *UserServiceService) markNeedsReauth(userId, serviceName string) error {
+	foundService, err := self.ServiceRepository.FindServiceByName(serviceName)
+	if err != nil {
+		return err
+	}
+
+	err = self.UserServiceRepository.MarkUserServiceNeedsReauth(userId, foundService.Id)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf(reauthorizationRequiredMessage)
+}
+
+// Refreshes the tokens expiring within the window before a reaction finds them expired
+// A connection whose refresh token is refused is marked as needing to be authorized again and is left aside
+// Returns the number of tokens refreshed
+func (self *UserServiceService) RefreshExpiringTokens(ctx context.Context) (int, error) {
+	userServices, err := self.UserServiceRepository.FindUserServicesExpiringBefore(time.Now().Add(tokenRefreshWindow()))
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	serviceNames := map[string]string{}
+	for _, userService := range userServices {
+		if ctx.Err() != nil {
+			return refreshed, ctx.Err()
+		}
+		if userService.RefreshToken == "" {
+			continue
+		}
+
+		serviceName, found := serviceNames[userService.ServiceId]
+		if !found {
+			foundService, err := self.ServiceRepository.FindServiceById(userService.ServiceId)
+			if err != nil {
+				continue
+			}
+			serviceName = foundService.Name
+			serviceNames[userService.ServiceId] = serviceName
+		}
+		if !self.canRefreshToken(serviceName) {
+			continue
+		}
+
+		_, err := self.refreshToken(userService.RefreshToken, userService.UserId, serviceName)
+		if err == nil {
+			refreshed++
+		}
+	}
+	return refreshed, nil
+}
@@ -0,0 +1,124 @@
+package userservice_service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func TestTokenRefreshWindow(test *testing.T) {
+	test.Setenv(tokenRefreshWindowEnv, "30")
+	require.Equal(test, 30*time.Minute, tokenRefreshWindow())
+
+	test.Setenv(tokenRefreshWindowEnv, "invalid")
+	require.Equal(test, defaultTokenRefreshWindow, tokenRefreshWindow())
+}
+
+func TestIsInvalidGrant(test *testing.T) {
+	require.True(test, isInvalidGrant(entities.ApiCallError{StatusCode: http.StatusBadRequest, Body: `{"error": "invalid_grant"}`}))
+	require.False(test, isInvalidGrant(entities.ApiCallError{StatusCode: http.StatusBadRequest, Body: `{"error": "invalid_client"}`}))
+	require.False(test, isInvalidGrant(entities.ApiCallError{StatusCode: http.StatusServiceUnavailable, Body: "Service unavailable"}))
+	require.False(test, isInvalidGrant(errors.New("invalid_grant")))
+}
+
+func TestRefreshExpiringTokens(test *testing.T) {
+	google := entities.Service{Id: "google", Name: "Google"}
+	github := entities.Service{Id: "github", Name: "Github"}
+
+	newRefreshService := func() (*UserServiceService, *MockServiceRepository, *MockUserServiceRepository, *MockServiceServiceRepository) {
+		mockServiceRepo := new(MockServiceRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+
+		userServiceService := &UserServiceService{
+			ServiceRepository:     mockServiceRepo,
+			UserServiceRepository: mockUserServiceRepo,
+			ServiceService:        mockServiceService,
+		}
+		return userServiceService, mockServiceRepo, mockUserServiceRepo, mockServiceService
+	}
+
+	test.Run("Successful", func(test *testing.T) {
+		userServiceService, mockServiceRepo, mockUserServiceRepo, mockServiceService := newRefreshService()
+
+		mockUserServiceRepo.On("FindUserServicesExpiringBefore", mock.Anything).
+			Return([]entities.UserService{
+				{UserId: "1", ServiceId: "google", RefreshToken: "refreshToken1"},
+				{UserId: "2", ServiceId: "google", RefreshToken: "refreshToken2"},
+				{UserId: "3", ServiceId: "google", RefreshToken: ""},
+				{UserId: "4", ServiceId: "github", RefreshToken: "refreshToken4"},
+			}, nil)
+
+		mockServiceRepo.On("FindServiceById", "google").Return(google, nil).Once()
+		mockServiceRepo.On("FindServiceById", "github").Return(github, nil).Once()
+		mockServiceRepo.On("FindServiceByName", "Google").Return(google, nil)
+
+		googleConnector := new(MockConnector)
+		githubConnector := new(MockConnector)
+		googleConnector.On("OAuthConfig").Return(entities.OAuthConfig{CanRefreshToken: true})
+		githubConnector.On("OAuthConfig").Return(entities.OAuthConfig{CanRefreshToken: false})
+		mockServiceService.On("FindConnector", "Google").Return(googleConnector, nil)
+		mockServiceService.On("FindConnector", "Github").Return(githubConnector, nil)
+
+		firstRequest := &http.Request{}
+		secondRequest := &http.Request{Method: "POST"}
+		googleConnector.On("RefreshTokenRequest", "refreshToken1").Return(firstRequest, nil)
+		googleConnector.On("RefreshTokenRequest", "refreshToken2").Return(secondRequest, nil)
+
+		mockServiceService.On("ExecuteRequest", firstRequest).
+			Return(&http.Response{Body: io.NopCloser(strings.NewReader(`{"access_token": "accessToken1", "expires_in": 3600}`))}, nil)
+		mockServiceService.On("ExecuteRequest", secondRequest).
+			Return((*http.Response)(nil), entities.ApiCallError{StatusCode: http.StatusBadRequest, Body: `{"error": "invalid_grant"}`})
+
+		mockUserServiceRepo.On("UpdateUserServiceByServiceIdAndUserId", "1", "accessToken1", "refreshToken1", mock.Anything, "google").
+			Return(nil)
+		mockUserServiceRepo.On("RecordUserServiceSuccess", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+		mockUserServiceRepo.On("MarkUserServiceNeedsReauth", "2", "google").
+			Return(nil)
+
+		refreshed, err := userServiceService.RefreshExpiringTokens(context.Background())
+
+		require.NoError(test, err)
+		require.Equal(test, 1, refreshed)
+		mockUserServiceRepo.AssertCalled(test, "MarkUserServiceNeedsReauth", "2", "google")
+		githubConnector.AssertNotCalled(test, "RefreshTokenRequest", mock.Anything)
+		mockServiceRepo.AssertNumberOfCalls(test, "FindServiceById", 2)
+	})
+
+	test.Run("Fail find user services", func(test *testing.T) {
+		userServiceService, _, mockUserServiceRepo, _ := newRefreshService()
+
+		mockUserServiceRepo.On("FindUserServicesExpiringBefore", mock.Anything).
+			Return([]entities.UserService{}, errors.New("Query error"))
+
+		_, err := userServiceService.RefreshExpiringTokens(context.Background())
+
+		require.EqualError(test, err, "Query error")
+	})
+
+	test.Run("Canceled", func(test *testing.T) {
+		userServiceService, mockServiceRepo, mockUserServiceRepo, _ := newRefreshService()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		mockUserServiceRepo.On("FindUserServicesExpiringBefore", mock.Anything).
+			Return([]entities.UserService{{UserId: "1", ServiceId: "google", RefreshToken: "refreshToken"}}, nil)
+
+		refreshed, err := userServiceService.RefreshExpiringTokens(ctx)
+
+		require.ErrorIs(test, err, context.Canceled)
+		require.Zero(test, refreshed)
+		mockServiceRepo.AssertNotCalled(test, "FindServiceById", mock.Anything)
+	})
+}
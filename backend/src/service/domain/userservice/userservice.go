@@ -1,9 +1,9 @@
 package userservice_service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"time"
 
 	"backend/src/entities"
@@ -15,6 +15,7 @@ type UserServiceService struct {
 	UserServiceRepository storage.UserServiceRepository
 	ServiceRepository     storage.ServiceRepository
 	UserRepository        storage.UserRepository
+	WorkflowRepository    storage.WorkflowRepository
 	ServiceService        service.ServiceService
 }
 
@@ -25,11 +26,12 @@ const formattingDate = "2006-01-02 15:04:05-07:00"
 const bearerType = "Bearer "
 
 func NewUserServiceService(ServiceRepository storage.ServiceRepository, UserRepository storage.UserRepository,
-	UserServiceRepository storage.UserServiceRepository, ServiceService service.ServiceService) *UserServiceService {
+	UserServiceRepository storage.UserServiceRepository, WorkflowRepository storage.WorkflowRepository, ServiceService service.ServiceService) *UserServiceService {
 	return &UserServiceService{
 		ServiceRepository:     ServiceRepository,
 		UserRepository:        UserRepository,
 		UserServiceRepository: UserServiceRepository,
+		WorkflowRepository:    WorkflowRepository,
 		ServiceService:        ServiceService,
 	}
 }
@@ -66,41 +68,39 @@ func (self *UserServiceService) RetrieveUserServiceAuthenticationStatus(email, c
 func (self *UserServiceService) refreshToken(refreshToken, userId, serviceName string) (entities.ResultToken, error) {
 	var tokenRes entities.ResultToken
 	var serviceFound entities.Service
-	var request *http.Request
-	var errRequest error
-
-	if serviceName == "Google" {
-		request, errRequest = self.ServiceService.GetGoogleRefreshTokenRequest(refreshToken)
-	} else if serviceName == "Spotify" {
-		request, errRequest = self.ServiceService.GetSpotifyRefreshTokenRequest(refreshToken)
-	} else if serviceName == "Discord" {
-		request, errRequest = self.ServiceService.GetDiscordRefreshTokenRequest(refreshToken)
-	} else if serviceName == "Reddit" {
-		request, errRequest = self.ServiceService.GetRedditRefreshTokenRequest(refreshToken)
-	} else if serviceName == "Asana" {
-		request, errRequest = self.ServiceService.GetAsanaRefreshTokenRequest(refreshToken)
-	} else if serviceName == "Dropbox" {
-		request, errRequest = self.ServiceService.GetDropboxRefreshTokenRequest(refreshToken)
-	} else if serviceName == "Gitlab" {
-		request, errRequest = self.ServiceService.GetGitlabRefreshTokenRequest(refreshToken)
-	} else {
-		return tokenRes, fmt.Errorf("Unknown service")
+
+	connector, err := self.ServiceService.FindConnector(serviceName)
+	if err != nil {
+		return tokenRes, err
 	}
 
+	request, errRequest := connector.RefreshTokenRequest(refreshToken)
 	if errRequest != nil {
 		return tokenRes, errRequest
 	}
 
 	res, err := self.ServiceService.ExecuteRequest(request)
+	if err != nil {
+		self.recordRefreshError(userId, serviceName, err)
+	}
+	if err != nil && isInvalidGrant(err) {
+		return tokenRes, self.markNeedsReauth(userId, serviceName)
+	}
 	if err != nil {
 		return tokenRes, err
 	}
+	defer res.Body.Close()
 
 	errDecoder := json.NewDecoder(res.Body).Decode(&tokenRes)
 	if errDecoder != nil {
 		return tokenRes, errDecoder
 	}
 
+	// Only the services rotating their refresh tokens send a new one
+	if tokenRes.RefreshToken == "" {
+		tokenRes.RefreshToken = refreshToken
+	}
+
 	currentTime := time.Now()
 	expiryDate := currentTime.Add(time.Duration(tokenRes.ExpiresIn) * time.Second).Format(formattingDate)
 
@@ -113,9 +113,19 @@ func (self *UserServiceService) refreshToken(refreshToken, userId, serviceName s
 	if err != nil {
 		return tokenRes, err
 	}
+	self.updateScopes(userId, serviceFound.Id, tokenRes.Scope)
+	self.RecordServiceCall(userId, serviceName, nil)
 	return tokenRes, nil
 }
 
+func (self *UserServiceService) canRefreshToken(serviceName string) bool {
+	connector, err := self.ServiceService.FindConnector(serviceName)
+	if err != nil {
+		return false
+	}
+	return connector.OAuthConfig().CanRefreshToken
+}
+
 func (self *UserServiceService) CallApiAndRefresh(email, connectionType, serviceName string) (string, error) {
 	foundUser, errGetUser := self.GetUser(email, connectionType)
 	if errGetUser != nil || len(foundUser.Email) <= 0 {
@@ -138,7 +148,10 @@ func (self *UserServiceService) CallApiAndRefresh(email, connectionType, service
 		return "", errTimestamp
 	}
 
-	if expiryDate.Before(time.Now()) && serviceName != "Github" && serviceName != "Linkedin" {
+	if expiryDate.Before(time.Now()) && foundUserService.NeedsReauth {
+		return "", fmt.Errorf(reauthorizationRequiredMessage)
+	}
+	if expiryDate.Before(time.Now()) && self.canRefreshToken(serviceName) {
 		tokenFromRefresh, errRefresh := self.refreshToken(foundUserService.RefreshToken, foundUser.Id, serviceName)
 		token = tokenFromRefresh.AccessToken
 		if errRefresh != nil {
@@ -159,15 +172,29 @@ func (self *UserServiceService) createOrUpdateUserService(userId, serviceId stri
 	} else {
 		self.UserServiceRepository.CreateUserService(userId, token.AccessToken, token.RefreshToken, expiryDate, serviceId)
 	}
+	self.updateScopes(userId, serviceId, token.Scope)
 }
 
-func (self *UserServiceService) UpdateTokenForService(code, serviceName, appType, email, connectionType string) error {
+// The services omitting the scopes in their token response keep the scopes known so far
+func (self *UserServiceService) updateScopes(userId, serviceId, scopes string) {
+	if scopes == "" {
+		return
+	}
+	self.UserServiceRepository.UpdateUserServiceScopes(userId, serviceId, scopes)
+}
+
+func (self *UserServiceService) UpdateTokenForService(code, state, session, serviceName, appType, email, connectionType string) error {
 	_, errorService := self.ServiceRepository.FindServiceByName(serviceName)
 	if errorService != nil {
 		return errorService
 	}
 
-	token, errToken := self.ServiceService.GetResultTokenFromCode(code, serviceName, "service", appType)
+	codeVerifier, errState := self.ServiceService.ConsumeOAuthState(state, session, serviceName, "service", appType)
+	if errState != nil {
+		return errState
+	}
+
+	token, errToken := self.ServiceService.GetResultTokenFromCode(code, serviceName, "service", appType, codeVerifier)
 	if errToken != nil {
 		return errToken
 	}
@@ -191,7 +218,7 @@ func (self *UserServiceService) RetrieveGithubUserRepositories(email, connection
 	if err != nil {
 		return []entities.GithubRepository{}, err
 	}
-	return self.ServiceService.RequestGithubUserRepositories(accessToken)
+	return self.ServiceService.RequestGithubUserRepositories(context.Background(), accessToken)
 }
 
 func (self *UserServiceService) RetrieveGitlabUserProjects(email, connectionType string) ([]entities.GitlabProject, error) {
@@ -1,6 +1,7 @@
 package userservice_service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"backend/src/entities"
+	"backend/src/service"
 )
 
 type MockUserRepository struct {
@@ -39,6 +41,11 @@ func (m *MockUserRepository) UpdateUser(email, password, connectionType string)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateUserTimezone(email, connectionType, timezone string) error {
+	args := m.Called(email, connectionType, timezone)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) DeleteUser(email, connectionType string) error {
 	args := m.Called(email, connectionType)
 	return args.Error(0)
@@ -48,8 +55,8 @@ type MockServiceRepository struct {
 	mock.Mock
 }
 
-func (m *MockServiceRepository) CreateService(name, color, logo string) error {
-	args := m.Called(name, color, logo)
+func (m *MockServiceRepository) CreateService(service entities.Service) error {
+	args := m.Called(service)
 	return args.Error(0)
 }
 
@@ -97,11 +104,51 @@ func (m *MockUserServiceRepository) UpdateUserServiceByServiceIdAndUserId(userId
 	return args.Error(0)
 }
 
+func (m *MockUserServiceRepository) FindUserServicesExpiringBefore(deadline time.Time) ([]entities.UserService, error) {
+	args := m.Called(deadline)
+	return args.Get(0).([]entities.UserService), args.Error(1)
+}
+
+func (m *MockUserServiceRepository) FindUserServicesByUserId(userId string) ([]entities.UserService, error) {
+	args := m.Called(userId)
+	return args.Get(0).([]entities.UserService), args.Error(1)
+}
+
+func (m *MockUserServiceRepository) UpdateUserServiceScopes(userId, serviceId, scopes string) error {
+	args := m.Called(userId, serviceId, scopes)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) RecordUserServiceSuccess(userId, serviceId string, at time.Time) error {
+	args := m.Called(userId, serviceId, at)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) RecordUserServiceError(userId, serviceId, lastError string, at time.Time) error {
+	args := m.Called(userId, serviceId, lastError, at)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) MarkUserServiceNeedsReauth(userId, serviceId string) error {
+	args := m.Called(userId, serviceId)
+	return args.Error(0)
+}
+
 func (m *MockUserServiceRepository) DeleteUserServiceByUserId(userId string) error {
 	args := m.Called(userId)
 	return args.Error(0)
 }
 
+func (m *MockUserServiceRepository) DeleteUserServiceByServiceIdAndUserId(userId, serviceId string) error {
+	args := m.Called(userId, serviceId)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) RotateUserServiceKeys() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
 type MockServiceServiceRepository struct {
 	mock.Mock
 }
@@ -151,39 +198,20 @@ func (m *MockServiceServiceRepository) RetrieveReactionsFromService(serviceName
 	return args.Get(0).([]entities.Reaction), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetGoogleRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) GetSpotifyRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) GetDiscordRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) GetRedditRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) GetAsanaRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
+func (m *MockServiceServiceRepository) FindConnector(serviceName string) (service.Connector, error) {
+	args := m.Called(serviceName)
+	connector, _ := args.Get(0).(service.Connector)
+	return connector, args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetDropboxRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
+func (m *MockServiceServiceRepository) RetrieveConnectors() []service.Connector {
+	args := m.Called()
+	return args.Get(0).([]service.Connector)
 }
 
-func (m *MockServiceServiceRepository) GetGitlabRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	args := m.Called(refreshToken)
-	return args.Get(0).(*http.Request), args.Error(1)
+func (m *MockServiceServiceRepository) SeedServices() error {
+	args := m.Called()
+	return args.Error(0)
 }
 
 func (m *MockServiceServiceRepository) ExecuteRequest(request *http.Request) (*http.Response, error) {
@@ -191,13 +219,23 @@ func (m *MockServiceServiceRepository) ExecuteRequest(request *http.Request) (*h
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
+func (m *MockServiceServiceRepository) ExecuteRequestWithClient(client *http.Client, request *http.Request) (*http.Response, error) {
+	args := m.Called(client, request)
+	return args.Get(0).(*http.Response), args.Error(1)
+}
+
 func (m *MockServiceServiceRepository) ExecuteApiRequest(url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
 	args := m.Called(url, method, typeToken, accessToken, body)
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetResultTokenFromCode(code, serviceName, callbackType, appType string) (entities.ResultToken, error) {
-	args := m.Called(code, serviceName, callbackType, appType)
+// The context is not matched, the expectations are registered on ExecuteApiRequest
+func (m *MockServiceServiceRepository) ExecuteApiRequestWithContext(ctx context.Context, url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
+	return m.ExecuteApiRequest(url, method, typeToken, accessToken, body)
+}
+
+func (m *MockServiceServiceRepository) GetResultTokenFromCode(code, serviceName, callbackType, appType, codeVerifier string) (entities.ResultToken, error) {
+	args := m.Called(code, serviceName, callbackType, appType, codeVerifier)
 	return args.Get(0).(entities.ResultToken), args.Error(1)
 }
 
@@ -206,17 +244,17 @@ func (m *MockServiceServiceRepository) GetUserInfoFromService(accessToken, servi
 	return args.Get(0).(entities.UserInfo), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) RequestToTimeApi() (entities.TimeResponse, error) {
-	args := m.Called()
-	return args.Get(0).(entities.TimeResponse), args.Error(1)
+func (m *MockServiceServiceRepository) OAuth2Service(serviceName, callbackType, appType, session string) (string, error) {
+	args := m.Called(serviceName, callbackType, appType, session)
+	return args.String(0), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) OAuth2Service(serviceName, callbackType, appType string) (string, error) {
-	args := m.Called(serviceName, callbackType, appType)
+func (m *MockServiceServiceRepository) ConsumeOAuthState(state, session, serviceName, callbackType, appType string) (string, error) {
+	args := m.Called(state, session, serviceName, callbackType, appType)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) RequestGithubUserRepositories(accessToken string) ([]entities.GithubRepository, error) {
+func (m *MockServiceServiceRepository) RequestGithubUserRepositories(ctx context.Context, accessToken string) ([]entities.GithubRepository, error) {
 	args := m.Called(accessToken)
 	return args.Get(0).([]entities.GithubRepository), args.Error(1)
 }
@@ -231,6 +269,160 @@ func (m *MockServiceServiceRepository) RetrieveDiscordGuildChannels(guildId stri
 	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
 
+type MockWorkflowRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkflowRepository) CreateWorkflow(name, ownerId, actionId, reactionId, filter, catchUpPolicy string, pollInterval int, actionParam, reactionParam, actionData map[string]interface{}) (string, error) {
+	args := m.Called(name, ownerId, actionId, reactionId, filter, catchUpPolicy, pollInterval, actionParam, reactionParam, actionData)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) FindWorkflowById(id string) (entities.Workflow, error) {
+	args := m.Called(id)
+	return args.Get(0).(entities.Workflow), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) FindWorkflowByWebhookToken(token string) (entities.Workflow, error) {
+	args := m.Called(token)
+	return args.Get(0).(entities.Workflow), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) FindWorkflowsByActionId(actionId string) ([]entities.Workflow, error) {
+	args := m.Called(actionId)
+	return args.Get(0).([]entities.Workflow), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) FindDueWorkflowsByActionId(actionId string, now time.Time) ([]entities.Workflow, error) {
+	args := m.Called(actionId, now)
+	return args.Get(0).([]entities.Workflow), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) FindWorkflowsByOwnerId(ownerId string) ([]entities.Workflow, error) {
+	args := m.Called(ownerId)
+	return args.Get(0).([]entities.Workflow), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) UpdateWorkflow(id string, updatedWorkflow entities.Workflow) error {
+	args := m.Called(id, updatedWorkflow)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) UpdateWorkflowActionData(id string, actionData map[string]interface{}) error {
+	args := m.Called(id, actionData)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) IncrementWorkflowFailures(id string) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) ResetWorkflowFailures(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) IncrementWorkflowPollFailures(id string) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) ResetWorkflowPollFailures(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) ClaimWorkflowCheck(id string, now, nextCheckAt time.Time) (bool, error) {
+	args := m.Called(id, now, nextCheckAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) SuspendWorkflow(id, reason string) (bool, error) {
+	args := m.Called(id, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) SuspendWorkflowsByOwnerIdAndServiceId(ownerId, serviceId, reason string) (int, error) {
+	args := m.Called(ownerId, serviceId, reason)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) DeleteWorkflow(id, ownerId string) error {
+	args := m.Called(id, ownerId)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) DeleteWorkflowByOwnerId(ownerId string) error {
+	args := m.Called(ownerId)
+	return args.Error(0)
+}
+
+type MockConnector struct {
+	mock.Mock
+}
+
+func (m *MockConnector) Definition() entities.ServiceDefinition {
+	args := m.Called()
+	return args.Get(0).(entities.ServiceDefinition)
+}
+
+func (m *MockConnector) OAuthConfig() entities.OAuthConfig {
+	args := m.Called()
+	return args.Get(0).(entities.OAuthConfig)
+}
+
+func (m *MockConnector) AuthorizationUrl(callbackType, appType, state, codeChallenge string) (string, error) {
+	args := m.Called(callbackType, appType, state, codeChallenge)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockConnector) AccessTokenRequest(code, callbackType, appType, codeVerifier string) (*http.Request, error) {
+	args := m.Called(code, callbackType, appType, codeVerifier)
+	return args.Get(0).(*http.Request), args.Error(1)
+}
+
+func (m *MockConnector) RefreshTokenRequest(refreshToken string) (*http.Request, error) {
+	args := m.Called(refreshToken)
+	return args.Get(0).(*http.Request), args.Error(1)
+}
+
+func (m *MockConnector) RevokeTokenRequest(accessToken, refreshToken string) (*http.Request, error) {
+	args := m.Called(accessToken, refreshToken)
+	request, _ := args.Get(0).(*http.Request)
+	return request, args.Error(1)
+}
+
+func (m *MockConnector) UserInfoRequest(accessToken string) (*http.Request, error) {
+	args := m.Called(accessToken)
+	return args.Get(0).(*http.Request), args.Error(1)
+}
+
+func (m *MockConnector) DecodeUserInfo(res *http.Response) (entities.UserInfo, error) {
+	args := m.Called(res)
+	return args.Get(0).(entities.UserInfo), args.Error(1)
+}
+
+func (m *MockConnector) ParseWebhook(headers http.Header, body []byte) (entities.WebhookEvent, error) {
+	args := m.Called(headers, body)
+	return args.Get(0).(entities.WebhookEvent), args.Error(1)
+}
+
+func (m *MockConnector) VerifyWebhook(headers http.Header, body []byte, secret string) bool {
+	args := m.Called(headers, body, secret)
+	return args.Bool(0)
+}
+
+func (m *MockConnector) PollJobs() []service.PollJob {
+	args := m.Called()
+	return args.Get(0).([]service.PollJob)
+}
+
+func (m *MockConnector) ReactionHandler() service.ReactionHandler {
+	args := m.Called()
+	return args.Get(0).(service.ReactionHandler)
+}
+
 func TestGetUser(test *testing.T) {
 	test.Run("Successful", func(test *testing.T) {
 		var user entities.User
@@ -405,8 +597,9 @@ func TestRetrieveUserServiceAuthenticationStatus(test *testing.T) {
 
 func TestRefreshToken(test *testing.T) {
 	mockService := entities.Service{
-		Id:   "1",
-		Name: "Google",
+		Id:           "1",
+		Name:         "Google",
+		IsAuthNeeded: true,
 	}
 
 	test.Run("Successful", func(test *testing.T) {
@@ -425,13 +618,18 @@ func TestRefreshToken(test *testing.T) {
 			Body: io.NopCloser(strings.NewReader(`{
 				"access_token": "accessToken",
 				"refresh_token": "refreshToken",
-				"expires_in": 3600}`,
+				"expires_in": 3600,
+				"scope": "openid email"}`,
 			)),
 		}
 
-		mockServiceService.On("GetGoogleRefreshTokenRequest", "refreshToken").
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
 			Return(mockRequest, nil)
 
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
 		mockServiceService.On("ExecuteRequest", mockRequest).
 			Return(mockResponse, nil)
 
@@ -440,10 +638,17 @@ func TestRefreshToken(test *testing.T) {
 
 		mockUserServiceRepo.On("UpdateUserServiceByServiceIdAndUserId", "1", "accessToken", "refreshToken", mock.Anything, mockService.Id).
 			Return(nil)
+		mockUserServiceRepo.On("RecordUserServiceSuccess", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+
+		mockUserServiceRepo.On("UpdateUserServiceScopes", "1", mockService.Id, "openid email").
+			Return(nil)
 
 		_, err := userService.refreshToken("refreshToken", "1", "Google")
 
 		require.NoError(test, err)
+		mockUserServiceRepo.AssertCalled(test, "UpdateUserServiceScopes", "1", mockService.Id, "openid email")
+		mockUserServiceRepo.AssertCalled(test, "RecordUserServiceSuccess", "1", mockService.Id, mock.Anything)
 	})
 
 	test.Run("Unknown service", func(test *testing.T) {
@@ -457,6 +662,9 @@ func TestRefreshToken(test *testing.T) {
 			UserServiceRepository: mockUserServiceRepo,
 		}
 
+		mockServiceService.On("FindConnector", "false").
+			Return(nil, errors.New("Unknown service"))
+
 		_, err := userService.refreshToken("refreshToken", "1", "false")
 
 		require.EqualError(test, err, "Unknown service")
@@ -475,9 +683,13 @@ func TestRefreshToken(test *testing.T) {
 
 		mockRequest := &http.Request{}
 
-		mockServiceService.On("GetGoogleRefreshTokenRequest", "refreshToken").
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
 			Return(mockRequest, errors.New("request error"))
 
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
 		_, err := userService.refreshToken("refreshToken", "1", "Google")
 
 		require.EqualError(test, err, "request error")
@@ -503,15 +715,143 @@ func TestRefreshToken(test *testing.T) {
 			)),
 		}
 
-		mockServiceService.On("GetGoogleRefreshTokenRequest", "refreshToken").
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
 			Return(mockRequest, nil)
 
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
 		mockServiceService.On("ExecuteRequest", mockRequest).
 			Return(mockResponse, errors.New("Fail execute"))
 
+		mockServiceRepo.On("FindServiceByName", "Google").
+			Return(mockService, nil)
+
+		mockUserServiceRepo.On("RecordUserServiceError", "1", mockService.Id, "Token refresh failed: Fail execute", mock.Anything).
+			Return(nil)
+
 		_, err := userService.refreshToken("refreshToken", "1", "Google")
 
 		require.EqualError(test, err, "Fail execute")
+		mockUserServiceRepo.AssertCalled(test, "RecordUserServiceError", "1", mockService.Id, "Token refresh failed: Fail execute", mock.Anything)
+	})
+
+	test.Run("Refresh token kept", func(test *testing.T) {
+		mockServiceService := new(MockServiceServiceRepository)
+		mockServiceRepo := new(MockServiceRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
+
+		userService := &UserServiceService{
+			ServiceService:        mockServiceService,
+			ServiceRepository:     mockServiceRepo,
+			UserServiceRepository: mockUserServiceRepo,
+		}
+
+		mockRequest := &http.Request{}
+		mockResponse := &http.Response{
+			Body: io.NopCloser(strings.NewReader(`{"access_token": "newAccessToken", "expires_in": 3600}`)),
+		}
+
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
+			Return(mockRequest, nil)
+
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
+		mockServiceService.On("ExecuteRequest", mockRequest).
+			Return(mockResponse, nil)
+
+		mockServiceRepo.On("FindServiceByName", "Google").
+			Return(mockService, nil)
+
+		mockUserServiceRepo.On("UpdateUserServiceByServiceIdAndUserId", "1", "newAccessToken", "refreshToken", mock.Anything, mockService.Id).
+			Return(nil)
+		mockUserServiceRepo.On("RecordUserServiceSuccess", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+
+		token, err := userService.refreshToken("refreshToken", "1", "Google")
+
+		require.NoError(test, err)
+		require.Equal(test, "refreshToken", token.RefreshToken)
+	})
+
+	test.Run("Refresh token rotated", func(test *testing.T) {
+		mockServiceService := new(MockServiceServiceRepository)
+		mockServiceRepo := new(MockServiceRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
+
+		userService := &UserServiceService{
+			ServiceService:        mockServiceService,
+			ServiceRepository:     mockServiceRepo,
+			UserServiceRepository: mockUserServiceRepo,
+		}
+
+		mockRequest := &http.Request{}
+		mockResponse := &http.Response{
+			Body: io.NopCloser(strings.NewReader(`{"access_token": "newAccessToken", "refresh_token": "newRefreshToken", "expires_in": 3600}`)),
+		}
+
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
+			Return(mockRequest, nil)
+
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
+		mockServiceService.On("ExecuteRequest", mockRequest).
+			Return(mockResponse, nil)
+
+		mockServiceRepo.On("FindServiceByName", "Google").
+			Return(mockService, nil)
+
+		mockUserServiceRepo.On("UpdateUserServiceByServiceIdAndUserId", "1", "newAccessToken", "newRefreshToken", mock.Anything, mockService.Id).
+			Return(nil)
+		mockUserServiceRepo.On("RecordUserServiceSuccess", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+
+		_, err := userService.refreshToken("refreshToken", "1", "Google")
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid grant", func(test *testing.T) {
+		mockServiceService := new(MockServiceServiceRepository)
+		mockServiceRepo := new(MockServiceRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
+
+		userService := &UserServiceService{
+			ServiceService:        mockServiceService,
+			ServiceRepository:     mockServiceRepo,
+			UserServiceRepository: mockUserServiceRepo,
+		}
+
+		mockRequest := &http.Request{}
+
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
+			Return(mockRequest, nil)
+
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
+		mockServiceService.On("ExecuteRequest", mockRequest).
+			Return((*http.Response)(nil), entities.ApiCallError{StatusCode: http.StatusBadRequest, Body: `{"error": "invalid_grant"}`})
+
+		mockServiceRepo.On("FindServiceByName", "Google").
+			Return(mockService, nil)
+
+		mockUserServiceRepo.On("MarkUserServiceNeedsReauth", "1", mockService.Id).
+			Return(nil)
+
+		mockUserServiceRepo.On("RecordUserServiceError", "1", mockService.Id, `Token refresh failed: API call failed (HTTP 400): {"error": "invalid_grant"}`, mock.Anything).
+			Return(nil)
+
+		_, err := userService.refreshToken("refreshToken", "1", "Google")
+
+		require.EqualError(test, err, reauthorizationRequiredMessage)
+		mockUserServiceRepo.AssertCalled(test, "MarkUserServiceNeedsReauth", "1", mockService.Id)
 	})
 
 	test.Run("Fail Decode", func(t *testing.T) {
@@ -530,9 +870,13 @@ func TestRefreshToken(test *testing.T) {
 			Body: io.NopCloser(strings.NewReader(`invalid json`)),
 		}
 
-		mockServiceService.On("GetGoogleRefreshTokenRequest", "refreshToken").
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
 			Return(mockRequest, nil)
 
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
 		mockServiceService.On("ExecuteRequest", mockRequest).
 			Return(mockResponse, nil)
 
@@ -561,9 +905,13 @@ func TestRefreshToken(test *testing.T) {
 			)),
 		}
 
-		mockServiceService.On("GetGoogleRefreshTokenRequest", "refreshToken").
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
 			Return(mockRequest, nil)
 
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
 		mockServiceService.On("ExecuteRequest", mockRequest).
 			Return(mockResponse, nil)
 
@@ -595,9 +943,13 @@ func TestRefreshToken(test *testing.T) {
 			)),
 		}
 
-		mockServiceService.On("GetGoogleRefreshTokenRequest", "refreshToken").
+		mockConnector := new(MockConnector)
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
 			Return(mockRequest, nil)
 
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
 		mockServiceService.On("ExecuteRequest", mockRequest).
 			Return(mockResponse, nil)
 
@@ -743,14 +1095,23 @@ func TestCallApiAndRefresh(test *testing.T) {
 			}`)),
 		}
 
-		mockServiceService.On("GetGoogleRefreshTokenRequest", "refreshToken").
+		mockConnector := new(MockConnector)
+		mockConnector.On("OAuthConfig").
+			Return(entities.OAuthConfig{CanRefreshToken: true})
+
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
 			Return(mockRequest, nil)
 
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
 		mockServiceService.On("ExecuteRequest", mockRequest).
 			Return(mockResponse, nil)
 
 		mockUserServiceRepo.On("UpdateUserServiceByServiceIdAndUserId", "1", "accessToken", "refreshToken", mock.Anything, "1").
 			Return(nil)
+		mockUserServiceRepo.On("RecordUserServiceSuccess", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
 
 		_, err := userService.CallApiAndRefresh("test@test.com", "basic", "Google")
 
@@ -801,9 +1162,16 @@ func TestCallApiAndRefresh(test *testing.T) {
 				}`)),
 		}
 
-		mockServiceService.On("GetGoogleRefreshTokenRequest", "refreshToken").
+		mockConnector := new(MockConnector)
+		mockConnector.On("OAuthConfig").
+			Return(entities.OAuthConfig{CanRefreshToken: true})
+
+		mockConnector.On("RefreshTokenRequest", "refreshToken").
 			Return(mockRequest, nil)
 
+		mockServiceService.On("FindConnector", "Google").
+			Return(mockConnector, nil)
+
 		mockServiceService.On("ExecuteRequest", mock.Anything).
 			Return(mockResponse, errors.New("refresh token failed"))
 
@@ -812,6 +1180,41 @@ func TestCallApiAndRefresh(test *testing.T) {
 		require.EqualError(test, err, "refresh token failed")
 	})
 
+	test.Run("Needs reauthorization", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockServiceRepo := new(MockServiceRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+
+		userService := &UserServiceService{
+			UserRepository:        mockUserRepo,
+			ServiceRepository:     mockServiceRepo,
+			UserServiceRepository: mockUserServiceRepo,
+			ServiceService:        mockServiceService,
+		}
+
+		serviceOfUser := entities.UserService{
+			AccessToken:  "accessToken",
+			RefreshToken: "refreshToken",
+			ExpiryDate:   time.Now().Add(-time.Hour).Format(formattingDate),
+			NeedsReauth:  true,
+		}
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1", Email: "test@test.com"}, nil)
+
+		mockServiceRepo.On("FindServiceByName", "Google").
+			Return(entities.Service{Id: "1"}, nil)
+
+		mockUserServiceRepo.On("FindUserServiceByServiceIdandUserId", "1", "1").
+			Return(serviceOfUser, nil)
+
+		_, err := userService.CallApiAndRefresh("test@test.com", "basic", "Google")
+
+		require.EqualError(test, err, reauthorizationRequiredMessage)
+		mockServiceService.AssertNotCalled(test, "FindConnector", mock.Anything)
+	})
+
 	test.Run("Token not expired", func(test *testing.T) {
 		var user entities.User
 		var service entities.Service
@@ -884,7 +1287,10 @@ func TestUpdateTokenForService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "Google").
 			Return(service, nil)
 
-		mockServiceService.On("GetResultTokenFromCode", "code", "Google", "service", "web").
+		mockServiceService.On("ConsumeOAuthState", "state", "session", "Google", "service", "web").
+			Return("verifier", nil)
+
+		mockServiceService.On("GetResultTokenFromCode", "code", "Google", "service", "web", "verifier").
 			Return(token, nil)
 
 		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
@@ -899,7 +1305,7 @@ func TestUpdateTokenForService(test *testing.T) {
 		mockUserServiceRepo.On("CreateUserService", "1", "accessToken", "refreshToken", mock.Anything, "2").
 			Return(nil)
 
-		err := userService.UpdateTokenForService("code", "Google", "web", "test@test.com", "basic")
+		err := userService.UpdateTokenForService("code", "state", "session", "Google", "web", "test@test.com", "basic")
 		require.NoError(test, err)
 	})
 
@@ -919,10 +1325,33 @@ func TestUpdateTokenForService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "Google").
 			Return(entities.Service{}, fmt.Errorf("service not found"))
 
-		err := userService.UpdateTokenForService("code", "Google", "web", "test@test.com", "basic")
+		err := userService.UpdateTokenForService("code", "state", "session", "Google", "web", "test@test.com", "basic")
 		require.EqualError(test, err, "service not found")
 	})
 
+	test.Run("Invalid OAuth state", func(test *testing.T) {
+		var service entities.Service
+
+		mockServiceRepo := new(MockServiceRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+
+		userService := &UserServiceService{
+			ServiceRepository: mockServiceRepo,
+			ServiceService:    mockServiceService,
+		}
+
+		mockServiceRepo.On("FindServiceByName", "Google").
+			Return(service, nil)
+
+		mockServiceService.On("ConsumeOAuthState", "state", "session", "Google", "service", "web").
+			Return("", errors.New("Invalid OAuth state"))
+
+		err := userService.UpdateTokenForService("code", "state", "session", "Google", "web", "test@test.com", "basic")
+
+		require.EqualError(test, err, "Invalid OAuth state")
+		mockServiceService.AssertNotCalled(test, "GetResultTokenFromCode", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	test.Run("Fail Token from Code", func(test *testing.T) {
 		var service entities.Service
 
@@ -941,10 +1370,13 @@ func TestUpdateTokenForService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "Google").
 			Return(service, nil)
 
-		mockServiceService.On("GetResultTokenFromCode", "code", "Google", "service", "web").
+		mockServiceService.On("ConsumeOAuthState", "state", "session", "Google", "service", "web").
+			Return("verifier", nil)
+
+		mockServiceService.On("GetResultTokenFromCode", "code", "Google", "service", "web", "verifier").
 			Return(entities.ResultToken{}, errors.New("token retrieval failed"))
 
-		err := userService.UpdateTokenForService("code", "Google", "web", "test@test.com", "basic")
+		err := userService.UpdateTokenForService("code", "state", "session", "Google", "web", "test@test.com", "basic")
 
 		require.EqualError(test, err, "token retrieval failed")
 	})
@@ -968,13 +1400,16 @@ func TestUpdateTokenForService(test *testing.T) {
 		mockServiceRepo.On("FindServiceByName", "Google").
 			Return(service, nil)
 
-		mockServiceService.On("GetResultTokenFromCode", "code", "Google", "service", "web").
+		mockServiceService.On("ConsumeOAuthState", "state", "session", "Google", "service", "web").
+			Return("verifier", nil)
+
+		mockServiceService.On("GetResultTokenFromCode", "code", "Google", "service", "web", "verifier").
 			Return(token, nil)
 
 		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
 			Return(entities.User{}, errors.New("user not found"))
 
-		err := userService.UpdateTokenForService("code", "Google", "web", "test@test.com", "basic")
+		err := userService.UpdateTokenForService("code", "state", "session", "Google", "web", "test@test.com", "basic")
 
 		require.EqualError(test, err, "Could not find requested user")
 	})
@@ -1768,3 +2203,143 @@ func TestRetrieveAsanaWorkspaceTags(test *testing.T) {
 		require.NoError(test, err)
 	})
 }
+
+func TestDisconnectService(test *testing.T) {
+	user := entities.User{Id: "1", Email: "test@test.com"}
+	foundService := entities.Service{Id: "2", Name: "Google"}
+	userService := entities.UserService{AccessToken: "accessToken", RefreshToken: "refreshToken"}
+	reason := disconnectedServiceReason("Google")
+
+	newDisconnectService := func() (*UserServiceService, *MockUserRepository, *MockServiceRepository, *MockUserServiceRepository, *MockWorkflowRepository, *MockServiceServiceRepository) {
+		mockUserRepo := new(MockUserRepository)
+		mockServiceRepo := new(MockServiceRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+
+		userServiceService := &UserServiceService{
+			UserRepository:        mockUserRepo,
+			ServiceRepository:     mockServiceRepo,
+			UserServiceRepository: mockUserServiceRepo,
+			WorkflowRepository:    mockWorkflowRepo,
+			ServiceService:        mockServiceService,
+		}
+		return userServiceService, mockUserRepo, mockServiceRepo, mockUserServiceRepo, mockWorkflowRepo, mockServiceService
+	}
+
+	test.Run("Successful", func(test *testing.T) {
+		userServiceService, mockUserRepo, mockServiceRepo, mockUserServiceRepo, mockWorkflowRepo, mockServiceService := newDisconnectService()
+
+		mockRequest := &http.Request{}
+		mockResponse := &http.Response{Body: io.NopCloser(strings.NewReader(""))}
+		mockConnector := new(MockConnector)
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").Return(user, nil)
+		mockServiceRepo.On("FindServiceByName", "Google").Return(foundService, nil)
+		mockUserServiceRepo.On("FindUserServiceByServiceIdandUserId", "1", "2").Return(userService, nil)
+		mockServiceService.On("FindConnector", "Google").Return(mockConnector, nil)
+		mockConnector.On("RevokeTokenRequest", "accessToken", "refreshToken").Return(mockRequest, nil)
+		mockServiceService.On("ExecuteRequest", mockRequest).Return(mockResponse, nil)
+		mockUserServiceRepo.On("DeleteUserServiceByServiceIdAndUserId", "1", "2").Return(nil)
+		mockWorkflowRepo.On("SuspendWorkflowsByOwnerIdAndServiceId", "1", "2", reason).Return(3, nil)
+
+		disconnection, err := userServiceService.DisconnectService("test@test.com", "basic", "Google")
+
+		require.NoError(test, err)
+		require.Equal(test, entities.ServiceDisconnection{DeactivatedWorkflows: 3, TokenRevoked: true}, disconnection)
+		mockServiceService.AssertCalled(test, "ExecuteRequest", mockRequest)
+	})
+
+	test.Run("Revocation not supported", func(test *testing.T) {
+		userServiceService, mockUserRepo, mockServiceRepo, mockUserServiceRepo, mockWorkflowRepo, mockServiceService := newDisconnectService()
+
+		mockConnector := new(MockConnector)
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").Return(user, nil)
+		mockServiceRepo.On("FindServiceByName", "Google").Return(foundService, nil)
+		mockUserServiceRepo.On("FindUserServiceByServiceIdandUserId", "1", "2").Return(userService, nil)
+		mockServiceService.On("FindConnector", "Google").Return(mockConnector, nil)
+		mockConnector.On("RevokeTokenRequest", "accessToken", "refreshToken").Return(nil, errors.New("Operation not supported by this service"))
+		mockUserServiceRepo.On("DeleteUserServiceByServiceIdAndUserId", "1", "2").Return(nil)
+		mockWorkflowRepo.On("SuspendWorkflowsByOwnerIdAndServiceId", "1", "2", reason).Return(0, nil)
+
+		disconnection, err := userServiceService.DisconnectService("test@test.com", "basic", "Google")
+
+		require.NoError(test, err)
+		require.Equal(test, entities.ServiceDisconnection{}, disconnection)
+		mockServiceService.AssertNotCalled(test, "ExecuteRequest", mock.Anything)
+	})
+
+	test.Run("Revocation failed", func(test *testing.T) {
+		userServiceService, mockUserRepo, mockServiceRepo, mockUserServiceRepo, mockWorkflowRepo, mockServiceService := newDisconnectService()
+
+		mockRequest := &http.Request{}
+		mockConnector := new(MockConnector)
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").Return(user, nil)
+		mockServiceRepo.On("FindServiceByName", "Google").Return(foundService, nil)
+		mockUserServiceRepo.On("FindUserServiceByServiceIdandUserId", "1", "2").Return(userService, nil)
+		mockServiceService.On("FindConnector", "Google").Return(mockConnector, nil)
+		mockConnector.On("RevokeTokenRequest", "accessToken", "refreshToken").Return(mockRequest, nil)
+		mockServiceService.On("ExecuteRequest", mockRequest).Return((*http.Response)(nil), entities.ApiCallError{StatusCode: http.StatusBadRequest})
+		mockUserServiceRepo.On("DeleteUserServiceByServiceIdAndUserId", "1", "2").Return(nil)
+		mockWorkflowRepo.On("SuspendWorkflowsByOwnerIdAndServiceId", "1", "2", reason).Return(1, nil)
+
+		disconnection, err := userServiceService.DisconnectService("test@test.com", "basic", "Google")
+
+		require.NoError(test, err)
+		require.Equal(test, 1, disconnection.DeactivatedWorkflows)
+		require.False(test, disconnection.TokenRevoked)
+		require.Equal(test, describeCallError(entities.ApiCallError{StatusCode: http.StatusBadRequest}), disconnection.RevocationError)
+		mockUserServiceRepo.AssertCalled(test, "DeleteUserServiceByServiceIdAndUserId", "1", "2")
+	})
+
+	test.Run("User not found", func(test *testing.T) {
+		userServiceService, mockUserRepo, _, _, _, _ := newDisconnectService()
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").Return(entities.User{}, errors.New("user not found"))
+
+		_, err := userServiceService.DisconnectService("test@test.com", "basic", "Google")
+
+		require.EqualError(test, err, "Could not find requested user")
+	})
+
+	test.Run("Unknown service", func(test *testing.T) {
+		userServiceService, mockUserRepo, mockServiceRepo, _, _, _ := newDisconnectService()
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").Return(user, nil)
+		mockServiceRepo.On("FindServiceByName", "Unknown").Return(entities.Service{}, errors.New("service not found"))
+
+		_, err := userServiceService.DisconnectService("test@test.com", "basic", "Unknown")
+
+		require.EqualError(test, err, "Unknown service")
+	})
+
+	test.Run("Service not connected", func(test *testing.T) {
+		userServiceService, mockUserRepo, mockServiceRepo, mockUserServiceRepo, _, mockServiceService := newDisconnectService()
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").Return(user, nil)
+		mockServiceRepo.On("FindServiceByName", "Google").Return(foundService, nil)
+		mockUserServiceRepo.On("FindUserServiceByServiceIdandUserId", "1", "2").Return(entities.UserService{}, errors.New("user service not found"))
+
+		_, err := userServiceService.DisconnectService("test@test.com", "basic", "Google")
+
+		require.EqualError(test, err, serviceNotConnectedMessage)
+		mockServiceService.AssertNotCalled(test, "FindConnector", mock.Anything)
+	})
+
+	test.Run("Fail delete", func(test *testing.T) {
+		userServiceService, mockUserRepo, mockServiceRepo, mockUserServiceRepo, mockWorkflowRepo, mockServiceService := newDisconnectService()
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").Return(user, nil)
+		mockServiceRepo.On("FindServiceByName", "Google").Return(foundService, nil)
+		mockUserServiceRepo.On("FindUserServiceByServiceIdandUserId", "1", "2").Return(userService, nil)
+		mockServiceService.On("FindConnector", "Google").Return(nil, errors.New("Unknown service"))
+		mockUserServiceRepo.On("DeleteUserServiceByServiceIdAndUserId", "1", "2").Return(errors.New("Fail delete"))
+
+		_, err := userServiceService.DisconnectService("test@test.com", "basic", "Google")
+
+		require.EqualError(test, err, "Fail delete")
+		mockWorkflowRepo.AssertNotCalled(test, "SuspendWorkflowsByOwnerIdAndServiceId", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
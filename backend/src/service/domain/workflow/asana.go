@@ -105,12 +105,7 @@ func (self *WorkflowService) createProjectAsana(accessToken string, workflow ent
 	return nil
 }
 
-func (self *WorkflowService) checkAsanaReactions(workflow entities.Workflow) error {
-	reactionFound, errReaction := self.ReactionRepository.FindReactionById(workflow.ReactionId)
-	if errReaction != nil {
-		return fmt.Errorf(errorRetrievingReaction)
-	}
-
+func (self *WorkflowService) CheckAsanaReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
 	accessToken, err := self.refreshTokenForService("Asana", reactionFound.Name, asanaReactions(), workflow)
 	if err != nil {
 		return fmt.Errorf(errorUpdatingToken)
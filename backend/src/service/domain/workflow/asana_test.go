@@ -1,7 +1,6 @@
 package workflow_service
 
 import (
-	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -39,40 +38,15 @@ func TestCreateProjectAsana(test *testing.T) {
 
 func TestCheckAsanaReactions(test *testing.T) {
 	test.Run("Success", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		asana := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
+		asana := &WorkflowService{}
 
 		workflow := entities.Workflow{
 			ReactionId: "1",
 		}
 
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, nil)
-
-		err := asana.checkAsanaReactions(workflow)
+		err := asana.CheckAsanaReactions(workflow, entities.Reaction{})
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Fail Find Reaction", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		asana := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		workflow := entities.Workflow{
-			ReactionId: "1",
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, errors.New(errorRetrievingReaction))
-
-		err := asana.checkAsanaReactions(workflow)
-
-		require.EqualError(test, err, errorRetrievingReaction)
-	})
 }
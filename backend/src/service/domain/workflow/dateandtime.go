@@ -1,11 +1,117 @@
 package workflow_service
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
 
 	"backend/src/entities"
 )
 
+// Timezone of the workflows whose owner has not chosen one
+const defaultTimezone = "Europe/Paris"
+const formattedTimeLayout = "02.01.2006 15:04:05"
+const atDateAndTimeLayout = "2006-01-02 15:04"
+
+const errorInvalidCronExpression = "Invalid cron expression, expected 5 fields: minute hour day-of-month month day-of-week"
+const errorInvalidMinutes = "Invalid number of minutes, expected a positive integer"
+const errorInvalidDateAndTime = "Invalid date and time, expected YYYY-MM-DD HH:MM"
+const errorDateAndTimeInPast = "Invalid date and time, expected a date in the future"
+
+const timeAndDateSchedulerName = "Time & Date"
+
+// Minutes missed for longer than this are not evaluated anymore after a downtime
+const maxCatchUpWindow = 24 * time.Hour
+
+// Standard cron expressions only, without seconds nor descriptors such as @daily
+var cronScheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+func loadTimezone(timezone string) *time.Location {
+	location, err := time.LoadLocation(timezone)
+	if timezone != "" && err == nil {
+		return location
+	}
+
+	location, err = time.LoadLocation(defaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// The week days go from 1 for Monday to 7 for Sunday
+func newTimeResponse(now time.Time) entities.TimeResponse {
+	weekDay := int(now.Weekday())
+	if weekDay == 0 {
+		weekDay = 7
+	}
+
+	return entities.TimeResponse{
+		Timezone:  now.Location().String(),
+		Formatted: now.Format(formattedTimeLayout),
+		Timestamp: int(now.Unix()),
+		WeekDay:   weekDay,
+		Day:       now.Day(),
+		Month:     int(now.Month()),
+		Year:      now.Year(),
+		Hour:      now.Hour(),
+		Minute:    now.Minute(),
+	}
+}
+
+// The minutes following the last evaluated tick up to the current one, which comes last.
+// Nothing is left to evaluate when the current minute has already been evaluated.
+func newSchedulerTicks(now, lastTick time.Time, lastTickExists bool) []time.Time {
+	currentTick := now.Truncate(time.Minute)
+	if !lastTickExists {
+		return []time.Time{currentTick}
+	}
+
+	firstTick := lastTick.Truncate(time.Minute).Add(time.Minute)
+	oldestTick := currentTick.Add(-maxCatchUpWindow)
+	if firstTick.Before(oldestTick) {
+		firstTick = oldestTick
+	}
+
+	var ticks []time.Time
+	for tick := firstTick; !tick.After(currentTick); tick = tick.Add(time.Minute) {
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
+
+func (self *WorkflowService) findSchedulerTicks(now time.Time) []time.Time {
+	lastTick, err := self.SchedulerTickRepository.FindLastTick(timeAndDateSchedulerName)
+	return newSchedulerTicks(now, lastTick, err == nil)
+}
+
+func (self *WorkflowService) getOwnerTimeResponses(ticks []time.Time, ownerId string, ownerTimeResponses map[string][]entities.TimeResponse) []entities.TimeResponse {
+	timeResponses, timeResponsesExist := ownerTimeResponses[ownerId]
+	if timeResponsesExist {
+		return timeResponses
+	}
+
+	location := loadTimezone(self.findOwnerTimezone(ownerId))
+	for _, tick := range ticks {
+		timeResponses = append(timeResponses, newTimeResponse(tick.In(location)))
+	}
+	ownerTimeResponses[ownerId] = timeResponses
+	return timeResponses
+}
+
+// An owner who cannot be found gets the default timezone
+func (self *WorkflowService) findOwnerTimezone(ownerId string) string {
+	owner, err := self.UserRepository.FindUserById(ownerId)
+	if err != nil {
+		return ""
+	}
+	return owner.Timezone
+}
+
 func checkEveryHourParamValidity(minute interface{}) (float64, error) {
 	minuteNumber, minuteIsNumber := minute.(float64)
 	if !minuteIsNumber {
@@ -66,111 +172,291 @@ func checkEveryYearParamValidity(month interface{}, day interface{}, hour interf
 	return monthNumber, dayNumber, hourNumber, minuteNumber, nil
 }
 
-func (self *WorkflowService) checkTimeAndDateEveryHourAction(timeRes entities.TimeResponse, workflow entities.Workflow) error {
+func checkCronScheduleParamValidity(expression interface{}) (cron.Schedule, error) {
+	expressionString, expressionIsString := expression.(string)
+	if !expressionIsString {
+		return nil, fmt.Errorf(errorMissingField)
+	}
+
+	schedule, err := cronScheduleParser.Parse(strings.TrimSpace(expressionString))
+	if err != nil {
+		return nil, fmt.Errorf(errorInvalidCronExpression)
+	}
+	return schedule, nil
+}
+
+func checkEveryNMinutesParamValidity(minutes interface{}) (float64, error) {
+	minutesNumber, minutesIsNumber := minutes.(float64)
+	if !minutesIsNumber {
+		return minutesNumber, fmt.Errorf(errorMissingField)
+	}
+	if minutesNumber < 1 || minutesNumber != math.Trunc(minutesNumber) {
+		return minutesNumber, fmt.Errorf(errorInvalidMinutes)
+	}
+	return minutesNumber, nil
+}
+
+func checkAtDateAndTimeParamValidity(date interface{}, location *time.Location) (time.Time, error) {
+	dateString, dateIsString := date.(string)
+	if !dateIsString {
+		return time.Time{}, fmt.Errorf(errorMissingField)
+	}
+
+	dateTime, err := time.ParseInLocation(atDateAndTimeLayout, strings.TrimSpace(dateString), location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(errorInvalidDateAndTime)
+	}
+	return dateTime, nil
+}
+
+// Checks the parameters of the scheduling actions when a workflow is saved, the others are checked when polled
+// The date of "At date and time" is in the timezone of the owner and must not be passed, it would never fire
+func checkTimeAndDateActionParams(actionName string, actionParam map[string]interface{}, timezone string) error {
+	var dateTime time.Time
+	var err error
+
+	switch actionName {
+	case "Cron schedule":
+		_, err = checkCronScheduleParamValidity(actionParam["expression"])
+	case "Every N minutes":
+		_, err = checkEveryNMinutesParamValidity(actionParam["minutes"])
+	case "At date and time":
+		dateTime, err = checkAtDateAndTimeParamValidity(actionParam["date"], loadTimezone(timezone))
+		if err == nil && !dateTime.After(time.Now()) {
+			err = fmt.Errorf(errorDateAndTimeInPast)
+		}
+	}
+	if err != nil {
+		return entities.WorkflowValidationError{Message: err.Error()}
+	}
+	return nil
+}
+
+func timeResponseToTime(timeRes entities.TimeResponse) time.Time {
+	return time.Unix(int64(timeRes.Timestamp), 0).In(loadTimezone(timeRes.Timezone))
+}
+
+func matchTimeAndDateEveryHourAction(timeRes entities.TimeResponse, workflow entities.Workflow) (bool, error) {
 	minute, minuteExists := workflow.ActionParam["minute"]
 	if !minuteExists {
-		return fmt.Errorf(errorMissingField)
+		return false, fmt.Errorf(errorMissingField)
 	}
 
 	minuteNumber, err := checkEveryHourParamValidity(minute)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	if int(minuteNumber) == timeRes.Minute {
-		self.checkReactions(workflow)
-	}
-	return nil
+	return int(minuteNumber) == timeRes.Minute, nil
 }
 
-func (self *WorkflowService) checkTimeAndDateEveryDayAction(timeRes entities.TimeResponse, workflow entities.Workflow) error {
+func matchTimeAndDateEveryDayAction(timeRes entities.TimeResponse, workflow entities.Workflow) (bool, error) {
 	hour, hourExists := workflow.ActionParam["hour"]
 	minute, minuteExists := workflow.ActionParam["minute"]
 	if !hourExists || !minuteExists {
-		return fmt.Errorf(errorMissingField)
+		return false, fmt.Errorf(errorMissingField)
 	}
 
 	hourNumber, minuteNumber, err := checkEveryDayParamValidity(hour, minute)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	if int(hourNumber) == timeRes.Hour && int(minuteNumber) == timeRes.Minute {
-		self.checkReactions(workflow)
-	}
-	return nil
+	return int(hourNumber) == timeRes.Hour && int(minuteNumber) == timeRes.Minute, nil
 }
 
-func (self *WorkflowService) checkTimeAndDateEveryWeekAtAction(timeRes entities.TimeResponse, workflow entities.Workflow) error {
+func matchTimeAndDateEveryWeekAtAction(timeRes entities.TimeResponse, workflow entities.Workflow) (bool, error) {
 	days, dayExists := workflow.ActionParam["day"]
 	hour, hourExists := workflow.ActionParam["hour"]
 	minute, minuteExists := workflow.ActionParam["minute"]
 	if !minuteExists || !hourExists || !dayExists {
-		return fmt.Errorf(errorMissingField)
+		return false, fmt.Errorf(errorMissingField)
 	}
 
 	dayArray, ok := days.([]interface{})
 	if !ok {
-		return fmt.Errorf("Error parsing the day array")
+		return false, fmt.Errorf("Error parsing the day array")
 	}
 
 	for _, day := range dayArray {
 		dayNumber, hourNumber, minuteNumber, err := checkEveryWeekAtParamValidity(day, hour, minute)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		if int(dayNumber) == timeRes.WeekDay && int(hourNumber) == timeRes.Hour &&
 			int(minuteNumber) == timeRes.Minute {
-			self.checkReactions(workflow)
+			return true, nil
 		}
 	}
-
-	return nil
+	return false, nil
 }
 
-func (self *WorkflowService) checkTimeAndDateEveryMonthOnTheAction(timeRes entities.TimeResponse, workflow entities.Workflow) error {
+func matchTimeAndDateEveryMonthOnTheAction(timeRes entities.TimeResponse, workflow entities.Workflow) (bool, error) {
 	day, dayExists := workflow.ActionParam["day"]
 	hour, hourExists := workflow.ActionParam["hour"]
 	minute, minuteExists := workflow.ActionParam["minute"]
 	if !minuteExists || !hourExists || !dayExists {
-		return fmt.Errorf(errorMissingField)
+		return false, fmt.Errorf(errorMissingField)
 	}
 
 	dayNumber, hourNumber, minuteNumber, err := checkEveryMonthOnTheParamValidity(day, hour, minute)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	if int(dayNumber) == timeRes.Day && int(hourNumber) == timeRes.Hour &&
-		int(minuteNumber) == timeRes.Minute {
-		self.checkReactions(workflow)
-	}
-	return nil
+	return int(dayNumber) == timeRes.Day && int(hourNumber) == timeRes.Hour &&
+		int(minuteNumber) == timeRes.Minute, nil
 }
 
-func (self *WorkflowService) checkTimeAndDateEveryYearOnAction(timeRes entities.TimeResponse, workflow entities.Workflow) error {
+func matchTimeAndDateEveryYearOnAction(timeRes entities.TimeResponse, workflow entities.Workflow) (bool, error) {
 	month, monthExists := workflow.ActionParam["month"]
 	day, dayExists := workflow.ActionParam["day"]
 	hour, hourExists := workflow.ActionParam["hour"]
 	minute, minuteExists := workflow.ActionParam["minute"]
 	if !minuteExists || !hourExists || !dayExists || !monthExists {
-		return fmt.Errorf(errorMissingField)
+		return false, fmt.Errorf(errorMissingField)
 	}
 
 	monthNumber, dayNumber, hourNumber, minuteNumber, err := checkEveryYearParamValidity(month, day, hour, minute)
 	if err != nil {
+		return false, err
+	}
+
+	return int(monthNumber) == timeRes.Month && int(dayNumber) == timeRes.Day && int(hourNumber) == timeRes.Hour &&
+		int(minuteNumber) == timeRes.Minute, nil
+}
+
+// Matches when the minute is a time of the schedule, in the timezone of the owner
+func matchTimeAndDateCronScheduleAction(timeRes entities.TimeResponse, workflow entities.Workflow) (bool, error) {
+	schedule, err := checkCronScheduleParamValidity(workflow.ActionParam["expression"])
+	if err != nil {
+		return false, err
+	}
+
+	minute := timeResponseToTime(timeRes).Truncate(time.Minute)
+	return schedule.Next(minute.Add(-time.Second)).Equal(minute), nil
+}
+
+// The minute of the last run is kept in the action data, the first evaluation only starts counting
+func matchTimeAndDateEveryNMinutesAction(timeRes entities.TimeResponse, workflow *entities.Workflow) (bool, error) {
+	minutesNumber, err := checkEveryNMinutesParamValidity(workflow.ActionParam["minutes"])
+	if err != nil {
+		return false, err
+	}
+
+	minute := float64(timeRes.Timestamp / 60)
+	lastRunMinute, lastRunExists := workflow.ActionData["lastrunminute"].(float64)
+	if lastRunExists && minute-lastRunMinute < minutesNumber {
+		return false, nil
+	}
+
+	if workflow.ActionData == nil {
+		workflow.ActionData = make(map[string]interface{})
+	}
+	workflow.ActionData["lastrunminute"] = minute
+	return lastRunExists, nil
+}
+
+// One-shot action, the workflow is deactivated when it matches so that it never fires twice
+func matchTimeAndDateAtDateAndTimeAction(timeRes entities.TimeResponse, workflow *entities.Workflow) (bool, error) {
+	minute := timeResponseToTime(timeRes).Truncate(time.Minute)
+	dateTime, err := checkAtDateAndTimeParamValidity(workflow.ActionParam["date"], minute.Location())
+	if err != nil {
+		return false, err
+	}
+
+	if !workflow.IsActivated || !dateTime.Equal(minute) {
+		return false, nil
+	}
+
+	workflow.IsActivated = false
+	return true, nil
+}
+
+// Returns whether the action matches the minute, and whether the state of the workflow has to be saved
+func matchTimeAndDateAction(actionName string, timeRes entities.TimeResponse, workflow *entities.Workflow) (bool, bool, error) {
+	var matched bool
+	var err error
+
+	switch actionName {
+	case "Every day at":
+		matched, err = matchTimeAndDateEveryDayAction(timeRes, *workflow)
+	case "Every hour at":
+		matched, err = matchTimeAndDateEveryHourAction(timeRes, *workflow)
+	case "Every day of the week at":
+		matched, err = matchTimeAndDateEveryWeekAtAction(timeRes, *workflow)
+	case "Every month on the":
+		matched, err = matchTimeAndDateEveryMonthOnTheAction(timeRes, *workflow)
+	case "Every year on":
+		matched, err = matchTimeAndDateEveryYearOnAction(timeRes, *workflow)
+	case "Cron schedule":
+		matched, err = matchTimeAndDateCronScheduleAction(timeRes, *workflow)
+	case "Every N minutes":
+		_, lastRunExists := workflow.ActionData["lastrunminute"]
+		matched, err = matchTimeAndDateEveryNMinutesAction(timeRes, workflow)
+		return matched, err == nil && (matched || !lastRunExists), err
+	case "At date and time":
+		matched, err = matchTimeAndDateAtDateAndTimeAction(timeRes, workflow)
+		return matched, matched, err
+	}
+	return matched, false, err
+}
+
+// Only the state of the action is saved, a one-shot workflow which fired is deactivated without a suspension reason
+func (self *WorkflowService) saveTimeAndDateWorkflowState(workflow entities.Workflow) error {
+	if !workflow.IsActivated {
+		_, err := self.WorkflowRepository.SuspendWorkflow(workflow.Id, "")
 		return err
 	}
+	return self.WorkflowRepository.UpdateWorkflowActionData(workflow.Id, workflow.ActionData)
+}
+
+// The minutes missed since the last evaluated tick come before the current one, they are fired
+// according to the catch-up policy of the workflow: once for the whole window, once per minute, or not at all
+func (self *WorkflowService) checkTimeAndDateWorkflow(actionName string, workflow entities.Workflow, timeResponses []entities.TimeResponse) error {
+	var missedTimeResponses, firedTimeResponses []entities.TimeResponse
+	workflowUpdated := false
+
+	for i, timeRes := range timeResponses {
+		isCurrentTick := i == len(timeResponses)-1
+		if !isCurrentTick && workflow.CatchUpPolicy == entities.CatchUpPolicySkip {
+			continue
+		}
+
+		matched, updated, err := matchTimeAndDateAction(actionName, timeRes, &workflow)
+		if err != nil {
+			return err
+		}
+		workflowUpdated = workflowUpdated || updated
+
+		if matched && isCurrentTick {
+			firedTimeResponses = append(firedTimeResponses, timeRes)
+		} else if matched {
+			missedTimeResponses = append(missedTimeResponses, timeRes)
+		}
+	}
 
-	if int(monthNumber) == timeRes.Month && int(dayNumber) == timeRes.Day && int(hourNumber) == timeRes.Hour &&
-		int(minuteNumber) == timeRes.Minute {
-		self.checkReactions(workflow)
+	if workflow.CatchUpPolicy != entities.CatchUpPolicyAll && len(missedTimeResponses) > 1 {
+		missedTimeResponses = missedTimeResponses[len(missedTimeResponses)-1:]
+	}
+	firedTimeResponses = append(missedTimeResponses, firedTimeResponses...)
+
+	// The state is saved before running the reactions so that a one-shot workflow never fires twice
+	if workflowUpdated {
+		err := self.saveTimeAndDateWorkflowState(workflow)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, timeRes := range firedTimeResponses {
+		self.checkReactions(workflow, newActionEvent("time", timeRes))
 	}
 	return nil
 }
 
-func (self *WorkflowService) checkWorkflowsWithTimeAndDateActions(timeRes entities.TimeResponse, action entities.Action) error {
+func (self *WorkflowService) checkWorkflowsWithTimeAndDateActions(ticks []time.Time, action entities.Action, ownerTimeResponses map[string][]entities.TimeResponse) error {
 	allWorkflowsFound, errWorkflow := self.WorkflowRepository.FindWorkflowsByActionId(action.Id)
 	if errWorkflow != nil {
 		return errWorkflow
@@ -181,29 +467,15 @@ func (self *WorkflowService) checkWorkflowsWithTimeAndDateActions(timeRes entiti
 			continue
 		}
 
-		switch action.Name {
-		case "Every day at":
-			self.checkTimeAndDateEveryDayAction(timeRes, workflow)
-		case "Every hour at":
-			self.checkTimeAndDateEveryHourAction(timeRes, workflow)
-		case "Every day of the week at":
-			self.checkTimeAndDateEveryWeekAtAction(timeRes, workflow)
-		case "Every month on the":
-			self.checkTimeAndDateEveryMonthOnTheAction(timeRes, workflow)
-		case "Every year on":
-			self.checkTimeAndDateEveryYearOnAction(timeRes, workflow)
-		}
+		timeResponses := self.getOwnerTimeResponses(ticks, workflow.OwnerId, ownerTimeResponses)
+		self.checkTimeAndDateWorkflow(action.Name, workflow, timeResponses)
 	}
 	return nil
 }
 
-func (self *WorkflowService) CheckTimeAndDateActions() error {
-	var timeRes entities.TimeResponse
-
-	timeRes, errRequest := self.ServiceService.RequestToTimeApi()
-	if errRequest != nil {
-		return errRequest
-	}
+// The last evaluated tick is saved even when an action fails, the minutes are not evaluated twice
+func (self *WorkflowService) CheckTimeAndDateActions(ctx context.Context) error {
+	ownerTimeResponses := make(map[string][]entities.TimeResponse)
 
 	serviceFound, errFindingService := self.ServiceService.FindServiceByName("Time & Date")
 	if errFindingService != nil {
@@ -215,11 +487,22 @@ func (self *WorkflowService) CheckTimeAndDateActions() error {
 		return errFindingActions
 	}
 
+	ticks := self.findSchedulerTicks(time.Now())
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	var errCheckActions error
 	for _, action := range allActionsFound {
-		errCheckName := self.checkWorkflowsWithTimeAndDateActions(timeRes, action)
-		if errCheckName != nil {
-			return errCheckName
+		errCheckName := self.checkWorkflowsWithTimeAndDateActions(ticks, action, ownerTimeResponses)
+		if errCheckName != nil && errCheckActions == nil {
+			errCheckActions = errCheckName
 		}
 	}
-	return nil
+
+	errUpdateTick := self.SchedulerTickRepository.UpdateLastTick(timeAndDateSchedulerName, ticks[len(ticks)-1])
+	if errUpdateTick != nil {
+		return errUpdateTick
+	}
+	return errCheckActions
 }
@@ -1,9 +1,13 @@
 package workflow_service
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"backend/src/entities"
@@ -93,10 +97,25 @@ func TestCheckEveryYearParamValidity(test *testing.T) {
 	})
 }
 
-func TestCheckTimeAndDateEveryHourAction(test *testing.T) {
-	test.Run("Success", func(test *testing.T) {
-		timeDate := &WorkflowService{}
+func TestMatchTimeAndDateEveryHourAction(test *testing.T) {
+	test.Run("Matching Minute", func(test *testing.T) {
+		time := entities.TimeResponse{
+			Minute: 1,
+		}
 
+		workflow := entities.Workflow{
+			ActionParam: map[string]interface{}{
+				"minute": 1.0,
+			},
+		}
+
+		matched, err := matchTimeAndDateEveryHourAction(time, workflow)
+
+		require.NoError(test, err)
+		require.True(test, matched)
+	})
+
+	test.Run("Success", func(test *testing.T) {
 		time := entities.TimeResponse{
 			Minute: 2,
 		}
@@ -107,14 +126,13 @@ func TestCheckTimeAndDateEveryHourAction(test *testing.T) {
 			},
 		}
 
-		err := timeDate.checkTimeAndDateEveryHourAction(time, workflow)
+		matched, err := matchTimeAndDateEveryHourAction(time, workflow)
 
 		require.NoError(test, err)
+		require.False(test, matched)
 	})
 
 	test.Run("Missing Field", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		time := entities.TimeResponse{
 			Minute: 1.0,
 		}
@@ -124,14 +142,12 @@ func TestCheckTimeAndDateEveryHourAction(test *testing.T) {
 			IsActivated: false,
 		}
 
-		err := timeDate.checkTimeAndDateEveryHourAction(time, workflow)
+		_, err := matchTimeAndDateEveryHourAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 
 	test.Run("Invalid Param", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		time := entities.TimeResponse{
 			Minute: 1,
 		}
@@ -143,16 +159,14 @@ func TestCheckTimeAndDateEveryHourAction(test *testing.T) {
 			IsActivated: false,
 		}
 
-		err := timeDate.checkTimeAndDateEveryHourAction(time, workflow)
+		_, err := matchTimeAndDateEveryHourAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 }
 
-func TestCheckTimeAndDateEveryDayAction(test *testing.T) {
+func TestMatchTimeAndDateEveryDayAction(test *testing.T) {
 	test.Run("Success", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"hour":   1.0,
@@ -165,14 +179,13 @@ func TestCheckTimeAndDateEveryDayAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryDayAction(time, workflow)
+		matched, err := matchTimeAndDateEveryDayAction(time, workflow)
 
 		require.NoError(test, err)
+		require.False(test, matched)
 	})
 
 	test.Run("Missing Field", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{},
 		}
@@ -182,14 +195,12 @@ func TestCheckTimeAndDateEveryDayAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryDayAction(time, workflow)
+		_, err := matchTimeAndDateEveryDayAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 
 	test.Run("Invalid Param", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"hour":   "1.0",
@@ -202,16 +213,14 @@ func TestCheckTimeAndDateEveryDayAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryDayAction(time, workflow)
+		_, err := matchTimeAndDateEveryDayAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 }
 
-func TestCheckTimeAndDateEveryWeekAtAction(test *testing.T) {
+func TestMatchTimeAndDateEveryWeekAtAction(test *testing.T) {
 	test.Run("Success", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"day":    []interface{}{float64(1)},
@@ -226,14 +235,13 @@ func TestCheckTimeAndDateEveryWeekAtAction(test *testing.T) {
 			Minute:  2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryWeekAtAction(time, workflow)
+		matched, err := matchTimeAndDateEveryWeekAtAction(time, workflow)
 
 		require.NoError(test, err)
+		require.False(test, matched)
 	})
 
 	test.Run("Fail Parsing Day", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"day":    1,
@@ -248,14 +256,12 @@ func TestCheckTimeAndDateEveryWeekAtAction(test *testing.T) {
 			Minute:  2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryWeekAtAction(time, workflow)
+		_, err := matchTimeAndDateEveryWeekAtAction(time, workflow)
 
 		require.EqualError(test, err, "Error parsing the day array")
 	})
 
 	test.Run("Missing Field", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{},
 		}
@@ -265,14 +271,12 @@ func TestCheckTimeAndDateEveryWeekAtAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryWeekAtAction(time, workflow)
+		_, err := matchTimeAndDateEveryWeekAtAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 
 	test.Run("Invalid Param", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"day":    []interface{}{float64(1)},
@@ -286,16 +290,14 @@ func TestCheckTimeAndDateEveryWeekAtAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryWeekAtAction(time, workflow)
+		_, err := matchTimeAndDateEveryWeekAtAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 }
 
-func TestCheckTimeAndDateEveryMonthOnTheAction(test *testing.T) {
+func TestMatchTimeAndDateEveryMonthOnTheAction(test *testing.T) {
 	test.Run("Success", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"day":    1.0,
@@ -310,14 +312,13 @@ func TestCheckTimeAndDateEveryMonthOnTheAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryMonthOnTheAction(time, workflow)
+		matched, err := matchTimeAndDateEveryMonthOnTheAction(time, workflow)
 
 		require.NoError(test, err)
+		require.False(test, matched)
 	})
 
 	test.Run("Missing Field", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{},
 		}
@@ -328,14 +329,12 @@ func TestCheckTimeAndDateEveryMonthOnTheAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryMonthOnTheAction(time, workflow)
+		_, err := matchTimeAndDateEveryMonthOnTheAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 
 	test.Run("Invalid Param", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"day":    "1.0",
@@ -350,16 +349,14 @@ func TestCheckTimeAndDateEveryMonthOnTheAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryMonthOnTheAction(time, workflow)
+		_, err := matchTimeAndDateEveryMonthOnTheAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 }
 
-func TestCheckTimeAndDateEveryYearOnAction(test *testing.T) {
+func TestMatchTimeAndDateEveryYearOnAction(test *testing.T) {
 	test.Run("Success", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"month":  1.0,
@@ -376,14 +373,13 @@ func TestCheckTimeAndDateEveryYearOnAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryYearOnAction(time, workflow)
+		matched, err := matchTimeAndDateEveryYearOnAction(time, workflow)
 
 		require.NoError(test, err)
+		require.False(test, matched)
 	})
 
 	test.Run("Missing Field", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{},
 		}
@@ -395,14 +391,12 @@ func TestCheckTimeAndDateEveryYearOnAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryYearOnAction(time, workflow)
+		_, err := matchTimeAndDateEveryYearOnAction(time, workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 
 	test.Run("Invalid Param", func(test *testing.T) {
-		timeDate := &WorkflowService{}
-
 		workflow := entities.Workflow{
 			ActionParam: map[string]interface{}{
 				"month":  "1.0",
@@ -419,9 +413,422 @@ func TestCheckTimeAndDateEveryYearOnAction(test *testing.T) {
 			Minute: 2,
 		}
 
-		err := timeDate.checkTimeAndDateEveryYearOnAction(time, workflow)
+		_, err := matchTimeAndDateEveryYearOnAction(time, workflow)
+
+		require.EqualError(test, err, errorMissingField)
+	})
+}
+
+func TestCheckCronScheduleParamValidity(test *testing.T) {
+	test.Run("Success", func(test *testing.T) {
+		schedule, err := checkCronScheduleParamValidity("*/15 9-17 * * 1-5")
+
+		require.NoError(test, err)
+		require.NotNil(test, schedule)
+	})
+
+	test.Run("Invalid Type", func(test *testing.T) {
+		_, err := checkCronScheduleParamValidity(1.0)
+
+		require.EqualError(test, err, errorMissingField)
+	})
+
+	test.Run("Invalid Expressions", func(test *testing.T) {
+		for _, expression := range []string{"", "* * * *", "0 * * * * *", "@daily", "61 * * * *"} {
+			_, err := checkCronScheduleParamValidity(expression)
+
+			require.EqualError(test, err, errorInvalidCronExpression, expression)
+		}
+	})
+}
+
+func TestCheckEveryNMinutesParamValidity(test *testing.T) {
+	test.Run("Success", func(test *testing.T) {
+		minutes, err := checkEveryNMinutesParamValidity(5.0)
+
+		require.NoError(test, err)
+		require.Equal(test, 5.0, minutes)
+	})
+
+	test.Run("Invalid Type", func(test *testing.T) {
+		_, err := checkEveryNMinutesParamValidity("5")
+
+		require.EqualError(test, err, errorMissingField)
+	})
+
+	test.Run("Invalid Number", func(test *testing.T) {
+		for _, minutes := range []float64{0, -5, 1.5} {
+			_, err := checkEveryNMinutesParamValidity(minutes)
+
+			require.EqualError(test, err, errorInvalidMinutes)
+		}
+	})
+}
+
+func TestCheckAtDateAndTimeParamValidity(test *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+
+	test.Run("Success", func(test *testing.T) {
+		dateTime, err := checkAtDateAndTimeParamValidity("2024-03-01 09:30", location)
+
+		require.NoError(test, err)
+		require.Equal(test, time.Date(2024, time.March, 1, 9, 30, 0, 0, location), dateTime)
+	})
+
+	test.Run("Invalid Type", func(test *testing.T) {
+		_, err := checkAtDateAndTimeParamValidity(nil, location)
+
+		require.EqualError(test, err, errorMissingField)
+	})
+
+	test.Run("Invalid Date", func(test *testing.T) {
+		_, err := checkAtDateAndTimeParamValidity("01.03.2024 09:30", location)
+
+		require.EqualError(test, err, errorInvalidDateAndTime)
+	})
+}
+
+func TestCheckTimeAndDateActionParams(test *testing.T) {
+	test.Run("Other Action", func(test *testing.T) {
+		require.NoError(test, checkTimeAndDateActionParams("Every hour at", nil, ""))
+	})
+
+	test.Run("Valid Params", func(test *testing.T) {
+		tomorrow := time.Now().Add(24 * time.Hour).Format(atDateAndTimeLayout)
+
+		require.NoError(test, checkTimeAndDateActionParams("Cron schedule", map[string]interface{}{"expression": "0 8 * * *"}, ""))
+		require.NoError(test, checkTimeAndDateActionParams("Every N minutes", map[string]interface{}{"minutes": 10.0}, ""))
+		require.NoError(test, checkTimeAndDateActionParams("At date and time", map[string]interface{}{"date": tomorrow}, "America/New_York"))
+	})
+
+	test.Run("Invalid Params", func(test *testing.T) {
+		err := checkTimeAndDateActionParams("Every N minutes", map[string]interface{}{"minutes": 0.0}, "")
+
+		var validationError entities.WorkflowValidationError
+		require.True(test, errors.As(err, &validationError))
+		require.EqualError(test, err, errorInvalidMinutes)
+	})
+
+	test.Run("Date In The Past", func(test *testing.T) {
+		err := checkTimeAndDateActionParams("At date and time", map[string]interface{}{"date": "2024-03-01 09:30"}, "America/New_York")
+
+		var validationError entities.WorkflowValidationError
+		require.True(test, errors.As(err, &validationError))
+		require.EqualError(test, err, errorDateAndTimeInPast)
+	})
+}
+
+type MockSchedulerTickRepository struct {
+	mock.Mock
+}
+
+func (m *MockSchedulerTickRepository) FindLastTick(name string) (time.Time, error) {
+	args := m.Called(name)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockSchedulerTickRepository) UpdateLastTick(name string, tick time.Time) error {
+	args := m.Called(name, tick)
+	return args.Error(0)
+}
+
+func (m *MockSchedulerTickRepository) TryAcquireSchedulerLock(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSchedulerTickRepository) ReleaseSchedulerLock() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestMatchTimeAndDateCronScheduleAction(test *testing.T) {
+	location, _ := time.LoadLocation("Asia/Tokyo")
+	workflow := entities.Workflow{
+		ActionParam: map[string]interface{}{"expression": "30 9 * * 1-5"},
+	}
+
+	test.Run("Matching Minute", func(test *testing.T) {
+		timeRes := newTimeResponse(time.Date(2024, time.March, 1, 9, 30, 42, 0, location))
+
+		matched, err := matchTimeAndDateCronScheduleAction(timeRes, workflow)
+
+		require.NoError(test, err)
+		require.True(test, matched)
+	})
+
+	test.Run("Other Minute", func(test *testing.T) {
+		timeRes := newTimeResponse(time.Date(2024, time.March, 2, 9, 30, 0, 0, location))
+
+		matched, err := matchTimeAndDateCronScheduleAction(timeRes, workflow)
+
+		require.NoError(test, err)
+		require.False(test, matched)
+	})
+
+	test.Run("Invalid Param", func(test *testing.T) {
+		_, err := matchTimeAndDateCronScheduleAction(entities.TimeResponse{}, entities.Workflow{ActionParam: map[string]interface{}{}})
+
+		require.EqualError(test, err, errorMissingField)
+	})
+}
+
+func TestMatchTimeAndDateEveryNMinutesAction(test *testing.T) {
+	timeRes := newTimeResponse(time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC))
+	currentMinute := float64(timeRes.Timestamp / 60)
+
+	test.Run("First Evaluation", func(test *testing.T) {
+		workflow := entities.Workflow{ActionParam: map[string]interface{}{"minutes": 5.0}}
+
+		matched, err := matchTimeAndDateEveryNMinutesAction(timeRes, &workflow)
+
+		require.NoError(test, err)
+		require.False(test, matched)
+		require.Equal(test, currentMinute, workflow.ActionData["lastrunminute"])
+	})
+
+	test.Run("Not Elapsed", func(test *testing.T) {
+		workflow := entities.Workflow{
+			ActionParam: map[string]interface{}{"minutes": 5.0},
+			ActionData:  map[string]interface{}{"lastrunminute": currentMinute - 4},
+		}
+
+		matched, err := matchTimeAndDateEveryNMinutesAction(timeRes, &workflow)
+
+		require.NoError(test, err)
+		require.False(test, matched)
+		require.Equal(test, currentMinute-4, workflow.ActionData["lastrunminute"])
+	})
+
+	test.Run("Elapsed", func(test *testing.T) {
+		workflow := entities.Workflow{
+			ActionParam: map[string]interface{}{"minutes": 5.0},
+			ActionData:  map[string]interface{}{"lastrunminute": currentMinute - 5},
+		}
+
+		matched, err := matchTimeAndDateEveryNMinutesAction(timeRes, &workflow)
+
+		require.NoError(test, err)
+		require.True(test, matched)
+		require.Equal(test, currentMinute, workflow.ActionData["lastrunminute"])
+	})
+
+	test.Run("Invalid Param", func(test *testing.T) {
+		workflow := entities.Workflow{ActionParam: map[string]interface{}{"minutes": 0.0}}
+
+		_, err := matchTimeAndDateEveryNMinutesAction(timeRes, &workflow)
+
+		require.EqualError(test, err, errorInvalidMinutes)
+	})
+}
+
+func TestMatchTimeAndDateAtDateAndTimeAction(test *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+	newWorkflow := func() entities.Workflow {
+		return entities.Workflow{
+			IsActivated: true,
+			ActionParam: map[string]interface{}{"date": "2024-03-01 09:30"},
+		}
+	}
+
+	test.Run("Other Minute", func(test *testing.T) {
+		workflow := newWorkflow()
+		timeRes := newTimeResponse(time.Date(2024, time.March, 1, 9, 29, 0, 0, location))
+
+		matched, err := matchTimeAndDateAtDateAndTimeAction(timeRes, &workflow)
+
+		require.NoError(test, err)
+		require.False(test, matched)
+		require.True(test, workflow.IsActivated)
+	})
+
+	test.Run("Matches And Deactivates", func(test *testing.T) {
+		workflow := newWorkflow()
+		timeRes := newTimeResponse(time.Date(2024, time.March, 1, 9, 30, 20, 0, location))
+
+		matched, err := matchTimeAndDateAtDateAndTimeAction(timeRes, &workflow)
+
+		require.NoError(test, err)
+		require.True(test, matched)
+		require.False(test, workflow.IsActivated)
+
+		matched, _ = matchTimeAndDateAtDateAndTimeAction(timeRes, &workflow)
+		require.False(test, matched)
+	})
+
+	test.Run("Owner Timezone", func(test *testing.T) {
+		workflow := newWorkflow()
+		// 09:30 in Paris is still 03:30 in New York
+		paris, _ := time.LoadLocation("Europe/Paris")
+		timeRes := newTimeResponse(time.Date(2024, time.March, 1, 9, 30, 0, 0, paris).In(location))
+
+		matched, err := matchTimeAndDateAtDateAndTimeAction(timeRes, &workflow)
+
+		require.NoError(test, err)
+		require.False(test, matched)
+	})
+
+	test.Run("Invalid Param", func(test *testing.T) {
+		workflow := entities.Workflow{ActionParam: map[string]interface{}{"date": "tomorrow"}}
+
+		_, err := matchTimeAndDateAtDateAndTimeAction(newTimeResponse(time.Now()), &workflow)
+
+		require.EqualError(test, err, errorInvalidDateAndTime)
+	})
+}
+
+func TestNewSchedulerTicks(test *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 30, 42, 0, time.UTC)
+	currentTick := time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC)
+
+	test.Run("No Last Tick", func(test *testing.T) {
+		require.Equal(test, []time.Time{currentTick}, newSchedulerTicks(now, time.Time{}, false))
+	})
+
+	test.Run("Next Minute", func(test *testing.T) {
+		require.Equal(test, []time.Time{currentTick}, newSchedulerTicks(now, currentTick.Add(-time.Minute), true))
+	})
+
+	test.Run("Already Evaluated", func(test *testing.T) {
+		require.Empty(test, newSchedulerTicks(now, currentTick, true))
+	})
+
+	test.Run("Missed Minutes", func(test *testing.T) {
+		ticks := newSchedulerTicks(now, currentTick.Add(-3*time.Minute), true)
+
+		require.Equal(test, []time.Time{currentTick.Add(-2 * time.Minute), currentTick.Add(-time.Minute), currentTick}, ticks)
+	})
+
+	test.Run("Window Limit", func(test *testing.T) {
+		ticks := newSchedulerTicks(now, currentTick.Add(-7*24*time.Hour), true)
+
+		require.Len(test, ticks, int(maxCatchUpWindow/time.Minute)+1)
+		require.Equal(test, currentTick.Add(-maxCatchUpWindow), ticks[0])
+		require.Equal(test, currentTick, ticks[len(ticks)-1])
+	})
+}
+
+// The reactions of the workflow cannot be found, so each run of the workflow only records a failed run
+func newTimeAndDateFiringService() (*WorkflowService, *MockWorkflowRepository, *MockWorkflowRunRepository) {
+	mockWorkflowRepo := new(MockWorkflowRepository)
+	mockWorkflowReactionRepo := new(MockWorkflowReactionRepository)
+	mockWorkflowRunRepo := new(MockWorkflowRunRepository)
+
+	mockWorkflowReactionRepo.On("FindWorkflowReactionsByWorkflowId", "1").
+		Return([]entities.WorkflowReaction{}, errors.New("Fail find reactions"))
+	mockWorkflowRunRepo.On("CreateWorkflowRun", "1", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	mockWorkflowRepo.On("IncrementWorkflowFailures", "1").
+		Return(1, nil)
+
+	return &WorkflowService{
+		WorkflowRepository:         mockWorkflowRepo,
+		WorkflowReactionRepository: mockWorkflowReactionRepo,
+		WorkflowRunRepository:      mockWorkflowRunRepo,
+	}, mockWorkflowRepo, mockWorkflowRunRepo
+}
+
+func TestCheckTimeAndDateWorkflow(test *testing.T) {
+	// The server was down from 08:58 to 10:01, the 09:00 and 10:00 ticks were missed
+	var timeResponses []entities.TimeResponse
+	for tick := time.Date(2024, time.March, 1, 8, 59, 0, 0, time.UTC); tick.Hour() < 10 || tick.Minute() <= 1; tick = tick.Add(time.Minute) {
+		timeResponses = append(timeResponses, newTimeResponse(tick))
+	}
+	newWorkflow := func(catchUpPolicy string, minute float64) entities.Workflow {
+		return entities.Workflow{
+			Id:            "1",
+			IsActivated:   true,
+			CatchUpPolicy: catchUpPolicy,
+			ActionParam:   map[string]interface{}{"minute": minute},
+		}
+	}
+
+	policies := []struct {
+		catchUpPolicy string
+		minute        float64
+		runs          int
+	}{
+		{entities.CatchUpPolicyOnce, 0, 1},
+		{"", 0, 1},
+		{entities.CatchUpPolicyAll, 0, 2},
+		{entities.CatchUpPolicySkip, 0, 0},
+		{entities.CatchUpPolicySkip, 1, 1},
+		{entities.CatchUpPolicyOnce, 1, 2},
+		{entities.CatchUpPolicyAll, 1, 2},
+		{entities.CatchUpPolicyOnce, 30, 1},
+		{entities.CatchUpPolicyAll, 30, 1},
+	}
+
+	for _, policy := range policies {
+		service, _, mockWorkflowRunRepo := newTimeAndDateFiringService()
+
+		err := service.checkTimeAndDateWorkflow("Every hour at", newWorkflow(policy.catchUpPolicy, policy.minute), timeResponses)
+
+		require.NoError(test, err)
+		require.Len(test, mockWorkflowRunRepo.Calls, policy.runs, policy.catchUpPolicy)
+	}
+
+	test.Run("Missed One-Shot", func(test *testing.T) {
+		service, mockWorkflowRepo, mockWorkflowRunRepo := newTimeAndDateFiringService()
+		workflow := entities.Workflow{
+			Id:            "1",
+			IsActivated:   true,
+			CatchUpPolicy: entities.CatchUpPolicyAll,
+			ActionParam:   map[string]interface{}{"date": "2024-03-01 09:15"},
+		}
+
+		mockWorkflowRepo.On("SuspendWorkflow", "1", "").Return(true, nil).Once()
+
+		err := service.checkTimeAndDateWorkflow("At date and time", workflow, timeResponses)
+
+		require.NoError(test, err)
+		mockWorkflowRepo.AssertExpectations(test)
+		mockWorkflowRunRepo.AssertNumberOfCalls(test, "CreateWorkflowRun", 1)
+	})
+
+	test.Run("Every N Minutes State Saved Once", func(test *testing.T) {
+		service, mockWorkflowRepo, mockWorkflowRunRepo := newTimeAndDateFiringService()
+		lastRunMinute := float64(timeResponses[0].Timestamp/60) - 5
+		workflow := entities.Workflow{
+			Id:            "1",
+			IsActivated:   true,
+			CatchUpPolicy: entities.CatchUpPolicyAll,
+			ActionParam:   map[string]interface{}{"minutes": 20.0},
+			ActionData:    map[string]interface{}{"lastrunminute": lastRunMinute},
+		}
+
+		mockWorkflowRepo.On("UpdateWorkflowActionData", "1", mock.Anything).Return(nil).Once()
+
+		err := service.checkTimeAndDateWorkflow("Every N minutes", workflow, timeResponses)
+
+		require.NoError(test, err)
+		mockWorkflowRepo.AssertNumberOfCalls(test, "UpdateWorkflowActionData", 1)
+		mockWorkflowRunRepo.AssertNumberOfCalls(test, "CreateWorkflowRun", 3)
+	})
+
+	test.Run("Fail Update Workflow", func(test *testing.T) {
+		service, mockWorkflowRepo, mockWorkflowRunRepo := newTimeAndDateFiringService()
+		workflow := entities.Workflow{
+			Id:          "1",
+			IsActivated: true,
+			ActionParam: map[string]interface{}{"date": "2024-03-01 10:01"},
+		}
+
+		mockWorkflowRepo.On("SuspendWorkflow", "1", "").Return(false, errors.New("Fail update workflow")).Once()
+
+		err := service.checkTimeAndDateWorkflow("At date and time", workflow, timeResponses)
+
+		require.EqualError(test, err, "Fail update workflow")
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun")
+	})
+
+	test.Run("Invalid Param", func(test *testing.T) {
+		service, _, mockWorkflowRunRepo := newTimeAndDateFiringService()
+
+		err := service.checkTimeAndDateWorkflow("Every hour at", entities.Workflow{Id: "1"}, timeResponses)
 
 		require.EqualError(test, err, errorMissingField)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun")
 	})
 }
 
@@ -445,18 +852,23 @@ func TestCheckWorkflowsWithTimeAndDateActions(test *testing.T) {
 		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
 			Return(workflows, nil)
 
-		err := timeDate.checkWorkflowsWithTimeAndDateActions(entities.TimeResponse{}, action)
+		err := timeDate.checkWorkflowsWithTimeAndDateActions([]time.Time{time.Now()}, action, map[string][]entities.TimeResponse{})
 
 		require.NoError(test, err)
 	})
 
 	test.Run("Success Activated", func(test *testing.T) {
 		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockUserRepo := new(MockUserRepository)
 
 		timeDate := &WorkflowService{
 			WorkflowRepository: mockWorkflowRepo,
+			UserRepository:     mockUserRepo,
 		}
 
+		mockUserRepo.On("FindUserById", "").
+			Return(entities.User{}, errors.New("user not found"))
+
 		action := entities.Action{
 			Id:   "1",
 			Name: "test",
@@ -469,7 +881,7 @@ func TestCheckWorkflowsWithTimeAndDateActions(test *testing.T) {
 		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
 			Return(workflows, nil)
 
-		err := timeDate.checkWorkflowsWithTimeAndDateActions(entities.TimeResponse{}, action)
+		err := timeDate.checkWorkflowsWithTimeAndDateActions([]time.Time{time.Now()}, action, map[string][]entities.TimeResponse{})
 
 		require.NoError(test, err)
 	})
@@ -493,28 +905,13 @@ func TestCheckWorkflowsWithTimeAndDateActions(test *testing.T) {
 		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
 			Return(workflows, errors.New("Fail find workflows"))
 
-		err := timeDate.checkWorkflowsWithTimeAndDateActions(entities.TimeResponse{}, action)
+		err := timeDate.checkWorkflowsWithTimeAndDateActions([]time.Time{time.Now()}, action, map[string][]entities.TimeResponse{})
 
 		require.EqualError(test, err, "Fail find workflows")
 	})
 }
 
 func TestCheckTimeAndDateActions(test *testing.T) {
-	test.Run("Fail Request Time API", func(test *testing.T) {
-		mockServiceServiceRepo := new(MockServiceServiceRepository)
-
-		timeDate := &WorkflowService{
-			ServiceService: mockServiceServiceRepo,
-		}
-
-		mockServiceServiceRepo.On("RequestToTimeApi").
-			Return(entities.TimeResponse{}, errors.New("Fail time request"))
-
-		err := timeDate.CheckTimeAndDateActions()
-
-		require.EqualError(test, err, "Fail time request")
-	})
-
 	test.Run("Fail Find Service", func(test *testing.T) {
 		mockServiceServiceRepo := new(MockServiceServiceRepository)
 
@@ -522,13 +919,10 @@ func TestCheckTimeAndDateActions(test *testing.T) {
 			ServiceService: mockServiceServiceRepo,
 		}
 
-		mockServiceServiceRepo.On("RequestToTimeApi").
-			Return(entities.TimeResponse{}, nil)
-
 		mockServiceServiceRepo.On("FindServiceByName", "Time & Date").
 			Return(entities.Service{}, errors.New("Fail find service"))
 
-		err := timeDate.CheckTimeAndDateActions()
+		err := timeDate.CheckTimeAndDateActions(context.Background())
 
 		require.EqualError(test, err, "Fail find service")
 	})
@@ -546,44 +940,131 @@ func TestCheckTimeAndDateActions(test *testing.T) {
 			Id: "1",
 		}
 
-		mockServiceServiceRepo.On("RequestToTimeApi").
-			Return(entities.TimeResponse{}, nil)
-
 		mockServiceServiceRepo.On("FindServiceByName", "Time & Date").
 			Return(service, nil)
 
 		mockActionRepo.On("FindActionsByServiceId", service.Id).
 			Return([]entities.Action{}, errors.New("Fail find actions"))
 
-		err := timeDate.CheckTimeAndDateActions()
+		err := timeDate.CheckTimeAndDateActions(context.Background())
 
 		require.EqualError(test, err, "Fail find actions")
 	})
 
-	test.Run("Success", func(test *testing.T) {
+	newService := func(lastTick time.Time, errLastTick error) (*WorkflowService, *MockSchedulerTickRepository) {
 		mockServiceServiceRepo := new(MockServiceServiceRepository)
 		mockActionRepo := new(MockActionRepository)
+		mockSchedulerTickRepo := new(MockSchedulerTickRepository)
+
+		mockServiceServiceRepo.On("FindServiceByName", "Time & Date").
+			Return(entities.Service{Id: "1"}, nil)
+		mockActionRepo.On("FindActionsByServiceId", "1").
+			Return([]entities.Action{}, nil)
+		mockSchedulerTickRepo.On("FindLastTick", "Time & Date").
+			Return(lastTick, errLastTick)
+
+		return &WorkflowService{
+			ServiceService:          mockServiceServiceRepo,
+			ActionRepository:        mockActionRepo,
+			SchedulerTickRepository: mockSchedulerTickRepo,
+		}, mockSchedulerTickRepo
+	}
+
+	test.Run("Success", func(test *testing.T) {
+		timeDate, mockSchedulerTickRepo := newService(time.Time{}, sql.ErrNoRows)
+
+		mockSchedulerTickRepo.On("UpdateLastTick", "Time & Date", mock.MatchedBy(func(tick time.Time) bool {
+			return tick.Second() == 0 && time.Since(tick) < 2*time.Minute
+		})).Return(nil).Once()
+
+		err := timeDate.CheckTimeAndDateActions(context.Background())
 
+		require.NoError(test, err)
+		mockSchedulerTickRepo.AssertExpectations(test)
+	})
+
+	test.Run("Already Evaluated", func(test *testing.T) {
+		timeDate, mockSchedulerTickRepo := newService(time.Now().Add(time.Minute), nil)
+
+		err := timeDate.CheckTimeAndDateActions(context.Background())
+
+		require.NoError(test, err)
+		mockSchedulerTickRepo.AssertNotCalled(test, "UpdateLastTick", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Fail Update Last Tick", func(test *testing.T) {
+		timeDate, mockSchedulerTickRepo := newService(time.Now().Add(-time.Hour), nil)
+
+		mockSchedulerTickRepo.On("UpdateLastTick", "Time & Date", mock.Anything).
+			Return(errors.New("Fail update last tick")).Once()
+
+		err := timeDate.CheckTimeAndDateActions(context.Background())
+
+		require.EqualError(test, err, "Fail update last tick")
+	})
+}
+
+func TestNewTimeResponse(test *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+	now := time.Date(2024, time.March, 10, 9, 5, 0, 0, location)
+
+	timeRes := newTimeResponse(now)
+
+	require.Equal(test, entities.TimeResponse{
+		Timezone:  "America/New_York",
+		Formatted: "10.03.2024 09:05:00",
+		Timestamp: int(now.Unix()),
+		WeekDay:   7,
+		Day:       10,
+		Month:     3,
+		Year:      2024,
+		Hour:      9,
+		Minute:    5,
+	}, timeRes)
+}
+
+func TestLoadTimezone(test *testing.T) {
+	require.Equal(test, "Asia/Tokyo", loadTimezone("Asia/Tokyo").String())
+	require.Equal(test, defaultTimezone, loadTimezone("").String())
+	require.Equal(test, defaultTimezone, loadTimezone("Mars/Olympus_Mons").String())
+}
+
+func TestGetOwnerTimeResponses(test *testing.T) {
+	now := time.Date(2024, time.June, 3, 7, 30, 0, 0, time.UTC)
+
+	test.Run("Owner Timezone", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
 		timeDate := &WorkflowService{
-			ServiceService:   mockServiceServiceRepo,
-			ActionRepository: mockActionRepo,
+			UserRepository: mockUserRepo,
 		}
+		ownerTimeResponses := map[string][]entities.TimeResponse{}
 
-		service := entities.Service{
-			Id: "1",
-		}
+		mockUserRepo.On("FindUserById", "1").
+			Return(entities.User{Id: "1", Timezone: "America/Los_Angeles"}, nil).Once()
 
-		mockServiceServiceRepo.On("RequestToTimeApi").
-			Return(entities.TimeResponse{}, nil)
+		timeResponses := timeDate.getOwnerTimeResponses([]time.Time{now.Add(-time.Minute), now}, "1", ownerTimeResponses)
+		cachedTimeResponses := timeDate.getOwnerTimeResponses([]time.Time{now}, "1", ownerTimeResponses)
 
-		mockServiceServiceRepo.On("FindServiceByName", "Time & Date").
-			Return(service, nil)
+		require.Len(test, timeResponses, 2)
+		require.Equal(test, 29, timeResponses[0].Minute)
+		require.Equal(test, 0, timeResponses[1].Hour)
+		require.Equal(test, 1, timeResponses[1].WeekDay)
+		require.Equal(test, timeResponses, cachedTimeResponses)
+		mockUserRepo.AssertNumberOfCalls(test, "FindUserById", 1)
+	})
 
-		mockActionRepo.On("FindActionsByServiceId", service.Id).
-			Return([]entities.Action{}, nil)
+	test.Run("Default Timezone", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		timeDate := &WorkflowService{
+			UserRepository: mockUserRepo,
+		}
 
-		err := timeDate.CheckTimeAndDateActions()
+		mockUserRepo.On("FindUserById", "1").
+			Return(entities.User{Id: "1"}, nil).Once()
 
-		require.NoError(test, err)
+		timeResponses := timeDate.getOwnerTimeResponses([]time.Time{now}, "1", map[string][]entities.TimeResponse{})
+
+		require.Equal(test, defaultTimezone, timeResponses[0].Timezone)
+		require.Equal(test, 9, timeResponses[0].Hour)
 	})
 }
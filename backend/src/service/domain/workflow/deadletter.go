@@ -0,0 +1,48 @@
+package workflow_service
+
+import (
+	"backend/src/entities"
+)
+
+func (self *WorkflowService) findUserWorkflow(email, connectionType, workflowId string) (entities.Workflow, error) {
+	user, err := self.UserRepository.FindUserByEmail(email, connectionType)
+	if err != nil {
+		return entities.Workflow{}, err
+	}
+
+	workflow, err := self.WorkflowRepository.FindWorkflowById(workflowId)
+	if err != nil {
+		return workflow, err
+	}
+	if workflow.OwnerId != user.Id {
+		return entities.Workflow{}, entities.WorkflowNotFoundError{}
+	}
+	return workflow, nil
+}
+
+// Dead letters are the reaction jobs which failed for good, a permanent error or too many attempts
+func (self *WorkflowService) GetWorkflowDeadLetters(email, connectionType, workflowId string) ([]entities.ReactionJob, error) {
+	_, err := self.findUserWorkflow(email, connectionType, workflowId)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters, err := self.JobRepository.FindFailedJobsByWorkflowId(workflowId)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetters == nil {
+		return []entities.ReactionJob{}, nil
+	}
+	return deadLetters, nil
+}
+
+// The dead letters are enqueued again with all their attempts, returns their number
+func (self *WorkflowService) RetriggerWorkflowDeadLetters(email, connectionType, workflowId string) (int, error) {
+	_, err := self.findUserWorkflow(email, connectionType, workflowId)
+	if err != nil {
+		return 0, err
+	}
+
+	return self.JobRepository.RequeueFailedJobs(workflowId)
+}
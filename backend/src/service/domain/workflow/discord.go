@@ -64,12 +64,8 @@ func (self *WorkflowService) createDiscordThread(tokenBot string, workflow entit
 	return nil
 }
 
-func (self *WorkflowService) checkDiscordReactions(workflow entities.Workflow) error {
+func (self *WorkflowService) CheckDiscordReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
 	tokenBot := os.Getenv("DISCORD_BOT_TOKEN")
-	reactionFound, errReaction := self.ReactionRepository.FindReactionById(workflow.ReactionId)
-	if errReaction != nil {
-		return fmt.Errorf(errorRetrievingReaction)
-	}
 
 	switch reactionFound.Name {
 	case "Post a message to a channel":
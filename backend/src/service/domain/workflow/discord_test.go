@@ -1,7 +1,6 @@
 package workflow_service
 
 import (
-	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -42,40 +41,14 @@ func TestCreateDiscordThread(test *testing.T) {
 }
 
 func TestCheckDiscordReactions(test *testing.T) {
-	test.Run("Fail Find Reaction", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		discord := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		workflow := entities.Workflow{
-			ReactionId: "1",
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, errors.New(errorRetrievingReaction))
-
-		err := discord.checkDiscordReactions(workflow)
-
-		require.EqualError(test, err, errorRetrievingReaction)
-	})
-
 	test.Run("Success", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		discord := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
+		discord := &WorkflowService{}
 
 		workflow := entities.Workflow{
 			ReactionId: "1",
 		}
 
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, nil)
-
-		err := discord.checkDiscordReactions(workflow)
+		err := discord.CheckDiscordReactions(workflow, entities.Reaction{})
 
 		require.EqualError(test, err, "Unknown reaction")
 	})
@@ -136,12 +136,7 @@ func (self *WorkflowService) appendTextFile(accessToken string, workflow entitie
 	return self.requestFileCreationModificationDropbox(url, accessToken, arg, bytes.NewBuffer([]byte(contentAppend)), workflow)
 }
 
-func (self *WorkflowService) checkDropboxReactions(workflow entities.Workflow) error {
-	reactionFound, errReaction := self.ReactionRepository.FindReactionById(workflow.ReactionId)
-	if errReaction != nil {
-		return fmt.Errorf(errorRetrievingReaction)
-	}
-
+func (self *WorkflowService) CheckDropboxReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
 	accessToken, err := self.refreshTokenForService("Dropbox", reactionFound.Name, dropboxReactions(), workflow)
 	if err != nil {
 		return fmt.Errorf(errorUpdatingToken)
@@ -1,7 +1,6 @@
 package workflow_service
 
 import (
-	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -58,31 +57,8 @@ func TestAppendTextFile(test *testing.T) {
 }
 
 func TestCheckDropboxReactions(test *testing.T) {
-	test.Run("Fail Find Reaction", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		dropbox := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		workflow := entities.Workflow{
-			ReactionId: "1",
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, errors.New(errorRetrievingReaction))
-
-		err := dropbox.checkDropboxReactions(workflow)
-
-		require.EqualError(test, err, errorRetrievingReaction)
-	})
-
 	test.Run("Success", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		dropbox := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
+		dropbox := &WorkflowService{}
 
 		workflow := entities.Workflow{
 			ReactionId: "1",
@@ -92,10 +68,7 @@ func TestCheckDropboxReactions(test *testing.T) {
 			Name: "test",
 		}
 
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(reaction, nil)
-
-		err := dropbox.checkDropboxReactions(workflow)
+		err := dropbox.CheckDropboxReactions(workflow, reaction)
 
 		require.NoError(test, err)
 	})
@@ -10,19 +10,9 @@ import (
 	"backend/src/entities"
 )
 
-func (self *WorkflowService) sendMeEmail(workflow entities.Workflow) error {
-	foundUser, errUser := self.UserRepository.FindUserById(workflow.OwnerId)
-	if errUser != nil {
-		return fmt.Errorf("Error finding user")
-	}
-
-	subject, subjectExists := workflow.ReactionParam["subject"]
-	body, bodyExists := workflow.ReactionParam["body"]
-	if !subjectExists || !bodyExists {
-		return fmt.Errorf(errorMissingField)
-	}
-
-	username, _, stringSplit := strings.Cut(foundUser.Email, "@")
+// Sends the email through SendGrid
+func (self *WorkflowService) sendEmail(email string, subject, body interface{}) error {
+	username, _, stringSplit := strings.Cut(email, "@")
 	if !stringSplit {
 		username = "Default Name"
 	}
@@ -32,7 +22,7 @@ func (self *WorkflowService) sendMeEmail(workflow entities.Workflow) error {
 			{
 				"to": []map[string]interface{}{
 					{
-						"email": foundUser.Email,
+						"email": email,
 						"name":  username,
 					},
 				},
@@ -67,12 +57,22 @@ func (self *WorkflowService) sendMeEmail(workflow entities.Workflow) error {
 	return nil
 }
 
-func (self *WorkflowService) checkSendEmailReactions(workflow entities.Workflow) error {
-	reactionFound, errReaction := self.ReactionRepository.FindReactionById(workflow.ReactionId)
-	if errReaction != nil {
-		return fmt.Errorf(errorRetrievingReaction)
+func (self *WorkflowService) sendMeEmail(workflow entities.Workflow) error {
+	foundUser, errUser := self.UserRepository.FindUserById(workflow.OwnerId)
+	if errUser != nil {
+		return fmt.Errorf("Error finding user")
+	}
+
+	subject, subjectExists := workflow.ReactionParam["subject"]
+	body, bodyExists := workflow.ReactionParam["body"]
+	if !subjectExists || !bodyExists {
+		return fmt.Errorf(errorMissingField)
 	}
 
+	return self.sendEmail(foundUser.Email, subject, body)
+}
+
+func (self *WorkflowService) CheckSendEmailReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
 	switch reactionFound.Name {
 	case "Send me an email":
 		return self.sendMeEmail(workflow)
@@ -56,31 +56,8 @@ func TestSendMeEmail(test *testing.T) {
 }
 
 func TestCheckSendEmailReactions(test *testing.T) {
-	test.Run("Fail Find Reaction", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		email := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		workflow := entities.Workflow{
-			ReactionId: "1",
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, errors.New(errorRetrievingReaction))
-
-		err := email.checkSendEmailReactions(workflow)
-
-		require.EqualError(test, err, errorRetrievingReaction)
-	})
-
 	test.Run("Success", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		email := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
+		email := &WorkflowService{}
 
 		workflow := entities.Workflow{
 			ReactionId: "1",
@@ -90,10 +67,7 @@ func TestCheckSendEmailReactions(test *testing.T) {
 			Name: "test",
 		}
 
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(reaction, nil)
-
-		err := email.checkSendEmailReactions(workflow)
+		err := email.CheckSendEmailReactions(workflow, reaction)
 
 		require.NoError(test, err)
 	})
@@ -0,0 +1,508 @@
+package workflow_service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"backend/src/entities"
+)
+
+// A filter is a condition on the event of the action, e.g. `title contains "release"`,
+// `event.action == "opened"` or `temp_c > 30 and hour < 20`.
+// Fields are paths in the event, the namespace of the action ("post", "weather", "time"...) can be omitted.
+
+const filterTokenField = "field"
+const filterTokenString = "string"
+const filterTokenNumber = "number"
+const filterTokenOperator = "operator"
+const filterTokenEnd = "end"
+
+type filterToken struct {
+	kind     string
+	value    string
+	position int
+}
+
+type filterExpression interface {
+	evaluate(event entities.ActionEvent) bool
+	fields() []string
+}
+
+type filterOperand struct {
+	field string
+	value interface{}
+}
+
+type filterComparison struct {
+	left     filterOperand
+	operator string
+	right    filterOperand
+}
+
+type filterAnd struct {
+	left  filterExpression
+	right filterExpression
+}
+
+type filterOr struct {
+	left  filterExpression
+	right filterExpression
+}
+
+type filterNot struct {
+	expression filterExpression
+}
+
+type filterParser struct {
+	tokens  []filterToken
+	current int
+}
+
+var filterKeywords = map[string]string{
+	"and":      "and",
+	"or":       "or",
+	"not":      "not",
+	"contains": "contains",
+	"&&":       "and",
+	"||":       "or",
+	"!":        "not",
+}
+
+var filterComparisonOperators = []string{"==", "!=", ">=", "<=", ">", "<", "contains"}
+
+func newFilterError(format string, args ...interface{}) error {
+	return entities.WorkflowValidationError{Message: "Invalid filter: " + fmt.Sprintf(format, args...)}
+}
+
+func isFilterFieldRune(character rune) bool {
+	return unicode.IsLetter(character) || unicode.IsDigit(character) || character == '_' || character == '.'
+}
+
+func tokenizeFilterString(filter []rune, start int) (filterToken, int, error) {
+	var builder strings.Builder
+	quote := filter[start]
+
+	for i := start + 1; i < len(filter); i++ {
+		if filter[i] == '\\' && i+1 < len(filter) {
+			i++
+			builder.WriteRune(filter[i])
+			continue
+		}
+		if filter[i] == quote {
+			return filterToken{kind: filterTokenString, value: builder.String(), position: start}, i + 1, nil
+		}
+		builder.WriteRune(filter[i])
+	}
+	return filterToken{}, 0, newFilterError("unterminated string at position %d", start)
+}
+
+func tokenizeFilter(filter string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(filter)
+
+	for i := 0; i < len(runes); {
+		character := runes[i]
+		switch {
+		case unicode.IsSpace(character):
+			i++
+		case character == '"' || character == '\'':
+			token, next, err := tokenizeFilterString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+			i = next
+		case unicode.IsDigit(character) || (character == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenNumber, value: string(runes[start:i]), position: start})
+		case isFilterFieldRune(character):
+			start := i
+			for ; i < len(runes) && isFilterFieldRune(runes[i]); i++ {
+			}
+			word := string(runes[start:i])
+			if keyword, isKeyword := filterKeywords[strings.ToLower(word)]; isKeyword {
+				tokens = append(tokens, filterToken{kind: filterTokenOperator, value: keyword, position: start})
+			} else {
+				tokens = append(tokens, filterToken{kind: filterTokenField, value: word, position: start})
+			}
+		default:
+			symbol := ""
+			for _, candidate := range []string{"==", "!=", ">=", "<=", "&&", "||", ">", "<", "!", "(", ")"} {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					symbol = candidate
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, newFilterError("unexpected character %q at position %d", character, i)
+			}
+
+			operator := symbol
+			if keyword, isKeyword := filterKeywords[symbol]; isKeyword {
+				operator = keyword
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, value: operator, position: i})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, filterToken{kind: filterTokenEnd, position: len(runes)}), nil
+}
+
+func (self *filterParser) peek() filterToken {
+	return self.tokens[self.current]
+}
+
+func (self *filterParser) next() filterToken {
+	token := self.tokens[self.current]
+	if token.kind != filterTokenEnd {
+		self.current++
+	}
+	return token
+}
+
+func (self *filterParser) isOperator(operator string) bool {
+	token := self.peek()
+	return token.kind == filterTokenOperator && token.value == operator
+}
+
+func unexpectedFilterToken(token filterToken) error {
+	if token.kind == filterTokenEnd {
+		return newFilterError("unexpected end of filter")
+	}
+	return newFilterError("unexpected %q at position %d", token.value, token.position)
+}
+
+func (self *filterParser) parseOr() (filterExpression, error) {
+	left, err := self.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for self.isOperator("or") {
+		self.next()
+		right, err := self.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (self *filterParser) parseAnd() (filterExpression, error) {
+	left, err := self.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for self.isOperator("and") {
+		self.next()
+		right, err := self.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (self *filterParser) parseNot() (filterExpression, error) {
+	if self.isOperator("not") {
+		self.next()
+		expression, err := self.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{expression: expression}, nil
+	}
+	return self.parsePrimary()
+}
+
+func (self *filterParser) parsePrimary() (filterExpression, error) {
+	if self.isOperator("(") {
+		self.next()
+		expression, err := self.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !self.isOperator(")") {
+			return nil, unexpectedFilterToken(self.peek())
+		}
+		self.next()
+		return expression, nil
+	}
+
+	left, err := self.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, operator := range filterComparisonOperators {
+		if self.isOperator(operator) {
+			self.next()
+			right, err := self.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return filterComparison{left: left, operator: operator, right: right}, nil
+		}
+	}
+	return filterComparison{left: left}, nil
+}
+
+func (self *filterParser) parseOperand() (filterOperand, error) {
+	token := self.next()
+
+	switch token.kind {
+	case filterTokenString:
+		return filterOperand{value: token.value}, nil
+	case filterTokenNumber:
+		number, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return filterOperand{}, newFilterError("invalid number %q at position %d", token.value, token.position)
+		}
+		return filterOperand{value: number}, nil
+	case filterTokenField:
+		switch strings.ToLower(token.value) {
+		case "true":
+			return filterOperand{value: true}, nil
+		case "false":
+			return filterOperand{value: false}, nil
+		case "null":
+			return filterOperand{value: nil}, nil
+		}
+		return filterOperand{field: token.value}, nil
+	}
+	return filterOperand{}, unexpectedFilterToken(token)
+}
+
+func parseFilter(filter string) (filterExpression, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &filterParser{tokens: tokens}
+	expression, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.peek().kind != filterTokenEnd {
+		return nil, unexpectedFilterToken(parser.peek())
+	}
+	return expression, nil
+}
+
+func isKnownFilterField(field string, variables []string) bool {
+	for _, variable := range variables {
+		names := []string{variable}
+		separatorIndex := strings.Index(variable, ".")
+		if separatorIndex >= 0 {
+			names = append(names, variable[separatorIndex+1:])
+		}
+
+		for _, name := range names {
+			if field == name || strings.HasPrefix(field, name+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// The fields of a filter must be variables exposed by the action, or a path inside an object variable such as "event"
+func checkFilterFields(expression filterExpression, variables []string) error {
+	for _, field := range expression.fields() {
+		if !isKnownFilterField(field, variables) {
+			if len(variables) == 0 {
+				return entities.WorkflowValidationError{Message: fmt.Sprintf("Unknown filter field %q, this action does not expose any field", field)}
+			}
+			return entities.WorkflowValidationError{Message: fmt.Sprintf("Unknown filter field %q, available fields are: %s", field, strings.Join(variables, ", "))}
+		}
+	}
+	return nil
+}
+
+func lookupFilterField(event entities.ActionEvent, field string) (interface{}, bool) {
+	value, valueExists := lookupEventVariable(event, field)
+	if valueExists {
+		return value, true
+	}
+
+	namespaces := make([]string, 0, len(event))
+	for namespace := range event {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		value, valueExists = lookupEventVariable(event, namespace+"."+field)
+		if valueExists {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func (self filterOperand) resolve(event entities.ActionEvent) (interface{}, bool) {
+	if self.field == "" {
+		return self.value, true
+	}
+	return lookupFilterField(event, self.field)
+}
+
+func filterValueToNumber(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case float64:
+		return typedValue, true
+	case int:
+		return float64(typedValue), true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(typedValue), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+func isFilterValueTruthy(value interface{}) bool {
+	switch typedValue := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return typedValue
+	case string:
+		return typedValue != ""
+	case float64:
+		return typedValue != 0
+	case []interface{}:
+		return len(typedValue) > 0
+	}
+	return true
+}
+
+func areFilterValuesEqual(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+
+	leftNumber, leftIsNumber := filterValueToNumber(left)
+	rightNumber, rightIsNumber := filterValueToNumber(right)
+	if leftIsNumber && rightIsNumber {
+		return leftNumber == rightNumber
+	}
+	return formatEventVariable(left) == formatEventVariable(right)
+}
+
+func filterValueContains(container, element interface{}) bool {
+	if elements, isSlice := container.([]interface{}); isSlice {
+		for _, containedElement := range elements {
+			if areFilterValuesEqual(containedElement, element) {
+				return true
+			}
+		}
+		return false
+	}
+	if container == nil || element == nil {
+		return false
+	}
+	return strings.Contains(formatEventVariable(container), formatEventVariable(element))
+}
+
+func compareFilterNumbers(left, right interface{}, operator string) bool {
+	leftNumber, leftIsNumber := filterValueToNumber(left)
+	rightNumber, rightIsNumber := filterValueToNumber(right)
+	if !leftIsNumber || !rightIsNumber {
+		return false
+	}
+
+	switch operator {
+	case ">":
+		return leftNumber > rightNumber
+	case ">=":
+		return leftNumber >= rightNumber
+	case "<":
+		return leftNumber < rightNumber
+	case "<=":
+		return leftNumber <= rightNumber
+	}
+	return false
+}
+
+func (self filterComparison) evaluate(event entities.ActionEvent) bool {
+	left, leftExists := self.left.resolve(event)
+	if self.operator == "" {
+		return leftExists && isFilterValueTruthy(left)
+	}
+	right, rightExists := self.right.resolve(event)
+
+	switch self.operator {
+	case "==":
+		return leftExists && rightExists && areFilterValuesEqual(left, right)
+	case "!=":
+		return !leftExists || !rightExists || !areFilterValuesEqual(left, right)
+	case "contains":
+		return leftExists && rightExists && filterValueContains(left, right)
+	}
+	return leftExists && rightExists && compareFilterNumbers(left, right, self.operator)
+}
+
+func (self filterComparison) fields() []string {
+	var fields []string
+
+	for _, operand := range []filterOperand{self.left, self.right} {
+		if operand.field != "" {
+			fields = append(fields, operand.field)
+		}
+	}
+	return fields
+}
+
+func (self filterAnd) evaluate(event entities.ActionEvent) bool {
+	return self.left.evaluate(event) && self.right.evaluate(event)
+}
+
+func (self filterAnd) fields() []string {
+	return append(self.left.fields(), self.right.fields()...)
+}
+
+func (self filterOr) evaluate(event entities.ActionEvent) bool {
+	return self.left.evaluate(event) || self.right.evaluate(event)
+}
+
+func (self filterOr) fields() []string {
+	return append(self.left.fields(), self.right.fields()...)
+}
+
+func (self filterNot) evaluate(event entities.ActionEvent) bool {
+	return !self.expression.evaluate(event)
+}
+
+func (self filterNot) fields() []string {
+	return self.expression.fields()
+}
+
+func checkWorkflowFilter(filter string, action entities.Action) error {
+	if strings.TrimSpace(filter) == "" {
+		return nil
+	}
+
+	expression, err := parseFilter(filter)
+	if err != nil {
+		return err
+	}
+	return checkFilterFields(expression, action.Variables)
+}
+
+func matchWorkflowFilter(workflow entities.Workflow, event entities.ActionEvent) bool {
+	if strings.TrimSpace(workflow.Filter) == "" {
+		return true
+	}
+
+	expression, err := parseFilter(workflow.Filter)
+	if err != nil {
+		return false
+	}
+	return expression.evaluate(event)
+}
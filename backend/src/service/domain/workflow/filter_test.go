@@ -0,0 +1,131 @@
+package workflow_service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func TestParseFilter(test *testing.T) {
+	test.Run("Valid Filters", func(test *testing.T) {
+		filters := []string{
+			`title contains "release"`,
+			`event.action == "opened"`,
+			`temp_c > 30 and hour < 20`,
+			`not (event.action == 'closed' || event.action == "merged")`,
+			`temp_c >= -5.5 && !event.draft`,
+		}
+
+		for _, filter := range filters {
+			_, err := parseFilter(filter)
+			require.NoError(test, err, filter)
+		}
+	})
+
+	test.Run("Invalid Filters", func(test *testing.T) {
+		filters := map[string]string{
+			`title contains`:               "Invalid filter: unexpected end of filter",
+			`title contains "release`:      "Invalid filter: unterminated string at position 15",
+			`(temp_c > 30`:                 "Invalid filter: unexpected end of filter",
+			`temp_c > 30 hour < 20`:        "Invalid filter: unexpected \"hour\" at position 12",
+			`temp_c # 30`:                  "Invalid filter: unexpected character '#' at position 7",
+			`temp_c > 30 and and hour < 2`: "Invalid filter: unexpected \"and\" at position 16",
+		}
+
+		for filter, message := range filters {
+			_, err := parseFilter(filter)
+
+			var validationError entities.WorkflowValidationError
+			require.True(test, errors.As(err, &validationError), filter)
+			require.EqualError(test, err, message, filter)
+		}
+	})
+}
+
+func TestCheckFilterFields(test *testing.T) {
+	variables := []string{"post.title", "event_name", "event"}
+
+	test.Run("Known Fields", func(test *testing.T) {
+		expression, _ := parseFilter(`title contains "release" and post.title != "" and event.object_attributes.action == "open" and event_name == "issue"`)
+
+		require.NoError(test, checkFilterFields(expression, variables))
+	})
+
+	test.Run("Unknown Field", func(test *testing.T) {
+		expression, _ := parseFilter(`author == "me"`)
+
+		err := checkFilterFields(expression, variables)
+		require.EqualError(test, err, `Unknown filter field "author", available fields are: post.title, event_name, event`)
+	})
+
+	test.Run("No Variables", func(test *testing.T) {
+		expression, _ := parseFilter(`title contains "release"`)
+
+		err := checkFilterFields(expression, nil)
+		require.EqualError(test, err, `Unknown filter field "title", this action does not expose any field`)
+	})
+}
+
+func TestMatchWorkflowFilter(test *testing.T) {
+	postEvent := newActionEvent("post", map[string]interface{}{"title": "New release 1.2", "score": "12"})
+	weatherEvent := entities.ActionEvent{
+		"weather": map[string]interface{}{"temp_c": 31.5},
+		"time":    map[string]interface{}{"hour": float64(18)},
+	}
+	webhookEvent := entities.ActionEvent{
+		"event_name": "issue",
+		"event": map[string]interface{}{
+			"action": "opened",
+			"labels": []interface{}{"bug", "urgent"},
+		},
+	}
+
+	filters := []struct {
+		filter string
+		event  entities.ActionEvent
+		match  bool
+	}{
+		{``, postEvent, true},
+		{`title contains "release"`, postEvent, true},
+		{`title contains "Release"`, postEvent, false},
+		{`post.title contains "1.2"`, postEvent, true},
+		{`score > 10`, postEvent, true},
+		{`title > 10`, postEvent, false},
+		{`temp_c > 30 and hour < 20`, weatherEvent, true},
+		{`temp_c > 30 and hour < 18`, weatherEvent, false},
+		{`temp_c > 35 or hour == 18`, weatherEvent, true},
+		{`event.action == "opened"`, webhookEvent, true},
+		{`not event.action == "opened"`, webhookEvent, false},
+		{`event.labels contains "urgent"`, webhookEvent, true},
+		{`event.missing == "opened"`, webhookEvent, false},
+		{`event.missing != "opened"`, webhookEvent, true},
+		{`event.action`, webhookEvent, true},
+		{`title contains`, postEvent, false},
+	}
+
+	for _, filter := range filters {
+		workflow := entities.Workflow{Filter: filter.filter}
+
+		require.Equal(test, filter.match, matchWorkflowFilter(workflow, filter.event), filter.filter)
+	}
+}
+
+func TestCheckWorkflowFilter(test *testing.T) {
+	action := entities.Action{Variables: []string{"post.title"}}
+
+	test.Run("Empty Filter", func(test *testing.T) {
+		require.NoError(test, checkWorkflowFilter(" ", entities.Action{}))
+	})
+
+	test.Run("Invalid Filter", func(test *testing.T) {
+		err := checkWorkflowFilter(`title contains`, action)
+		require.EqualError(test, err, "Invalid filter: unexpected end of filter")
+	})
+
+	test.Run("Success", func(test *testing.T) {
+		require.NoError(test, checkWorkflowFilter(`title contains "release"`, action))
+	})
+}
@@ -2,27 +2,16 @@ package workflow_service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 
 	"backend/src/entities"
 )
 
-func githubWebhooksEventsToActions() map[string]string {
-	return map[string]string{
-		"watch":        "New star",
-		"public":       "Visibility update",
-		"milestone":    "Milestone update",
-		"release":      "Release update",
-		"gollum":       "Wiki update",
-		"workflow_job": "Workflow job update",
-		"workflow_run": "Workflow run update",
-		"fork":         "Fork update",
-	}
-}
-
 func githubWebhookEvents() []string {
 	return []string{
 		"watch",
@@ -39,22 +28,35 @@ func githubWebhookEvents() []string {
 const githubBaseUrl = "https://api.github.com/"
 const githubRepositoryEndpoint = "repos/"
 
-func (self *WorkflowService) executeGithubRequest(workflow entities.Workflow, method, accessToken, endpoint string) (*http.Response, error) {
+func (self *WorkflowService) executeGithubRequest(ctx context.Context, workflow entities.Workflow, method, accessToken, endpoint string) (*http.Response, error) {
 	repository, repositoryExists := workflow.ActionParam["repository"]
 	if !repositoryExists {
 		return nil, fmt.Errorf(errorMissingField)
 	}
 
 	url := githubBaseUrl + githubRepositoryEndpoint + repository.(string) + endpoint
-	return self.ServiceService.ExecuteApiRequest(url, method, bearerType, accessToken, nil)
+	return self.ServiceService.ExecuteApiRequestWithContext(ctx, url, method, bearerType, accessToken, nil)
+}
+
+func newGithubCountActionEvent(workflow entities.Workflow, count float64) entities.ActionEvent {
+	repository, _ := getWorkflowStringActionParam(workflow, "repository")
+
+	return newActionEvent("github", entities.GithubCountEvent{
+		Repository: repository,
+		Count:      int(count),
+	})
 }
 
-func (self *WorkflowService) checkActionDataLen(workflow entities.Workflow, lenActualTurn float64) error {
+func (self *WorkflowService) checkActionDataLen(ctx context.Context, workflow entities.Workflow, lenActualTurn float64) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
 	lenLastTurn, lenLastTurnExists := workflow.ActionData["len"]
 	if !lenLastTurnExists {
 		workflow.ActionData = make(map[string]interface{})
 		workflow.ActionData["len"] = lenActualTurn
-		err := self.WorkflowRepository.UpdateWorkflow(workflow.Id, workflow)
+		err := self.WorkflowRepository.UpdateWorkflowActionData(workflow.Id, workflow.ActionData)
 		if err != nil {
 			return err
 		}
@@ -63,27 +65,27 @@ func (self *WorkflowService) checkActionDataLen(workflow entities.Workflow, lenA
 
 	if lenLastTurn.(float64) < lenActualTurn {
 		workflow.ActionData["len"] = lenActualTurn
-		err := self.WorkflowRepository.UpdateWorkflow(workflow.Id, workflow)
+		err := self.WorkflowRepository.UpdateWorkflowActionData(workflow.Id, workflow.ActionData)
 		if err != nil {
 			return err
 		}
-		self.checkReactions(workflow)
+		self.checkReactions(workflow, newGithubCountActionEvent(workflow, lenActualTurn))
 	}
 	return nil
 }
 
-func (self *WorkflowService) checkGithubNewRepositoryAction(workflow entities.Workflow, accessToken string) error {
-	repositories, err := self.ServiceService.RequestGithubUserRepositories(accessToken)
+func (self *WorkflowService) checkGithubNewRepositoryAction(ctx context.Context, workflow entities.Workflow, accessToken string) error {
+	repositories, err := self.ServiceService.RequestGithubUserRepositories(ctx, accessToken)
 	if err != nil {
 		return err
 	}
-	return self.checkActionDataLen(workflow, float64(len(repositories)))
+	return self.checkActionDataLen(ctx, workflow, float64(len(repositories)))
 }
 
-func (self *WorkflowService) checkGithubNewIssueAction(workflow entities.Workflow, accessToken string) error {
+func (self *WorkflowService) checkGithubNewIssueAction(ctx context.Context, workflow entities.Workflow, accessToken string) error {
 	var issues []entities.GithubIssue
 
-	resp, err := self.ServiceService.ExecuteApiRequest(githubBaseUrl+"issues", "GET", bearerType, accessToken, nil)
+	resp, err := self.ServiceService.ExecuteApiRequestWithContext(ctx, githubBaseUrl+"issues", "GET", bearerType, accessToken, nil)
 	if err != nil {
 		return err
 	}
@@ -93,13 +95,13 @@ func (self *WorkflowService) checkGithubNewIssueAction(workflow entities.Workflo
 		return err
 	}
 
-	return self.checkActionDataLen(workflow, float64(len(issues)))
+	return self.checkActionDataLen(ctx, workflow, float64(len(issues)))
 }
 
-func (self *WorkflowService) checkGithubNewPullRequestAction(workflow entities.Workflow, accessToken string) error {
+func (self *WorkflowService) checkGithubNewPullRequestAction(ctx context.Context, workflow entities.Workflow, accessToken string) error {
 	var pullRequests []entities.GithubRepository
 
-	resp, err := self.executeGithubRequest(workflow, "GET", accessToken, "/pulls")
+	resp, err := self.executeGithubRequest(ctx, workflow, "GET", accessToken, "/pulls")
 	if err != nil {
 		return err
 	}
@@ -109,13 +111,13 @@ func (self *WorkflowService) checkGithubNewPullRequestAction(workflow entities.W
 		return err
 	}
 
-	return self.checkActionDataLen(workflow, float64(len(pullRequests)))
+	return self.checkActionDataLen(ctx, workflow, float64(len(pullRequests)))
 }
 
-func (self *WorkflowService) checkGithubNewBranchAction(workflow entities.Workflow, accessToken string) error {
+func (self *WorkflowService) checkGithubNewBranchAction(ctx context.Context, workflow entities.Workflow, accessToken string) error {
 	var branches []entities.GithubBranch
 
-	resp, err := self.executeGithubRequest(workflow, "GET", accessToken, "/branches")
+	resp, err := self.executeGithubRequest(ctx, workflow, "GET", accessToken, "/branches")
 	if err != nil {
 		return err
 	}
@@ -125,13 +127,13 @@ func (self *WorkflowService) checkGithubNewBranchAction(workflow entities.Workfl
 		return err
 	}
 
-	return self.checkActionDataLen(workflow, float64(len(branches)))
+	return self.checkActionDataLen(ctx, workflow, float64(len(branches)))
 }
 
-func (self *WorkflowService) checkGithubNewCommitAction(workflow entities.Workflow, accessToken string) error {
+func (self *WorkflowService) checkGithubNewCommitAction(ctx context.Context, workflow entities.Workflow, accessToken string) error {
 	var commits []entities.GithubCommit
 
-	resp, err := self.executeGithubRequest(workflow, "GET", accessToken, "/commits")
+	resp, err := self.executeGithubRequest(ctx, workflow, "GET", accessToken, "/commits")
 	if err != nil {
 		return err
 	}
@@ -141,158 +143,101 @@ func (self *WorkflowService) checkGithubNewCommitAction(workflow entities.Workfl
 		return err
 	}
 
-	return self.checkActionDataLen(workflow, float64(len(commits)))
+	return self.checkActionDataLen(ctx, workflow, float64(len(commits)))
 }
 
-func (self *WorkflowService) checkWorkflowsWithGithubActions(action entities.Action) error {
-	workflows, err := self.WorkflowRepository.FindWorkflowsByActionId(action.Id)
+func (self *WorkflowService) checkGithubWorkflow(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+	accessToken, err := self.getAccessToken("Github", workflow)
 	if err != nil {
 		return err
 	}
 
-	for _, workflow := range workflows {
-		if !workflow.IsActivated {
-			continue
-		}
-		accessToken, err := self.getAccessToken("Github", workflow)
-		if err != nil {
-			continue
-		}
-		switch action.Name {
-		case "New repository":
-			self.checkGithubNewRepositoryAction(workflow, accessToken)
-		case "New issue assignated":
-			self.checkGithubNewIssueAction(workflow, accessToken)
-		case "New pull request":
-			self.checkGithubNewPullRequestAction(workflow, accessToken)
-		case "New branch":
-			self.checkGithubNewBranchAction(workflow, accessToken)
-		case "New push":
-			self.checkGithubNewCommitAction(workflow, accessToken)
-		}
+	switch action.Name {
+	case "New repository":
+		return self.checkGithubNewRepositoryAction(ctx, workflow, accessToken)
+	case "New issue assignated":
+		return self.checkGithubNewIssueAction(ctx, workflow, accessToken)
+	case "New pull request":
+		return self.checkGithubNewPullRequestAction(ctx, workflow, accessToken)
+	case "New branch":
+		return self.checkGithubNewBranchAction(ctx, workflow, accessToken)
+	case "New push":
+		return self.checkGithubNewCommitAction(ctx, workflow, accessToken)
 	}
 	return nil
 }
 
-func (self *WorkflowService) CheckGithubActions() error {
-	service, err := self.ServiceService.FindServiceByName("Github")
-	if err != nil {
-		return err
-	}
-
-	actions, err := self.ActionRepository.FindActionsByServiceId(service.Id)
-	if err != nil {
-		return err
-	}
-
-	for _, action := range actions {
-		err := self.checkWorkflowsWithGithubActions(action)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+func (self *WorkflowService) CheckGithubActions(ctx context.Context) error {
+	_, err := self.pollServiceWorkflows(ctx, "Github", self.checkGithubWorkflow)
+	return err
 }
 
-func (self *WorkflowService) checkGithubWebhooksWorkflow(workflow entities.Workflow, webhookRepository string) error {
-	userRepository, err := getWorkflowStringActionParam(workflow, "repository")
-	if err != nil {
-		return err
-	}
+func newGithubWebhook(secret string) entities.GithubWebhookResponse {
+	var webhook entities.GithubWebhookResponse
 
-	if userRepository == webhookRepository {
-		self.checkReactions(workflow)
-	}
-	return nil
+	webhook.Name = "web"
+	webhook.Active = true
+	webhook.Events = githubWebhookEvents()
+	webhook.Config.Url = os.Getenv("NGROK_APP_URL") + "/webhooks/Github"
+	webhook.Config.ContentType = "json"
+	webhook.Config.Secret = secret
+	return webhook
 }
 
-func (self *WorkflowService) checkGithubWebhooksWorkflows(headers http.Header, webhookJsonDataBytes []byte, eventsToActions map[string]string, serviceId string) error {
-	var webhookResponse entities.GithubWebhookTriggeredResponse
-	if len(webhookJsonDataBytes) > 0 {
-		err := json.Unmarshal(webhookJsonDataBytes, &webhookResponse)
-		if err != nil {
-			return err
-		}
-	}
-	if webhookResponse.Repository.Name == "" {
-		return fmt.Errorf("Incorrect repository name")
-	}
-
-	eventName := headers["X-Github-Event"][0]
-	if eventName == "ping" {
-		return nil
-	}
-
-	actionName, actionNameExists := eventsToActions[eventName]
-	if !actionNameExists {
-		return fmt.Errorf("No action mapped with this event")
-	}
-
-	action, err := self.ActionRepository.FindActionByNameAndServiceId(actionName, serviceId)
+func (self *WorkflowService) sendGithubWebhook(url, method, accessToken string, webhook entities.GithubWebhookResponse) error {
+	jsonBody, err := json.Marshal(webhook)
 	if err != nil {
-		return err
+		return fmt.Errorf(errorMarshaling)
 	}
 
-	workflows, err := self.WorkflowRepository.FindWorkflowsByActionId(action.Id)
+	res, err := self.ServiceService.ExecuteApiRequest(url, method, bearerType, accessToken, bytes.NewBuffer([]byte(jsonBody)))
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 
-	for _, workflow := range workflows {
-		if !workflow.IsActivated {
-			continue
-		}
-		self.checkGithubWebhooksWorkflow(workflow, webhookResponse.Repository.Name)
-	}
 	return nil
 }
 
 func (self *WorkflowService) createNewWorkflowGithubWebhook(repository, accessToken string) error {
-	var webhook entities.GithubWebhookResponse
 	createWebhookUrl := githubBaseUrl + "repos/" + repository + "/hooks"
-	webhookUrl := os.Getenv("NGROK_APP_URL") + "/webhooks/Github"
 
-	webhook.Name = "web"
-	webhook.Active = true
-	webhook.Events = githubWebhookEvents()
-	webhook.Config.Url = webhookUrl
-	webhook.Config.ContentType = "json"
-	jsonBody, err := json.Marshal(webhook)
-	if err != nil {
-		return fmt.Errorf(errorMarshaling)
-	}
+	return self.registerServiceWebhookSecret("Github", repository, func(secret string) error {
+		return self.sendGithubWebhook(createWebhookUrl, "POST", accessToken, newGithubWebhook(secret))
+	})
+}
 
-	res, err := self.ServiceService.ExecuteApiRequest(createWebhookUrl, "POST", bearerType, accessToken, bytes.NewBuffer([]byte(jsonBody)))
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
+// Hooks registered before their deliveries were signed are given a secret
+func (self *WorkflowService) updateGithubWebhookSecret(repository string, webhookId int, accessToken string) error {
+	updateWebhookUrl := githubBaseUrl + githubRepositoryEndpoint + repository + "/hooks/" + strconv.Itoa(webhookId)
 
-	return nil
+	return self.registerServiceWebhookSecret("Github", repository, func(secret string) error {
+		return self.sendGithubWebhook(updateWebhookUrl, "PATCH", accessToken, newGithubWebhook(secret))
+	})
 }
 
-func (self *WorkflowService) isGithubRepositoryWebhookPresent(repository, accessToken string) (bool, error) {
+func (self *WorkflowService) findGithubRepositoryWebhook(repository, accessToken string) (entities.GithubWebhookResponse, bool, error) {
 	var webhooks []entities.GithubWebhookResponse
 	getWebhooksUrl := githubBaseUrl + githubRepositoryEndpoint + repository + "/hooks"
 	webhookUrl := os.Getenv("NGROK_APP_URL") + "/webhooks/Github"
 
 	res, err := self.ServiceService.ExecuteApiRequest(getWebhooksUrl, "GET", bearerType, accessToken, nil)
 	if err != nil {
-		return false, err
+		return entities.GithubWebhookResponse{}, false, err
 	}
 	defer res.Body.Close()
 
 	err = json.NewDecoder(res.Body).Decode(&webhooks)
 	if err != nil {
-		return false, err
+		return entities.GithubWebhookResponse{}, false, err
 	}
 
 	for _, webhook := range webhooks {
 		if webhook.Config.Url == webhookUrl {
-			return true, nil
+			return webhook, true, nil
 		}
 	}
-	return false, nil
+	return entities.GithubWebhookResponse{}, false, nil
 }
 
 func (self *WorkflowService) checkNewWorkflowGithubWebhook(workflow entities.Workflow, accessToken string) error {
@@ -301,17 +246,22 @@ func (self *WorkflowService) checkNewWorkflowGithubWebhook(workflow entities.Wor
 		return err
 	}
 
-	isWebhookPresent, err := self.isGithubRepositoryWebhookPresent(repository, accessToken)
+	webhook, isWebhookPresent, err := self.findGithubRepositoryWebhook(repository, accessToken)
 	if err != nil {
 		return err
 	}
 
 	if !isWebhookPresent {
-		self.createNewWorkflowGithubWebhook(repository, accessToken)
-		err := self.WorkflowRepository.UpdateWorkflow(workflow.Id, workflow)
+		err = self.createNewWorkflowGithubWebhook(repository, accessToken)
+		if err != nil {
+			return err
+		}
+		err = self.WorkflowRepository.UpdateWorkflowActionData(workflow.Id, workflow.ActionData)
 		if err != nil {
 			return err
 		}
+	} else if !self.hasServiceWebhookSecret("Github", repository) {
+		return self.updateGithubWebhookSecret(repository, webhook.Id, accessToken)
 	}
 	return nil
 }
@@ -332,7 +282,7 @@ func (self *WorkflowService) checkNewWorkflowsGithubWebhook(action entities.Acti
 	return nil
 }
 
-func (self *WorkflowService) CheckNewGithubWorkflows() error {
+func (self *WorkflowService) CheckNewGithubWorkflows(ctx context.Context) error {
 	service, err := self.ServiceService.FindServiceByName("Github")
 	if err != nil {
 		return err
@@ -1,12 +1,17 @@
 package workflow_service
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"backend/src/entities"
@@ -16,7 +21,7 @@ func TestExecuteGithubRequest(test *testing.T) {
 	test.Run("Missing Field", func(test *testing.T) {
 		github := &WorkflowService{}
 
-		_, err := github.executeGithubRequest(entities.Workflow{}, "method", "accessToken", "endPoint")
+		_, err := github.executeGithubRequest(context.Background(), entities.Workflow{}, "method", "accessToken", "endPoint")
 
 		require.EqualError(test, err, errorMissingField)
 	})
@@ -37,10 +42,10 @@ func TestCheckActionDataLen(test *testing.T) {
 			},
 		}
 
-		mockWorkflowRepo.On("UpdateWorkflow", workflow.Id, workflow).
+		mockWorkflowRepo.On("UpdateWorkflowActionData", workflow.Id, workflow.ActionData).
 			Return(nil)
 
-		err := github.checkActionDataLen(workflow, 1.0)
+		err := github.checkActionDataLen(context.Background(), workflow, 1.0)
 
 		require.NoError(test, err)
 	})
@@ -59,13 +64,34 @@ func TestCheckActionDataLen(test *testing.T) {
 			},
 		}
 
-		mockWorkflowRepo.On("UpdateWorkflow", workflow.Id, workflow).
+		mockWorkflowRepo.On("UpdateWorkflowActionData", workflow.Id, workflow.ActionData).
 			Return(errors.New("Fail update worklfow"))
 
-		err := github.checkActionDataLen(workflow, 1.0)
+		err := github.checkActionDataLen(context.Background(), workflow, 1.0)
 
 		require.EqualError(test, err, "Fail update worklfow")
 	})
+	test.Run("Abandoned Check", func(test *testing.T) {
+		mockWorkflowRepo := new(MockWorkflowRepository)
+
+		github := &WorkflowService{
+			WorkflowRepository: mockWorkflowRepo,
+		}
+
+		workflow := entities.Workflow{
+			Id: "1",
+			ActionData: map[string]interface{}{
+				"len": 0.4,
+			},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := github.checkActionDataLen(ctx, workflow, 1.0)
+
+		require.ErrorIs(test, err, context.Canceled)
+		mockWorkflowRepo.AssertNotCalled(test, "UpdateWorkflowActionData", mock.Anything, mock.Anything)
+	})
 }
 
 func TestCheckGithubNewRepositoryAction(test *testing.T) {
@@ -79,7 +105,7 @@ func TestCheckGithubNewRepositoryAction(test *testing.T) {
 		mockServiceServiceRepo.On("RequestGithubUserRepositories", "accessToken").
 			Return([]entities.GithubRepository{}, errors.New("Fail request repositories"))
 
-		err := github.checkGithubNewRepositoryAction(entities.Workflow{}, "accessToken")
+		err := github.checkGithubNewRepositoryAction(context.Background(), entities.Workflow{}, "accessToken")
 
 		require.EqualError(test, err, "Fail request repositories")
 	})
@@ -102,7 +128,7 @@ func TestCheckGithubNewIssueAction(test *testing.T) {
 		mockServiceServiceRepo.On("ExecuteApiRequest", githubBaseUrl+"issues", "GET", bearerType, "accessToken", nil).
 			Return(mockResponse, errors.New("Fail request"))
 
-		err := github.checkGithubNewIssueAction(entities.Workflow{}, "accessToken")
+		err := github.checkGithubNewIssueAction(context.Background(), entities.Workflow{}, "accessToken")
 
 		require.EqualError(test, err, "Fail request")
 	})
@@ -123,49 +149,45 @@ func TestCheckGithubNewIssueAction(test *testing.T) {
 		mockServiceServiceRepo.On("ExecuteApiRequest", githubBaseUrl+"issues", "GET", bearerType, "accessToken", nil).
 			Return(mockResponse, nil)
 
-		err := github.checkGithubNewIssueAction(entities.Workflow{}, "accessToken")
+		err := github.checkGithubNewIssueAction(context.Background(), entities.Workflow{}, "accessToken")
 
 		require.Error(test, err)
 	})
 }
 
-func TestCheckWorkflowsWithGithubActions(test *testing.T) {
-	test.Run("Fail Find Workflows", func(test *testing.T) {
-		mockWorkflowRepo := new(MockWorkflowRepository)
+func TestCheckGithubWorkflow(test *testing.T) {
+	workflow := entities.Workflow{Id: "1", OwnerId: "owner"}
 
-		github := &WorkflowService{
-			WorkflowRepository: mockWorkflowRepo,
-		}
+	test.Run("Fail Access Token", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
 
-		action := entities.Action{
-			Id:   "1",
-			Name: "test",
+		github := &WorkflowService{
+			UserRepository: mockUserRepo,
 		}
 
-		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
-			Return([]entities.Workflow{}, errors.New("Fail find worklfows"))
+		mockUserRepo.On("FindUserById", "owner").
+			Return(entities.User{}, errors.New("Fail find user"))
 
-		err := github.checkWorkflowsWithGithubActions(action)
+		err := github.checkGithubWorkflow(context.Background(), entities.Action{Name: "test"}, workflow)
 
-		require.EqualError(test, err, "Fail find worklfows")
+		require.EqualError(test, err, "Error finding user")
 	})
 
-	test.Run("Success", func(test *testing.T) {
-		mockWorkflowRepo := new(MockWorkflowRepository)
+	test.Run("Unknown Action", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
 
 		github := &WorkflowService{
-			WorkflowRepository: mockWorkflowRepo,
-		}
-
-		action := entities.Action{
-			Id:   "1",
-			Name: "test",
+			UserRepository:     mockUserRepo,
+			UserServiceService: mockUserServiceRepo,
 		}
 
-		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
-			Return([]entities.Workflow{}, nil)
+		mockUserRepo.On("FindUserById", "owner").
+			Return(entities.User{Email: "email", ConnectionType: "basic"}, nil)
+		mockUserServiceRepo.On("CallApiAndRefresh", "email", "basic", "Github").
+			Return("accessToken", nil)
 
-		err := github.checkWorkflowsWithGithubActions(action)
+		err := github.checkGithubWorkflow(context.Background(), entities.Action{Name: "test"}, workflow)
 
 		require.NoError(test, err)
 	})
@@ -182,7 +204,7 @@ func TestCheckGithubActions(test *testing.T) {
 		mockServiceServiceRepo.On("FindServiceByName", "Github").
 			Return(entities.Service{}, errors.New("Fail find service"))
 
-		err := github.CheckGithubActions()
+		err := github.CheckGithubActions(context.Background())
 
 		require.EqualError(test, err, "Fail find service")
 	})
@@ -206,7 +228,7 @@ func TestCheckGithubActions(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", service.Id).
 			Return([]entities.Action{}, errors.New("Fail find actions"))
 
-		err := github.CheckGithubActions()
+		err := github.CheckGithubActions(context.Background())
 
 		require.EqualError(test, err, "Fail find actions")
 	})
@@ -230,23 +252,13 @@ func TestCheckGithubActions(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", service.Id).
 			Return([]entities.Action{}, nil)
 
-		err := github.CheckGithubActions()
+		err := github.CheckGithubActions(context.Background())
 
 		require.NoError(test, err)
 	})
 }
 
-func TestCheckGithubWebhooksWorkflow(test *testing.T) {
-	test.Run("Fail Get Action Param", func(test *testing.T) {
-		github := &WorkflowService{}
-
-		err := github.checkGithubWebhooksWorkflow(entities.Workflow{}, "webhookRepo")
-
-		require.EqualError(test, err, errorMissingField)
-	})
-}
-
-func TestIsGithubRepositoryWebhookPresent(test *testing.T) {
+func TestFindGithubRepositoryWebhook(test *testing.T) {
 	test.Run("Fail Execute Request", func(test *testing.T) {
 		mockServiceServiceRepo := new(MockServiceServiceRepository)
 
@@ -259,7 +271,7 @@ func TestIsGithubRepositoryWebhookPresent(test *testing.T) {
 		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl, "GET", bearerType, "accessToken", nil).
 			Return(&http.Response{}, errors.New("Fail Execute API"))
 
-		_, err := github.isGithubRepositoryWebhookPresent("repo", "accessToken")
+		_, _, err := github.findGithubRepositoryWebhook("repo", "accessToken")
 
 		require.EqualError(test, err, "Fail Execute API")
 	})
@@ -280,7 +292,7 @@ func TestIsGithubRepositoryWebhookPresent(test *testing.T) {
 		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl, "GET", bearerType, "accessToken", nil).
 			Return(mockResponse, nil)
 
-		_, err := github.isGithubRepositoryWebhookPresent("repo", "accessToken")
+		_, _, err := github.findGithubRepositoryWebhook("repo", "accessToken")
 
 		require.Error(test, err)
 	})
@@ -294,6 +306,147 @@ func TestCheckNewWorkflowGithubWebhook(test *testing.T) {
 
 		require.EqualError(test, err, errorMissingField)
 	})
+
+	workflow := entities.Workflow{Id: "1", ActionParam: map[string]interface{}{"repository": "owner/repo"}}
+	getWebhooksUrl := githubBaseUrl + githubRepositoryEndpoint + "owner/repo/hooks"
+	newHooksResponse := func(hooks string) *http.Response {
+		return &http.Response{Body: io.NopCloser(strings.NewReader(hooks))}
+	}
+	// The secret sent to GitHub is kept to check the one saved afterwards
+	hasSecret := func(secret *string) interface{} {
+		return mock.MatchedBy(func(body *bytes.Buffer) bool {
+			var webhook entities.GithubWebhookResponse
+			json.Unmarshal(body.Bytes(), &webhook)
+			*secret = webhook.Config.Secret
+			return webhook.Config.Secret != ""
+		})
+	}
+	isSecret := func(secret *string) interface{} {
+		return mock.MatchedBy(func(saved string) bool {
+			return saved == *secret
+		})
+	}
+
+	test.Run("Create Hook With Secret", func(test *testing.T) {
+		mockServiceServiceRepo := new(MockServiceServiceRepository)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		var secret string
+
+		github := &WorkflowService{
+			ServiceService:           mockServiceServiceRepo,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+			WorkflowRepository:       mockWorkflowRepo,
+		}
+
+		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl, "GET", bearerType, "accessToken", nil).
+			Return(newHooksResponse(`[]`), nil)
+		mockServiceServiceRepo.On("ExecuteApiRequest", githubBaseUrl+"repos/owner/repo/hooks", "POST", bearerType, "accessToken", hasSecret(&secret)).
+			Return(newHooksResponse(`{}`), nil).Once()
+		mockServiceWebhookRepo.On("SaveServiceWebhookSecret", "Github", "owner/repo", isSecret(&secret)).
+			Return(nil).Once()
+		mockWorkflowRepo.On("UpdateWorkflowActionData", "1", workflow.ActionData).
+			Return(nil).Once()
+
+		err := github.checkNewWorkflowGithubWebhook(workflow, "accessToken")
+
+		require.NoError(test, err)
+		mockServiceServiceRepo.AssertExpectations(test)
+		mockServiceWebhookRepo.AssertExpectations(test)
+	})
+
+	test.Run("Register Secret On Existing Hook", func(test *testing.T) {
+		mockServiceServiceRepo := new(MockServiceServiceRepository)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+		var secret string
+
+		github := &WorkflowService{
+			ServiceService:           mockServiceServiceRepo,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+		}
+
+		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl, "GET", bearerType, "accessToken", nil).
+			Return(newHooksResponse(`[{"id": 7, "config": {"url": "`+os.Getenv("NGROK_APP_URL")+`/webhooks/Github"}}]`), nil)
+		mockServiceWebhookRepo.On("FindServiceWebhookSecret", "Github", "owner/repo").
+			Return("", errors.New("no rows")).Once()
+		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl+"/7", "PATCH", bearerType, "accessToken", hasSecret(&secret)).
+			Return(newHooksResponse(`{}`), nil).Once()
+		mockServiceWebhookRepo.On("SaveServiceWebhookSecret", "Github", "owner/repo", isSecret(&secret)).
+			Return(nil).Once()
+
+		err := github.checkNewWorkflowGithubWebhook(workflow, "accessToken")
+
+		require.NoError(test, err)
+		mockServiceServiceRepo.AssertExpectations(test)
+		mockServiceWebhookRepo.AssertExpectations(test)
+	})
+
+	test.Run("Fail Create Hook", func(test *testing.T) {
+		mockServiceServiceRepo := new(MockServiceServiceRepository)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		var secret string
+
+		github := &WorkflowService{
+			ServiceService:           mockServiceServiceRepo,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+			WorkflowRepository:       mockWorkflowRepo,
+		}
+
+		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl, "GET", bearerType, "accessToken", nil).
+			Return(newHooksResponse(`[]`), nil)
+		mockServiceServiceRepo.On("ExecuteApiRequest", githubBaseUrl+"repos/owner/repo/hooks", "POST", bearerType, "accessToken", hasSecret(&secret)).
+			Return((*http.Response)(nil), entities.ApiCallError{StatusCode: http.StatusForbidden}).Once()
+
+		err := github.checkNewWorkflowGithubWebhook(workflow, "accessToken")
+
+		require.ErrorAs(test, err, &entities.ApiCallError{})
+		mockServiceWebhookRepo.AssertNotCalled(test, "SaveServiceWebhookSecret", mock.Anything, mock.Anything, mock.Anything)
+		mockWorkflowRepo.AssertNotCalled(test, "UpdateWorkflowActionData", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Secret Refused On Existing Hook", func(test *testing.T) {
+		mockServiceServiceRepo := new(MockServiceServiceRepository)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+		var secret string
+
+		github := &WorkflowService{
+			ServiceService:           mockServiceServiceRepo,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+		}
+
+		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl, "GET", bearerType, "accessToken", nil).
+			Return(newHooksResponse(`[{"id": 7, "config": {"url": "`+os.Getenv("NGROK_APP_URL")+`/webhooks/Github"}}]`), nil)
+		mockServiceWebhookRepo.On("FindServiceWebhookSecret", "Github", "owner/repo").
+			Return("", errors.New("no rows")).Once()
+		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl+"/7", "PATCH", bearerType, "accessToken", hasSecret(&secret)).
+			Return((*http.Response)(nil), entities.ApiCallError{StatusCode: http.StatusNotFound}).Once()
+
+		err := github.checkNewWorkflowGithubWebhook(workflow, "accessToken")
+
+		require.Error(test, err)
+		mockServiceWebhookRepo.AssertNotCalled(test, "SaveServiceWebhookSecret", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Hook Already Secured", func(test *testing.T) {
+		mockServiceServiceRepo := new(MockServiceServiceRepository)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+
+		github := &WorkflowService{
+			ServiceService:           mockServiceServiceRepo,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+		}
+
+		mockServiceServiceRepo.On("ExecuteApiRequest", getWebhooksUrl, "GET", bearerType, "accessToken", nil).
+			Return(newHooksResponse(`[{"id": 7, "config": {"url": "`+os.Getenv("NGROK_APP_URL")+`/webhooks/Github"}}]`), nil)
+		mockServiceWebhookRepo.On("FindServiceWebhookSecret", "Github", "owner/repo").
+			Return("secret", nil).Once()
+
+		err := github.checkNewWorkflowGithubWebhook(workflow, "accessToken")
+
+		require.NoError(test, err)
+		mockServiceWebhookRepo.AssertNotCalled(test, "SaveServiceWebhookSecret", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 func TestCheckNewWorkflowsGithubWebhook(test *testing.T) {
@@ -356,7 +509,7 @@ func TestCheckNewGithubWorkflows(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", service.Id).
 			Return([]entities.Action{}, nil)
 
-		err := github.CheckNewGithubWorkflows()
+		err := github.CheckNewGithubWorkflows(context.Background())
 
 		require.NoError(test, err)
 	})
@@ -380,7 +533,7 @@ func TestCheckNewGithubWorkflows(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", service.Id).
 			Return([]entities.Action{}, errors.New("Fail find actions"))
 
-		err := github.CheckNewGithubWorkflows()
+		err := github.CheckNewGithubWorkflows(context.Background())
 
 		require.EqualError(test, err, "Fail find actions")
 	})
@@ -395,7 +548,7 @@ func TestCheckNewGithubWorkflows(test *testing.T) {
 		mockServiceServiceRepo.On("FindServiceByName", "Github").
 			Return(entities.Service{}, errors.New("Fail find service"))
 
-		err := github.CheckNewGithubWorkflows()
+		err := github.CheckNewGithubWorkflows(context.Background())
 
 		require.EqualError(test, err, "Fail find service")
 	})
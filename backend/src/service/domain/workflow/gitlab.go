@@ -2,7 +2,7 @@ package workflow_service
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,23 +13,6 @@ import (
 	"backend/src/entities"
 )
 
-func gitlabWebhooksEventsToActions() map[string]string {
-	return map[string]string{
-		"Push Hook":          "New push",
-		"Merge Request Hook": "Merge request update",
-		"Issue Hook":         "Issue update",
-		"Note Hook":          "Comment update",
-		"Tag Push Hook":      "New tag push",
-		"Wiki Page Hook":     "Wiki page update",
-		"Release Hook":       "Release update",
-		"Feature Flag Hook":  "Feature flag update",
-		"Pipeline Hook":      "Pipeline update",
-		"Job Hook":           "Job update",
-		"Deployment Hook":    "Deployment update",
-		"Emoji Hook":         "Emoji update",
-	}
-}
-
 func gitlabWebhookEventsToState() map[string]string {
 	return map[string]string{
 		"push_events":           "true",
@@ -47,56 +30,6 @@ func gitlabWebhookEventsToState() map[string]string {
 	}
 }
 
-func (self *WorkflowService) checkGitlabWebhooksWorkflow(workflow entities.Workflow, webhookProjectId int) error {
-	webhookProjectIdString := strconv.Itoa(webhookProjectId)
-	userProjectIdString, err := getWorkflowStringActionParam(workflow, "project")
-	if err != nil {
-		return err
-	}
-
-	if userProjectIdString == webhookProjectIdString {
-		self.checkReactions(workflow)
-	}
-	return nil
-}
-
-func (self *WorkflowService) checkGitlabWebhooksWorkflows(headers http.Header, webhookJsonDataBytes []byte, eventsToActions map[string]string, serviceId string) error {
-	var webhookResponse entities.GitlabWebhookTriggeredResponse
-	if len(webhookJsonDataBytes) > 0 {
-		err := json.Unmarshal(webhookJsonDataBytes, &webhookResponse)
-		if err != nil {
-			return err
-		}
-	}
-	if webhookResponse.Project.Id == 0 {
-		return fmt.Errorf("Incorrect project id")
-	}
-
-	eventName := headers["X-Gitlab-Event"][0]
-	actionName, actionNameExists := eventsToActions[eventName]
-	if !actionNameExists {
-		return fmt.Errorf("No action mapped with this event")
-	}
-
-	action, err := self.ActionRepository.FindActionByNameAndServiceId(actionName, serviceId)
-	if err != nil {
-		return err
-	}
-
-	workflows, err := self.WorkflowRepository.FindWorkflowsByActionId(action.Id)
-	if err != nil {
-		return err
-	}
-
-	for _, workflow := range workflows {
-		if !workflow.IsActivated {
-			continue
-		}
-		self.checkGitlabWebhooksWorkflow(workflow, webhookResponse.Project.Id)
-	}
-	return nil
-}
-
 func (self *WorkflowService) executeGitlabRequest(method, url, accessToken string, body io.Reader) (*http.Response, error) {
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
@@ -112,66 +45,85 @@ func (self *WorkflowService) executeGitlabRequest(method, url, accessToken strin
 	return res, nil
 }
 
-func (self *WorkflowService) createNewWorkflowGitlabWebhook(projectId, accessToken string) error {
-	createWebhookUrl := "https://gitlab.com/api/v4/projects/" + projectId + "/hooks"
-	webhookUrl := os.Getenv("NGROK_APP_URL") + "/webhooks/Gitlab"
+// GitLab sends the token back in the X-Gitlab-Token header of each delivery
+func newGitlabWebhookParams(token string) url.Values {
 	params := url.Values{}
 
-	params.Set("url", webhookUrl)
+	params.Set("url", os.Getenv("NGROK_APP_URL")+"/webhooks/Gitlab")
+	params.Set("token", token)
 	for webhookEvent, webhookState := range gitlabWebhookEventsToState() {
 		params.Set(webhookEvent, webhookState)
 	}
+	return params
+}
 
-	res, err := self.executeGitlabRequest("POST", createWebhookUrl, accessToken, bytes.NewBufferString(params.Encode()))
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
+func (self *WorkflowService) sendGitlabWebhook(method, url, projectId, accessToken string) error {
+	return self.registerServiceWebhookSecret("Gitlab", projectId, func(token string) error {
+		res, err := self.executeGitlabRequest(method, url, accessToken, bytes.NewBufferString(newGitlabWebhookParams(token).Encode()))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
 
-	return nil
+		return nil
+	})
+}
+
+func (self *WorkflowService) createNewWorkflowGitlabWebhook(projectId, accessToken string) error {
+	createWebhookUrl := "https://gitlab.com/api/v4/projects/" + projectId + "/hooks"
+
+	return self.sendGitlabWebhook("POST", createWebhookUrl, projectId, accessToken)
+}
+
+// Hooks registered before their deliveries were checked are given a token
+func (self *WorkflowService) updateGitlabWebhookToken(projectId, webhookId, accessToken string) error {
+	updateWebhookUrl := "https://gitlab.com/api/v4/projects/" + projectId + "/hooks/" + webhookId
+
+	return self.sendGitlabWebhook("PUT", updateWebhookUrl, projectId, accessToken)
 }
 
-func (self *WorkflowService) isGitlabProjectWebhookPresent(projectId, accessToken string) (bool, error) {
+func (self *WorkflowService) findGitlabProjectWebhookId(projectId, accessToken string) (string, bool, error) {
 	webhookUrl := os.Getenv("NGROK_APP_URL") + "/webhooks/Gitlab"
 	getWebhooksUrl := "https://gitlab.com/api/v4/projects/" + projectId + "/hooks"
 
 	res, err := self.executeGitlabRequest("GET", getWebhooksUrl, accessToken, nil)
 	if err != nil {
-		return false, err
+		return "", false, err
 	}
 	defer res.Body.Close()
 
 	webhooksJsonDataBytes, err := io.ReadAll(res.Body)
 	if err != nil {
-		return false, err
+		return "", false, err
 	}
 
 	webhooksJsonData, err := unmarshalJsonToMap(webhooksJsonDataBytes)
 	if err != nil {
-		return false, err
+		return "", false, err
 	}
 
 	if len(webhooksJsonData) == 0 {
-		return false, nil
+		return "", false, nil
 	}
 
 	for _, webhookJsonData := range webhooksJsonData {
 		url, urlExists := webhookJsonData["url"]
 		if !urlExists {
-			return false, fmt.Errorf(errorMissingField)
+			return "", false, fmt.Errorf(errorMissingField)
 		}
 
 		urlString, urlIsString := url.(string)
 		if !urlIsString {
-			return false, fmt.Errorf(errorMissingField)
+			return "", false, fmt.Errorf(errorMissingField)
 		}
 
 		if urlString == webhookUrl {
-			return true, nil
+			webhookId, _ := webhookJsonData["id"].(float64)
+			return strconv.FormatFloat(webhookId, 'f', -1, 64), true, nil
 		}
 	}
 
-	return false, nil
+	return "", false, nil
 }
 
 func (self *WorkflowService) checkNewWorkflowGitlabWebhook(workflow entities.Workflow, accessToken string) error {
@@ -180,17 +132,22 @@ func (self *WorkflowService) checkNewWorkflowGitlabWebhook(workflow entities.Wor
 		return err
 	}
 
-	isWebhookPresent, err := self.isGitlabProjectWebhookPresent(projectId, accessToken)
+	webhookId, isWebhookPresent, err := self.findGitlabProjectWebhookId(projectId, accessToken)
 	if err != nil {
 		return err
 	}
 
 	if !isWebhookPresent {
-		self.createNewWorkflowGitlabWebhook(projectId, accessToken)
-		err := self.WorkflowRepository.UpdateWorkflow(workflow.Id, workflow)
+		err = self.createNewWorkflowGitlabWebhook(projectId, accessToken)
+		if err != nil {
+			return err
+		}
+		err = self.WorkflowRepository.UpdateWorkflowActionData(workflow.Id, workflow.ActionData)
 		if err != nil {
 			return err
 		}
+	} else if !self.hasServiceWebhookSecret("Gitlab", projectId) {
+		return self.updateGitlabWebhookToken(projectId, webhookId, accessToken)
 	}
 	return nil
 }
@@ -211,7 +168,7 @@ func (self *WorkflowService) checkNewWorkflowsGitlabWebhook(action entities.Acti
 	return nil
 }
 
-func (self *WorkflowService) CheckNewGitlabWorkflows() error {
+func (self *WorkflowService) CheckNewGitlabWorkflows(ctx context.Context) error {
 	service, err := self.ServiceService.FindServiceByName("Gitlab")
 	if err != nil {
 		return err
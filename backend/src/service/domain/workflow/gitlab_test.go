@@ -1,49 +1,134 @@
 package workflow_service
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"backend/src/entities"
 )
 
-func TestCheckGitlabWebhooksWorkflow(test *testing.T) {
+func TestCheckNewWorkflowGitlabWebhook(test *testing.T) {
 	test.Run("Fail Get Action Param", func(test *testing.T) {
 		gitlab := &WorkflowService{}
 
 		workflow := entities.Workflow{}
 
-		err := gitlab.checkGitlabWebhooksWorkflow(workflow, 1)
+		err := gitlab.checkNewWorkflowGitlabWebhook(workflow, "accessToken")
 
 		require.EqualError(test, err, errorMissingField)
 	})
 
-	test.Run("Success", func(test *testing.T) {
-		gitlab := &WorkflowService{}
+	workflow := entities.Workflow{Id: "1", ActionParam: map[string]interface{}{"project": "42"}}
+	webhookUrl := os.Getenv("NGROK_APP_URL") + "/webhooks/Gitlab"
+	newResponse := func(body string) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}
+	}
+	isRequest := func(method, url string, token *string) interface{} {
+		return mock.MatchedBy(func(req *http.Request) bool {
+			if req.Method != method || req.URL.String() != url {
+				return false
+			}
+			if token == nil {
+				return true
+			}
+			body, _ := io.ReadAll(req.Body)
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			params, _ := neturl.ParseQuery(string(body))
+			*token = params.Get("token")
+			return params.Get("url") == webhookUrl && params.Get("token") != ""
+		})
+	}
+	isToken := func(token *string) interface{} {
+		return mock.MatchedBy(func(saved string) bool {
+			return saved == *token
+		})
+	}
+
+	test.Run("Create Hook With Token", func(test *testing.T) {
+		mockServiceService := new(MockHttpRequestServiceService)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		var token string
 
-		workflow := entities.Workflow{
-			ActionParam: map[string]interface{}{
-				"project": "value",
-			},
+		gitlab := &WorkflowService{
+			ServiceService:           mockServiceService,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+			WorkflowRepository:       mockWorkflowRepo,
 		}
 
-		err := gitlab.checkGitlabWebhooksWorkflow(workflow, 1)
+		mockServiceService.On("ExecuteRequest", isRequest("GET", "https://gitlab.com/api/v4/projects/42/hooks", nil)).
+			Return(newResponse(`[]`), nil).Once()
+		mockServiceService.On("ExecuteRequest", isRequest("POST", "https://gitlab.com/api/v4/projects/42/hooks", &token)).
+			Return(newResponse(`{}`), nil).Once()
+		mockServiceWebhookRepo.On("SaveServiceWebhookSecret", "Gitlab", "42", isToken(&token)).
+			Return(nil).Once()
+		mockWorkflowRepo.On("UpdateWorkflowActionData", "1", workflow.ActionData).
+			Return(nil).Once()
+
+		err := gitlab.checkNewWorkflowGitlabWebhook(workflow, "accessToken")
 
 		require.NoError(test, err)
+		mockServiceService.AssertExpectations(test)
+		mockServiceWebhookRepo.AssertExpectations(test)
 	})
-}
 
-func TestCheckNewWorkflowGitlabWebhook(test *testing.T) {
-	test.Run("Fail Get Action Param", func(test *testing.T) {
-		gitlab := &WorkflowService{}
+	test.Run("Register Token On Existing Hook", func(test *testing.T) {
+		mockServiceService := new(MockHttpRequestServiceService)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+		var token string
 
-		workflow := entities.Workflow{}
+		gitlab := &WorkflowService{
+			ServiceService:           mockServiceService,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+		}
+
+		mockServiceService.On("ExecuteRequest", isRequest("GET", "https://gitlab.com/api/v4/projects/42/hooks", nil)).
+			Return(newResponse(`[{"id": 9, "url": "`+webhookUrl+`"}]`), nil).Once()
+		mockServiceWebhookRepo.On("FindServiceWebhookSecret", "Gitlab", "42").
+			Return("", errors.New("no rows")).Once()
+		mockServiceService.On("ExecuteRequest", isRequest("PUT", "https://gitlab.com/api/v4/projects/42/hooks/9", &token)).
+			Return(newResponse(`{}`), nil).Once()
+		mockServiceWebhookRepo.On("SaveServiceWebhookSecret", "Gitlab", "42", isToken(&token)).
+			Return(nil).Once()
 
 		err := gitlab.checkNewWorkflowGitlabWebhook(workflow, "accessToken")
 
-		require.EqualError(test, err, errorMissingField)
+		require.NoError(test, err)
+		mockServiceService.AssertExpectations(test)
+		mockServiceWebhookRepo.AssertExpectations(test)
+	})
+	test.Run("Fail Create Hook", func(test *testing.T) {
+		mockServiceService := new(MockHttpRequestServiceService)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		var token string
+
+		gitlab := &WorkflowService{
+			ServiceService:           mockServiceService,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+			WorkflowRepository:       mockWorkflowRepo,
+		}
+
+		mockServiceService.On("ExecuteRequest", isRequest("GET", "https://gitlab.com/api/v4/projects/42/hooks", nil)).
+			Return(newResponse(`[]`), nil).Once()
+		mockServiceService.On("ExecuteRequest", isRequest("POST", "https://gitlab.com/api/v4/projects/42/hooks", &token)).
+			Return((*http.Response)(nil), entities.ApiCallError{StatusCode: http.StatusForbidden}).Once()
+
+		err := gitlab.checkNewWorkflowGitlabWebhook(workflow, "accessToken")
+
+		require.Error(test, err)
+		mockServiceWebhookRepo.AssertNotCalled(test, "SaveServiceWebhookSecret", mock.Anything, mock.Anything, mock.Anything)
+		mockWorkflowRepo.AssertNotCalled(test, "UpdateWorkflowActionData", mock.Anything, mock.Anything)
 	})
 }
 
@@ -98,7 +183,7 @@ func TestCheckNewGitlabWorkflows(test *testing.T) {
 		mockServiceServiceRepo.On("FindServiceByName", "Gitlab").
 			Return(entities.Service{}, errors.New("Fail find service"))
 
-		err := gitlab.CheckNewGitlabWorkflows()
+		err := gitlab.CheckNewGitlabWorkflows(context.Background())
 
 		require.EqualError(test, err, "Fail find service")
 	})
@@ -126,7 +211,7 @@ func TestCheckNewGitlabWorkflows(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", action.Id).
 			Return([]entities.Action{}, errors.New("Fail find service"))
 
-		err := gitlab.CheckNewGitlabWorkflows()
+		err := gitlab.CheckNewGitlabWorkflows(context.Background())
 
 		require.EqualError(test, err, "Fail find service")
 	})
@@ -154,7 +239,7 @@ func TestCheckNewGitlabWorkflows(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", action.Id).
 			Return([]entities.Action{}, nil)
 
-		err := gitlab.CheckNewGitlabWorkflows()
+		err := gitlab.CheckNewGitlabWorkflows(context.Background())
 
 		require.NoError(test, err)
 	})
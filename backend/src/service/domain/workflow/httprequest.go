@@ -0,0 +1,488 @@
+package workflow_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"backend/src/entities"
+)
+
+const httpRequestReactionName = "HTTP request"
+
+// Comma separated hosts and CIDRs the HTTP request reaction may call even if they are private or loopback
+const httpRequestAllowlistEnv = "HTTP_REQUEST_ALLOWLIST"
+
+const defaultHttpRequestTimeout = 10
+const maxHttpRequestTimeout = 60
+const maxHttpRequestRetries = 5
+
+const errorInvalidHttpMethod = "Invalid HTTP method"
+const errorInvalidHttpUrl = "Invalid URL, an http or https URL is expected"
+const errorForbiddenHttpAddress = "Requests to private or loopback addresses are not allowed"
+const errorInvalidHttpHeaders = "Invalid headers, a JSON object of strings is expected"
+const errorInvalidHttpBodyType = "Invalid body type, json or form is expected"
+const errorInvalidHttpBody = "Invalid body, it does not match the body type"
+const errorInvalidHttpTimeout = "Invalid timeout, a number of seconds between 1 and 60 is expected"
+const errorInvalidExpectedStatus = "Invalid expected status codes, comma separated status codes are expected"
+const errorInvalidHttpRetries = "Invalid retries, a number between 0 and 5 is expected"
+
+var httpRequestMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+var lookupHttpRequestHost = func(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// Not covered by the net.IP helpers, "this network" and the carrier-grade NAT range
+var forbiddenHttpRequestNetworks = []string{"0.0.0.0/8", "100.64.0.0/10"}
+
+type httpRequestSettings struct {
+	method         string
+	url            *url.URL
+	headers        map[string]string
+	body           []byte
+	contentType    string
+	timeout        time.Duration
+	expectedStatus []int
+	// Attempts of the job running the request, 0 keeps the JOB_MAX_ATTEMPTS of the jobs
+	maxAttempts int
+}
+
+type httpRequestGuard struct {
+	hosts      []string
+	networks   []*net.IPNet
+	allowedIps map[string]bool
+	blocked    bool
+	mutex      sync.Mutex
+}
+
+func newHttpRequestGuard(allowlist string) *httpRequestGuard {
+	guard := &httpRequestGuard{allowedIps: map[string]bool{}}
+
+	for _, entry := range strings.Split(allowlist, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err == nil {
+			guard.networks = append(guard.networks, network)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			guard.allowedIps[ip.String()] = true
+		} else {
+			guard.hosts = append(guard.hosts, entry)
+		}
+	}
+	return guard
+}
+
+func isForbiddenHttpRequestIp(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+
+	for _, cidr := range forbiddenHttpRequestNetworks {
+		_, network, _ := net.ParseCIDR(cidr)
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *httpRequestGuard) isAllowedIp(ip net.IP) bool {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	if !isForbiddenHttpRequestIp(ip) || self.allowedIps[ip.String()] {
+		return true
+	}
+
+	for _, network := range self.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// The addresses of an allowlisted host are allowed as they are resolved, so that the connection to them is not refused
+func (self *httpRequestGuard) checkHost(ctx context.Context, host string) error {
+	host = strings.ToLower(host)
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		resolvedIps, err := lookupHttpRequestHost(ctx, host)
+		if err != nil {
+			return err
+		}
+		ips = resolvedIps
+	}
+
+	for _, allowedHost := range self.hosts {
+		if allowedHost == host {
+			self.mutex.Lock()
+			for _, ip := range ips {
+				self.allowedIps[ip.String()] = true
+			}
+			self.mutex.Unlock()
+			return nil
+		}
+	}
+
+	for _, ip := range ips {
+		if !self.isAllowedIp(ip) {
+			return fmt.Errorf(errorForbiddenHttpAddress)
+		}
+	}
+	return nil
+}
+
+// Redirections and hosts resolving differently at connection time are checked on the address actually dialed,
+// the connection to a forbidden one is refused before being opened
+func (self *httpRequestGuard) control(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !self.isAllowedIp(ip) {
+		self.mutex.Lock()
+		self.blocked = true
+		self.mutex.Unlock()
+		return fmt.Errorf(errorForbiddenHttpAddress)
+	}
+	return nil
+}
+
+// The requests are never sent through a proxy, the guard would only see the address of the proxy
+func (self *httpRequestGuard) newClient() *http.Client {
+	dialer := &net.Dialer{Control: self.control}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+func (self *httpRequestGuard) isBlocked() bool {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	return self.blocked
+}
+
+func readHttpRequestMethod(method interface{}) (string, error) {
+	if method == nil {
+		return "GET", nil
+	}
+
+	methodString, methodIsString := method.(string)
+	if !methodIsString {
+		return "", fmt.Errorf(errorInvalidHttpMethod)
+	}
+
+	methodString = strings.ToUpper(strings.TrimSpace(methodString))
+	if methodString == "" {
+		return "GET", nil
+	}
+
+	for _, allowedMethod := range httpRequestMethods {
+		if methodString == allowedMethod {
+			return methodString, nil
+		}
+	}
+	return "", fmt.Errorf(errorInvalidHttpMethod)
+}
+
+func readHttpRequestUrl(rawUrl interface{}) (*url.URL, error) {
+	urlString, urlIsString := rawUrl.(string)
+	if !urlIsString {
+		return nil, fmt.Errorf(errorMissingField)
+	}
+
+	requestUrl, err := url.Parse(strings.TrimSpace(urlString))
+	if err != nil || (requestUrl.Scheme != "http" && requestUrl.Scheme != "https") || requestUrl.Hostname() == "" {
+		return nil, fmt.Errorf(errorInvalidHttpUrl)
+	}
+	return requestUrl, nil
+}
+
+func readHttpRequestHeaders(headers interface{}) (map[string]string, error) {
+	headersMap := map[string]interface{}{}
+
+	switch typedHeaders := headers.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if strings.TrimSpace(typedHeaders) == "" {
+			return nil, nil
+		}
+		if json.Unmarshal([]byte(typedHeaders), &headersMap) != nil {
+			return nil, fmt.Errorf(errorInvalidHttpHeaders)
+		}
+	case map[string]interface{}:
+		headersMap = typedHeaders
+	default:
+		return nil, fmt.Errorf(errorInvalidHttpHeaders)
+	}
+
+	headersStrings := make(map[string]string, len(headersMap))
+	for key, value := range headersMap {
+		valueString, valueIsString := value.(string)
+		if !valueIsString {
+			return nil, fmt.Errorf(errorInvalidHttpHeaders)
+		}
+		headersStrings[key] = valueString
+	}
+	return headersStrings, nil
+}
+
+// The body is either a string, sent as is, or an object encoded according to the body type
+func readHttpRequestBody(body, bodyType interface{}) ([]byte, string, error) {
+	bodyTypeString, _ := bodyType.(string)
+
+	switch strings.ToLower(strings.TrimSpace(bodyTypeString)) {
+	case "", "json":
+		switch typedBody := body.(type) {
+		case nil:
+			return nil, "", nil
+		case string:
+			if strings.TrimSpace(typedBody) == "" {
+				return nil, "", nil
+			}
+			if !json.Valid([]byte(typedBody)) {
+				return nil, "", fmt.Errorf(errorInvalidHttpBody)
+			}
+			return []byte(typedBody), "application/json", nil
+		case map[string]interface{}, []interface{}:
+			bodyBytes, err := json.Marshal(typedBody)
+			if err != nil {
+				return nil, "", fmt.Errorf(errorInvalidHttpBody)
+			}
+			return bodyBytes, "application/json", nil
+		}
+	case "form":
+		switch typedBody := body.(type) {
+		case nil:
+			return nil, "", nil
+		case string:
+			if strings.TrimSpace(typedBody) == "" {
+				return nil, "", nil
+			}
+			_, err := url.ParseQuery(typedBody)
+			if err != nil {
+				return nil, "", fmt.Errorf(errorInvalidHttpBody)
+			}
+			return []byte(typedBody), "application/x-www-form-urlencoded", nil
+		case map[string]interface{}:
+			form := url.Values{}
+			for key, value := range typedBody {
+				form.Set(key, formatEventVariable(value))
+			}
+			return []byte(form.Encode()), "application/x-www-form-urlencoded", nil
+		}
+	default:
+		return nil, "", fmt.Errorf(errorInvalidHttpBodyType)
+	}
+	return nil, "", fmt.Errorf(errorInvalidHttpBody)
+}
+
+// Numbers may come from the int parameters as well as from templated strings
+func readHttpRequestNumber(value interface{}, defaultValue, min, max int, message string) (int, error) {
+	var number float64
+
+	switch typedValue := value.(type) {
+	case nil:
+		return defaultValue, nil
+	case float64:
+		number = typedValue
+	case string:
+		if strings.TrimSpace(typedValue) == "" {
+			return defaultValue, nil
+		}
+		parsedNumber, err := strconv.ParseFloat(strings.TrimSpace(typedValue), 64)
+		if err != nil {
+			return 0, fmt.Errorf(message)
+		}
+		number = parsedNumber
+	default:
+		return 0, fmt.Errorf(message)
+	}
+
+	if number != float64(int(number)) || int(number) < min || int(number) > max {
+		return 0, fmt.Errorf(message)
+	}
+	return int(number), nil
+}
+
+func readHttpRequestExpectedStatus(expectedStatus interface{}) ([]int, error) {
+	var codes []string
+
+	switch typedStatus := expectedStatus.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		codes = []string{strconv.FormatFloat(typedStatus, 'f', -1, 64)}
+	case string:
+		codes = strings.Split(typedStatus, ",")
+	default:
+		return nil, fmt.Errorf(errorInvalidExpectedStatus)
+	}
+
+	var statusCodes []int
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+
+		statusCode, err := strconv.Atoi(code)
+		if err != nil || statusCode < 100 || statusCode > 599 {
+			return nil, fmt.Errorf(errorInvalidExpectedStatus)
+		}
+		statusCodes = append(statusCodes, statusCode)
+	}
+	return statusCodes, nil
+}
+
+func newHttpRequestSettings(reactionParam map[string]interface{}) (httpRequestSettings, error) {
+	var settings httpRequestSettings
+	var err error
+
+	settings.method, err = readHttpRequestMethod(reactionParam["method"])
+	if err != nil {
+		return settings, err
+	}
+	settings.url, err = readHttpRequestUrl(reactionParam["url"])
+	if err != nil {
+		return settings, err
+	}
+	settings.headers, err = readHttpRequestHeaders(reactionParam["headers"])
+	if err != nil {
+		return settings, err
+	}
+	settings.body, settings.contentType, err = readHttpRequestBody(reactionParam["body"], reactionParam["bodytype"])
+	if err != nil {
+		return settings, err
+	}
+
+	timeout, err := readHttpRequestNumber(reactionParam["timeout"], defaultHttpRequestTimeout, 1, maxHttpRequestTimeout, errorInvalidHttpTimeout)
+	if err != nil {
+		return settings, err
+	}
+	settings.timeout = time.Duration(timeout) * time.Second
+
+	settings.expectedStatus, err = readHttpRequestExpectedStatus(reactionParam["expectedstatus"])
+	if err != nil {
+		return settings, err
+	}
+	retries, err := readHttpRequestNumber(reactionParam["retries"], -1, 0, maxHttpRequestRetries, errorInvalidHttpRetries)
+	settings.maxAttempts = retries + 1
+	return settings, err
+}
+
+// Without expected status codes, any 2xx status is a success
+func (self httpRequestSettings) isExpectedStatus(statusCode int) bool {
+	if len(self.expectedStatus) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+
+	for _, expectedStatus := range self.expectedStatus {
+		if statusCode == expectedStatus {
+			return true
+		}
+	}
+	return false
+}
+
+func isRetryableHttpStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// Retryable failures, such as 429 and 5xx statuses or network errors, are attempted again by the job of the reaction
+func (self *WorkflowService) sendHttpRequest(workflow entities.Workflow) error {
+	settings, err := newHttpRequestSettings(workflow.ReactionParam)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), settings.timeout)
+	defer cancel()
+
+	guard := newHttpRequestGuard(os.Getenv(httpRequestAllowlistEnv))
+	err = guard.checkHost(ctx, settings.url.Hostname())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, settings.method, settings.url.String(), bytes.NewReader(settings.body))
+	if err != nil {
+		return err
+	}
+	if settings.contentType != "" {
+		req.Header.Set(contentType, settings.contentType)
+	}
+	for key, value := range settings.headers {
+		req.Header.Set(key, value)
+	}
+
+	var apiCallError entities.ApiCallError
+
+	client := guard.newClient()
+	defer client.CloseIdleConnections()
+
+	res, err := self.ServiceService.ExecuteRequestWithClient(client, req)
+	if errors.As(err, &apiCallError) {
+		if settings.isExpectedStatus(apiCallError.StatusCode) {
+			return nil
+		}
+		return apiCallError
+	}
+	if err != nil && guard.isBlocked() {
+		return fmt.Errorf(errorForbiddenHttpAddress)
+	}
+	if err != nil {
+		return err
+	}
+
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<20))
+	res.Body.Close()
+	if settings.isExpectedStatus(res.StatusCode) {
+		return nil
+	}
+	return entities.ApiCallError{StatusCode: res.StatusCode}
+}
+
+// The attempts chosen with the "retries" parameter, 0 when it is not given or the request is invalid
+func httpRequestMaxAttempts(reactionParam map[string]interface{}) int {
+	settings, err := newHttpRequestSettings(reactionParam)
+	if err != nil {
+		return 0
+	}
+	return settings.maxAttempts
+}
+
+func (self *WorkflowService) CheckHttpRequestReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
+	switch reactionFound.Name {
+	case httpRequestReactionName:
+		return self.sendHttpRequest(workflow)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package workflow_service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+type MockHttpRequestServiceService struct {
+	MockServiceServiceRepository
+}
+
+func (m *MockHttpRequestServiceService) ExecuteRequest(request *http.Request) (*http.Response, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*http.Response), args.Error(1)
+}
+
+func (m *MockHttpRequestServiceService) ExecuteRequestWithClient(client *http.Client, request *http.Request) (*http.Response, error) {
+	args := m.Called(client, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*http.Response), args.Error(1)
+}
+
+func newHttpResponse(statusCode int) *http.Response {
+	return &http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+func TestNewHttpRequestSettings(test *testing.T) {
+	test.Run("Defaults", func(test *testing.T) {
+		settings, err := newHttpRequestSettings(map[string]interface{}{"url": "https://example.com/hook"})
+
+		require.NoError(test, err)
+		require.Equal(test, "GET", settings.method)
+		require.Equal(test, "https://example.com/hook", settings.url.String())
+		require.Nil(test, settings.body)
+		require.Equal(test, 10, int(settings.timeout.Seconds()))
+		require.Equal(test, 0, settings.maxAttempts)
+		require.True(test, settings.isExpectedStatus(204))
+		require.False(test, settings.isExpectedStatus(301))
+	})
+
+	test.Run("Full Settings", func(test *testing.T) {
+		settings, err := newHttpRequestSettings(map[string]interface{}{
+			"method":         "post",
+			"url":            "http://example.com",
+			"headers":        `{"X-Api-Key": "key"}`,
+			"bodytype":       "json",
+			"body":           `{"title": "release"}`,
+			"timeout":        float64(30),
+			"expectedstatus": "200, 409",
+			"retries":        "2",
+		})
+
+		require.NoError(test, err)
+		require.Equal(test, "POST", settings.method)
+		require.Equal(test, map[string]string{"X-Api-Key": "key"}, settings.headers)
+		require.Equal(test, `{"title": "release"}`, string(settings.body))
+		require.Equal(test, "application/json", settings.contentType)
+		require.Equal(test, 30, int(settings.timeout.Seconds()))
+		require.Equal(test, []int{200, 409}, settings.expectedStatus)
+		require.Equal(test, 3, settings.maxAttempts)
+		require.True(test, settings.isExpectedStatus(409))
+		require.False(test, settings.isExpectedStatus(201))
+	})
+
+	test.Run("Form Body", func(test *testing.T) {
+		settings, err := newHttpRequestSettings(map[string]interface{}{
+			"url":      "http://example.com",
+			"bodytype": "form",
+			"body":     map[string]interface{}{"title": "release & notes", "score": float64(12)},
+		})
+
+		require.NoError(test, err)
+		require.Equal(test, "score=12&title=release+%26+notes", string(settings.body))
+		require.Equal(test, "application/x-www-form-urlencoded", settings.contentType)
+	})
+
+	test.Run("Invalid Settings", func(test *testing.T) {
+		invalidParams := map[string]map[string]interface{}{
+			errorMissingField:          {"method": "GET"},
+			errorInvalidHttpMethod:     {"method": "CONNECT", "url": "http://example.com"},
+			errorInvalidHttpUrl:        {"url": "ftp://example.com"},
+			errorInvalidHttpHeaders:    {"url": "http://example.com", "headers": `{"X-Count": 1}`},
+			errorInvalidHttpBodyType:   {"url": "http://example.com", "bodytype": "xml", "body": "<a/>"},
+			errorInvalidHttpBody:       {"url": "http://example.com", "body": "{not json"},
+			errorInvalidHttpTimeout:    {"url": "http://example.com", "timeout": float64(120)},
+			errorInvalidExpectedStatus: {"url": "http://example.com", "expectedstatus": "ok"},
+			errorInvalidHttpRetries:    {"url": "http://example.com", "retries": float64(1.5)},
+		}
+
+		for message, reactionParam := range invalidParams {
+			_, err := newHttpRequestSettings(reactionParam)
+			require.EqualError(test, err, message)
+		}
+	})
+}
+
+func TestHttpRequestGuard(test *testing.T) {
+	lookupHttpRequestHost = func(ctx context.Context, host string) ([]net.IP, error) {
+		switch host {
+		case "internal.example.com":
+			return []net.IP{net.ParseIP("10.0.0.5")}, nil
+		case "public.example.com":
+			return []net.IP{net.ParseIP("93.184.216.34")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	defer func() {
+		lookupHttpRequestHost = func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip", host)
+		}
+	}()
+
+	test.Run("Default Guard", func(test *testing.T) {
+		guard := newHttpRequestGuard("")
+
+		require.NoError(test, guard.checkHost(context.Background(), "public.example.com"))
+		require.NoError(test, guard.checkHost(context.Background(), "93.184.216.34"))
+		for _, host := range []string{"internal.example.com", "127.0.0.1", "::1", "169.254.169.254", "192.168.1.1", "0.0.0.0", "100.64.0.1"} {
+			require.EqualError(test, guard.checkHost(context.Background(), host), errorForbiddenHttpAddress, host)
+		}
+		require.Error(test, guard.checkHost(context.Background(), "unknown.example.com"))
+	})
+
+	test.Run("Allowlist", func(test *testing.T) {
+		guard := newHttpRequestGuard("internal.example.com, 192.168.0.0/16, 127.0.0.1")
+
+		require.NoError(test, guard.checkHost(context.Background(), "INTERNAL.example.com"))
+		require.True(test, guard.isAllowedIp(net.ParseIP("10.0.0.5")))
+		require.NoError(test, guard.checkHost(context.Background(), "192.168.1.1"))
+		require.NoError(test, guard.checkHost(context.Background(), "127.0.0.1"))
+		require.EqualError(test, guard.checkHost(context.Background(), "127.0.0.2"), errorForbiddenHttpAddress)
+	})
+
+	test.Run("Blocked Connection", func(test *testing.T) {
+		guard := newHttpRequestGuard("")
+
+		_, err := guard.newClient().Get("http://127.0.0.1:1/")
+
+		require.ErrorContains(test, err, errorForbiddenHttpAddress)
+		require.True(test, guard.isBlocked())
+	})
+
+	test.Run("Proxy Ignored", func(test *testing.T) {
+		test.Setenv("HTTP_PROXY", "http://93.184.216.34:3128")
+		guard := newHttpRequestGuard("")
+
+		_, err := guard.newClient().Get("http://10.0.0.5:1/")
+
+		require.ErrorContains(test, err, errorForbiddenHttpAddress)
+		require.True(test, guard.isBlocked())
+	})
+}
+
+func TestSendHttpRequest(test *testing.T) {
+	workflow := entities.Workflow{
+		ReactionParam: map[string]interface{}{
+			"method":         "POST",
+			"url":            "http://93.184.216.34/hook",
+			"headers":        map[string]interface{}{"X-Api-Key": "key"},
+			"body":           map[string]interface{}{"title": "release"},
+			"expectedstatus": "200,204",
+			"retries":        float64(2),
+		},
+	}
+
+	test.Run("Success", func(test *testing.T) {
+		mockServiceService := new(MockHttpRequestServiceService)
+		service := &WorkflowService{ServiceService: mockServiceService}
+
+		mockServiceService.On("ExecuteRequestWithClient", mock.MatchedBy(func(client *http.Client) bool {
+			transport, isTransport := client.Transport.(*http.Transport)
+			return isTransport && transport.Proxy == nil
+		}), mock.MatchedBy(func(req *http.Request) bool {
+			body, _ := io.ReadAll(req.Body)
+			return req.Method == "POST" && req.URL.String() == "http://93.184.216.34/hook" &&
+				req.Header.Get("X-Api-Key") == "key" && req.Header.Get(contentType) == "application/json" &&
+				string(body) == `{"title":"release"}`
+		})).Return(nil, entities.ApiCallError{StatusCode: 204}).Once()
+
+		require.NoError(test, service.sendHttpRequest(workflow))
+		mockServiceService.AssertExpectations(test)
+	})
+
+	test.Run("Server Error Sent Once", func(test *testing.T) {
+		mockServiceService := new(MockHttpRequestServiceService)
+		service := &WorkflowService{ServiceService: mockServiceService}
+
+		mockServiceService.On("ExecuteRequestWithClient", mock.Anything, mock.Anything).
+			Return(nil, entities.ApiCallError{StatusCode: 502, RetryAfter: time.Minute})
+
+		err := service.sendHttpRequest(workflow)
+
+		require.Equal(test, entities.ApiCallError{StatusCode: 502, RetryAfter: time.Minute}, err)
+		require.True(test, isRetryableReactionError(err))
+		mockServiceService.AssertNumberOfCalls(test, "ExecuteRequestWithClient", 1)
+	})
+
+	test.Run("Network Error", func(test *testing.T) {
+		mockServiceService := new(MockHttpRequestServiceService)
+		service := &WorkflowService{ServiceService: mockServiceService}
+		networkError := &url.Error{Op: "Post", URL: "http://93.184.216.34/hook", Err: &net.OpError{Op: "dial"}}
+
+		mockServiceService.On("ExecuteRequestWithClient", mock.Anything, mock.Anything).
+			Return(nil, networkError)
+
+		err := service.sendHttpRequest(workflow)
+
+		require.Equal(test, networkError, err)
+		mockServiceService.AssertNumberOfCalls(test, "ExecuteRequestWithClient", 1)
+	})
+
+	test.Run("Unexpected Status Not Retried", func(test *testing.T) {
+		mockServiceService := new(MockHttpRequestServiceService)
+		service := &WorkflowService{ServiceService: mockServiceService}
+
+		mockServiceService.On("ExecuteRequestWithClient", mock.Anything, mock.Anything).
+			Return(newHttpResponse(201), nil).Once()
+
+		err := service.sendHttpRequest(workflow)
+
+		require.Equal(test, entities.ApiCallError{StatusCode: 201}, err)
+		mockServiceService.AssertNumberOfCalls(test, "ExecuteRequestWithClient", 1)
+	})
+
+	test.Run("Forbidden Address", func(test *testing.T) {
+		mockServiceService := new(MockHttpRequestServiceService)
+		service := &WorkflowService{ServiceService: mockServiceService}
+
+		err := service.sendHttpRequest(entities.Workflow{
+			ReactionParam: map[string]interface{}{"url": "http://127.0.0.1:8080/admin"},
+		})
+
+		require.EqualError(test, err, errorForbiddenHttpAddress)
+		mockServiceService.AssertNotCalled(test, "ExecuteRequestWithClient", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Allowlisted Address", func(test *testing.T) {
+		test.Setenv(httpRequestAllowlistEnv, "127.0.0.0/8")
+		mockServiceService := new(MockHttpRequestServiceService)
+		service := &WorkflowService{ServiceService: mockServiceService}
+
+		mockServiceService.On("ExecuteRequestWithClient", mock.Anything, mock.Anything).
+			Return(newHttpResponse(200), nil).Once()
+
+		err := service.sendHttpRequest(entities.Workflow{
+			ReactionParam: map[string]interface{}{"url": "http://127.0.0.1:8080/admin"},
+		})
+
+		require.NoError(test, err)
+	})
+}
+
+func TestHttpRequestMaxAttempts(test *testing.T) {
+	require.Equal(test, 3, httpRequestMaxAttempts(map[string]interface{}{"url": "http://example.com", "retries": float64(2)}))
+	require.Equal(test, 1, httpRequestMaxAttempts(map[string]interface{}{"url": "http://example.com", "retries": "0"}))
+	require.Equal(test, 0, httpRequestMaxAttempts(map[string]interface{}{"url": "http://example.com"}))
+	require.Equal(test, 0, httpRequestMaxAttempts(map[string]interface{}{"retries": float64(2)}))
+}
+
+func TestCheckHttpRequestReactions(test *testing.T) {
+	service := &WorkflowService{}
+
+	err := service.CheckHttpRequestReactions(entities.Workflow{}, entities.Reaction{Name: "Unknown"})
+	require.NoError(test, err)
+
+	err = service.CheckHttpRequestReactions(entities.Workflow{}, entities.Reaction{Name: httpRequestReactionName})
+	require.EqualError(test, err, errorMissingField)
+}
@@ -0,0 +1,230 @@
+package workflow_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/src/entities"
+)
+
+const jobWorkersEnv = "JOB_WORKERS"
+const defaultJobWorkers = 2
+const jobMaxAttemptsEnv = "JOB_MAX_ATTEMPTS"
+const defaultJobMaxAttempts = 5
+const jobRetryMaxDelay = time.Hour
+
+// A running job locked for longer belongs to a worker which stopped, it is claimed again
+const jobLockTimeout = 10 * time.Minute
+
+const errorEnqueuingJob = "Could not enqueue reaction job"
+
+var jobPollInterval = time.Second
+var jobRetryBaseDelay = 30 * time.Second
+
+// <VARIABLE>_<SERVICE> overrides <VARIABLE> for one service, e.g. JOB_WORKERS_DROPBOX=1
+func serviceCountFromEnv(variable, serviceName string, defaultCount int) int {
+	for _, name := range []string{variable + "_" + strings.ToUpper(serviceName), variable} {
+		count, err := strconv.Atoi(os.Getenv(name))
+		if err == nil && count > 0 {
+			return count
+		}
+	}
+	return defaultCount
+}
+
+func jobWorkersCount(serviceName string) int {
+	return serviceCountFromEnv(jobWorkersEnv, serviceName, defaultJobWorkers)
+}
+
+func jobMaxAttempts() int {
+	attempts, err := strconv.Atoi(os.Getenv(jobMaxAttemptsEnv))
+	if err != nil || attempts < 1 {
+		return defaultJobMaxAttempts
+	}
+	return attempts
+}
+
+// The HTTP request reaction chooses its number of attempts with its "retries" parameter
+func reactionMaxAttempts(reactionName string, reactionParam map[string]interface{}) int {
+	if reactionName == httpRequestReactionName {
+		maxAttempts := httpRequestMaxAttempts(reactionParam)
+		if maxAttempts > 0 {
+			return maxAttempts
+		}
+	}
+	return jobMaxAttempts()
+}
+
+// Rate limits, server errors and network errors may pass on a later attempt, the other errors are permanent
+func isRetryableReactionError(err error) bool {
+	var apiCallError entities.ApiCallError
+	var networkError net.Error
+
+	if errors.As(err, &apiCallError) {
+		return isRetryableHttpStatus(apiCallError.StatusCode)
+	}
+	return errors.As(err, &networkError)
+}
+
+// Exponential backoff with jitter from the number of attempts already made, a longer Retry-After is honored
+func jobRetryDelay(attempts int, err error) time.Duration {
+	var apiCallError entities.ApiCallError
+
+	delay := jobRetryBaseDelay
+	for attempt := 1; attempt < attempts && delay < jobRetryMaxDelay; attempt++ {
+		delay *= 2
+	}
+	delay = min(delay, jobRetryMaxDelay)
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	if errors.As(err, &apiCallError) && apiCallError.RetryAfter > delay {
+		return apiCallError.RetryAfter
+	}
+	return delay
+}
+
+// Enqueues the first step of steps, the steps which service cannot be resolved are recorded as failed runs.
+// runFailed tells whether a previous step of the run already failed.
+func (self *WorkflowService) enqueueReactionSteps(workflow entities.Workflow, event entities.ActionEvent, steps []entities.WorkflowReaction, runFailed bool) {
+	for index, step := range steps {
+		_, serviceName, _, err := self.resolveReaction(step.ReactionId)
+		if err == nil {
+			err = self.JobRepository.EnqueueJob(entities.ReactionJob{
+				WorkflowId:  workflow.Id,
+				ServiceName: serviceName,
+				Workflow:    workflow,
+				Event:       event,
+				Steps:       steps[index:],
+				RunFailed:   runFailed,
+			})
+			if err == nil {
+				return
+			}
+			err = fmt.Errorf(errorEnqueuingJob)
+		}
+
+		result := setReactionResultError(entities.ReactionResult{ReactionId: step.ReactionId, ServiceName: serviceName}, err)
+		self.recordWorkflowRun(workflow, event, result, runFailed)
+		runFailed = true
+		if !step.ContinueOnError {
+			return
+		}
+	}
+}
+
+// The workflow is reloaded before each step, the steps of a workflow deleted or deactivated since they were enqueued are dropped.
+// A one-shot workflow is deactivated by its trigger before its steps are enqueued, they still run.
+func (self *WorkflowService) findJobWorkflow(job entities.ReactionJob) (entities.Workflow, bool, error) {
+	workflow, err := self.WorkflowRepository.FindWorkflowById(job.WorkflowId)
+	if errors.As(err, &entities.WorkflowNotFoundError{}) {
+		return workflow, false, nil
+	}
+	if err != nil {
+		return workflow, false, err
+	}
+	return workflow, workflow.IsActivated || !job.Workflow.IsActivated, nil
+}
+
+// Executes the first step of the job, the following steps are enqueued unless the step failed without continueonerror.
+// A retryable failure is attempted again later, the job failing for good is kept as a dead letter.
+func (self *WorkflowService) runReactionJob(job entities.ReactionJob) entities.ReactionResult {
+	if len(job.Steps) == 0 {
+		self.JobRepository.CompleteJob(job.Id)
+		return entities.ReactionResult{}
+	}
+
+	currentWorkflow, runnable, err := self.findJobWorkflow(job)
+	if err != nil {
+		self.JobRepository.RetryJob(job.Id, errorRetrievingWorkflow, time.Now().Add(jobRetryDelay(job.Attempts, err)))
+		return entities.ReactionResult{}
+	}
+	if !runnable {
+		self.JobRepository.CompleteJob(job.Id)
+		return entities.ReactionResult{}
+	}
+
+	step := job.Steps[0]
+	workflow := currentWorkflow
+	workflow.ReactionId = step.ReactionId
+	workflow.ReactionParam = renderReactionParams(step.ReactionParam, job.Event)
+
+	result, err := self.executeReaction(workflow)
+	if result.ServiceName != "" {
+		self.UserServiceService.RecordServiceCall(workflow.OwnerId, result.ServiceName, err)
+	}
+	if isRetryableReactionError(err) && job.Attempts < reactionMaxAttempts(result.ReactionName, workflow.ReactionParam) {
+		self.JobRepository.RetryJob(job.Id, result.ErrorMessage, time.Now().Add(jobRetryDelay(job.Attempts, err)))
+		return result
+	}
+	self.recordWorkflowRun(workflow, job.Event, result, job.RunFailed)
+
+	failed := result.Status == entities.WorkflowRunFailure
+	if !failed || step.ContinueOnError {
+		self.enqueueReactionSteps(currentWorkflow, job.Event, job.Steps[1:], job.RunFailed || failed)
+	}
+	if failed {
+		self.JobRepository.FailJob(job.Id, result.ErrorMessage)
+		return result
+	}
+	// The workflow run succeeds with its last step, unless one of its previous steps failed
+	if len(job.Steps) == 1 && !job.RunFailed {
+		self.WorkflowRepository.ResetWorkflowFailures(job.WorkflowId)
+	}
+	self.JobRepository.CompleteJob(job.Id)
+	return result
+}
+
+func (self *WorkflowService) runJobWorker(ctx context.Context, serviceName string) {
+	for ctx.Err() == nil {
+		job, found, err := self.JobRepository.ClaimJob(serviceName, time.Now().Add(-jobLockTimeout))
+		if err != nil || !found {
+			select {
+			case <-ctx.Done():
+			case <-time.After(jobPollInterval):
+			}
+			continue
+		}
+		self.runReactionJob(job)
+	}
+}
+
+// Starts the workers executing the enqueued reaction jobs until ctx is done, each reaction service has its own workers
+// so a slow service does not delay the reactions of the others
+func (self *WorkflowService) StartJobWorkers(ctx context.Context) {
+	for _, reactionConnector := range self.ServiceService.RetrieveConnectors() {
+		if reactionConnector.ReactionHandler() == nil {
+			continue
+		}
+		serviceName := reactionConnector.Definition().Name
+		for worker := 0; worker < jobWorkersCount(serviceName); worker++ {
+			self.jobWorkers.Add(1)
+			go func(serviceName string) {
+				defer self.jobWorkers.Done()
+				self.runJobWorker(ctx, serviceName)
+			}(serviceName)
+		}
+	}
+}
+
+// Waits for the workers to finish their jobs in progress once the ctx given to StartJobWorkers is done
+func (self *WorkflowService) WaitJobWorkers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		self.jobWorkers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
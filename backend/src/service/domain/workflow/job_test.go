@@ -0,0 +1,547 @@
+package workflow_service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) EnqueueJob(job entities.ReactionJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ClaimJob(serviceName string, staleBefore time.Time) (entities.ReactionJob, bool, error) {
+	args := m.Called(serviceName, staleBefore)
+	return args.Get(0).(entities.ReactionJob), args.Bool(1), args.Error(2)
+}
+
+func (m *MockJobRepository) CompleteJob(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) FailJob(id, lastError string) error {
+	args := m.Called(id, lastError)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) RetryJob(id, lastError string, runAt time.Time) error {
+	args := m.Called(id, lastError, runAt)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) FindFailedJobsByWorkflowId(workflowId string) ([]entities.ReactionJob, error) {
+	args := m.Called(workflowId)
+	return args.Get(0).([]entities.ReactionJob), args.Error(1)
+}
+
+func (m *MockJobRepository) RequeueFailedJobs(workflowId string) (int, error) {
+	args := m.Called(workflowId)
+	return args.Int(0), args.Error(1)
+}
+
+// Reaction "1" is a Discord reaction failing without its parameters, reaction "2" an SMS reaction doing nothing
+func newReactionJobService(workflowReactions []entities.WorkflowReaction) (*WorkflowService, *MockJobRepository, *MockWorkflowRunRepository) {
+	mockWorkflowRepo := new(MockWorkflowRepository)
+	mockReactionRepo := new(MockReactionRepository)
+	mockServiceService := new(MockServiceServiceRepository)
+	mockWorkflowReactionRepo := new(MockWorkflowReactionRepository)
+	mockWorkflowRunRepo := new(MockWorkflowRunRepository)
+	mockJobRepo := new(MockJobRepository)
+	mockUserServiceService := new(MockUserServiceRepository)
+
+	mockWorkflowReactionRepo.On("FindWorkflowReactionsByWorkflowId", "workflow").
+		Return(workflowReactions, nil)
+	mockReactionRepo.On("FindReactionById", "1").
+		Return(entities.Reaction{Name: "reaction", ServiceId: "discord"}, nil)
+	mockReactionRepo.On("FindReactionById", "2").
+		Return(entities.Reaction{Name: "reaction", ServiceId: "sms"}, nil)
+	mockServiceService.On("FindServiceById", "discord").
+		Return(entities.Service{Name: "Discord"}, nil)
+	mockServiceService.On("FindServiceById", "sms").
+		Return(entities.Service{Name: "SMS"}, nil)
+	onFindReactionConnector(mockServiceService, "Discord")
+	onFindReactionConnector(mockServiceService, "SMS")
+	mockWorkflowRunRepo.On("CreateWorkflowRun", "workflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	mockWorkflowRepo.On("IncrementWorkflowFailures", "workflow").
+		Return(1, nil)
+	mockWorkflowRepo.On("ResetWorkflowFailures", "workflow").
+		Return(nil)
+	mockUserServiceService.On("RecordServiceCall", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	mockWorkflowRepo.On("FindWorkflowById", "workflow").
+		Return(entities.Workflow{Id: "workflow", OwnerId: "owner", IsActivated: true}, nil)
+
+	return &WorkflowService{
+		WorkflowRepository:         mockWorkflowRepo,
+		ReactionRepository:         mockReactionRepo,
+		ServiceService:             mockServiceService,
+		WorkflowReactionRepository: mockWorkflowReactionRepo,
+		WorkflowRunRepository:      mockWorkflowRunRepo,
+		JobRepository:              mockJobRepo,
+		UserServiceService:         mockUserServiceService,
+	}, mockJobRepo, mockWorkflowRunRepo
+}
+
+func TestJobWorkersCount(test *testing.T) {
+	require.Equal(test, defaultJobWorkers, jobWorkersCount("Dropbox"))
+
+	test.Setenv(jobWorkersEnv, "4")
+	require.Equal(test, 4, jobWorkersCount("Dropbox"))
+
+	test.Setenv("JOB_WORKERS_DROPBOX", "1")
+	require.Equal(test, 1, jobWorkersCount("Dropbox"))
+	require.Equal(test, 4, jobWorkersCount("HTTP"))
+
+	test.Setenv("JOB_WORKERS_HTTP", "none")
+	require.Equal(test, 4, jobWorkersCount("HTTP"))
+}
+
+func TestEnqueueReactionSteps(test *testing.T) {
+	workflow := entities.Workflow{Id: "workflow"}
+
+	test.Run("Unknown Reaction Stops The Workflow", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService(nil)
+		service.ReactionRepository.(*MockReactionRepository).On("FindReactionById", "3").
+			Return(entities.Reaction{}, errors.New("no rows"))
+
+		service.enqueueReactionSteps(workflow, entities.ActionEvent{}, []entities.WorkflowReaction{{ReactionId: "3"}, {ReactionId: "2"}}, false)
+
+		mockWorkflowRunRepo.AssertCalled(test, "CreateWorkflowRun", "workflow", "3", entities.WorkflowRunFailure, errorRetrievingReaction, 0, mock.Anything)
+		mockJobRepo.AssertNotCalled(test, "EnqueueJob", mock.Anything)
+	})
+
+	test.Run("Unknown Reaction Continued", func(test *testing.T) {
+		service, mockJobRepo, _ := newReactionJobService(nil)
+		service.ReactionRepository.(*MockReactionRepository).On("FindReactionById", "3").
+			Return(entities.Reaction{}, errors.New("no rows"))
+
+		mockJobRepo.On("EnqueueJob", mock.MatchedBy(func(job entities.ReactionJob) bool {
+			return job.ServiceName == "SMS" && len(job.Steps) == 1 && job.Steps[0].ReactionId == "2" && job.RunFailed
+		})).Return(nil).Once()
+
+		service.enqueueReactionSteps(workflow, entities.ActionEvent{}, []entities.WorkflowReaction{{ReactionId: "3", ContinueOnError: true}, {ReactionId: "2"}}, false)
+
+		mockJobRepo.AssertExpectations(test)
+	})
+
+	test.Run("Failed Run Counted Once", func(test *testing.T) {
+		service, mockJobRepo, _ := newReactionJobService(nil)
+		service.ReactionRepository.(*MockReactionRepository).On("FindReactionById", "3").
+			Return(entities.Reaction{}, errors.New("no rows"))
+
+		service.enqueueReactionSteps(workflow, entities.ActionEvent{}, []entities.WorkflowReaction{{ReactionId: "3", ContinueOnError: true}, {ReactionId: "3"}}, false)
+
+		mockJobRepo.AssertNotCalled(test, "EnqueueJob", mock.Anything)
+		service.WorkflowRepository.(*MockWorkflowRepository).AssertNumberOfCalls(test, "IncrementWorkflowFailures", 1)
+	})
+}
+
+func TestRunReactionJob(test *testing.T) {
+	event := entities.ActionEvent{"post": map[string]interface{}{"title": "title"}}
+	newJob := func(steps ...entities.WorkflowReaction) entities.ReactionJob {
+		return entities.ReactionJob{Id: "job", WorkflowId: "workflow", Workflow: entities.Workflow{Id: "workflow", OwnerId: "owner", IsActivated: true}, Event: event, Steps: steps}
+	}
+
+	test.Run("Stop On Error", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService(nil)
+
+		mockJobRepo.On("FailJob", "job", mock.Anything).
+			Return(nil).Once()
+
+		result := service.runReactionJob(newJob(entities.WorkflowReaction{ReactionId: "1"}, entities.WorkflowReaction{ReactionId: "2"}))
+
+		require.Equal(test, entities.WorkflowRunFailure, result.Status)
+		mockJobRepo.AssertExpectations(test)
+		mockJobRepo.AssertNotCalled(test, "EnqueueJob", mock.Anything)
+		mockWorkflowRunRepo.AssertNumberOfCalls(test, "CreateWorkflowRun", 1)
+		service.UserServiceService.(*MockUserServiceRepository).AssertCalled(test, "RecordServiceCall", "owner", "Discord", mock.Anything)
+	})
+
+	test.Run("Continue On Error", func(test *testing.T) {
+		service, mockJobRepo, _ := newReactionJobService(nil)
+
+		mockJobRepo.On("EnqueueJob", mock.MatchedBy(func(job entities.ReactionJob) bool {
+			return job.ServiceName == "SMS" && len(job.Steps) == 1 && job.Steps[0].ReactionId == "2" && job.RunFailed
+		})).Return(nil).Once()
+		mockJobRepo.On("FailJob", "job", mock.Anything).
+			Return(nil).Once()
+
+		result := service.runReactionJob(newJob(entities.WorkflowReaction{ReactionId: "1", ContinueOnError: true}, entities.WorkflowReaction{ReactionId: "2"}))
+
+		require.Equal(test, entities.WorkflowRunFailure, result.Status)
+		mockJobRepo.AssertExpectations(test)
+	})
+
+	test.Run("Run Already Failed", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService(nil)
+		job := newJob(entities.WorkflowReaction{ReactionId: "1", ContinueOnError: true}, entities.WorkflowReaction{ReactionId: "2"})
+		job.RunFailed = true
+
+		mockJobRepo.On("EnqueueJob", mock.MatchedBy(func(job entities.ReactionJob) bool {
+			return job.RunFailed
+		})).Return(nil).Once()
+		mockJobRepo.On("FailJob", "job", mock.Anything).
+			Return(nil).Once()
+
+		service.runReactionJob(job)
+
+		mockWorkflowRunRepo.AssertNumberOfCalls(test, "CreateWorkflowRun", 1)
+		service.WorkflowRepository.(*MockWorkflowRepository).AssertNotCalled(test, "IncrementWorkflowFailures", mock.Anything)
+	})
+
+	test.Run("Last Step Of A Failed Run", func(test *testing.T) {
+		service, mockJobRepo, _ := newReactionJobService(nil)
+		job := newJob(entities.WorkflowReaction{ReactionId: "2"})
+		job.RunFailed = true
+
+		mockJobRepo.On("CompleteJob", "job").
+			Return(nil).Once()
+
+		result := service.runReactionJob(job)
+
+		require.Equal(test, entities.WorkflowRunSuccess, result.Status)
+		service.WorkflowRepository.(*MockWorkflowRepository).AssertNotCalled(test, "ResetWorkflowFailures", mock.Anything)
+	})
+
+	test.Run("Last Step Succeeds", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService(nil)
+
+		mockJobRepo.On("CompleteJob", "job").
+			Return(nil).Once()
+
+		result := service.runReactionJob(newJob(entities.WorkflowReaction{ReactionId: "2", ReactionParam: map[string]interface{}{"body": "{{post.title}}"}}))
+
+		require.Equal(test, entities.WorkflowRunSuccess, result.Status)
+		require.Equal(test, "SMS", result.ServiceName)
+		mockJobRepo.AssertExpectations(test)
+		mockJobRepo.AssertNotCalled(test, "EnqueueJob", mock.Anything)
+		mockWorkflowRunRepo.AssertCalled(test, "CreateWorkflowRun", "workflow", "2", entities.WorkflowRunSuccess, "", 0, mock.Anything)
+		service.WorkflowRepository.(*MockWorkflowRepository).AssertCalled(test, "ResetWorkflowFailures", "workflow")
+		service.UserServiceService.(*MockUserServiceRepository).AssertCalled(test, "RecordServiceCall", "owner", "SMS", nil)
+	})
+
+	test.Run("Deleted Workflow", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService(nil)
+		service.WorkflowRepository = new(MockWorkflowRepository)
+		service.WorkflowRepository.(*MockWorkflowRepository).On("FindWorkflowById", "workflow").
+			Return(entities.Workflow{}, entities.WorkflowNotFoundError{})
+
+		mockJobRepo.On("CompleteJob", "job").
+			Return(nil).Once()
+
+		result := service.runReactionJob(newJob(entities.WorkflowReaction{ReactionId: "2"}, entities.WorkflowReaction{ReactionId: "2"}))
+
+		require.Empty(test, result.Status)
+		mockJobRepo.AssertExpectations(test)
+		mockJobRepo.AssertNotCalled(test, "EnqueueJob", mock.Anything)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Deactivated Workflow", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService(nil)
+		service.WorkflowRepository = new(MockWorkflowRepository)
+		service.WorkflowRepository.(*MockWorkflowRepository).On("FindWorkflowById", "workflow").
+			Return(entities.Workflow{Id: "workflow", IsActivated: false}, nil)
+
+		mockJobRepo.On("CompleteJob", "job").
+			Return(nil).Once()
+
+		service.runReactionJob(newJob(entities.WorkflowReaction{ReactionId: "2"}))
+
+		mockJobRepo.AssertExpectations(test)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("One-Shot Workflow", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService(nil)
+		service.WorkflowRepository.(*MockWorkflowRepository).On("FindWorkflowById", "oneshot").
+			Return(entities.Workflow{Id: "oneshot", IsActivated: false}, nil)
+		mockWorkflowRunRepo.On("CreateWorkflowRun", "oneshot", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+		service.WorkflowRepository.(*MockWorkflowRepository).On("ResetWorkflowFailures", "oneshot").
+			Return(nil)
+
+		mockJobRepo.On("CompleteJob", "job").
+			Return(nil).Once()
+
+		result := service.runReactionJob(entities.ReactionJob{Id: "job", WorkflowId: "oneshot", Workflow: entities.Workflow{Id: "oneshot"}, Steps: []entities.WorkflowReaction{{ReactionId: "2"}}})
+
+		require.Equal(test, entities.WorkflowRunSuccess, result.Status)
+		mockWorkflowRunRepo.AssertCalled(test, "CreateWorkflowRun", "oneshot", "2", entities.WorkflowRunSuccess, "", 0, mock.Anything)
+	})
+
+	test.Run("Workflow Not Retrieved", func(test *testing.T) {
+		service, mockJobRepo, _ := newReactionJobService(nil)
+		service.WorkflowRepository = new(MockWorkflowRepository)
+		service.WorkflowRepository.(*MockWorkflowRepository).On("FindWorkflowById", "workflow").
+			Return(entities.Workflow{}, errors.New("connection refused"))
+
+		mockJobRepo.On("RetryJob", "job", errorRetrievingWorkflow, mock.Anything).
+			Return(nil).Once()
+
+		service.runReactionJob(newJob(entities.WorkflowReaction{ReactionId: "2"}))
+
+		mockJobRepo.AssertExpectations(test)
+	})
+}
+
+func TestIsRetryableReactionError(test *testing.T) {
+	require.True(test, isRetryableReactionError(entities.ApiCallError{StatusCode: 429}))
+	require.True(test, isRetryableReactionError(entities.ApiCallError{StatusCode: 503}))
+	require.True(test, isRetryableReactionError(&url.Error{Op: "Post", URL: "https://api.dropboxapi.com", Err: &net.OpError{Op: "dial"}}))
+	require.False(test, isRetryableReactionError(entities.ApiCallError{StatusCode: 400}))
+	require.False(test, isRetryableReactionError(entities.ApiCallError{StatusCode: 401}))
+	require.False(test, isRetryableReactionError(errors.New(errorMissingField)))
+	require.False(test, isRetryableReactionError(nil))
+}
+
+func TestJobRetryDelay(test *testing.T) {
+	for attempts, maxDelay := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 20: time.Hour} {
+		delay := jobRetryDelay(attempts, errors.New("connection reset"))
+
+		require.GreaterOrEqual(test, delay, maxDelay/2)
+		require.LessOrEqual(test, delay, maxDelay)
+	}
+
+	delay := jobRetryDelay(1, entities.ApiCallError{StatusCode: 429, RetryAfter: 10 * time.Minute})
+	require.Equal(test, 10*time.Minute, delay)
+}
+
+func TestRunReactionJobRetry(test *testing.T) {
+	job := entities.ReactionJob{
+		Id:         "job",
+		WorkflowId: "workflow",
+		Workflow:   entities.Workflow{Id: "workflow", OwnerId: "owner", IsActivated: true},
+		Steps: []entities.WorkflowReaction{
+			{ReactionId: "3", ReactionParam: map[string]interface{}{"url": "http://93.184.216.34/hook"}},
+			{ReactionId: "3"},
+		},
+	}
+	newService := func(err error) (*WorkflowService, *MockJobRepository, *MockWorkflowRunRepository) {
+		mockReactionRepo := new(MockReactionRepository)
+		mockServiceService := new(MockHttpRequestServiceService)
+		mockWorkflowRunRepo := new(MockWorkflowRunRepository)
+		mockJobRepo := new(MockJobRepository)
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockUserServiceService := new(MockUserServiceRepository)
+
+		mockReactionRepo.On("FindReactionById", "3").
+			Return(entities.Reaction{Name: httpRequestReactionName, ServiceId: "http"}, nil)
+		mockServiceService.On("FindServiceById", "http").
+			Return(entities.Service{Name: "HTTP"}, nil)
+		onFindReactionConnector(&mockServiceService.MockServiceServiceRepository, "HTTP")
+		mockServiceService.On("ExecuteRequestWithClient", mock.Anything, mock.Anything).
+			Return(nil, err)
+		mockWorkflowRunRepo.On("CreateWorkflowRun", "workflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+		mockWorkflowRepo.On("IncrementWorkflowFailures", "workflow").
+			Return(1, nil)
+		mockWorkflowRepo.On("FindWorkflowById", "workflow").
+			Return(job.Workflow, nil)
+		mockUserServiceService.On("RecordServiceCall", "owner", "HTTP", err).
+			Return(nil)
+
+		return &WorkflowService{
+			WorkflowRepository:    mockWorkflowRepo,
+			ReactionRepository:    mockReactionRepo,
+			ServiceService:        mockServiceService,
+			WorkflowRunRepository: mockWorkflowRunRepo,
+			JobRepository:         mockJobRepo,
+			UserServiceService:    mockUserServiceService,
+		}, mockJobRepo, mockWorkflowRunRepo
+	}
+
+	test.Run("Retryable Error", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newService(entities.ApiCallError{StatusCode: 503})
+		job.Attempts = 1
+
+		mockJobRepo.On("RetryJob", "job", "API call failed", mock.MatchedBy(func(runAt time.Time) bool {
+			return runAt.After(time.Now())
+		})).Return(nil).Once()
+
+		service.runReactionJob(job)
+
+		mockJobRepo.AssertExpectations(test)
+		mockJobRepo.AssertNotCalled(test, "EnqueueJob", mock.Anything)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Attempts Exhausted", func(test *testing.T) {
+		test.Setenv(jobMaxAttemptsEnv, "3")
+		service, mockJobRepo, mockWorkflowRunRepo := newService(entities.ApiCallError{StatusCode: 503})
+		job.Attempts = 3
+
+		mockJobRepo.On("FailJob", "job", "API call failed").
+			Return(nil).Once()
+
+		service.runReactionJob(job)
+
+		mockJobRepo.AssertExpectations(test)
+		mockWorkflowRunRepo.AssertCalled(test, "CreateWorkflowRun", "workflow", "3", entities.WorkflowRunFailure, "API call failed", 503, mock.Anything)
+	})
+
+	test.Run("Retries Of The Request", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newService(entities.ApiCallError{StatusCode: 503})
+		requestJob := job
+		requestJob.Attempts = 1
+		requestJob.Steps = []entities.WorkflowReaction{
+			{ReactionId: "3", ReactionParam: map[string]interface{}{"url": "http://93.184.216.34/hook", "retries": float64(0)}},
+		}
+
+		mockJobRepo.On("FailJob", "job", "API call failed").
+			Return(nil).Once()
+
+		service.runReactionJob(requestJob)
+
+		mockJobRepo.AssertExpectations(test)
+		mockJobRepo.AssertNotCalled(test, "RetryJob", mock.Anything, mock.Anything, mock.Anything)
+		mockWorkflowRunRepo.AssertCalled(test, "CreateWorkflowRun", "workflow", "3", entities.WorkflowRunFailure, "API call failed", 503, mock.Anything)
+	})
+
+	test.Run("Permanent Error", func(test *testing.T) {
+		service, mockJobRepo, _ := newService(entities.ApiCallError{StatusCode: 401})
+		job.Attempts = 1
+
+		mockJobRepo.On("FailJob", "job", "API call failed").
+			Return(nil).Once()
+
+		service.runReactionJob(job)
+
+		mockJobRepo.AssertExpectations(test)
+		mockJobRepo.AssertNotCalled(test, "RetryJob", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWorkflowDeadLetters(test *testing.T) {
+	newService := func() (*WorkflowService, *MockJobRepository) {
+		mockUserRepo := new(MockUserRepository)
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockJobRepo := new(MockJobRepository)
+
+		mockUserRepo.On("FindUserByEmail", "email", "basic").
+			Return(entities.User{Id: "owner"}, nil)
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{Id: "1", OwnerId: "owner"}, nil)
+		mockWorkflowRepo.On("FindWorkflowById", "2").
+			Return(entities.Workflow{Id: "2", OwnerId: "other"}, nil)
+
+		return &WorkflowService{
+			UserRepository:     mockUserRepo,
+			WorkflowRepository: mockWorkflowRepo,
+			JobRepository:      mockJobRepo,
+		}, mockJobRepo
+	}
+
+	test.Run("Get Dead Letters", func(test *testing.T) {
+		service, mockJobRepo := newService()
+
+		mockJobRepo.On("FindFailedJobsByWorkflowId", "1").
+			Return([]entities.ReactionJob(nil), nil).Once()
+
+		deadLetters, err := service.GetWorkflowDeadLetters("email", "basic", "1")
+
+		require.NoError(test, err)
+		require.Equal(test, []entities.ReactionJob{}, deadLetters)
+	})
+
+	test.Run("Workflow Of Another User", func(test *testing.T) {
+		service, mockJobRepo := newService()
+
+		_, err := service.GetWorkflowDeadLetters("email", "basic", "2")
+		require.ErrorIs(test, err, entities.WorkflowNotFoundError{})
+
+		_, err = service.RetriggerWorkflowDeadLetters("email", "basic", "2")
+		require.ErrorIs(test, err, entities.WorkflowNotFoundError{})
+
+		mockJobRepo.AssertNotCalled(test, "RequeueFailedJobs", mock.Anything)
+	})
+
+	test.Run("Retrigger Dead Letters", func(test *testing.T) {
+		service, mockJobRepo := newService()
+
+		mockJobRepo.On("RequeueFailedJobs", "1").
+			Return(2, nil).Once()
+
+		retriggered, err := service.RetriggerWorkflowDeadLetters("email", "basic", "1")
+
+		require.NoError(test, err)
+		require.Equal(test, 2, retriggered)
+	})
+}
+
+func TestRunJobWorker(test *testing.T) {
+	jobPollInterval = time.Millisecond
+	service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockJobRepo.On("ClaimJob", "SMS", mock.Anything).
+		Return(entities.ReactionJob{Id: "job", WorkflowId: "workflow", Workflow: entities.Workflow{Id: "workflow"}, Steps: []entities.WorkflowReaction{{ReactionId: "2"}}}, true, nil).Once()
+	mockJobRepo.On("ClaimJob", "SMS", mock.Anything).
+		Return(entities.ReactionJob{}, false, errors.New("Fail claim")).Once()
+	mockJobRepo.On("ClaimJob", "SMS", mock.Anything).
+		Return(entities.ReactionJob{}, false, nil)
+	mockJobRepo.On("CompleteJob", "job").
+		Return(nil).Run(func(args mock.Arguments) { cancel() }).Once()
+
+	done := make(chan struct{})
+	go func() {
+		service.runJobWorker(ctx, "SMS")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		test.Fatal("Worker did not stop")
+	}
+	mockWorkflowRunRepo.AssertNumberOfCalls(test, "CreateWorkflowRun", 1)
+}
+
+func TestWaitJobWorkers(test *testing.T) {
+	test.Run("Workers Done", func(test *testing.T) {
+		service := &WorkflowService{}
+		release := make(chan struct{})
+		service.jobWorkers.Add(1)
+		go func() {
+			defer service.jobWorkers.Done()
+			<-release
+		}()
+		close(release)
+
+		err := service.WaitJobWorkers(context.Background())
+
+		require.NoError(test, err)
+	})
+
+	test.Run("Deadline Reached", func(test *testing.T) {
+		service := &WorkflowService{}
+		release := make(chan struct{})
+		defer close(release)
+		service.jobWorkers.Add(1)
+		go func() {
+			defer service.jobWorkers.Done()
+			<-release
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := service.WaitJobWorkers(ctx)
+
+		require.ErrorIs(test, err, context.DeadlineExceeded)
+	})
+}
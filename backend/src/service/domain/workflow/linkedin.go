@@ -131,12 +131,7 @@ func (self *WorkflowService) publishURLLinkedinPost(urn, accessToken string, wor
 	return nil
 }
 
-func (self *WorkflowService) checkLinkedinReactions(workflow entities.Workflow) error {
-	reactionFound, errReaction := self.ReactionRepository.FindReactionById(workflow.ReactionId)
-	if errReaction != nil {
-		return fmt.Errorf(errorRetrievingReaction)
-	}
-
+func (self *WorkflowService) CheckLinkedinReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
 	accessToken, err := self.refreshTokenForService("Linkedin", reactionFound.Name, linkedinReactions(), workflow)
 	if err != nil {
 		return fmt.Errorf(errorUpdatingToken)
@@ -1,7 +1,6 @@
 package workflow_service
 
 import (
-	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -38,40 +37,14 @@ func TestPublishURLLinkedinPost(test *testing.T) {
 }
 
 func TestCheckLinkedinReactions(test *testing.T) {
-	test.Run("Fail Find Reactions", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		linkedin := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		workflow := entities.Workflow{
-			ReactionId: "1",
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, errors.New(errorRetrievingReaction))
-
-		err := linkedin.checkLinkedinReactions(workflow)
-
-		require.EqualError(test, err, errorRetrievingReaction)
-	})
-
 	test.Run("Success", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		linkedin := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
+		linkedin := &WorkflowService{}
 
 		workflow := entities.Workflow{
 			ReactionId: "1",
 		}
 
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, nil)
-
-		err := linkedin.checkLinkedinReactions(workflow)
+		err := linkedin.CheckLinkedinReactions(workflow, entities.Reaction{})
 
 		require.NoError(test, err)
 	})
@@ -0,0 +1,176 @@
+package workflow_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"backend/src/entities"
+)
+
+const pollConcurrencyEnv = "POLL_CONCURRENCY"
+const defaultPollConcurrency = 4
+
+const errorPollTimeout = "Workflow check timed out"
+const errorPollCancelled = "Workflow check cancelled"
+const errorPollPanic = "Workflow check panicked"
+const errorPollFailures = "Some workflows could not be checked"
+
+var pollWorkflowTimeout = 30 * time.Second
+var pollPassTimeout = 10 * time.Minute
+
+// The check must give ctx to its requests, and neither update the workflow nor run its reactions once ctx is done
+type pollCheck func(ctx context.Context, action entities.Action, workflow entities.Workflow) error
+
+type pollTarget struct {
+	action   entities.Action
+	workflow entities.Workflow
+}
+
+func pollConcurrency(serviceName string) int {
+	return serviceCountFromEnv(pollConcurrencyEnv, serviceName, defaultPollConcurrency)
+}
+
+// Checks the due workflows of every polled action of the service concurrently, a failing workflow does not stop the pass
+func (self *WorkflowService) pollServiceWorkflows(ctx context.Context, serviceName string, check pollCheck) (entities.PollSummary, error) {
+	service, err := self.ServiceService.FindServiceByName(serviceName)
+	if err != nil {
+		return entities.PollSummary{}, err
+	}
+
+	actions, err := self.ActionRepository.FindActionsByServiceId(service.Id)
+	if err != nil {
+		return entities.PollSummary{}, err
+	}
+
+	summary := entities.PollSummary{Service: serviceName, StartedAt: time.Now(), Errors: []string{}}
+	targets := []pollTarget{}
+	for _, action := range actions {
+		if action.MinimumInterval == 0 {
+			continue
+		}
+		workflows, err := self.WorkflowRepository.FindDueWorkflowsByActionId(action.Id, summary.StartedAt)
+		if err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("action %s: %s", action.Name, err.Error()))
+			continue
+		}
+		for _, workflow := range workflows {
+			claimed, err := self.claimWorkflowCheck(action, workflow, summary.StartedAt)
+			if err != nil {
+				summary.Errors = append(summary.Errors, fmt.Sprintf("workflow %s: %s", workflow.Id, err.Error()))
+			} else if claimed {
+				targets = append(targets, pollTarget{action: action, workflow: workflow})
+			}
+		}
+	}
+
+	self.runPollTargets(ctx, pollConcurrency(serviceName), targets, check, &summary)
+	summary.Duration = time.Since(summary.StartedAt)
+	self.recordPollSummary(summary)
+
+	if len(summary.Errors) > 0 {
+		return summary, fmt.Errorf(errorPollFailures)
+	}
+	return summary, nil
+}
+
+// The interval chosen by the workflow, or the default interval of its action, never below the minimum of the action
+func workflowPollInterval(action entities.Action, workflow entities.Workflow) time.Duration {
+	interval := action.DefaultInterval
+	if workflow.PollInterval > 0 {
+		interval = workflow.PollInterval
+	}
+	return time.Duration(max(interval, action.MinimumInterval)) * time.Second
+}
+
+// The next check is scheduled before the workflow is checked, a failing check waits for the next interval as well
+func (self *WorkflowService) claimWorkflowCheck(action entities.Action, workflow entities.Workflow, now time.Time) (bool, error) {
+	return self.WorkflowRepository.ClaimWorkflowCheck(workflow.Id, now, now.Add(workflowPollInterval(action, workflow)))
+}
+
+// Runs at most concurrency checks at once, the targets not started before the pass deadline are skipped
+func (self *WorkflowService) runPollTargets(ctx context.Context, concurrency int, targets []pollTarget, check pollCheck, summary *entities.PollSummary) {
+	ctx, cancel := context.WithTimeout(ctx, pollPassTimeout)
+	defer cancel()
+
+	var mutex sync.Mutex
+	var group sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	skipped := 0
+
+	for index, target := range targets {
+		if !acquirePollSlot(ctx, slots) {
+			skipped = len(targets) - index
+			break
+		}
+		group.Add(1)
+		go func(target pollTarget) {
+			defer group.Done()
+			defer func() { <-slots }()
+
+			err := runPollCheck(ctx, target, check)
+			// A check cancelled with the pass is not the fault of the workflow
+			if err == nil || ctx.Err() == nil {
+				self.countWorkflowPollCheck(target.workflow, err)
+			}
+			self.recordPollServiceCall(summary.Service, target.workflow, err)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			summary.Checked++
+			if err != nil {
+				summary.Failed++
+				summary.Errors = append(summary.Errors, fmt.Sprintf("workflow %s: %s", target.workflow.Id, err.Error()))
+			}
+		}(target)
+	}
+	group.Wait()
+
+	summary.Skipped = skipped
+	if skipped > 0 {
+		summary.Errors = append(summary.Errors, fmt.Sprintf("%d workflows skipped: %s", skipped, errorPollCancelled))
+	}
+}
+
+// Only a check without error or refused by the service tells about the connection of the owner to the service
+func (self *WorkflowService) recordPollServiceCall(serviceName string, workflow entities.Workflow, err error) {
+	var apiCallError entities.ApiCallError
+	if err == nil || errors.As(err, &apiCallError) {
+		self.UserServiceService.RecordServiceCall(workflow.OwnerId, serviceName, err)
+	}
+}
+
+func acquirePollSlot(ctx context.Context, slots chan struct{}) bool {
+	select {
+	case slots <- struct{}{}:
+		if ctx.Err() != nil {
+			<-slots
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// The check is abandoned at the deadline through its context, its slot is only freed once it has returned
+func runPollCheck(ctx context.Context, target pollTarget, check pollCheck) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, pollWorkflowTimeout)
+	defer cancel()
+	defer func() {
+		if recover() != nil {
+			err = fmt.Errorf(errorPollPanic)
+		}
+	}()
+
+	err = check(ctx, target.action, target.workflow)
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf(errorPollTimeout)
+	}
+	return fmt.Errorf(errorPollCancelled)
+}
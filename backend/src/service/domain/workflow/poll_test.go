@@ -0,0 +1,235 @@
+package workflow_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func newPollService(workflows []entities.Workflow) (*WorkflowService, *MockWorkflowRepository) {
+	mockServiceService := new(MockServiceServiceRepository)
+	mockActionRepo := new(MockActionRepository)
+	mockWorkflowRepo := new(MockWorkflowRepository)
+	mockUserServiceService := new(MockUserServiceRepository)
+
+	mockServiceService.On("FindServiceByName", "Test").
+		Return(entities.Service{Id: "1", Name: "Test"}, nil)
+	mockActionRepo.On("FindActionsByServiceId", "1").
+		Return([]entities.Action{
+			{Id: "1", Name: "Broken", MinimumInterval: 60, DefaultInterval: 300},
+			{Id: "2", Name: "Action", MinimumInterval: 60, DefaultInterval: 300},
+			{Id: "3", Name: "Webhook"},
+		}, nil)
+	mockWorkflowRepo.On("FindDueWorkflowsByActionId", "1", mock.Anything).
+		Return([]entities.Workflow{}, errors.New("Fail find workflows"))
+	mockWorkflowRepo.On("FindDueWorkflowsByActionId", "2", mock.Anything).
+		Return(workflows, nil)
+	mockWorkflowRepo.On("ClaimWorkflowCheck", "claimed", mock.Anything, mock.Anything).
+		Return(false, nil)
+	mockWorkflowRepo.On("ClaimWorkflowCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(true, nil)
+	mockWorkflowRepo.On("IncrementWorkflowPollFailures", mock.Anything).
+		Return(1, nil)
+	mockWorkflowRepo.On("ResetWorkflowPollFailures", mock.Anything).
+		Return(nil)
+	mockUserServiceService.On("RecordServiceCall", mock.Anything, "Test", mock.Anything).
+		Return(nil)
+
+	return &WorkflowService{
+		ServiceService:     mockServiceService,
+		ActionRepository:   mockActionRepo,
+		WorkflowRepository: mockWorkflowRepo,
+		UserServiceService: mockUserServiceService,
+	}, mockWorkflowRepo
+}
+
+func TestPollConcurrency(test *testing.T) {
+	require.Equal(test, defaultPollConcurrency, pollConcurrency("Reddit"))
+
+	test.Setenv(pollConcurrencyEnv, "8")
+	require.Equal(test, 8, pollConcurrency("Reddit"))
+
+	test.Setenv(pollConcurrencyEnv+"_FREEWEATHER", "1")
+	require.Equal(test, 1, pollConcurrency("FreeWeather"))
+	require.Equal(test, 8, pollConcurrency("Reddit"))
+}
+
+func TestRecordPollServiceCall(test *testing.T) {
+	mockUserServiceService := new(MockUserServiceRepository)
+	service := &WorkflowService{UserServiceService: mockUserServiceService}
+	workflow := entities.Workflow{Id: "workflow", OwnerId: "owner"}
+	refused := entities.ApiCallError{StatusCode: http.StatusUnauthorized}
+
+	mockUserServiceService.On("RecordServiceCall", "owner", "Github", mock.Anything).
+		Return(nil)
+
+	service.recordPollServiceCall("Github", workflow, nil)
+	service.recordPollServiceCall("Github", workflow, refused)
+	service.recordPollServiceCall("Github", workflow, errors.New("Missing field"))
+	service.recordPollServiceCall("Github", workflow, fmt.Errorf(errorPollTimeout))
+
+	mockUserServiceService.AssertNumberOfCalls(test, "RecordServiceCall", 2)
+	mockUserServiceService.AssertCalled(test, "RecordServiceCall", "owner", "Github", nil)
+	mockUserServiceService.AssertCalled(test, "RecordServiceCall", "owner", "Github", refused)
+}
+
+func TestWorkflowPollInterval(test *testing.T) {
+	action := entities.Action{MinimumInterval: 300, DefaultInterval: 900}
+
+	require.Equal(test, 15*time.Minute, workflowPollInterval(action, entities.Workflow{}))
+	require.Equal(test, time.Hour, workflowPollInterval(action, entities.Workflow{PollInterval: 3600}))
+	require.Equal(test, 5*time.Minute, workflowPollInterval(action, entities.Workflow{PollInterval: 60}))
+}
+
+func TestPollServiceWorkflows(test *testing.T) {
+	test.Run("Due Workflows Only", func(test *testing.T) {
+		service, mockWorkflowRepo := newPollService([]entities.Workflow{{Id: "1", IsActivated: true, PollInterval: 3600}})
+		checked := []string{}
+
+		_, err := service.pollServiceWorkflows(context.Background(), "Test", func(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+			checked = append(checked, action.Name+" "+workflow.Id)
+			return nil
+		})
+
+		require.EqualError(test, err, errorPollFailures)
+		require.Equal(test, []string{"Action 1"}, checked)
+		mockWorkflowRepo.AssertNotCalled(test, "FindDueWorkflowsByActionId", "3", mock.Anything)
+		mockWorkflowRepo.AssertCalled(test, "ClaimWorkflowCheck", "1", mock.Anything, mock.MatchedBy(func(nextCheckAt time.Time) bool {
+			return time.Until(nextCheckAt) > 59*time.Minute && time.Until(nextCheckAt) <= time.Hour
+		}))
+	})
+
+	test.Run("Continue Past Failures", func(test *testing.T) {
+		service, mockWorkflowRepo := newPollService([]entities.Workflow{
+			{Id: "1", IsActivated: true, ConsecutivePollFailures: 2},
+			{Id: "2", IsActivated: true},
+			{Id: "3", IsActivated: true},
+			{Id: "claimed", IsActivated: true},
+		})
+
+		summary, err := service.pollServiceWorkflows(context.Background(), "Test", func(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+			switch workflow.Id {
+			case "2":
+				return errors.New("Fail check")
+			case "3":
+				panic("unexpected payload")
+			}
+			return nil
+		})
+
+		require.EqualError(test, err, errorPollFailures)
+		require.Equal(test, "Test", summary.Service)
+		require.Equal(test, 3, summary.Checked)
+		require.Equal(test, 2, summary.Failed)
+		require.Equal(test, 0, summary.Skipped)
+		require.ElementsMatch(test, []string{
+			"action Broken: Fail find workflows",
+			"workflow 2: Fail check",
+			"workflow 3: " + errorPollPanic,
+		}, summary.Errors)
+		mockWorkflowRepo.AssertCalled(test, "ResetWorkflowPollFailures", "1")
+		mockWorkflowRepo.AssertCalled(test, "IncrementWorkflowPollFailures", "2")
+		mockWorkflowRepo.AssertCalled(test, "IncrementWorkflowPollFailures", "3")
+		mockWorkflowRepo.AssertNotCalled(test, "IncrementWorkflowPollFailures", "claimed")
+	})
+
+	test.Run("Bounded Concurrency", func(test *testing.T) {
+		test.Setenv(pollConcurrencyEnv+"_TEST", "2")
+		workflows := []entities.Workflow{}
+		for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+			workflows = append(workflows, entities.Workflow{Id: id, IsActivated: true})
+		}
+		service, _ := newPollService(workflows)
+		var running, maxRunning atomic.Int32
+
+		summary, _ := service.pollServiceWorkflows(context.Background(), "Test", func(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for {
+				previous := maxRunning.Load()
+				if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})
+
+		require.Equal(test, 6, summary.Checked)
+		require.Equal(test, 0, summary.Failed)
+		require.LessOrEqual(test, maxRunning.Load(), int32(2))
+	})
+
+	test.Run("Workflow Timeout", func(test *testing.T) {
+		pollWorkflowTimeout = 10 * time.Millisecond
+		defer func() { pollWorkflowTimeout = 30 * time.Second }()
+		service, _ := newPollService([]entities.Workflow{{Id: "1", IsActivated: true}, {Id: "2", IsActivated: true}})
+		release := make(chan struct{})
+		defer close(release)
+
+		summary, err := service.pollServiceWorkflows(context.Background(), "Test", func(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+			if workflow.Id == "1" {
+				select {
+				case <-release:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+
+		require.EqualError(test, err, errorPollFailures)
+		require.Equal(test, 2, summary.Checked)
+		require.Equal(test, 1, summary.Failed)
+		require.Contains(test, summary.Errors, "workflow 1: "+errorPollTimeout)
+	})
+
+	test.Run("Slot Kept Until Check Returns", func(test *testing.T) {
+		test.Setenv(pollConcurrencyEnv, "1")
+		pollWorkflowTimeout = 10 * time.Millisecond
+		defer func() { pollWorkflowTimeout = 30 * time.Second }()
+		service, _ := newPollService([]entities.Workflow{{Id: "1", IsActivated: true}, {Id: "2", IsActivated: true}})
+		var running atomic.Int32
+		var overlapped atomic.Bool
+
+		summary, _ := service.pollServiceWorkflows(context.Background(), "Test", func(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+			if running.Add(1) > 1 {
+				overlapped.Store(true)
+			}
+			defer running.Add(-1)
+			if workflow.Id == "1" {
+				<-ctx.Done()
+				time.Sleep(20 * time.Millisecond)
+				return ctx.Err()
+			}
+			return nil
+		})
+
+		require.Equal(test, 2, summary.Checked)
+		require.Contains(test, summary.Errors, "workflow 1: "+errorPollTimeout)
+		require.False(test, overlapped.Load())
+	})
+
+	test.Run("Cancelled Pass", func(test *testing.T) {
+		service, _ := newPollService([]entities.Workflow{{Id: "1", IsActivated: true}, {Id: "2", IsActivated: true}})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		summary, err := service.pollServiceWorkflows(ctx, "Test", func(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+			return nil
+		})
+
+		require.EqualError(test, err, errorPollFailures)
+		require.Equal(test, 0, summary.Checked)
+		require.Equal(test, 2, summary.Skipped)
+	})
+}
@@ -0,0 +1,78 @@
+package workflow_service
+
+import (
+	"errors"
+	"fmt"
+
+	"backend/src/entities"
+	"backend/src/service"
+)
+
+// The handler of the reaction is registered by the connector of its service
+func (self *WorkflowService) resolveReaction(reactionId string) (entities.Reaction, string, service.ReactionHandler, error) {
+	reaction, err := self.ReactionRepository.FindReactionById(reactionId)
+	if err != nil {
+		return reaction, "", nil, fmt.Errorf(errorRetrievingReaction)
+	}
+
+	reactionService, err := self.ServiceService.FindServiceById(reaction.ServiceId)
+	if err != nil {
+		return reaction, "", nil, fmt.Errorf(errorRetrievingReaction)
+	}
+
+	reactionConnector, err := self.ServiceService.FindConnector(reactionService.Name)
+	if err != nil || reactionConnector.ReactionHandler() == nil {
+		return reaction, reactionService.Name, nil, fmt.Errorf(errorUnknownReactionService)
+	}
+	return reaction, reactionService.Name, reactionConnector.ReactionHandler(), nil
+}
+
+// Workflows stored before the steps existed run their single reactionid and reactionparam
+func (self *WorkflowService) findWorkflowReactions(workflow entities.Workflow) ([]entities.WorkflowReaction, error) {
+	workflowReactions, err := self.WorkflowReactionRepository.FindWorkflowReactionsByWorkflowId(workflow.Id)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingReaction)
+	}
+
+	if len(workflowReactions) == 0 {
+		return []entities.WorkflowReaction{{
+			WorkflowId:    workflow.Id,
+			ReactionId:    workflow.ReactionId,
+			ReactionParam: workflow.ReactionParam,
+		}}, nil
+	}
+	return workflowReactions, nil
+}
+
+func setReactionResultError(result entities.ReactionResult, err error) entities.ReactionResult {
+	var apiCallError entities.ApiCallError
+
+	if err == nil {
+		return result
+	}
+
+	result.Status = entities.WorkflowRunFailure
+	result.ErrorMessage = err.Error()
+	if errors.As(err, &apiCallError) {
+		result.HttpStatus = apiCallError.StatusCode
+	}
+	return result
+}
+
+// The error of the reaction is returned along its result to decide whether the reaction is retried
+func (self *WorkflowService) executeReaction(workflow entities.Workflow) (entities.ReactionResult, error) {
+	result := entities.ReactionResult{
+		ReactionId: workflow.ReactionId,
+		Status:     entities.WorkflowRunSuccess,
+	}
+
+	reaction, serviceName, handler, err := self.resolveReaction(workflow.ReactionId)
+	result.ReactionName = reaction.Name
+	result.ServiceName = serviceName
+	if err != nil {
+		return setReactionResultError(result, err), err
+	}
+
+	err = handler(self, workflow, reaction)
+	return setReactionResultError(result, err), err
+}
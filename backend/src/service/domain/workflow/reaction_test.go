@@ -0,0 +1,129 @@
+package workflow_service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+	"backend/src/service/connector"
+)
+
+// The reactions resolve their handler through the connector of their service
+func onFindReactionConnector(mockServiceService *MockServiceServiceRepository, serviceName string) {
+	reactionConnector, err := connector.NewRegistry().FindConnector(serviceName)
+	mockServiceService.On("FindConnector", serviceName).
+		Return(reactionConnector, err)
+}
+
+func TestExecuteReaction(test *testing.T) {
+	workflow := entities.Workflow{
+		ReactionId: "1",
+	}
+
+	test.Run("Fail Find Reaction", func(test *testing.T) {
+		mockReactionRepo := new(MockReactionRepository)
+
+		service := &WorkflowService{
+			ReactionRepository: mockReactionRepo,
+		}
+
+		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
+			Return(entities.Reaction{}, errors.New("reaction not found")).Once()
+
+		result, err := service.executeReaction(workflow)
+
+		require.EqualError(test, err, errorRetrievingReaction)
+		require.Equal(test, entities.WorkflowRunFailure, result.Status)
+		require.Equal(test, errorRetrievingReaction, result.ErrorMessage)
+	})
+
+	test.Run("Unknown Service", func(test *testing.T) {
+		mockReactionRepo := new(MockReactionRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+
+		service := &WorkflowService{
+			ReactionRepository: mockReactionRepo,
+			ServiceService:     mockServiceService,
+		}
+
+		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
+			Return(entities.Reaction{Name: "reaction", ServiceId: "2"}, nil).Once()
+		mockServiceService.On("FindServiceById", "2").
+			Return(entities.Service{Name: "Unknown"}, nil).Once()
+		onFindReactionConnector(mockServiceService, "Unknown")
+
+		result, err := service.executeReaction(workflow)
+
+		require.Error(test, err)
+		require.Equal(test, entities.WorkflowRunFailure, result.Status)
+		require.Equal(test, "Unknown", result.ServiceName)
+		require.Equal(test, errorUnknownReactionService, result.ErrorMessage)
+	})
+
+	test.Run("Failed Reaction", func(test *testing.T) {
+		mockReactionRepo := new(MockReactionRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+
+		service := &WorkflowService{
+			ReactionRepository: mockReactionRepo,
+			ServiceService:     mockServiceService,
+		}
+
+		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
+			Return(entities.Reaction{Name: "reaction", ServiceId: "2"}, nil).Once()
+		mockServiceService.On("FindServiceById", "2").
+			Return(entities.Service{Name: "Discord"}, nil).Once()
+		onFindReactionConnector(mockServiceService, "Discord")
+
+		result, err := service.executeReaction(workflow)
+
+		require.EqualError(test, err, "Unknown reaction")
+		require.Equal(test, entities.ReactionResult{
+			ReactionId:   "1",
+			ReactionName: "reaction",
+			ServiceName:  "Discord",
+			Status:       entities.WorkflowRunFailure,
+			ErrorMessage: "Unknown reaction",
+		}, result)
+	})
+
+	test.Run("Success", func(test *testing.T) {
+		mockReactionRepo := new(MockReactionRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+
+		service := &WorkflowService{
+			ReactionRepository: mockReactionRepo,
+			ServiceService:     mockServiceService,
+		}
+
+		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
+			Return(entities.Reaction{Name: "reaction", ServiceId: "2"}, nil).Once()
+		mockServiceService.On("FindServiceById", "2").
+			Return(entities.Service{Name: "SMS"}, nil).Once()
+		onFindReactionConnector(mockServiceService, "SMS")
+
+		result, err := service.executeReaction(workflow)
+
+		require.NoError(test, err)
+		require.Equal(test, entities.WorkflowRunSuccess, result.Status)
+		require.Empty(test, result.ErrorMessage)
+		mockReactionRepo.AssertNumberOfCalls(test, "FindReactionById", 1)
+	})
+}
+
+func TestSetReactionResultError(test *testing.T) {
+	test.Run("Api Call Error", func(test *testing.T) {
+		result := setReactionResultError(entities.ReactionResult{Status: entities.WorkflowRunSuccess}, entities.ApiCallError{StatusCode: 429})
+
+		require.Equal(test, entities.WorkflowRunFailure, result.Status)
+		require.Equal(test, 429, result.HttpStatus)
+	})
+
+	test.Run("No Error", func(test *testing.T) {
+		result := setReactionResultError(entities.ReactionResult{Status: entities.WorkflowRunSuccess}, nil)
+
+		require.Equal(test, entities.WorkflowRunSuccess, result.Status)
+	})
+}
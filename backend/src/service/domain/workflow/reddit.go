@@ -1,6 +1,7 @@
 package workflow_service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -37,7 +38,7 @@ func (self *WorkflowService) postRedditComment(accessToken string, workflow enti
 	body.Set("text", comment.(string))
 	url := "https://oauth.reddit.com/api/comment"
 
-	resp, err := self.executeRedditRequest("POST", url, accessToken, strings.NewReader(body.Encode()))
+	resp, err := self.executeRedditRequest(context.Background(), "POST", url, accessToken, strings.NewReader(body.Encode()))
 	if err != nil {
 		return err
 	}
@@ -53,7 +54,7 @@ func (self *WorkflowService) upvoteDowvoteRedditPost(postId, accessToken string,
 
 	url := "https://oauth.reddit.com/api/vote"
 
-	resp, err := self.executeRedditRequest("POST", url, accessToken, strings.NewReader(body.Encode()))
+	resp, err := self.executeRedditRequest(context.Background(), "POST", url, accessToken, strings.NewReader(body.Encode()))
 	if err != nil {
 		return err
 	}
@@ -95,7 +96,7 @@ func (self *WorkflowService) submitRedditPost(accessToken string, workflow entit
 	body.Set("kind", "self")
 	url := "https://oauth.reddit.com/api/submit"
 
-	resp, err := self.executeRedditRequest("POST", url, accessToken, strings.NewReader(body.Encode()))
+	resp, err := self.executeRedditRequest(context.Background(), "POST", url, accessToken, strings.NewReader(body.Encode()))
 	if err != nil {
 		return err
 	}
@@ -119,7 +120,7 @@ func (self *WorkflowService) submitRedditPostLink(accessToken string, workflow e
 	body.Set("kind", "link")
 	url := "https://oauth.reddit.com/api/submit"
 
-	resp, err := self.executeRedditRequest("POST", url, accessToken, strings.NewReader(body.Encode()))
+	resp, err := self.executeRedditRequest(context.Background(), "POST", url, accessToken, strings.NewReader(body.Encode()))
 	if err != nil {
 		return err
 	}
@@ -128,12 +129,7 @@ func (self *WorkflowService) submitRedditPostLink(accessToken string, workflow e
 	return nil
 }
 
-func (self *WorkflowService) checkRedditReactions(workflow entities.Workflow) error {
-	reactionFound, errReaction := self.ReactionRepository.FindReactionById(workflow.ReactionId)
-	if errReaction != nil {
-		return fmt.Errorf(errorRetrievingReaction)
-	}
-
+func (self *WorkflowService) CheckRedditReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
 	accessToken, err := self.refreshTokenForService("Reddit", reactionFound.Name, redditReactions(), workflow)
 	if err != nil {
 		return fmt.Errorf(errorUpdatingToken)
@@ -154,8 +150,8 @@ func (self *WorkflowService) checkRedditReactions(workflow entities.Workflow) er
 	return nil
 }
 
-func (self *WorkflowService) executeRedditRequest(method, url, accessToken string, body io.Reader) (*http.Response, error) {
-	req, _ := http.NewRequest(method, url, body)
+func (self *WorkflowService) executeRedditRequest(ctx context.Context, method, url, accessToken string, body io.Reader) (*http.Response, error) {
+	req, _ := http.NewRequestWithContext(ctx, method, url, body)
 	req.Header.Add("Authorization", bearerType+accessToken)
 	req.Header.Add("User-Agent", os.Getenv("REDDIT_SERVICE_USER_AGENT"))
 
@@ -166,10 +162,10 @@ func (self *WorkflowService) executeRedditRequest(method, url, accessToken strin
 	return resp, nil
 }
 
-func (self *WorkflowService) getRedditPost(url, accessToken string) (entities.RedditPostResponse, error) {
+func (self *WorkflowService) getRedditPost(ctx context.Context, url, accessToken string) (entities.RedditPostResponse, error) {
 	var result entities.RedditPostResponse
 
-	resp, err := self.executeRedditRequest("GET", url, accessToken, nil)
+	resp, err := self.executeRedditRequest(ctx, "GET", url, accessToken, nil)
 	if err != nil {
 		return result, err
 	}
@@ -186,10 +182,10 @@ func (self *WorkflowService) getRedditPost(url, accessToken string) (entities.Re
 	return result, nil
 }
 
-func (self *WorkflowService) getRedditComments(url, accessToken string) (entities.RedditCommentResponse, error) {
+func (self *WorkflowService) getRedditComments(ctx context.Context, url, accessToken string) (entities.RedditCommentResponse, error) {
 	var result entities.RedditCommentResponse
 
-	resp, err := self.executeRedditRequest("GET", url, accessToken, nil)
+	resp, err := self.executeRedditRequest(ctx, "GET", url, accessToken, nil)
 	if err != nil {
 		return result, err
 	}
@@ -206,10 +202,10 @@ func (self *WorkflowService) getRedditComments(url, accessToken string) (entitie
 	return result, nil
 }
 
-func (self *WorkflowService) getRedditVotes(url, accessToken string) (entities.RedditVoteResponse, error) {
+func (self *WorkflowService) getRedditVotes(ctx context.Context, url, accessToken string) (entities.RedditVoteResponse, error) {
 	var result entities.RedditVoteResponse
 
-	resp, err := self.executeRedditRequest("GET", url, accessToken, nil)
+	resp, err := self.executeRedditRequest(ctx, "GET", url, accessToken, nil)
 	if err != nil {
 		return result, err
 	}
@@ -226,11 +222,11 @@ func (self *WorkflowService) getRedditVotes(url, accessToken string) (entities.R
 	return result, nil
 }
 
-func (self *WorkflowService) getUsernameReddit(accessToken string, workflow entities.Workflow) (entities.RedditUsername, error) {
+func (self *WorkflowService) getUsernameReddit(ctx context.Context, accessToken string, workflow entities.Workflow) (entities.RedditUsername, error) {
 	var result entities.RedditUsername
 	url := "https://oauth.reddit.com/api/v1/me"
 
-	resp, err := self.executeRedditRequest("GET", url, accessToken, nil)
+	resp, err := self.executeRedditRequest(ctx, "GET", url, accessToken, nil)
 	if err != nil {
 		return result, err
 	}
@@ -243,7 +239,11 @@ func (self *WorkflowService) getUsernameReddit(accessToken string, workflow enti
 	return result, nil
 }
 
-func (self *WorkflowService) isANewPost(workflow entities.Workflow, result entities.RedditPostResponse) error {
+func (self *WorkflowService) isANewPost(ctx context.Context, workflow entities.Workflow, result entities.RedditPostResponse) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
 	latestPost := result.Data.Children[0].Data
 	idLatest, idLatestExist := workflow.ActionData["id"]
 
@@ -251,19 +251,23 @@ func (self *WorkflowService) isANewPost(workflow entities.Workflow, result entit
 		workflow.ActionData = make(map[string]interface{})
 		workflow.ActionData["id"] = latestPost.ID
 
-		err := self.WorkflowRepository.UpdateWorkflow(workflow.Id, workflow)
+		err := self.WorkflowRepository.UpdateWorkflowActionData(workflow.Id, workflow.ActionData)
 		if err != nil {
 			return fmt.Errorf(errorUpdatingWorkflow)
 		}
 	}
 
 	if idLatestExist && idLatest != latestPost.ID {
-		self.checkReactions(workflow)
+		self.checkReactions(workflow, newActionEvent("post", latestPost))
 	}
 	return nil
 }
 
-func (self *WorkflowService) isANewComment(workflow entities.Workflow, result entities.RedditCommentResponse) error {
+func (self *WorkflowService) isANewComment(ctx context.Context, workflow entities.Workflow, result entities.RedditCommentResponse) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
 	latestComment := result.Data.Children[0].Data
 	idLatest, idLatestExist := workflow.ActionData["id"]
 
@@ -271,19 +275,23 @@ func (self *WorkflowService) isANewComment(workflow entities.Workflow, result en
 		workflow.ActionData = make(map[string]interface{})
 		workflow.ActionData["id"] = latestComment.ID
 
-		err := self.WorkflowRepository.UpdateWorkflow(workflow.Id, workflow)
+		err := self.WorkflowRepository.UpdateWorkflowActionData(workflow.Id, workflow.ActionData)
 		if err != nil {
 			return fmt.Errorf(errorUpdatingWorkflow)
 		}
 	}
 
 	if idLatestExist && idLatest != latestComment.ID {
-		self.checkReactions(workflow)
+		self.checkReactions(workflow, newActionEvent("comment", latestComment))
 	}
 	return nil
 }
 
-func (self *WorkflowService) isANewVote(workflow entities.Workflow, result entities.RedditVoteResponse) error {
+func (self *WorkflowService) isANewVote(ctx context.Context, workflow entities.Workflow, result entities.RedditVoteResponse) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
 	latestVote := result.Data.Children[0].Data
 	idLatest, idLatestExist := workflow.ActionData["id"]
 
@@ -291,26 +299,26 @@ func (self *WorkflowService) isANewVote(workflow entities.Workflow, result entit
 		workflow.ActionData = make(map[string]interface{})
 		workflow.ActionData["id"] = latestVote.ID
 
-		err := self.WorkflowRepository.UpdateWorkflow(workflow.Id, workflow)
+		err := self.WorkflowRepository.UpdateWorkflowActionData(workflow.Id, workflow.ActionData)
 		if err != nil {
 			return fmt.Errorf(errorUpdatingWorkflow)
 		}
 	}
 
 	if idLatestExist && idLatest != latestVote.ID {
-		self.checkReactions(workflow)
+		self.checkReactions(workflow, newActionEvent("post", latestVote))
 	}
 	return nil
 }
 
-func (self *WorkflowService) checkRedditNewPostInSubredditAction(accessToken string, workflow entities.Workflow) error {
+func (self *WorkflowService) checkRedditNewPostInSubredditAction(ctx context.Context, accessToken string, workflow entities.Workflow) error {
 	subreddit, subredditExists := workflow.ActionParam["subreddit"]
 	if !subredditExists {
 		return fmt.Errorf(errorMissingField)
 	}
 
 	url := "https://oauth.reddit.com/" + subreddit.(string) + "/new.json?limit=1"
-	result, err := self.getRedditPost(url, accessToken)
+	result, err := self.getRedditPost(ctx, url, accessToken)
 	if err != nil {
 		return err
 	}
@@ -319,47 +327,47 @@ func (self *WorkflowService) checkRedditNewPostInSubredditAction(accessToken str
 		return fmt.Errorf("No posts found in subreddit")
 	}
 
-	return self.isANewPost(workflow, result)
+	return self.isANewPost(ctx, workflow, result)
 }
 
-func (self *WorkflowService) checkRedditNewCommentByMeAction(accessToken string, workflow entities.Workflow) error {
-	resultUsername, err := self.getUsernameReddit(accessToken, workflow)
+func (self *WorkflowService) checkRedditNewCommentByMeAction(ctx context.Context, accessToken string, workflow entities.Workflow) error {
+	resultUsername, err := self.getUsernameReddit(ctx, accessToken, workflow)
 	if err != nil {
 		return err
 	}
 
 	url := redditUserRoute + resultUsername.Name + "/comments?limit=1"
-	result, err := self.getRedditComments(url, accessToken)
+	result, err := self.getRedditComments(ctx, url, accessToken)
 	if err != nil {
 		return err
 	}
 
-	return self.isANewComment(workflow, result)
+	return self.isANewComment(ctx, workflow, result)
 }
 
-func (self *WorkflowService) checkRedditVoteByMeAction(voteType, accessToken string, workflow entities.Workflow) error {
-	resultUsername, err := self.getUsernameReddit(accessToken, workflow)
+func (self *WorkflowService) checkRedditVoteByMeAction(ctx context.Context, voteType, accessToken string, workflow entities.Workflow) error {
+	resultUsername, err := self.getUsernameReddit(ctx, accessToken, workflow)
 	if err != nil {
 		return err
 	}
 
 	urlPostMe := redditUserRoute + resultUsername.Name + voteType + "?limit=1"
-	resultPost, err := self.getRedditVotes(urlPostMe, accessToken)
+	resultPost, err := self.getRedditVotes(ctx, urlPostMe, accessToken)
 	if err != nil {
 		return err
 	}
 
-	return self.isANewVote(workflow, resultPost)
+	return self.isANewVote(ctx, workflow, resultPost)
 }
 
-func (self *WorkflowService) checkRedditPostAction(postType, accessToken string, workflow entities.Workflow) error {
-	resultUsername, err := self.getUsernameReddit(accessToken, workflow)
+func (self *WorkflowService) checkRedditPostAction(ctx context.Context, postType, accessToken string, workflow entities.Workflow) error {
+	resultUsername, err := self.getUsernameReddit(ctx, accessToken, workflow)
 	if err != nil {
 		return err
 	}
 
 	url := redditUserRoute + resultUsername.Name + postType + "?limit=1"
-	result, err := self.getRedditPost(url, accessToken)
+	result, err := self.getRedditPost(ctx, url, accessToken)
 	if err != nil {
 		return err
 	}
@@ -368,58 +376,33 @@ func (self *WorkflowService) checkRedditPostAction(postType, accessToken string,
 		return fmt.Errorf("No posts found")
 	}
 
-	return self.isANewPost(workflow, result)
+	return self.isANewPost(ctx, workflow, result)
 }
 
-func (self *WorkflowService) checkWorkflowsWithRedditActions(action entities.Action) error {
-	allWorkflowsFound, errWorkflow := self.WorkflowRepository.FindWorkflowsByActionId(action.Id)
-	if errWorkflow != nil {
-		return errWorkflow
+func (self *WorkflowService) checkRedditWorkflow(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+	accessToken, err := self.getAccessToken("Reddit", workflow)
+	if err != nil {
+		return err
 	}
 
-	for _, workflow := range allWorkflowsFound {
-		if !workflow.IsActivated {
-			continue
-		}
-
-		accessToken, err := self.getAccessToken("Reddit", workflow)
-		if err != nil {
-			continue
-		}
-		switch action.Name {
-		case "Any new post in subreddit":
-			self.checkRedditNewPostInSubredditAction(accessToken, workflow)
-		case "New post by you":
-			self.checkRedditPostAction("/submitted", accessToken, workflow)
-		case "New comment by you":
-			self.checkRedditNewCommentByMeAction(accessToken, workflow)
-		case "New downvoted post by you":
-			self.checkRedditVoteByMeAction("/downvoted", accessToken, workflow)
-		case "New upvoted post by you":
-			self.checkRedditVoteByMeAction("/upvoted", accessToken, workflow)
-		case "New post saved by you":
-			self.checkRedditPostAction("/saved", accessToken, workflow)
-		}
+	switch action.Name {
+	case "Any new post in subreddit":
+		return self.checkRedditNewPostInSubredditAction(ctx, accessToken, workflow)
+	case "New post by you":
+		return self.checkRedditPostAction(ctx, "/submitted", accessToken, workflow)
+	case "New comment by you":
+		return self.checkRedditNewCommentByMeAction(ctx, accessToken, workflow)
+	case "New downvoted post by you":
+		return self.checkRedditVoteByMeAction(ctx, "/downvoted", accessToken, workflow)
+	case "New upvoted post by you":
+		return self.checkRedditVoteByMeAction(ctx, "/upvoted", accessToken, workflow)
+	case "New post saved by you":
+		return self.checkRedditPostAction(ctx, "/saved", accessToken, workflow)
 	}
 	return nil
 }
 
-func (self *WorkflowService) CheckRedditActions() error {
-	serviceFound, errFindingService := self.ServiceService.FindServiceByName("Reddit")
-	if errFindingService != nil {
-		return errFindingService
-	}
-
-	allActionsFound, errFindingActions := self.ActionRepository.FindActionsByServiceId(serviceFound.Id)
-	if errFindingActions != nil {
-		return errFindingActions
-	}
-
-	for _, action := range allActionsFound {
-		errCheckName := self.checkWorkflowsWithRedditActions(action)
-		if errCheckName != nil {
-			return errCheckName
-		}
-	}
-	return nil
+func (self *WorkflowService) CheckRedditActions(ctx context.Context) error {
+	_, err := self.pollServiceWorkflows(ctx, "Reddit", self.checkRedditWorkflow)
+	return err
 }
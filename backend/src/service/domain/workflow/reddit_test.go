@@ -1,6 +1,7 @@
 package workflow_service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -73,38 +74,17 @@ func TestCheckRedditReactions(test *testing.T) {
 	}
 
 	test.Run("Success", func(t *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		reddit := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
+		reddit := &WorkflowService{}
 
 		reactionFound := entities.Reaction{
 			Name: "Test",
 		}
 
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(reactionFound, nil)
-
-		err := reddit.checkRedditReactions(workflow)
+		err := reddit.CheckRedditReactions(workflow, reactionFound)
 
 		require.NoError(test, err)
 	})
 
-	test.Run("Fail Find Reaction", func(t *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		reddit := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, errors.New(errorRetrievingReaction))
-
-		err := reddit.checkRedditReactions(workflow)
-
-		require.EqualError(test, err, errorRetrievingReaction)
-	})
 }
 
 func TestIsANewPost(test *testing.T) {
@@ -142,10 +122,10 @@ func TestIsANewPost(test *testing.T) {
 		expectedWorkflow := workflow
 		expectedWorkflow.ActionData = map[string]interface{}{"id": "id"}
 
-		mockWorkflowRepo.On("UpdateWorkflow", expectedWorkflow.Id, expectedWorkflow).
+		mockWorkflowRepo.On("UpdateWorkflowActionData", expectedWorkflow.Id, expectedWorkflow.ActionData).
 			Return(fmt.Errorf("update error"))
 
-		err := reddit.isANewPost(workflow, result)
+		err := reddit.isANewPost(context.Background(), workflow, result)
 
 		require.EqualError(t, err, errorUpdatingWorkflow)
 	})
@@ -185,10 +165,10 @@ func TestIsANewComment(test *testing.T) {
 		expectedWorkflow := workflow
 		expectedWorkflow.ActionData = map[string]interface{}{"id": "id"}
 
-		mockWorkflowRepo.On("UpdateWorkflow", expectedWorkflow.Id, expectedWorkflow).
+		mockWorkflowRepo.On("UpdateWorkflowActionData", expectedWorkflow.Id, expectedWorkflow.ActionData).
 			Return(fmt.Errorf("update error"))
 
-		err := reddit.isANewComment(workflow, result)
+		err := reddit.isANewComment(context.Background(), workflow, result)
 
 		require.EqualError(t, err, errorUpdatingWorkflow)
 	})
@@ -214,7 +194,10 @@ func TestIsANewVote(test *testing.T) {
 				"children": [
 					{
 						"data": {
-							"id": "id"
+							"id": "id",
+							"title": "title",
+							"author": "author",
+							"url": "url"
 						}
 					}
 				]
@@ -226,13 +209,28 @@ func TestIsANewVote(test *testing.T) {
 		expectedWorkflow := workflow
 		expectedWorkflow.ActionData = map[string]interface{}{"id": "id"}
 
-		mockWorkflowRepo.On("UpdateWorkflow", expectedWorkflow.Id, expectedWorkflow).
+		mockWorkflowRepo.On("UpdateWorkflowActionData", expectedWorkflow.Id, expectedWorkflow.ActionData).
 			Return(fmt.Errorf("update error"))
 
-		err := reddit.isANewVote(workflow, result)
+		err := reddit.isANewVote(context.Background(), workflow, result)
 
 		require.EqualError(t, err, errorUpdatingWorkflow)
 	})
+
+	test.Run("Event Exposes The Post", func(t *testing.T) {
+		var result entities.RedditVoteResponse
+
+		redditResponse := `{"data": {"children": [{"data": {"id": "id", "title": "title", "author": "author", "url": "url"}}]}}`
+		json.Unmarshal([]byte(redditResponse), &result)
+
+		event := newActionEvent("post", result.Data.Children[0].Data)
+
+		for variable, expected := range map[string]string{"post.id": "id", "post.title": "title", "post.author": "author", "post.url": "url"} {
+			value, found := lookupEventVariable(event, variable)
+			require.True(t, found)
+			require.Equal(t, expected, value)
+		}
+	})
 }
 
 func TestCheckRedditNewPostInSubredditAction(test *testing.T) {
@@ -243,49 +241,45 @@ func TestCheckRedditNewPostInSubredditAction(test *testing.T) {
 			ActionParam: map[string]interface{}{"key": "value"},
 		}
 
-		err := reddit.checkRedditNewPostInSubredditAction("accessToken", workflow)
+		err := reddit.checkRedditNewPostInSubredditAction(context.Background(), "accessToken", workflow)
 
 		require.EqualError(test, err, errorMissingField)
 	})
 }
 
-func TestCheckWorkflowsWithRedditActions(test *testing.T) {
-	test.Run("Fail Find Workflow", func(test *testing.T) {
-		mockWorkflowRepo := new(MockWorkflowRepository)
+func TestCheckRedditWorkflow(test *testing.T) {
+	workflow := entities.Workflow{Id: "1", OwnerId: "owner"}
 
-		reddit := &WorkflowService{
-			WorkflowRepository: mockWorkflowRepo,
-		}
+	test.Run("Fail Access Token", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
 
-		action := entities.Action{
-			Id:   "1",
-			Name: "test",
+		reddit := &WorkflowService{
+			UserRepository: mockUserRepo,
 		}
 
-		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
-			Return([]entities.Workflow{}, errors.New("Fail find workflows"))
+		mockUserRepo.On("FindUserById", "owner").
+			Return(entities.User{}, errors.New("Fail find user"))
 
-		err := reddit.checkWorkflowsWithRedditActions(action)
+		err := reddit.checkRedditWorkflow(context.Background(), entities.Action{Name: "test"}, workflow)
 
-		require.EqualError(test, err, "Fail find workflows")
+		require.EqualError(test, err, "Error finding user")
 	})
 
-	test.Run("Fail Find Workflow", func(test *testing.T) {
-		mockWorkflowRepo := new(MockWorkflowRepository)
+	test.Run("Unknown Action", func(test *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
 
 		reddit := &WorkflowService{
-			WorkflowRepository: mockWorkflowRepo,
-		}
-
-		action := entities.Action{
-			Id:   "1",
-			Name: "test",
+			UserRepository:     mockUserRepo,
+			UserServiceService: mockUserServiceRepo,
 		}
 
-		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
-			Return([]entities.Workflow{}, nil)
+		mockUserRepo.On("FindUserById", "owner").
+			Return(entities.User{Email: "email", ConnectionType: "basic"}, nil)
+		mockUserServiceRepo.On("CallApiAndRefresh", "email", "basic", "Reddit").
+			Return("accessToken", nil)
 
-		err := reddit.checkWorkflowsWithRedditActions(action)
+		err := reddit.checkRedditWorkflow(context.Background(), entities.Action{Name: "test"}, workflow)
 
 		require.NoError(test, err)
 	})
@@ -302,7 +296,7 @@ func TestCheckRedditActions(test *testing.T) {
 		mockServiceServiceRepo.On("FindServiceByName", "Reddit").
 			Return(entities.Service{}, errors.New("Fail find service"))
 
-		err := reddit.CheckRedditActions()
+		err := reddit.CheckRedditActions(context.Background())
 
 		require.EqualError(test, err, "Fail find service")
 	})
@@ -326,7 +320,7 @@ func TestCheckRedditActions(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", serviceFound.Id).
 			Return([]entities.Action{}, errors.New("Fail find actions"))
 
-		err := reddit.CheckRedditActions()
+		err := reddit.CheckRedditActions(context.Background())
 
 		require.EqualError(test, err, "Fail find actions")
 	})
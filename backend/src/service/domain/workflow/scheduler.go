@@ -0,0 +1,61 @@
+package workflow_service
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"backend/src/entities"
+)
+
+type schedulerState struct {
+	mutex     sync.Mutex
+	isLeader  bool
+	lastPolls map[string]entities.PollSummary
+}
+
+// Every replica serves HTTP and runs job workers, only the replica holding the scheduler lock runs the poll jobs
+// A replica which cannot reach the database is not the leader, the lock is taken over once its session is closed
+func (self *WorkflowService) IsSchedulerLeader(ctx context.Context) bool {
+	isLeader, err := self.SchedulerTickRepository.TryAcquireSchedulerLock(ctx)
+	isLeader = err == nil && isLeader
+
+	self.scheduler.mutex.Lock()
+	defer self.scheduler.mutex.Unlock()
+	self.scheduler.isLeader = isLeader
+	return isLeader
+}
+
+// Lets another replica take the poll jobs over without waiting for the session of this one to close
+func (self *WorkflowService) ReleaseSchedulerLock() error {
+	self.scheduler.mutex.Lock()
+	self.scheduler.isLeader = false
+	self.scheduler.mutex.Unlock()
+
+	return self.SchedulerTickRepository.ReleaseSchedulerLock()
+}
+
+func (self *WorkflowService) recordPollSummary(summary entities.PollSummary) {
+	self.scheduler.mutex.Lock()
+	defer self.scheduler.mutex.Unlock()
+
+	if self.scheduler.lastPolls == nil {
+		self.scheduler.lastPolls = map[string]entities.PollSummary{}
+	}
+	self.scheduler.lastPolls[summary.Service] = summary
+}
+
+// Leadership as of the last tick and the last poll pass of each service run by this replica
+func (self *WorkflowService) GetSchedulerState() entities.SchedulerState {
+	self.scheduler.mutex.Lock()
+	defer self.scheduler.mutex.Unlock()
+
+	state := entities.SchedulerState{IsLeader: self.scheduler.isLeader, LastPolls: []entities.PollSummary{}}
+	for _, summary := range self.scheduler.lastPolls {
+		state.LastPolls = append(state.LastPolls, summary)
+	}
+	sort.Slice(state.LastPolls, func(i, j int) bool {
+		return state.LastPolls[i].Service < state.LastPolls[j].Service
+	})
+	return state
+}
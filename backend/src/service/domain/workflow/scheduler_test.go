@@ -0,0 +1,72 @@
+package workflow_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func TestIsSchedulerLeader(test *testing.T) {
+	test.Run("Lock Acquired", func(test *testing.T) {
+		mockSchedulerTickRepo := new(MockSchedulerTickRepository)
+		service := &WorkflowService{SchedulerTickRepository: mockSchedulerTickRepo}
+
+		mockSchedulerTickRepo.On("TryAcquireSchedulerLock", context.Background()).
+			Return(true, nil).Once()
+
+		require.True(test, service.IsSchedulerLeader(context.Background()))
+	})
+
+	test.Run("Lock Held By Another Replica", func(test *testing.T) {
+		mockSchedulerTickRepo := new(MockSchedulerTickRepository)
+		service := &WorkflowService{SchedulerTickRepository: mockSchedulerTickRepo}
+
+		mockSchedulerTickRepo.On("TryAcquireSchedulerLock", context.Background()).
+			Return(false, nil).Once()
+
+		require.False(test, service.IsSchedulerLeader(context.Background()))
+	})
+
+	test.Run("Database Unreachable", func(test *testing.T) {
+		mockSchedulerTickRepo := new(MockSchedulerTickRepository)
+		service := &WorkflowService{SchedulerTickRepository: mockSchedulerTickRepo}
+
+		mockSchedulerTickRepo.On("TryAcquireSchedulerLock", context.Background()).
+			Return(false, errors.New("connection refused")).Once()
+
+		require.False(test, service.IsSchedulerLeader(context.Background()))
+	})
+}
+
+func TestGetSchedulerState(test *testing.T) {
+	mockSchedulerTickRepo := new(MockSchedulerTickRepository)
+	service := &WorkflowService{SchedulerTickRepository: mockSchedulerTickRepo}
+
+	require.Equal(test, entities.SchedulerState{LastPolls: []entities.PollSummary{}}, service.GetSchedulerState())
+
+	mockSchedulerTickRepo.On("TryAcquireSchedulerLock", context.Background()).
+		Return(true, nil).Once()
+	mockSchedulerTickRepo.On("ReleaseSchedulerLock").
+		Return(nil).Once()
+	service.IsSchedulerLeader(context.Background())
+	service.recordPollSummary(entities.PollSummary{Service: "Reddit", Checked: 1})
+	service.recordPollSummary(entities.PollSummary{Service: "FreeWeather", Checked: 2})
+	service.recordPollSummary(entities.PollSummary{Service: "Reddit", Checked: 3})
+
+	state := service.GetSchedulerState()
+
+	require.True(test, state.IsLeader)
+	require.Equal(test, []entities.PollSummary{
+		{Service: "FreeWeather", Checked: 2},
+		{Service: "Reddit", Checked: 3},
+	}, state.LastPolls)
+
+	err := service.ReleaseSchedulerLock()
+
+	require.NoError(test, err)
+	require.False(test, service.GetSchedulerState().IsLeader)
+}
@@ -41,12 +41,7 @@ func (self *WorkflowService) sendAnSMS(workflow entities.Workflow) error {
 	return nil
 }
 
-func (self *WorkflowService) checkSMSReactions(workflow entities.Workflow) error {
-	reactionFound, errReaction := self.ReactionRepository.FindReactionById(workflow.ReactionId)
-	if errReaction != nil {
-		return fmt.Errorf(errorRetrievingReaction)
-	}
-
+func (self *WorkflowService) CheckSMSReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
 	switch reactionFound.Name {
 	case "Send an SMS":
 		return self.sendAnSMS(workflow)
@@ -1,7 +1,6 @@
 package workflow_service
 
 import (
-	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -25,39 +24,14 @@ func TestSendAnSMS(test *testing.T) {
 
 func TestCheckSMSReactions(test *testing.T) {
 	test.Run("Success", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		spotify := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
+		spotify := &WorkflowService{}
 
 		workflow := entities.Workflow{
 			ReactionId: "1",
 		}
 
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, nil)
-
-		err := spotify.checkSMSReactions(workflow)
+		err := spotify.CheckSMSReactions(workflow, entities.Reaction{})
 
 		require.NoError(test, err)
 	})
-	test.Run("Fail Find Reaction", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		spotify := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		workflow := entities.Workflow{
-			ReactionId: "1",
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, errors.New(errorRetrievingReaction))
-
-		err := spotify.checkSMSReactions(workflow)
-
-		require.EqualError(test, err, errorRetrievingReaction)
-	})
 }
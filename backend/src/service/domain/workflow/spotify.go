@@ -69,12 +69,7 @@ func (self *WorkflowService) executeSpotifyRequest(workflow entities.Workflow, r
 	return self.ServiceService.ExecuteApiRequest(url, requestParameters.Method, bearerType, accessToken, nil)
 }
 
-func (self *WorkflowService) checkSpotifyReactions(workflow entities.Workflow) error {
-	reactionFound, errReaction := self.ReactionRepository.FindReactionById(workflow.ReactionId)
-	if errReaction != nil {
-		return fmt.Errorf(errorRetrievingReaction)
-	}
-
+func (self *WorkflowService) CheckSpotifyReactions(workflow entities.Workflow, reactionFound entities.Reaction) error {
 	accessToken, err := self.refreshTokenForService("Spotify", reactionFound.Name, spotifyReactions(), workflow)
 	if err != nil {
 		return fmt.Errorf(errorUpdatingToken)
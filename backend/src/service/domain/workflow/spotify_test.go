@@ -1,7 +1,6 @@
 package workflow_service
 
 import (
-	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,11 +14,16 @@ type MockReactionRepository struct {
 	mock.Mock
 }
 
-func (m *MockReactionRepository) CreateReaction(name, description, serviceId string, nbParam int) error {
-	args := m.Called(name, description, serviceId, nbParam)
+func (m *MockReactionRepository) CreateReaction(reaction entities.Reaction) error {
+	args := m.Called(reaction)
 	return args.Error(0)
 }
 
+func (m *MockReactionRepository) FindReactionByNameAndServiceId(name, serviceId string) (entities.Reaction, error) {
+	args := m.Called(name, serviceId)
+	return args.Get(0).(entities.Reaction), args.Error(1)
+}
+
 func (m *MockReactionRepository) FindReactionById(id string) (entities.Reaction, error) {
 	args := m.Called(id)
 	return args.Get(0).(entities.Reaction), args.Error(1)
@@ -85,32 +89,10 @@ func TestCheckSpotifyReactions(test *testing.T) {
 		ReactionId: "1",
 	}
 
-	test.Run("Fail Retrieve Reaction", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		spotify := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, errors.New(errorRetrievingReaction))
-
-		err := spotify.checkSpotifyReactions(workflow)
-
-		require.EqualError(test, err, errorRetrievingReaction)
-	})
-
 	test.Run("Unknown Reaction", func(test *testing.T) {
-		mockReactionRepo := new(MockReactionRepository)
-
-		spotify := &WorkflowService{
-			ReactionRepository: mockReactionRepo,
-		}
-
-		mockReactionRepo.On("FindReactionById", workflow.ReactionId).
-			Return(entities.Reaction{}, nil)
+		spotify := &WorkflowService{}
 
-		err := spotify.checkSpotifyReactions(workflow)
+		err := spotify.CheckSpotifyReactions(workflow, entities.Reaction{})
 
 		require.EqualError(test, err, "Unknown reaction")
 	})
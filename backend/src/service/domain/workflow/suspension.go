@@ -0,0 +1,84 @@
+package workflow_service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"backend/src/entities"
+)
+
+const workflowFailureThresholdEnv = "WORKFLOW_FAILURE_THRESHOLD"
+const defaultWorkflowFailureThreshold = 10
+
+const workflowSuspendedSubject = "Your workflow has been deactivated"
+
+func workflowFailureThreshold() int {
+	threshold, err := strconv.Atoi(os.Getenv(workflowFailureThresholdEnv))
+	if err != nil || threshold < 1 {
+		return defaultWorkflowFailureThreshold
+	}
+	return threshold
+}
+
+func newWorkflowSuspendedReason(failures int, result entities.ReactionResult) string {
+	reason := fmt.Sprintf("Suspended after %d consecutive failures, last error: %s", failures, result.ErrorMessage)
+	if result.HttpStatus != 0 {
+		reason += fmt.Sprintf(" (HTTP %d)", result.HttpStatus)
+	}
+	return reason
+}
+
+func newWorkflowPollSuspendedReason(failures int, err error) string {
+	reason := fmt.Sprintf("Suspended after %d consecutive failed checks, last error: %s", failures, err.Error())
+	var apiCallError entities.ApiCallError
+	if errors.As(err, &apiCallError) && apiCallError.StatusCode != 0 {
+		reason += fmt.Sprintf(" (HTTP %d)", apiCallError.StatusCode)
+	}
+	return reason
+}
+
+// Deactivates the workflow once its failures reach the threshold, its owner is told by email
+func (self *WorkflowService) countWorkflowFailure(workflow entities.Workflow, result entities.ReactionResult) {
+	failures, err := self.WorkflowRepository.IncrementWorkflowFailures(workflow.Id)
+	if err != nil || failures < workflowFailureThreshold() {
+		return
+	}
+	self.suspendFailingWorkflow(workflow, newWorkflowSuspendedReason(failures, result))
+}
+
+// The failed checks of the action are counted apart from the failed runs, a successful check resets their count
+func (self *WorkflowService) countWorkflowPollCheck(workflow entities.Workflow, checkErr error) {
+	if checkErr == nil {
+		if workflow.ConsecutivePollFailures > 0 {
+			self.WorkflowRepository.ResetWorkflowPollFailures(workflow.Id)
+		}
+		return
+	}
+
+	failures, err := self.WorkflowRepository.IncrementWorkflowPollFailures(workflow.Id)
+	if err != nil || failures < workflowFailureThreshold() {
+		return
+	}
+	self.suspendFailingWorkflow(workflow, newWorkflowPollSuspendedReason(failures, checkErr))
+}
+
+func (self *WorkflowService) suspendFailingWorkflow(workflow entities.Workflow, reason string) {
+	suspended, err := self.WorkflowRepository.SuspendWorkflow(workflow.Id, reason)
+	if err != nil || !suspended {
+		return
+	}
+	self.notifyWorkflowSuspension(workflow, reason)
+}
+
+func (self *WorkflowService) notifyWorkflowSuspension(workflow entities.Workflow, reason string) error {
+	owner, err := self.UserRepository.FindUserById(workflow.OwnerId)
+	if err != nil {
+		return fmt.Errorf("Error finding user")
+	}
+
+	body := fmt.Sprintf("Your workflow \"%s\" kept failing and has been deactivated.\n\n%s\n\nFix it, then activate it again from your workflows.",
+		workflow.Name, reason)
+	return self.sendEmail(owner.Email, workflowSuspendedSubject, body)
+}
@@ -0,0 +1,161 @@
+package workflow_service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func TestWorkflowFailureThreshold(test *testing.T) {
+	require.Equal(test, defaultWorkflowFailureThreshold, workflowFailureThreshold())
+
+	test.Setenv(workflowFailureThresholdEnv, "3")
+	require.Equal(test, 3, workflowFailureThreshold())
+
+	test.Setenv(workflowFailureThresholdEnv, "0")
+	require.Equal(test, defaultWorkflowFailureThreshold, workflowFailureThreshold())
+}
+
+func TestNewWorkflowSuspendedReason(test *testing.T) {
+	require.Equal(test, "Suspended after 10 consecutive failures, last error: API call failed (HTTP 401)",
+		newWorkflowSuspendedReason(10, entities.ReactionResult{ErrorMessage: "API call failed", HttpStatus: 401}))
+	require.Equal(test, "Suspended after 3 consecutive failures, last error: Missing required field",
+		newWorkflowSuspendedReason(3, entities.ReactionResult{ErrorMessage: errorMissingField}))
+}
+
+func TestNewWorkflowPollSuspendedReason(test *testing.T) {
+	require.Equal(test, "Suspended after 10 consecutive failed checks, last error: API call failed (HTTP 403)",
+		newWorkflowPollSuspendedReason(10, entities.ApiCallError{StatusCode: 403}))
+	require.Equal(test, "Suspended after 3 consecutive failed checks, last error: "+errorPollTimeout,
+		newWorkflowPollSuspendedReason(3, errors.New(errorPollTimeout)))
+}
+
+func TestCountWorkflowFailure(test *testing.T) {
+	test.Setenv(workflowFailureThresholdEnv, "3")
+	workflow := entities.Workflow{Id: "workflow", Name: "Reddit digest", OwnerId: "owner"}
+	result := entities.ReactionResult{Status: entities.WorkflowRunFailure, ErrorMessage: "API call failed", HttpStatus: 401}
+	reason := "Suspended after 3 consecutive failures, last error: API call failed (HTTP 401)"
+
+	newService := func(failures int) (*WorkflowService, *MockWorkflowRepository, *MockServiceServiceRepository) {
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+
+		mockWorkflowRepo.On("IncrementWorkflowFailures", "workflow").
+			Return(failures, nil)
+		mockUserRepo.On("FindUserById", "owner").
+			Return(entities.User{Email: "owner@area.com"}, nil)
+
+		return &WorkflowService{
+			WorkflowRepository: mockWorkflowRepo,
+			UserRepository:     mockUserRepo,
+			ServiceService:     mockServiceService,
+		}, mockWorkflowRepo, mockServiceService
+	}
+
+	test.Run("Below Threshold", func(test *testing.T) {
+		service, mockWorkflowRepo, _ := newService(2)
+
+		service.countWorkflowFailure(workflow, result)
+
+		mockWorkflowRepo.AssertNotCalled(test, "SuspendWorkflow", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Threshold Reached", func(test *testing.T) {
+		service, mockWorkflowRepo, mockServiceService := newService(3)
+
+		mockWorkflowRepo.On("SuspendWorkflow", "workflow", reason).
+			Return(true, nil).Once()
+		mockServiceService.On("ExecuteApiRequest", "https://api.sendgrid.com/v3/mail/send", "POST", bearerType, mock.Anything,
+			mock.MatchedBy(func(body io.Reader) bool {
+				content, _ := io.ReadAll(body)
+				return strings.Contains(string(content), `"email":"owner@area.com"`) &&
+					strings.Contains(string(content), workflowSuspendedSubject) && strings.Contains(string(content), reason)
+			})).
+			Return(&http.Response{Body: io.NopCloser(strings.NewReader(""))}, nil).Once()
+
+		service.countWorkflowFailure(workflow, result)
+
+		mockWorkflowRepo.AssertExpectations(test)
+		mockServiceService.AssertExpectations(test)
+	})
+
+	test.Run("Already Deactivated", func(test *testing.T) {
+		service, mockWorkflowRepo, mockServiceService := newService(4)
+
+		mockWorkflowRepo.On("SuspendWorkflow", "workflow", mock.Anything).
+			Return(false, nil).Once()
+
+		service.countWorkflowFailure(workflow, result)
+
+		mockServiceService.AssertNotCalled(test, "ExecuteApiRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Fail Count", func(test *testing.T) {
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		service := &WorkflowService{WorkflowRepository: mockWorkflowRepo}
+
+		mockWorkflowRepo.On("IncrementWorkflowFailures", "workflow").
+			Return(0, errors.New("Fail update")).Once()
+
+		service.countWorkflowFailure(workflow, result)
+
+		mockWorkflowRepo.AssertNotCalled(test, "SuspendWorkflow", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCountWorkflowPollCheck(test *testing.T) {
+	test.Setenv(workflowFailureThresholdEnv, "3")
+	workflow := entities.Workflow{Id: "workflow", Name: "Reddit digest", OwnerId: "owner"}
+
+	test.Run("Successful Check", func(test *testing.T) {
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		service := &WorkflowService{WorkflowRepository: mockWorkflowRepo}
+		failingWorkflow := workflow
+		failingWorkflow.ConsecutivePollFailures = 2
+
+		mockWorkflowRepo.On("ResetWorkflowPollFailures", "workflow").
+			Return(nil).Once()
+
+		service.countWorkflowPollCheck(workflow, nil)
+		service.countWorkflowPollCheck(failingWorkflow, nil)
+
+		mockWorkflowRepo.AssertNumberOfCalls(test, "ResetWorkflowPollFailures", 1)
+		mockWorkflowRepo.AssertNotCalled(test, "ResetWorkflowFailures", mock.Anything)
+	})
+
+	test.Run("Below Threshold", func(test *testing.T) {
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		service := &WorkflowService{WorkflowRepository: mockWorkflowRepo}
+
+		mockWorkflowRepo.On("IncrementWorkflowPollFailures", "workflow").
+			Return(2, nil).Once()
+
+		service.countWorkflowPollCheck(workflow, errors.New("Fail check"))
+
+		mockWorkflowRepo.AssertNotCalled(test, "IncrementWorkflowFailures", mock.Anything)
+		mockWorkflowRepo.AssertNotCalled(test, "SuspendWorkflow", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Threshold Reached", func(test *testing.T) {
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := &WorkflowService{WorkflowRepository: mockWorkflowRepo, UserRepository: mockUserRepo}
+
+		mockWorkflowRepo.On("IncrementWorkflowPollFailures", "workflow").
+			Return(3, nil).Once()
+		mockWorkflowRepo.On("SuspendWorkflow", "workflow", "Suspended after 3 consecutive failed checks, last error: API call failed (HTTP 401)").
+			Return(false, nil).Once()
+
+		service.countWorkflowPollCheck(workflow, entities.ApiCallError{StatusCode: 401})
+
+		mockWorkflowRepo.AssertExpectations(test)
+	})
+}
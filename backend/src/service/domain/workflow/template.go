@@ -0,0 +1,101 @@
+package workflow_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"backend/src/entities"
+)
+
+var templateVariableRegexp = regexp.MustCompile(`{{\s*([A-Za-z0-9_.]+)\s*}}`)
+
+func newActionEvent(namespace string, value interface{}) entities.ActionEvent {
+	var content interface{}
+
+	valueBytes, err := json.Marshal(value)
+	if err == nil {
+		json.Unmarshal(valueBytes, &content)
+	}
+	return entities.ActionEvent{namespace: content}
+}
+
+func lookupEventVariable(event entities.ActionEvent, path string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(event)
+
+	for _, key := range strings.Split(path, ".") {
+		currentMap, isMap := current.(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+
+		value, valueExists := currentMap[key]
+		if !valueExists {
+			return nil, false
+		}
+		current = value
+	}
+	return current, true
+}
+
+func formatEventVariable(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typedValue)
+	case map[string]interface{}, []interface{}:
+		valueBytes, err := json.Marshal(typedValue)
+		if err != nil {
+			return ""
+		}
+		return string(valueBytes)
+	}
+	return fmt.Sprint(value)
+}
+
+func renderTemplateString(template string, event entities.ActionEvent) string {
+	return templateVariableRegexp.ReplaceAllStringFunc(template, func(placeholder string) string {
+		path := templateVariableRegexp.FindStringSubmatch(placeholder)[1]
+
+		value, valueExists := lookupEventVariable(event, path)
+		if !valueExists {
+			return ""
+		}
+		return formatEventVariable(value)
+	})
+}
+
+func renderTemplateValue(value interface{}, event entities.ActionEvent) interface{} {
+	switch typedValue := value.(type) {
+	case string:
+		return renderTemplateString(typedValue, event)
+	case map[string]interface{}:
+		return renderReactionParams(typedValue, event)
+	case []interface{}:
+		renderedValues := make([]interface{}, len(typedValue))
+		for i, element := range typedValue {
+			renderedValues[i] = renderTemplateValue(element, event)
+		}
+		return renderedValues
+	}
+	return value
+}
+
+func renderReactionParams(reactionParam map[string]interface{}, event entities.ActionEvent) map[string]interface{} {
+	if reactionParam == nil {
+		return nil
+	}
+
+	renderedParam := make(map[string]interface{}, len(reactionParam))
+	for key, value := range reactionParam {
+		renderedParam[key] = renderTemplateValue(value, event)
+	}
+	return renderedParam
+}
@@ -0,0 +1,79 @@
+package workflow_service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func TestNewActionEvent(test *testing.T) {
+	test.Run("Success", func(test *testing.T) {
+		event := newActionEvent("post", entities.RedditPost{ID: "id", Title: "title"})
+
+		value, valueExists := lookupEventVariable(event, "post.title")
+		require.True(test, valueExists)
+		require.Equal(test, "title", value)
+	})
+
+	test.Run("Unknown variable", func(test *testing.T) {
+		event := newActionEvent("post", entities.RedditPost{ID: "id"})
+
+		_, valueExists := lookupEventVariable(event, "post.unknown")
+		require.False(test, valueExists)
+	})
+}
+
+func TestRenderReactionParams(test *testing.T) {
+	event := entities.ActionEvent{
+		"post": map[string]interface{}{
+			"title": "New release",
+			"url":   "https://reddit.com/r/golang",
+		},
+		"weather": map[string]interface{}{
+			"temp_c": 31.5,
+		},
+	}
+
+	test.Run("Success", func(test *testing.T) {
+		reactionParam := map[string]interface{}{
+			"message": "{{post.title}} - {{ post.url }}",
+			"count":   1.0,
+		}
+
+		renderedParam := renderReactionParams(reactionParam, event)
+
+		require.Equal(test, "New release - https://reddit.com/r/golang", renderedParam["message"])
+		require.Equal(test, 1.0, renderedParam["count"])
+		require.Equal(test, "{{post.title}} - {{ post.url }}", reactionParam["message"])
+	})
+
+	test.Run("Number variable", func(test *testing.T) {
+		renderedParam := renderReactionParams(map[string]interface{}{"message": "{{weather.temp_c}}°C"}, event)
+
+		require.Equal(test, "31.5°C", renderedParam["message"])
+	})
+
+	test.Run("Nested params", func(test *testing.T) {
+		reactionParam := map[string]interface{}{
+			"body": map[string]interface{}{
+				"lines": []interface{}{"{{post.title}}"},
+			},
+		}
+
+		renderedParam := renderReactionParams(reactionParam, event)
+
+		require.Equal(test, map[string]interface{}{"lines": []interface{}{"New release"}}, renderedParam["body"])
+	})
+
+	test.Run("Unknown variable", func(test *testing.T) {
+		renderedParam := renderReactionParams(map[string]interface{}{"message": "[{{comment.body}}]"}, event)
+
+		require.Equal(test, "[]", renderedParam["message"])
+	})
+
+	test.Run("Nil params", func(test *testing.T) {
+		require.Nil(test, renderReactionParams(nil, event))
+	})
+}
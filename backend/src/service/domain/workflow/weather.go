@@ -1,6 +1,7 @@
 package workflow_service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,11 +10,11 @@ import (
 	"backend/src/entities"
 )
 
-func (self *WorkflowService) currentWeather(city string, workflow entities.Workflow) (entities.WeatherResponse, error) {
+func (self *WorkflowService) currentWeather(ctx context.Context, city string, workflow entities.Workflow) (entities.WeatherResponse, error) {
 	var weatherData entities.WeatherResponse
 	baseUrl := "http://api.weatherapi.com/v1/forecast.json?key=" + os.Getenv("WEATHER_API_KEY") + "&q=" + city
 
-	req, _ := http.NewRequest("GET", baseUrl, nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", baseUrl, nil)
 	res, err := self.ServiceService.ExecuteRequest(req)
 	if err != nil {
 		return weatherData, err
@@ -27,73 +28,64 @@ func (self *WorkflowService) currentWeather(city string, workflow entities.Workf
 	return weatherData, nil
 }
 
-func (self *WorkflowService) checkWeatherCurrentWeatherComparisonAction(checkType string, workflow entities.Workflow) error {
+func newWeatherActionEvent(city string, weatherData entities.WeatherResponse) entities.ActionEvent {
+	weatherEvent := entities.WeatherEvent{
+		City:        city,
+		Temperature: weatherData.Current.Temperature,
+	}
+
+	if len(weatherData.Forecast.ForecastDay) > 0 {
+		weatherEvent.MaxTemperature = weatherData.Forecast.ForecastDay[0].Day.MaxTemperature
+		weatherEvent.MinTemperature = weatherData.Forecast.ForecastDay[0].Day.MinTemperature
+	}
+	return newActionEvent("weather", weatherEvent)
+}
+
+func (self *WorkflowService) checkWeatherCurrentWeatherComparisonAction(ctx context.Context, checkType string, workflow entities.Workflow) error {
 	city, cityExists := workflow.ActionParam["city"]
 	temp, tempExists := workflow.ActionParam["temperature"]
 	if !cityExists || !tempExists {
 		return fmt.Errorf(errorMissingField)
 	}
 
-	weatherData, err := self.currentWeather(city.(string), workflow)
+	weatherData, err := self.currentWeather(ctx, city.(string), workflow)
 	if err != nil {
 		return err
 	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	event := newWeatherActionEvent(city.(string), weatherData)
 
 	if checkType == "aboveCurrent" && weatherData.Current.Temperature > temp.(float64) {
-		self.checkReactions(workflow)
+		self.checkReactions(workflow, event)
 	} else if checkType == "belowCurrent" && weatherData.Current.Temperature < temp.(float64) {
-		self.checkReactions(workflow)
+		self.checkReactions(workflow, event)
 	} else if checkType == "aboveForecast" && weatherData.Forecast.ForecastDay[0].Day.MaxTemperature < temp.(float64) {
-		self.checkReactions(workflow)
+		self.checkReactions(workflow, event)
 	} else if checkType == "belowForecast" && weatherData.Forecast.ForecastDay[0].Day.MinTemperature > temp.(float64) {
-		self.checkReactions(workflow)
+		self.checkReactions(workflow, event)
 	} else {
 		fmt.Errorf("Check type isn't valid")
 	}
 	return nil
 }
 
-func (self *WorkflowService) checkWorkflowsWithWeatherActions(action entities.Action) error {
-	allWorkflowsFound, errWorkflow := self.WorkflowRepository.FindWorkflowsByActionId(action.Id)
-	if errWorkflow != nil {
-		return errWorkflow
-	}
-
-	for _, workflow := range allWorkflowsFound {
-		if !workflow.IsActivated {
-			continue
-		}
-
-		switch action.Name {
-		case "Current temperature rises above":
-			self.checkWeatherCurrentWeatherComparisonAction("aboveCurrent", workflow)
-		case "Current temperature drops below":
-			self.checkWeatherCurrentWeatherComparisonAction("belowCurrent", workflow)
-		case "Tomorrow's low drops below":
-			self.checkWeatherCurrentWeatherComparisonAction("belowForecast", workflow)
-		case "Tomorrow's high rises above":
-			self.checkWeatherCurrentWeatherComparisonAction("aboveForecast", workflow)
-		}
+func (self *WorkflowService) checkWeatherWorkflow(ctx context.Context, action entities.Action, workflow entities.Workflow) error {
+	switch action.Name {
+	case "Current temperature rises above":
+		return self.checkWeatherCurrentWeatherComparisonAction(ctx, "aboveCurrent", workflow)
+	case "Current temperature drops below":
+		return self.checkWeatherCurrentWeatherComparisonAction(ctx, "belowCurrent", workflow)
+	case "Tomorrow's low drops below":
+		return self.checkWeatherCurrentWeatherComparisonAction(ctx, "belowForecast", workflow)
+	case "Tomorrow's high rises above":
+		return self.checkWeatherCurrentWeatherComparisonAction(ctx, "aboveForecast", workflow)
 	}
 	return nil
 }
 
-func (self *WorkflowService) CheckWeatherActions() error {
-	serviceFound, errFindingService := self.ServiceService.FindServiceByName("FreeWeather")
-	if errFindingService != nil {
-		return errFindingService
-	}
-
-	allActionsFound, errFindingActions := self.ActionRepository.FindActionsByServiceId(serviceFound.Id)
-	if errFindingActions != nil {
-		return errFindingActions
-	}
-
-	for _, action := range allActionsFound {
-		errCheckName := self.checkWorkflowsWithWeatherActions(action)
-		if errCheckName != nil {
-			return errCheckName
-		}
-	}
-	return nil
+func (self *WorkflowService) CheckWeatherActions(ctx context.Context) error {
+	_, err := self.pollServiceWorkflows(ctx, "FreeWeather", self.checkWeatherWorkflow)
+	return err
 }
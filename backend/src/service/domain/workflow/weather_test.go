@@ -1,6 +1,7 @@
 package workflow_service
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -10,14 +11,20 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"backend/src/entities"
+	"backend/src/service"
 )
 
 type MockServiceServiceRepository struct {
 	mock.Mock
 }
 
-func (m *MockServiceServiceRepository) OAuth2Service(serviceName, callbackType, appType string) (string, error) {
-	args := m.Called(serviceName, callbackType, appType)
+func (m *MockServiceServiceRepository) OAuth2Service(serviceName, callbackType, appType, session string) (string, error) {
+	args := m.Called(serviceName, callbackType, appType, session)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockServiceServiceRepository) ConsumeOAuthState(state, session, serviceName, callbackType, appType string) (string, error) {
+	args := m.Called(state, session, serviceName, callbackType, appType)
 	return args.String(0), args.Error(1)
 }
 
@@ -61,35 +68,25 @@ func (m *MockServiceServiceRepository) RetrieveReactionsFromService(serviceName
 	return args.Get(0).([]entities.Reaction), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetGoogleRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
-}
-
-func (m *MockServiceServiceRepository) GetSpotifyRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
-}
-
-func (m *MockServiceServiceRepository) GetDiscordRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
-}
-
-func (m *MockServiceServiceRepository) GetRedditRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
+func (m *MockServiceServiceRepository) FindConnector(serviceName string) (service.Connector, error) {
+	args := m.Called(serviceName)
+	connector, _ := args.Get(0).(service.Connector)
+	return connector, args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetAsanaRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
+func (m *MockServiceServiceRepository) RetrieveConnectors() []service.Connector {
+	return nil
 }
 
-func (m *MockServiceServiceRepository) GetDropboxRefreshTokenRequest(refreshToken string) (*http.Request, error) {
-	return nil, nil
+func (m *MockServiceServiceRepository) SeedServices() error {
+	return nil
 }
 
-func (m *MockServiceServiceRepository) GetGitlabRefreshTokenRequest(refreshToken string) (*http.Request, error) {
+func (m *MockServiceServiceRepository) ExecuteRequest(request *http.Request) (*http.Response, error) {
 	return nil, nil
 }
 
-func (m *MockServiceServiceRepository) ExecuteRequest(request *http.Request) (*http.Response, error) {
+func (m *MockServiceServiceRepository) ExecuteRequestWithClient(client *http.Client, request *http.Request) (*http.Response, error) {
 	return nil, nil
 }
 
@@ -98,7 +95,12 @@ func (m *MockServiceServiceRepository) ExecuteApiRequest(url, method, typeToken,
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
-func (m *MockServiceServiceRepository) GetResultTokenFromCode(code, serviceName, callbackType, appType string) (entities.ResultToken, error) {
+// The context is not matched, the expectations are registered on ExecuteApiRequest
+func (m *MockServiceServiceRepository) ExecuteApiRequestWithContext(ctx context.Context, url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error) {
+	return m.ExecuteApiRequest(url, method, typeToken, accessToken, body)
+}
+
+func (m *MockServiceServiceRepository) GetResultTokenFromCode(code, serviceName, callbackType, appType, codeVerifier string) (entities.ResultToken, error) {
 	var test entities.ResultToken
 	return test, nil
 }
@@ -108,12 +110,7 @@ func (m *MockServiceServiceRepository) GetUserInfoFromService(accessToken, servi
 	return test, nil
 }
 
-func (m *MockServiceServiceRepository) RequestToTimeApi() (entities.TimeResponse, error) {
-	args := m.Called()
-	return args.Get(0).(entities.TimeResponse), args.Error(1)
-}
-
-func (m *MockServiceServiceRepository) RequestGithubUserRepositories(accessToken string) ([]entities.GithubRepository, error) {
+func (m *MockServiceServiceRepository) RequestGithubUserRepositories(ctx context.Context, accessToken string) ([]entities.GithubRepository, error) {
 	args := m.Called(accessToken)
 	return args.Get(0).([]entities.GithubRepository), args.Error(1)
 }
@@ -136,8 +133,13 @@ type MockActionRepository struct {
 	mock.Mock
 }
 
-func (m *MockActionRepository) CreateAction(name, description, serviceId string, nbParam int) error {
-	args := m.Called(name, description, serviceId, nbParam)
+func (m *MockActionRepository) CreateAction(action entities.Action) error {
+	args := m.Called(action)
+	return args.Error(0)
+}
+
+func (m *MockActionRepository) UpdateAction(id string, action entities.Action) error {
+	args := m.Called(id, action)
 	return args.Error(0)
 }
 
@@ -161,48 +163,12 @@ func (m *MockActionRepository) FindActionByNameAndServiceId(name, serviceId stri
 	return args.Get(0).(entities.Action), args.Error(1)
 }
 
-func TestCheckWorkflowsWithWeatherActions(test *testing.T) {
-	action := entities.Action{
-		Id:          "1",
-		Name:        "action",
-		Description: "description",
-		ServiceId:   "2",
-		NbParam:     0,
-	}
-
-	workflowEntities := []entities.Workflow{
-		{Name: "test 1", IsActivated: true, ActionId: "1"},
-		{Name: "test 2", IsActivated: false, ActionId: "2"},
-	}
-	test.Run("Success", func(test *testing.T) {
-		mockWorkflowRepo := new(MockWorkflowRepository)
-
-		workflow := &WorkflowService{
-			WorkflowRepository: mockWorkflowRepo,
-		}
-
-		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
-			Return(workflowEntities, nil)
+func TestCheckWeatherWorkflow(test *testing.T) {
+	workflow := &WorkflowService{}
 
-		err := workflow.checkWorkflowsWithWeatherActions(action)
+	err := workflow.checkWeatherWorkflow(context.Background(), entities.Action{Name: "action"}, entities.Workflow{Name: "test 1"})
 
-		require.NoError(test, err)
-	})
-
-	test.Run("Fail find workflow", func(test *testing.T) {
-		mockWorkflowRepo := new(MockWorkflowRepository)
-
-		workflow := &WorkflowService{
-			WorkflowRepository: mockWorkflowRepo,
-		}
-
-		mockWorkflowRepo.On("FindWorkflowsByActionId", action.Id).
-			Return(workflowEntities, errors.New("Fail find workflows"))
-
-		err := workflow.checkWorkflowsWithWeatherActions(action)
-
-		require.EqualError(test, err, "Fail find workflows")
-	})
+	require.NoError(test, err)
 }
 
 func TestCheckWeatherActions(test *testing.T) {
@@ -212,21 +178,23 @@ func TestCheckWeatherActions(test *testing.T) {
 		}
 
 		actionFound := []entities.Action{
-			{Name: "Action 1", Id: "1"},
+			{Name: "Action 1", Id: "1", MinimumInterval: 3600, DefaultInterval: 43200},
 		}
 
 		workflowFound := []entities.Workflow{
-			{Name: "test 1", IsActivated: true, ActionId: "1"},
+			{Id: "1", Name: "test 1", IsActivated: true, ActionId: "1"},
 		}
 
 		mockServiceServiecRepo := new(MockServiceServiceRepository)
 		mockActionRepo := new(MockActionRepository)
 		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockUserServiceRepo := new(MockUserServiceRepository)
 
 		workflow := &WorkflowService{
 			ServiceService:     mockServiceServiecRepo,
 			ActionRepository:   mockActionRepo,
 			WorkflowRepository: mockWorkflowRepo,
+			UserServiceService: mockUserServiceRepo,
 		}
 
 		mockServiceServiecRepo.On("FindServiceByName", "FreeWeather").
@@ -235,10 +203,14 @@ func TestCheckWeatherActions(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", serviceFound.Id).
 			Return(actionFound, nil)
 
-		mockWorkflowRepo.On("FindWorkflowsByActionId", actionFound[0].Id).
+		mockWorkflowRepo.On("FindDueWorkflowsByActionId", actionFound[0].Id, mock.Anything).
 			Return(workflowFound, nil)
+		mockWorkflowRepo.On("ClaimWorkflowCheck", "1", mock.Anything, mock.Anything).
+			Return(true, nil)
+		mockUserServiceRepo.On("RecordServiceCall", mock.Anything, "FreeWeather", nil).
+			Return(nil)
 
-		err := workflow.CheckWeatherActions()
+		err := workflow.CheckWeatherActions(context.Background())
 
 		require.NoError(test, err)
 	})
@@ -253,7 +225,7 @@ func TestCheckWeatherActions(test *testing.T) {
 		mockServiceServiecRepo.On("FindServiceByName", "FreeWeather").
 			Return(entities.Service{}, errors.New("Fail find service"))
 
-		err := workflow.CheckWeatherActions()
+		err := workflow.CheckWeatherActions(context.Background())
 
 		require.EqualError(test, err, "Fail find service")
 	})
@@ -277,7 +249,7 @@ func TestCheckWeatherActions(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", serviceFound.Id).
 			Return([]entities.Action{}, errors.New("Fail find actions"))
 
-		err := workflow.CheckWeatherActions()
+		err := workflow.CheckWeatherActions(context.Background())
 
 		require.EqualError(test, err, "Fail find actions")
 	})
@@ -288,11 +260,11 @@ func TestCheckWeatherActions(test *testing.T) {
 		}
 
 		actionFound := []entities.Action{
-			{Name: "Action 1", Id: "1"},
+			{Name: "Action 1", Id: "1", MinimumInterval: 3600, DefaultInterval: 43200},
 		}
 
 		workflowFound := []entities.Workflow{
-			{Name: "test 1", IsActivated: true, ActionId: "1"},
+			{Id: "1", Name: "test 1", IsActivated: true, ActionId: "1"},
 		}
 
 		mockServiceServiecRepo := new(MockServiceServiceRepository)
@@ -311,11 +283,11 @@ func TestCheckWeatherActions(test *testing.T) {
 		mockActionRepo.On("FindActionsByServiceId", serviceFound.Id).
 			Return(actionFound, nil)
 
-		mockWorkflowRepo.On("FindWorkflowsByActionId", actionFound[0].Id).
+		mockWorkflowRepo.On("FindDueWorkflowsByActionId", actionFound[0].Id, mock.Anything).
 			Return(workflowFound, errors.New("Fail check workflows"))
 
-		err := workflow.CheckWeatherActions()
+		err := workflow.CheckWeatherActions(context.Background())
 
-		require.EqualError(test, err, "Fail check workflows")
+		require.EqualError(test, err, errorPollFailures)
 	})
 }
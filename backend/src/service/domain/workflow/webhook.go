@@ -0,0 +1,136 @@
+package workflow_service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"backend/src/entities"
+)
+
+// Each workflow on the incoming webhook action is triggered by a POST on /hooks/in/:token,
+// the token is generated when the workflow is saved and kept in its action data.
+const incomingWebhookActionName = "Incoming webhook"
+const webhookTokenKey = "webhooktoken"
+const webhookTokenBytes = 32
+
+// When the workflow has a secret, the body must be signed with it: "sha256=" followed by the hex HMAC-SHA256
+const webhookSignatureHeader = "X-Webhook-Signature-256"
+const webhookSignaturePrefix = "sha256="
+
+const errorWebhookNotFound = "Webhook not found"
+const errorInvalidWebhookSignature = "Invalid webhook signature"
+const errorInvalidWebhookBody = "Invalid webhook body"
+const errorWebhookBodyTooLarge = "Webhook body too large"
+const errorMissingWebhookSecret = "No webhook secret registered"
+
+func generateWebhookToken() (string, error) {
+	token := make([]byte, webhookTokenBytes)
+
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// Gives a token to the workflows of the incoming webhook action, a token already saved is kept
+func newWorkflowActionData(action entities.Action, actionData map[string]interface{}) (map[string]interface{}, error) {
+	if action.Name != incomingWebhookActionName {
+		return actionData, nil
+	}
+
+	token, tokenIsString := actionData[webhookTokenKey].(string)
+	if tokenIsString && token != "" {
+		return actionData, nil
+	}
+
+	token, err := generateWebhookToken()
+	if err != nil {
+		return actionData, err
+	}
+	if actionData == nil {
+		actionData = make(map[string]interface{})
+	}
+	actionData[webhookTokenKey] = token
+	return actionData, nil
+}
+
+func checkWebhookSignature(secret, signature string, body []byte) error {
+	if secret == "" {
+		return nil
+	}
+
+	signatureBytes, err := hex.DecodeString(strings.TrimPrefix(signature, webhookSignaturePrefix))
+	if err != nil || !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return fmt.Errorf(errorInvalidWebhookSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(signatureBytes, mac.Sum(nil)) {
+		return fmt.Errorf(errorInvalidWebhookSignature)
+	}
+	return nil
+}
+
+func (self *WorkflowService) findIncomingWebhookWorkflow(token string) (entities.Workflow, error) {
+	if token == "" {
+		return entities.Workflow{}, fmt.Errorf(errorWebhookNotFound)
+	}
+
+	workflow, err := self.WorkflowRepository.FindWorkflowByWebhookToken(token)
+	if err != nil {
+		return workflow, fmt.Errorf(errorWebhookNotFound)
+	}
+
+	action, err := self.ActionRepository.FindActionById(workflow.ActionId)
+	if err != nil || action.Name != incomingWebhookActionName {
+		return workflow, fmt.Errorf(errorWebhookNotFound)
+	}
+	return workflow, nil
+}
+
+// Any JSON body is accepted, it is exposed to the filter and the reactions as the "body" variable
+func (self *WorkflowService) CheckIncomingWebhook(token string, request *http.Request) error {
+	body, err := io.ReadAll(request.Body)
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return fmt.Errorf(errorWebhookBodyTooLarge)
+	}
+	if err != nil {
+		return fmt.Errorf(errorInvalidWebhookBody)
+	}
+
+	workflow, err := self.findIncomingWebhookWorkflow(token)
+	if err != nil {
+		return err
+	}
+
+	secret, _ := workflow.ActionParam["secret"].(string)
+	err = checkWebhookSignature(secret, request.Header.Get(webhookSignatureHeader), body)
+	if err != nil {
+		return err
+	}
+
+	var payload interface{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		err = json.Unmarshal(body, &payload)
+		if err != nil {
+			return fmt.Errorf(errorInvalidWebhookBody)
+		}
+	}
+
+	if workflow.IsActivated {
+		self.checkReactions(workflow, entities.ActionEvent{"body": payload})
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package workflow_service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+)
+
+func signWebhookBody(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestNewWorkflowActionData(test *testing.T) {
+	webhookAction := entities.Action{Name: incomingWebhookActionName}
+
+	test.Run("Other Action", func(test *testing.T) {
+		actionData, err := newWorkflowActionData(entities.Action{Name: "Every hour at"}, nil)
+
+		require.NoError(test, err)
+		require.Nil(test, actionData)
+	})
+
+	test.Run("New Token", func(test *testing.T) {
+		actionData, err := newWorkflowActionData(webhookAction, nil)
+
+		require.NoError(test, err)
+		require.Len(test, actionData[webhookTokenKey], 2*webhookTokenBytes)
+
+		otherActionData, _ := newWorkflowActionData(webhookAction, nil)
+		require.NotEqual(test, actionData[webhookTokenKey], otherActionData[webhookTokenKey])
+	})
+
+	test.Run("Existing Token", func(test *testing.T) {
+		actionData, err := newWorkflowActionData(webhookAction, map[string]interface{}{webhookTokenKey: "token"})
+
+		require.NoError(test, err)
+		require.Equal(test, "token", actionData[webhookTokenKey])
+	})
+}
+
+func TestCheckWebhookSignature(test *testing.T) {
+	body := []byte(`{"status":"failed"}`)
+
+	test.Run("No Secret", func(test *testing.T) {
+		require.NoError(test, checkWebhookSignature("", "", body))
+	})
+
+	test.Run("Valid Signature", func(test *testing.T) {
+		require.NoError(test, checkWebhookSignature("secret", signWebhookBody("secret", string(body)), body))
+	})
+
+	test.Run("Invalid Signatures", func(test *testing.T) {
+		signatures := []string{
+			"",
+			signWebhookBody("other", string(body)),
+			strings.TrimPrefix(signWebhookBody("secret", string(body)), webhookSignaturePrefix),
+			webhookSignaturePrefix + "not hex",
+		}
+
+		for _, signature := range signatures {
+			require.EqualError(test, checkWebhookSignature("secret", signature, body), errorInvalidWebhookSignature, signature)
+		}
+	})
+}
+
+func TestCheckIncomingWebhook(test *testing.T) {
+	newService := func(workflow entities.Workflow, actionName string) (*WorkflowService, *MockWorkflowRunRepository) {
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockActionRepo := new(MockActionRepository)
+		mockWorkflowReactionRepo := new(MockWorkflowReactionRepository)
+		mockWorkflowRunRepo := new(MockWorkflowRunRepository)
+
+		mockWorkflowRepo.On("FindWorkflowByWebhookToken", "token").
+			Return(workflow, nil)
+		mockWorkflowRepo.On("FindWorkflowByWebhookToken", mock.Anything).
+			Return(entities.Workflow{}, errors.New("sql: no rows in result set"))
+		mockActionRepo.On("FindActionById", "action").
+			Return(entities.Action{Name: actionName}, nil)
+		mockWorkflowReactionRepo.On("FindWorkflowReactionsByWorkflowId", "1").
+			Return([]entities.WorkflowReaction{}, errors.New("Fail find reactions"))
+		mockWorkflowRunRepo.On("CreateWorkflowRun", "1", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+		mockWorkflowRepo.On("IncrementWorkflowFailures", "1").
+			Return(1, nil)
+
+		return &WorkflowService{
+			WorkflowRepository:         mockWorkflowRepo,
+			ActionRepository:           mockActionRepo,
+			WorkflowReactionRepository: mockWorkflowReactionRepo,
+			WorkflowRunRepository:      mockWorkflowRunRepo,
+		}, mockWorkflowRunRepo
+	}
+	newRequest := func(body, signature string) *http.Request {
+		request, _ := http.NewRequest("POST", "/hooks/in/token", strings.NewReader(body))
+		if signature != "" {
+			request.Header.Set(webhookSignatureHeader, signature)
+		}
+		return request
+	}
+	workflow := entities.Workflow{Id: "1", ActionId: "action", IsActivated: true}
+	signedWorkflow := entities.Workflow{Id: "1", ActionId: "action", IsActivated: true, ActionParam: map[string]interface{}{"secret": "secret"}}
+
+	test.Run("Success", func(test *testing.T) {
+		service, mockWorkflowRunRepo := newService(workflow, incomingWebhookActionName)
+
+		err := service.CheckIncomingWebhook("token", newRequest(`{"status": "failed"}`, ""))
+
+		require.NoError(test, err)
+		mockWorkflowRunRepo.AssertCalled(test, "CreateWorkflowRun", "1", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			map[string]interface{}{"body": map[string]interface{}{"status": "failed"}})
+	})
+
+	test.Run("Empty Body", func(test *testing.T) {
+		service, mockWorkflowRunRepo := newService(workflow, incomingWebhookActionName)
+
+		err := service.CheckIncomingWebhook("token", newRequest("", ""))
+
+		require.NoError(test, err)
+		mockWorkflowRunRepo.AssertNumberOfCalls(test, "CreateWorkflowRun", 1)
+	})
+
+	test.Run("Signed Body", func(test *testing.T) {
+		service, mockWorkflowRunRepo := newService(signedWorkflow, incomingWebhookActionName)
+		body := `["deploy", 42]`
+
+		err := service.CheckIncomingWebhook("token", newRequest(body, signWebhookBody("secret", body)))
+
+		require.NoError(test, err)
+		mockWorkflowRunRepo.AssertNumberOfCalls(test, "CreateWorkflowRun", 1)
+	})
+
+	test.Run("Invalid Signature", func(test *testing.T) {
+		service, mockWorkflowRunRepo := newService(signedWorkflow, incomingWebhookActionName)
+
+		err := service.CheckIncomingWebhook("token", newRequest(`{}`, signWebhookBody("other", `{}`)))
+
+		require.EqualError(test, err, errorInvalidWebhookSignature)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun")
+	})
+
+	test.Run("Body Too Large", func(test *testing.T) {
+		service, mockWorkflowRunRepo := newService(workflow, incomingWebhookActionName)
+		request := newRequest(`{"status": "failed"}`, "")
+		request.Body = http.MaxBytesReader(nil, request.Body, 4)
+
+		err := service.CheckIncomingWebhook("token", request)
+
+		require.EqualError(test, err, errorWebhookBodyTooLarge)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun")
+	})
+
+	test.Run("Unknown Token", func(test *testing.T) {
+		service, _ := newService(workflow, incomingWebhookActionName)
+
+		err := service.CheckIncomingWebhook("unknown", newRequest(`{}`, ""))
+
+		require.EqualError(test, err, errorWebhookNotFound)
+	})
+
+	test.Run("Empty Token", func(test *testing.T) {
+		service, _ := newService(workflow, incomingWebhookActionName)
+
+		err := service.CheckIncomingWebhook("", newRequest(`{}`, ""))
+
+		require.EqualError(test, err, errorWebhookNotFound)
+	})
+
+	test.Run("Other Action", func(test *testing.T) {
+		service, _ := newService(workflow, "Every hour at")
+
+		err := service.CheckIncomingWebhook("token", newRequest(`{}`, ""))
+
+		require.EqualError(test, err, errorWebhookNotFound)
+	})
+
+	test.Run("Invalid Body", func(test *testing.T) {
+		service, mockWorkflowRunRepo := newService(workflow, incomingWebhookActionName)
+
+		err := service.CheckIncomingWebhook("token", newRequest(`{"status":`, ""))
+
+		require.EqualError(test, err, errorInvalidWebhookBody)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun")
+	})
+
+	test.Run("Deactivated Workflow", func(test *testing.T) {
+		service, mockWorkflowRunRepo := newService(entities.Workflow{Id: "1", ActionId: "action"}, incomingWebhookActionName)
+
+		err := service.CheckIncomingWebhook("token", newRequest(`{}`, ""))
+
+		require.NoError(test, err)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun")
+	})
+}
@@ -0,0 +1,116 @@
+package workflow_service
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/src/entities"
+)
+
+// Services retry the deliveries they consider failed, the same delivery id received again within the window is ignored
+const webhookDeliveryDuplicateWindow = 24 * time.Hour
+
+// The id of the stored delivery is given to the reactions, and so kept in the runs, to be able to replay it
+const webhookDeliveryIdVariable = "delivery_id"
+
+const errorWebhookDeliveryNotFound = "Webhook delivery not found"
+
+func webhookDeliveryHeaders(headers http.Header) http.Header {
+	storedHeaders := headers.Clone()
+
+	for _, secretHeader := range []string{"X-Hub-Signature", "X-Hub-Signature-256", "X-Gitlab-Token", "Authorization", "Cookie"} {
+		storedHeaders.Del(secretHeader)
+	}
+	return storedHeaders
+}
+
+// Returns false when the delivery was already received within the duplicate window
+func (self *WorkflowService) storeWebhookDelivery(serviceName string, webhookEvent *entities.WebhookEvent, headers http.Header, body []byte) (bool, error) {
+	if webhookEvent.DeliveryId == "" {
+		return true, nil
+	}
+
+	id, err := self.WebhookDeliveryRepository.CreateWebhookDelivery(serviceName, webhookEvent.DeliveryId, webhookDeliveryHeaders(headers),
+		body, time.Now().Add(-webhookDeliveryDuplicateWindow))
+	if err != nil {
+		return false, err
+	}
+	if id == "" {
+		return false, nil
+	}
+
+	if webhookEvent.Event == nil {
+		webhookEvent.Event = entities.ActionEvent{}
+	}
+	webhookEvent.Event[webhookDeliveryIdVariable] = id
+	return true, nil
+}
+
+func (self *WorkflowService) findOwnerWebhookWorkflows(webhookEvent entities.WebhookEvent, serviceId, ownerId string) ([]entities.Workflow, error) {
+	var ownerWorkflows []entities.Workflow
+
+	action, err := self.ActionRepository.FindActionByNameAndServiceId(webhookEvent.ActionName, serviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	workflows, err := self.WorkflowRepository.FindWorkflowsByActionId(action.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, workflow := range workflows {
+		paramValue, err := getWorkflowStringActionParam(workflow, webhookEvent.ParamKey)
+		if err != nil || !workflow.IsActivated || workflow.OwnerId != ownerId || paramValue != webhookEvent.ParamValue {
+			continue
+		}
+		ownerWorkflows = append(ownerWorkflows, workflow)
+	}
+	return ownerWorkflows, nil
+}
+
+// Only the workflows of the user are run again, a delivery triggering none of them is not found
+func (self *WorkflowService) ReplayWebhookDelivery(email, connectionType, deliveryId string) error {
+	user, err := self.UserRepository.FindUserByEmail(email, connectionType)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := self.WebhookDeliveryRepository.FindWebhookDeliveryById(deliveryId)
+	if err != nil {
+		return fmt.Errorf(errorWebhookDeliveryNotFound)
+	}
+
+	service, err := self.ServiceService.FindServiceByName(delivery.ServiceName)
+	if err != nil {
+		return err
+	}
+
+	connector, err := self.ServiceService.FindConnector(service.Name)
+	if err != nil {
+		return err
+	}
+
+	webhookEvent, err := connector.ParseWebhook(delivery.Headers, delivery.Body)
+	if err != nil {
+		return err
+	}
+	if webhookEvent.ActionName == "" {
+		return fmt.Errorf(errorWebhookDeliveryNotFound)
+	}
+
+	workflows, err := self.findOwnerWebhookWorkflows(webhookEvent, service.Id, user.Id)
+	if err != nil {
+		return err
+	}
+	if len(workflows) == 0 {
+		return fmt.Errorf(errorWebhookDeliveryNotFound)
+	}
+
+	webhookEvent.Event[webhookDeliveryIdVariable] = delivery.Id
+	for _, workflow := range workflows {
+		self.checkReactions(workflow, webhookEvent.Event)
+	}
+	return nil
+}
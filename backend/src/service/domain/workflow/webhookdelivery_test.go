@@ -0,0 +1,233 @@
+package workflow_service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/src/entities"
+	"backend/src/service/connector"
+)
+
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) CreateWebhookDelivery(serviceName, deliveryId string, headers http.Header, body []byte, duplicateSince time.Time) (string, error) {
+	args := m.Called(serviceName, deliveryId, headers, body, duplicateSince)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) FindWebhookDeliveryById(id string) (entities.WebhookDelivery, error) {
+	args := m.Called(id)
+	return args.Get(0).(entities.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhookDeliveryHeaders(test *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-GitHub-Event", "watch")
+	headers.Set("X-GitHub-Delivery", "delivery")
+	headers.Set("X-Hub-Signature-256", "sha256=signature")
+	headers.Set("X-Gitlab-Token", "token")
+
+	storedHeaders := webhookDeliveryHeaders(headers)
+
+	require.Equal(test, http.Header{
+		"X-Github-Event":    []string{"watch"},
+		"X-Github-Delivery": []string{"delivery"},
+	}, storedHeaders)
+	require.Equal(test, "token", headers.Get("X-Gitlab-Token"))
+}
+
+func TestStoreWebhookDelivery(test *testing.T) {
+	headers := http.Header{"X-Github-Event": []string{"watch"}}
+	isRecentWindow := mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) >= webhookDeliveryDuplicateWindow && time.Since(since) < webhookDeliveryDuplicateWindow+time.Minute
+	})
+
+	test.Run("No Delivery Id", func(test *testing.T) {
+		mockWebhookDeliveryRepo := new(MockWebhookDeliveryRepository)
+		service := &WorkflowService{WebhookDeliveryRepository: mockWebhookDeliveryRepo}
+		webhookEvent := entities.WebhookEvent{Event: entities.ActionEvent{}}
+
+		isNewDelivery, err := service.storeWebhookDelivery("Github", &webhookEvent, headers, []byte(`{}`))
+
+		require.NoError(test, err)
+		require.True(test, isNewDelivery)
+		mockWebhookDeliveryRepo.AssertNotCalled(test, "CreateWebhookDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("New Delivery", func(test *testing.T) {
+		mockWebhookDeliveryRepo := new(MockWebhookDeliveryRepository)
+		service := &WorkflowService{WebhookDeliveryRepository: mockWebhookDeliveryRepo}
+		webhookEvent := entities.WebhookEvent{DeliveryId: "delivery", Event: entities.ActionEvent{}}
+
+		mockWebhookDeliveryRepo.On("CreateWebhookDelivery", "Github", "delivery", headers, []byte(`{}`), isRecentWindow).
+			Return("1", nil).Once()
+
+		isNewDelivery, err := service.storeWebhookDelivery("Github", &webhookEvent, headers, []byte(`{}`))
+
+		require.NoError(test, err)
+		require.True(test, isNewDelivery)
+		require.Equal(test, "1", webhookEvent.Event[webhookDeliveryIdVariable])
+	})
+
+	test.Run("Duplicate Delivery", func(test *testing.T) {
+		mockWebhookDeliveryRepo := new(MockWebhookDeliveryRepository)
+		service := &WorkflowService{WebhookDeliveryRepository: mockWebhookDeliveryRepo}
+		webhookEvent := entities.WebhookEvent{DeliveryId: "delivery", Event: entities.ActionEvent{}}
+
+		mockWebhookDeliveryRepo.On("CreateWebhookDelivery", "Github", "delivery", headers, []byte(`{}`), isRecentWindow).
+			Return("", nil).Once()
+
+		isNewDelivery, err := service.storeWebhookDelivery("Github", &webhookEvent, headers, []byte(`{}`))
+
+		require.NoError(test, err)
+		require.False(test, isNewDelivery)
+	})
+
+	test.Run("Fail Store", func(test *testing.T) {
+		mockWebhookDeliveryRepo := new(MockWebhookDeliveryRepository)
+		service := &WorkflowService{WebhookDeliveryRepository: mockWebhookDeliveryRepo}
+		webhookEvent := entities.WebhookEvent{DeliveryId: "delivery", Event: entities.ActionEvent{}}
+
+		mockWebhookDeliveryRepo.On("CreateWebhookDelivery", "Github", "delivery", headers, []byte(`{}`), isRecentWindow).
+			Return("", errors.New("Fail store")).Once()
+
+		_, err := service.storeWebhookDelivery("Github", &webhookEvent, headers, []byte(`{}`))
+
+		require.EqualError(test, err, "Fail store")
+	})
+}
+
+func TestCheckWebhooksWorkflowsDuplicateDelivery(test *testing.T) {
+	githubConnector, _ := connector.NewRegistry().FindConnector("Github")
+	body := `{"repository": {"full_name": "owner/repo"}}`
+
+	mockServiceService := new(MockServiceServiceRepository)
+	mockActionRepo := new(MockActionRepository)
+	mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+	mockWebhookDeliveryRepo := new(MockWebhookDeliveryRepository)
+
+	service := &WorkflowService{
+		ServiceService:            mockServiceService,
+		ActionRepository:          mockActionRepo,
+		ServiceWebhookRepository:  mockServiceWebhookRepo,
+		WebhookDeliveryRepository: mockWebhookDeliveryRepo,
+	}
+
+	mockServiceService.On("FindServiceByName", "Github").
+		Return(entities.Service{Id: "1", Name: "Github"}, nil)
+	mockServiceService.On("FindConnector", "Github").
+		Return(githubConnector, nil)
+	mockServiceWebhookRepo.On("FindServiceWebhookSecret", "Github", "owner/repo").
+		Return("secret", nil)
+	mockWebhookDeliveryRepo.On("CreateWebhookDelivery", "Github", "delivery", mock.MatchedBy(func(headers http.Header) bool {
+		return headers.Get("X-Hub-Signature-256") == "" && headers.Get("X-GitHub-Event") == "watch"
+	}), []byte(body), mock.Anything).
+		Return("", nil).Once()
+
+	request, _ := http.NewRequest("POST", "/webhooks/Github", io.NopCloser(strings.NewReader(body)))
+	request.Header.Set("X-GitHub-Event", "watch")
+	request.Header.Set("X-GitHub-Delivery", "delivery")
+	request.Header.Set("X-Hub-Signature-256", signWebhookBody("secret", body))
+
+	err := service.CheckWebhooksWorkflows("Github", request)
+
+	require.NoError(test, err)
+	mockWebhookDeliveryRepo.AssertExpectations(test)
+	mockActionRepo.AssertNotCalled(test, "FindActionByNameAndServiceId", mock.Anything, mock.Anything)
+}
+
+func TestReplayWebhookDelivery(test *testing.T) {
+	githubConnector, _ := connector.NewRegistry().FindConnector("Github")
+	delivery := entities.WebhookDelivery{
+		Id:          "delivery",
+		ServiceName: "Github",
+		Headers:     http.Header{"X-Github-Event": []string{"watch"}},
+		Body:        []byte(`{"repository": {"full_name": "owner/repo"}}`),
+	}
+	user := entities.User{Id: "owner"}
+
+	newService := func() (*WorkflowService, *MockUserRepository, *MockWebhookDeliveryRepository, *MockWorkflowRepository, *MockWorkflowRunRepository) {
+		service, mockWorkflowRepo, mockWorkflowRunRepo := newTimeAndDateFiringService()
+		mockUserRepo := new(MockUserRepository)
+		mockWebhookDeliveryRepo := new(MockWebhookDeliveryRepository)
+		mockServiceService := new(MockServiceServiceRepository)
+		mockActionRepo := new(MockActionRepository)
+
+		mockServiceService.On("FindServiceByName", "Github").
+			Return(entities.Service{Id: "1", Name: "Github"}, nil)
+		mockServiceService.On("FindConnector", "Github").
+			Return(githubConnector, nil)
+		mockActionRepo.On("FindActionByNameAndServiceId", "New star", "1").
+			Return(entities.Action{Id: "2"}, nil)
+
+		service.UserRepository = mockUserRepo
+		service.WebhookDeliveryRepository = mockWebhookDeliveryRepo
+		service.ServiceService = mockServiceService
+		service.ActionRepository = mockActionRepo
+		return service, mockUserRepo, mockWebhookDeliveryRepo, mockWorkflowRepo, mockWorkflowRunRepo
+	}
+
+	test.Run("Unknown Delivery", func(test *testing.T) {
+		service, mockUserRepo, mockWebhookDeliveryRepo, _, _ := newService()
+
+		mockUserRepo.On("FindUserByEmail", "email", "basic").
+			Return(user, nil)
+		mockWebhookDeliveryRepo.On("FindWebhookDeliveryById", "unknown").
+			Return(entities.WebhookDelivery{}, errors.New("no rows"))
+
+		err := service.ReplayWebhookDelivery("email", "basic", "unknown")
+
+		require.EqualError(test, err, errorWebhookDeliveryNotFound)
+	})
+
+	test.Run("Delivery Of Another User", func(test *testing.T) {
+		service, mockUserRepo, mockWebhookDeliveryRepo, mockWorkflowRepo, mockWorkflowRunRepo := newService()
+
+		mockUserRepo.On("FindUserByEmail", "email", "basic").
+			Return(user, nil)
+		mockWebhookDeliveryRepo.On("FindWebhookDeliveryById", "delivery").
+			Return(delivery, nil)
+		mockWorkflowRepo.On("FindWorkflowsByActionId", "2").
+			Return([]entities.Workflow{
+				{Id: "1", OwnerId: "other", IsActivated: true, ActionParam: map[string]interface{}{"repository": "owner/repo"}},
+				{Id: "1", OwnerId: "owner", IsActivated: true, ActionParam: map[string]interface{}{"repository": "other/repo"}},
+			}, nil)
+
+		err := service.ReplayWebhookDelivery("email", "basic", "delivery")
+
+		require.EqualError(test, err, errorWebhookDeliveryNotFound)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Success", func(test *testing.T) {
+		service, mockUserRepo, mockWebhookDeliveryRepo, mockWorkflowRepo, mockWorkflowRunRepo := newService()
+
+		mockUserRepo.On("FindUserByEmail", "email", "basic").
+			Return(user, nil)
+		mockWebhookDeliveryRepo.On("FindWebhookDeliveryById", "delivery").
+			Return(delivery, nil)
+		mockWorkflowRepo.On("FindWorkflowsByActionId", "2").
+			Return([]entities.Workflow{
+				{Id: "1", OwnerId: "owner", IsActivated: true, ActionParam: map[string]interface{}{"repository": "owner/repo"}},
+				{Id: "3", OwnerId: "other", IsActivated: true, ActionParam: map[string]interface{}{"repository": "owner/repo"}},
+			}, nil)
+
+		err := service.ReplayWebhookDelivery("email", "basic", "delivery")
+
+		require.NoError(test, err)
+		mockWorkflowRunRepo.AssertNumberOfCalls(test, "CreateWorkflowRun", 1)
+		mockWorkflowRunRepo.AssertCalled(test, "CreateWorkflowRun", "1", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.MatchedBy(func(actionPayload map[string]interface{}) bool {
+				return actionPayload[webhookDeliveryIdVariable] == "delivery"
+			}))
+	})
+}
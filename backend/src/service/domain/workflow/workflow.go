@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
 	"backend/src/entities"
 	"backend/src/service"
@@ -12,12 +13,21 @@ import (
 )
 
 type WorkflowService struct {
-	WorkflowRepository storage.WorkflowRepository
-	UserRepository     storage.UserRepository
-	ActionRepository   storage.ActionRepository
-	ReactionRepository storage.ReactionRepository
-	ServiceService     service.ServiceService
-	UserServiceService service.UserServiceService
+	WorkflowRepository         storage.WorkflowRepository
+	UserRepository             storage.UserRepository
+	ActionRepository           storage.ActionRepository
+	ReactionRepository         storage.ReactionRepository
+	WorkflowReactionRepository storage.WorkflowReactionRepository
+	WorkflowRunRepository      storage.WorkflowRunRepository
+	SchedulerTickRepository    storage.SchedulerTickRepository
+	ServiceWebhookRepository   storage.ServiceWebhookRepository
+	WebhookDeliveryRepository  storage.WebhookDeliveryRepository
+	JobRepository              storage.JobRepository
+	ServiceService             service.ServiceService
+	UserServiceService         service.UserServiceService
+
+	jobWorkers sync.WaitGroup
+	scheduler  schedulerState
 }
 
 const bearerType = "Bearer "
@@ -26,20 +36,110 @@ const contentType = "Content-Type"
 const errorUpdatingWorkflow = "Could not update workflow"
 const errorUpdatingToken = "Could not update token"
 const errorRetrievingReaction = "Error finding reaction"
+const errorRetrievingWorkflow = "Error finding workflow"
 const errorMissingField = "Missing required field"
 const errorMarshaling = "Could not marshal JSON"
+const errorUnknownReactionService = "Unknown reaction service"
+const errorMissingReaction = "Workflow must have at least one reaction"
+const errorInvalidCatchUpPolicy = "Invalid catch-up policy, expected once, all or skip"
+const errorInvalidPollInterval = "Invalid poll interval, expected a positive number of seconds"
+const errorActionNotPolled = "This action is not polled, it does not take a poll interval"
+const errorPollIntervalBelowMinimum = "Poll interval below the minimum of this action, expected at least %d seconds"
 
 func NewWorkflowService(WorkflowRepository storage.WorkflowRepository, UserRepository storage.UserRepository,
-	ActionRepository storage.ActionRepository, ReactionRepository storage.ReactionRepository,
-	ServiceService service.ServiceService, UserServiceService service.UserServiceService) *WorkflowService {
+	ActionRepository storage.ActionRepository, ReactionRepository storage.ReactionRepository, WorkflowReactionRepository storage.WorkflowReactionRepository,
+	WorkflowRunRepository storage.WorkflowRunRepository, SchedulerTickRepository storage.SchedulerTickRepository, ServiceWebhookRepository storage.ServiceWebhookRepository,
+	WebhookDeliveryRepository storage.WebhookDeliveryRepository, JobRepository storage.JobRepository, ServiceService service.ServiceService, UserServiceService service.UserServiceService) *WorkflowService {
 	return &WorkflowService{
-		WorkflowRepository: WorkflowRepository,
-		UserRepository:     UserRepository,
-		ActionRepository:   ActionRepository,
-		ReactionRepository: ReactionRepository,
-		ServiceService:     ServiceService,
-		UserServiceService: UserServiceService,
+		WorkflowRepository:         WorkflowRepository,
+		UserRepository:             UserRepository,
+		ActionRepository:           ActionRepository,
+		ReactionRepository:         ReactionRepository,
+		WorkflowReactionRepository: WorkflowReactionRepository,
+		WorkflowRunRepository:      WorkflowRunRepository,
+		SchedulerTickRepository:    SchedulerTickRepository,
+		ServiceWebhookRepository:   ServiceWebhookRepository,
+		WebhookDeliveryRepository:  WebhookDeliveryRepository,
+		JobRepository:              JobRepository,
+		ServiceService:             ServiceService,
+		UserServiceService:         UserServiceService,
+	}
+}
+
+// Workflows created with a single reactionid and reactionparam are stored as a one step workflow
+func newWorkflowReactions(reactions []entities.NewWorkflowReaction, reactionId string, reactionParam map[string]interface{}) []entities.NewWorkflowReaction {
+	if len(reactions) > 0 {
+		return reactions
+	}
+	if reactionId == "" {
+		return nil
+	}
+	return []entities.NewWorkflowReaction{{ReactionId: reactionId, ReactionParam: reactionParam}}
+}
+
+func checkWorkflowReactions(reactions []entities.NewWorkflowReaction) error {
+	if len(reactions) == 0 {
+		return fmt.Errorf(errorMissingReaction)
+	}
+	for _, reaction := range reactions {
+		if reaction.ReactionId == "" {
+			return fmt.Errorf(errorMissingField)
+		}
+	}
+	return nil
+}
+
+func (self *WorkflowService) saveWorkflowReactions(workflowId string, reactions []entities.NewWorkflowReaction) error {
+	for position, reaction := range reactions {
+		err := self.WorkflowReactionRepository.CreateWorkflowReaction(workflowId, reaction.ReactionId, position, reaction.ContinueOnError, reaction.ReactionParam)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkCatchUpPolicy(catchUpPolicy string) error {
+	switch catchUpPolicy {
+	case entities.CatchUpPolicyOnce, entities.CatchUpPolicyAll, entities.CatchUpPolicySkip:
+		return nil
+	}
+	return entities.WorkflowValidationError{Message: errorInvalidCatchUpPolicy}
+}
+
+// A poll interval of 0 uses the default interval of the action
+func checkPollInterval(action entities.Action, pollInterval int) error {
+	if pollInterval < 0 {
+		return entities.WorkflowValidationError{Message: errorInvalidPollInterval}
+	}
+	if pollInterval == 0 {
+		return nil
+	}
+	if action.MinimumInterval == 0 {
+		return entities.WorkflowValidationError{Message: errorActionNotPolled}
+	}
+	if pollInterval < action.MinimumInterval {
+		return entities.WorkflowValidationError{Message: fmt.Sprintf(errorPollIntervalBelowMinimum, action.MinimumInterval)}
+	}
+	return nil
+}
+
+func (self *WorkflowService) checkWorkflowAction(actionId, filter, timezone string, pollInterval int, actionParam map[string]interface{}) (entities.Action, error) {
+	action, err := self.ActionRepository.FindActionById(actionId)
+	if err != nil {
+		return action, err
+	}
+
+	err = checkPollInterval(action, pollInterval)
+	if err != nil {
+		return action, err
+	}
+
+	err = checkTimeAndDateActionParams(action.Name, actionParam, timezone)
+	if err != nil {
+		return action, err
 	}
+	return action, checkWorkflowFilter(filter, action)
 }
 
 func (self *WorkflowService) CreateWorkflow(userEmail, userConnectionType string, newWorkflow entities.NewWorkflow) error {
@@ -47,13 +147,40 @@ func (self *WorkflowService) CreateWorkflow(userEmail, userConnectionType string
 	if errFindingUser != nil {
 		return errFindingUser
 	}
-	errCreationWorkflow := self.WorkflowRepository.CreateWorkflow(newWorkflow.Name,
-		userFound.Id, newWorkflow.ActionId, newWorkflow.ReactionId,
-		newWorkflow.ActionParam, newWorkflow.ReactionParam, newWorkflow.ActionData)
+
+	reactions := newWorkflowReactions(newWorkflow.Reactions, newWorkflow.ReactionId, newWorkflow.ReactionParam)
+	errReactions := checkWorkflowReactions(reactions)
+	if errReactions != nil {
+		return errReactions
+	}
+
+	if newWorkflow.CatchUpPolicy == "" {
+		newWorkflow.CatchUpPolicy = entities.CatchUpPolicyOnce
+	}
+	errCatchUpPolicy := checkCatchUpPolicy(newWorkflow.CatchUpPolicy)
+	if errCatchUpPolicy != nil {
+		return errCatchUpPolicy
+	}
+
+	action, errAction := self.checkWorkflowAction(newWorkflow.ActionId, newWorkflow.Filter, userFound.Timezone, newWorkflow.PollInterval, newWorkflow.ActionParam)
+	if errAction != nil {
+		return errAction
+	}
+
+	// The token of an incoming webhook is always generated by the server
+	delete(newWorkflow.ActionData, webhookTokenKey)
+	newWorkflow.ActionData, errAction = newWorkflowActionData(action, newWorkflow.ActionData)
+	if errAction != nil {
+		return errAction
+	}
+
+	workflowId, errCreationWorkflow := self.WorkflowRepository.CreateWorkflow(newWorkflow.Name,
+		userFound.Id, newWorkflow.ActionId, reactions[0].ReactionId, newWorkflow.Filter, newWorkflow.CatchUpPolicy,
+		newWorkflow.PollInterval, newWorkflow.ActionParam, reactions[0].ReactionParam, newWorkflow.ActionData)
 	if errCreationWorkflow != nil {
 		return errCreationWorkflow
 	}
-	return nil
+	return self.saveWorkflowReactions(workflowId, reactions)
 }
 
 func (self *WorkflowService) GetUserWorkflows(email, connectionType string) ([]entities.Workflow, error) {
@@ -66,6 +193,13 @@ func (self *WorkflowService) GetUserWorkflows(email, connectionType string) ([]e
 	if err != nil {
 		return nil, err
 	}
+
+	for i := range retrievedWorkflow {
+		retrievedWorkflow[i].Reactions, err = self.WorkflowReactionRepository.FindWorkflowReactionsByWorkflowId(retrievedWorkflow[i].Id)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return retrievedWorkflow, nil
 }
 
@@ -93,12 +227,61 @@ func (self *WorkflowService) UpdateWorkflow(workflowId string, workflow entities
 	if workflow.ReactionParam != nil {
 		updatedWorkflow.ReactionParam = *workflow.ReactionParam
 	}
+	if workflow.Filter != nil {
+		updatedWorkflow.Filter = *workflow.Filter
+	}
+	if workflow.CatchUpPolicy != nil {
+		err = checkCatchUpPolicy(*workflow.CatchUpPolicy)
+		if err != nil {
+			return err
+		}
+		updatedWorkflow.CatchUpPolicy = *workflow.CatchUpPolicy
+	}
+	if workflow.PollInterval != nil {
+		updatedWorkflow.PollInterval = *workflow.PollInterval
+	}
+	if workflow.Filter != nil || workflow.ActionId != nil || workflow.ActionParam != nil || workflow.PollInterval != nil {
+		timezone := self.findOwnerTimezone(updatedWorkflow.OwnerId)
+		action, err := self.checkWorkflowAction(updatedWorkflow.ActionId, updatedWorkflow.Filter, timezone, updatedWorkflow.PollInterval, updatedWorkflow.ActionParam)
+		if err != nil {
+			return err
+		}
+
+		updatedWorkflow.ActionData, err = newWorkflowActionData(action, updatedWorkflow.ActionData)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Updating the single reactionid or reactionparam replaces the steps by a one step workflow
+	var reactions []entities.NewWorkflowReaction
+	if workflow.Reactions != nil {
+		reactions = *workflow.Reactions
+	} else if workflow.ReactionId != nil || workflow.ReactionParam != nil {
+		reactions = newWorkflowReactions(nil, updatedWorkflow.ReactionId, updatedWorkflow.ReactionParam)
+	}
+	if workflow.Reactions != nil || reactions != nil {
+		err = checkWorkflowReactions(reactions)
+		if err != nil {
+			return err
+		}
+		updatedWorkflow.ReactionId = reactions[0].ReactionId
+		updatedWorkflow.ReactionParam = reactions[0].ReactionParam
+	}
 
 	err = self.WorkflowRepository.UpdateWorkflow(workflowId, updatedWorkflow)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	if reactions == nil {
+		return nil
+	}
+	err = self.WorkflowReactionRepository.DeleteWorkflowReactionsByWorkflowId(workflowId)
+	if err != nil {
+		return err
+	}
+	return self.saveWorkflowReactions(workflowId, reactions)
 }
 
 func (self *WorkflowService) DeleteWorkflow(email, connectionType, workflowId string) error {
@@ -114,6 +297,33 @@ func (self *WorkflowService) DeleteWorkflow(email, connectionType, workflowId st
 	return nil
 }
 
+func (self *WorkflowService) GetWorkflowRuns(email, connectionType, workflowId, status string, page, pageSize int) (entities.WorkflowRunsPage, error) {
+	workflowRunsPage := entities.WorkflowRunsPage{
+		Runs:     []entities.WorkflowRun{},
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	_, err := self.findUserWorkflow(email, connectionType, workflowId)
+	if err != nil {
+		return workflowRunsPage, err
+	}
+
+	workflowRunsPage.Total, err = self.WorkflowRunRepository.CountWorkflowRunsByWorkflowId(workflowId, status)
+	if err != nil {
+		return workflowRunsPage, err
+	}
+
+	workflowRuns, err := self.WorkflowRunRepository.FindWorkflowRunsByWorkflowId(workflowId, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return workflowRunsPage, err
+	}
+	if workflowRuns != nil {
+		workflowRunsPage.Runs = workflowRuns
+	}
+	return workflowRunsPage, nil
+}
+
 func (self *WorkflowService) getAccessToken(serviceName string, workflow entities.Workflow) (string, error) {
 	foundUser, errUser := self.UserRepository.FindUserById(workflow.OwnerId)
 	if errUser != nil {
@@ -176,6 +386,73 @@ func getWorkflowStringActionParam(workflow entities.Workflow, paramKey string) (
 	return paramString, nil
 }
 
+func (self *WorkflowService) checkWebhookWorkflow(workflow entities.Workflow, webhookEvent entities.WebhookEvent) error {
+	paramValue, err := getWorkflowStringActionParam(workflow, webhookEvent.ParamKey)
+	if err != nil {
+		return err
+	}
+
+	if paramValue == webhookEvent.ParamValue {
+		self.checkReactions(workflow, webhookEvent.Event)
+	}
+	return nil
+}
+
+func (self *WorkflowService) checkWebhookWorkflows(webhookEvent entities.WebhookEvent, serviceId string) error {
+	action, err := self.ActionRepository.FindActionByNameAndServiceId(webhookEvent.ActionName, serviceId)
+	if err != nil {
+		return err
+	}
+
+	workflows, err := self.WorkflowRepository.FindWorkflowsByActionId(action.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, workflow := range workflows {
+		if !workflow.IsActivated {
+			continue
+		}
+		self.checkWebhookWorkflow(workflow, webhookEvent)
+	}
+	return nil
+}
+
+// The secret is saved once the service accepted it, a hook left without it is registered again on the next check
+func (self *WorkflowService) registerServiceWebhookSecret(serviceName, resource string, register func(secret string) error) error {
+	secret, err := generateWebhookToken()
+	if err != nil {
+		return err
+	}
+
+	err = register(secret)
+	if err != nil {
+		return err
+	}
+	return self.ServiceWebhookRepository.SaveServiceWebhookSecret(serviceName, resource, secret)
+}
+
+func (self *WorkflowService) hasServiceWebhookSecret(serviceName, resource string) bool {
+	_, err := self.ServiceWebhookRepository.FindServiceWebhookSecret(serviceName, resource)
+	return err == nil
+}
+
+// Deliveries for a repository or project without a registered secret are refused as well
+func (self *WorkflowService) verifyServiceWebhook(connector service.Connector, serviceName string, webhookEvent entities.WebhookEvent,
+	request *http.Request, body []byte) error {
+	reason := errorInvalidWebhookSignature
+
+	secret, err := self.ServiceWebhookRepository.FindServiceWebhookSecret(serviceName, webhookEvent.ParamValue)
+	if err != nil {
+		reason = errorMissingWebhookSecret
+	} else if connector.VerifyWebhook(request.Header, body, secret) {
+		return nil
+	}
+
+	self.ServiceWebhookRepository.CreateWebhookRejection(serviceName, webhookEvent.ParamValue, reason, request.RemoteAddr)
+	return fmt.Errorf(errorInvalidWebhookSignature)
+}
+
 func (self *WorkflowService) CheckWebhooksWorkflows(serviceName string, request *http.Request) error {
 	webhookJsonDataBytes, err := io.ReadAll(request.Body)
 	if err != nil {
@@ -187,22 +464,52 @@ func (self *WorkflowService) CheckWebhooksWorkflows(serviceName string, request
 		return err
 	}
 
-	switch service.Name {
-	case "Gitlab":
-		return self.checkGitlabWebhooksWorkflows(request.Header, webhookJsonDataBytes, gitlabWebhooksEventsToActions(), service.Id)
-	case "Github":
-		return self.checkGithubWebhooksWorkflows(request.Header, webhookJsonDataBytes, githubWebhooksEventsToActions(), service.Id)
+	connector, err := self.ServiceService.FindConnector(service.Name)
+	if err != nil {
+		return err
 	}
-	return nil
+
+	webhookEvent, err := connector.ParseWebhook(request.Header, webhookJsonDataBytes)
+	if err != nil {
+		return err
+	}
+	if webhookEvent.ActionName == "" {
+		return nil
+	}
+
+	err = self.verifyServiceWebhook(connector, service.Name, webhookEvent, request, webhookJsonDataBytes)
+	if err != nil {
+		return err
+	}
+
+	isNewDelivery, err := self.storeWebhookDelivery(service.Name, &webhookEvent, request.Header, webhookJsonDataBytes)
+	if err != nil || !isNewDelivery {
+		return err
+	}
+	return self.checkWebhookWorkflows(webhookEvent, service.Id)
 }
 
-func (self *WorkflowService) checkReactions(workflow entities.Workflow) {
-	self.checkSpotifyReactions(workflow)
-	self.checkDiscordReactions(workflow)
-	self.checkLinkedinReactions(workflow)
-	self.checkAsanaReactions(workflow)
-	self.checkSMSReactions(workflow)
-	self.checkSendEmailReactions(workflow)
-	self.checkDropboxReactions(workflow)
-	self.checkRedditReactions(workflow)
+// The reactions are not executed here but enqueued, the job workers execute them
+func (self *WorkflowService) checkReactions(workflow entities.Workflow, event entities.ActionEvent) {
+	if !matchWorkflowFilter(workflow, event) {
+		return
+	}
+
+	workflowReactions, err := self.findWorkflowReactions(workflow)
+	if err != nil {
+		result := setReactionResultError(entities.ReactionResult{ReactionId: workflow.ReactionId}, err)
+		self.recordWorkflowRun(workflow, event, result, false)
+		return
+	}
+
+	self.enqueueReactionSteps(workflow, event, workflowReactions, false)
+}
+
+// A failed run counts towards the suspension of the workflow, once whatever the number of its failed steps
+func (self *WorkflowService) recordWorkflowRun(workflow entities.Workflow, event entities.ActionEvent, result entities.ReactionResult, runFailed bool) error {
+	err := self.WorkflowRunRepository.CreateWorkflowRun(workflow.Id, result.ReactionId, result.Status, result.ErrorMessage, result.HttpStatus, event)
+	if result.Status == entities.WorkflowRunFailure && !runFailed {
+		self.countWorkflowFailure(workflow, result)
+	}
+	return err
 }
@@ -1,22 +1,28 @@
 package workflow_service
 
 import (
+	"context"
 	"errors"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"backend/src/entities"
+	"backend/src/service/connector"
 )
 
 type MockWorkflowRepository struct {
 	mock.Mock
 }
 
-func (m *MockWorkflowRepository) CreateWorkflow(name, ownerId, actionId, reactionId string, actionParam, reactionParam, actionData map[string]interface{}) error {
-	args := m.Called(name, ownerId, actionId, reactionId, actionParam, reactionParam, actionData)
-	return args.Error(0)
+func (m *MockWorkflowRepository) CreateWorkflow(name, ownerId, actionId, reactionId, filter, catchUpPolicy string, pollInterval int, actionParam, reactionParam, actionData map[string]interface{}) (string, error) {
+	args := m.Called(name, ownerId, actionId, reactionId, filter, catchUpPolicy, pollInterval, actionParam, reactionParam, actionData)
+	return args.String(0), args.Error(1)
 }
 
 func (m *MockWorkflowRepository) FindWorkflowById(id string) (entities.Workflow, error) {
@@ -24,11 +30,21 @@ func (m *MockWorkflowRepository) FindWorkflowById(id string) (entities.Workflow,
 	return args.Get(0).(entities.Workflow), args.Error(1)
 }
 
+func (m *MockWorkflowRepository) FindWorkflowByWebhookToken(token string) (entities.Workflow, error) {
+	args := m.Called(token)
+	return args.Get(0).(entities.Workflow), args.Error(1)
+}
+
 func (m *MockWorkflowRepository) FindWorkflowsByActionId(actionId string) ([]entities.Workflow, error) {
 	args := m.Called(actionId)
 	return args.Get(0).([]entities.Workflow), args.Error(1)
 }
 
+func (m *MockWorkflowRepository) FindDueWorkflowsByActionId(actionId string, now time.Time) ([]entities.Workflow, error) {
+	args := m.Called(actionId, now)
+	return args.Get(0).([]entities.Workflow), args.Error(1)
+}
+
 func (m *MockWorkflowRepository) FindWorkflowsByOwnerId(ownerId string) ([]entities.Workflow, error) {
 	args := m.Called(ownerId)
 	return args.Get(0).([]entities.Workflow), args.Error(1)
@@ -39,6 +55,46 @@ func (m *MockWorkflowRepository) UpdateWorkflow(id string, updatedWorkflow entit
 	return args.Error(0)
 }
 
+func (m *MockWorkflowRepository) UpdateWorkflowActionData(id string, actionData map[string]interface{}) error {
+	args := m.Called(id, actionData)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) IncrementWorkflowFailures(id string) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) ResetWorkflowFailures(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) IncrementWorkflowPollFailures(id string) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) ResetWorkflowPollFailures(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRepository) ClaimWorkflowCheck(id string, now, nextCheckAt time.Time) (bool, error) {
+	args := m.Called(id, now, nextCheckAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) SuspendWorkflow(id, reason string) (bool, error) {
+	args := m.Called(id, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWorkflowRepository) SuspendWorkflowsByOwnerIdAndServiceId(ownerId, serviceId, reason string) (int, error) {
+	args := m.Called(ownerId, serviceId, reason)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockWorkflowRepository) DeleteWorkflow(id, ownerId string) error {
 	args := m.Called(id, ownerId)
 	return args.Error(0)
@@ -49,6 +105,63 @@ func (m *MockWorkflowRepository) DeleteWorkflowByOwnerId(ownerId string) error {
 	return args.Error(0)
 }
 
+type MockWorkflowReactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkflowReactionRepository) CreateWorkflowReaction(workflowId, reactionId string, position int, continueOnError bool, reactionParam map[string]interface{}) error {
+	args := m.Called(workflowId, reactionId, position, continueOnError, reactionParam)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowReactionRepository) FindWorkflowReactionsByWorkflowId(workflowId string) ([]entities.WorkflowReaction, error) {
+	args := m.Called(workflowId)
+	return args.Get(0).([]entities.WorkflowReaction), args.Error(1)
+}
+
+func (m *MockWorkflowReactionRepository) DeleteWorkflowReactionsByWorkflowId(workflowId string) error {
+	args := m.Called(workflowId)
+	return args.Error(0)
+}
+
+type MockWorkflowRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkflowRunRepository) CreateWorkflowRun(workflowId, reactionId, status, errorMessage string, httpStatus int, actionPayload map[string]interface{}) error {
+	args := m.Called(workflowId, reactionId, status, errorMessage, httpStatus, actionPayload)
+	return args.Error(0)
+}
+
+func (m *MockWorkflowRunRepository) FindWorkflowRunsByWorkflowId(workflowId, status string, limit, offset int) ([]entities.WorkflowRun, error) {
+	args := m.Called(workflowId, status, limit, offset)
+	return args.Get(0).([]entities.WorkflowRun), args.Error(1)
+}
+
+func (m *MockWorkflowRunRepository) CountWorkflowRunsByWorkflowId(workflowId, status string) (int, error) {
+	args := m.Called(workflowId, status)
+	return args.Int(0), args.Error(1)
+}
+
+type MockServiceWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockServiceWebhookRepository) FindServiceWebhookSecret(serviceName, resource string) (string, error) {
+	args := m.Called(serviceName, resource)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockServiceWebhookRepository) SaveServiceWebhookSecret(serviceName, resource, secret string) error {
+	args := m.Called(serviceName, resource, secret)
+	return args.Error(0)
+}
+
+func (m *MockServiceWebhookRepository) CreateWebhookRejection(serviceName, resource, reason, remoteAddr string) error {
+	args := m.Called(serviceName, resource, reason, remoteAddr)
+	return args.Error(0)
+}
+
 type MockUserRepository struct {
 	mock.Mock
 }
@@ -73,6 +186,11 @@ func (m *MockUserRepository) UpdateUser(email, password, connectionType string)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateUserTimezone(email, connectionType, timezone string) error {
+	args := m.Called(email, connectionType, timezone)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) DeleteUser(email, connectionType string) error {
 	args := m.Called(email, connectionType)
 	return args.Error(0)
@@ -87,11 +205,31 @@ func (m *MockUserServiceRepository) CallApiAndRefresh(email, connectionType, ser
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUserServiceRepository) UpdateTokenForService(code, serviceName, appType, email, connectionType string) error {
-	args := m.Called(code, serviceName, appType, email, connectionType)
+func (m *MockUserServiceRepository) UpdateTokenForService(code, state, session, serviceName, appType, email, connectionType string) error {
+	args := m.Called(code, state, session, serviceName, appType, email, connectionType)
 	return args.Error(0)
 }
 
+func (m *MockUserServiceRepository) RecordServiceCall(userId, serviceName string, callErr error) error {
+	args := m.Called(userId, serviceName, callErr)
+	return args.Error(0)
+}
+
+func (m *MockUserServiceRepository) RetrieveServiceConnections(email, connectionType string) ([]entities.ServiceConnection, error) {
+	args := m.Called(email, connectionType)
+	return args.Get(0).([]entities.ServiceConnection), args.Error(1)
+}
+
+func (m *MockUserServiceRepository) RefreshExpiringTokens(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserServiceRepository) DisconnectService(email, connectionType, serviceName string) (entities.ServiceDisconnection, error) {
+	args := m.Called(email, connectionType, serviceName)
+	return args.Get(0).(entities.ServiceDisconnection), args.Error(1)
+}
+
 func (m *MockUserServiceRepository) RetrieveGithubUserRepositories(email, connectionType string) ([]entities.GithubRepository, error) {
 	args := m.Called(email, connectionType)
 	return args.Get(0).([]entities.GithubRepository), args.Error(1)
@@ -135,9 +273,13 @@ func (m *MockUserServiceRepository) RetrieveUserServiceAuthenticationStatus(emai
 func TestCreateWorkflow(test *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockWorkflowRepo := new(MockWorkflowRepository)
+	mockWorkflowReactionRepo := new(MockWorkflowReactionRepository)
+	mockActionRepo := new(MockActionRepository)
 	service := &WorkflowService{
-		UserRepository:     mockUserRepo,
-		WorkflowRepository: mockWorkflowRepo,
+		UserRepository:             mockUserRepo,
+		WorkflowRepository:         mockWorkflowRepo,
+		WorkflowReactionRepository: mockWorkflowReactionRepo,
+		ActionRepository:           mockActionRepo,
 	}
 
 	test.Run("User not found", func(test *testing.T) {
@@ -148,15 +290,78 @@ func TestCreateWorkflow(test *testing.T) {
 		require.EqualError(test, err, "user not found")
 	})
 
+	test.Run("Missing reaction", func(test *testing.T) {
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+
+		err := service.CreateWorkflow("test@test.com", "basic", entities.NewWorkflow{Name: "Test Workflow"})
+		require.EqualError(test, err, errorMissingReaction)
+	})
+
+	test.Run("Unknown filter field", func(test *testing.T) {
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+		mockActionRepo.On("FindActionById", "1").
+			Return(entities.Action{Variables: []string{"post.title"}}, nil).Once()
+
+		newWorkflow := entities.NewWorkflow{
+			Name:       "Test Workflow",
+			ActionId:   "1",
+			ReactionId: "2",
+			Filter:     `author == "me"`,
+		}
+
+		err := service.CreateWorkflow("test@test.com", "basic", newWorkflow)
+		require.EqualError(test, err, `Unknown filter field "author", available fields are: post.title`)
+		mockWorkflowRepo.AssertNotCalled(test, "CreateWorkflow", "Test Workflow", "1", "1", "2", `author == "me"`, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Invalid catch-up policy", func(test *testing.T) {
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+
+		newWorkflow := entities.NewWorkflow{
+			Name:          "Test Workflow",
+			ActionId:      "1",
+			ReactionId:    "2",
+			CatchUpPolicy: "twice",
+		}
+
+		err := service.CreateWorkflow("test@test.com", "basic", newWorkflow)
+		require.EqualError(test, err, errorInvalidCatchUpPolicy)
+	})
+
+	test.Run("Invalid cron expression", func(test *testing.T) {
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+		mockActionRepo.On("FindActionById", "1").
+			Return(entities.Action{Name: "Cron schedule"}, nil).Once()
+
+		newWorkflow := entities.NewWorkflow{
+			Name:        "Test Workflow",
+			ActionId:    "1",
+			ReactionId:  "2",
+			ActionParam: map[string]interface{}{"expression": "0 0 * *"},
+		}
+
+		err := service.CreateWorkflow("test@test.com", "basic", newWorkflow)
+		require.EqualError(test, err, errorInvalidCronExpression)
+
+		var validationError entities.WorkflowValidationError
+		require.True(test, errors.As(err, &validationError))
+	})
+
 	test.Run("Fail workflow creation", func(test *testing.T) {
 		var user entities.User
 		user.Id = "1"
 
 		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
 			Return(user, nil).Once()
+		mockActionRepo.On("FindActionById", "1").
+			Return(entities.Action{}, nil).Once()
 
-		mockWorkflowRepo.On("CreateWorkflow", "Test Workflow", "1", "1", "2", map[string]interface{}{"key": "value"}, map[string]interface{}{"key": "value"}, map[string]interface{}{"key": "value"}).
-			Return(errors.New("Fail workflow creation")).Once()
+		mockWorkflowRepo.On("CreateWorkflow", "Test Workflow", "1", "1", "2", "", "once", 0, map[string]interface{}{"key": "value"}, map[string]interface{}{"key": "value"}, map[string]interface{}{"key": "value"}).
+			Return("", errors.New("Fail workflow creation")).Once()
 
 		newWorkflow := entities.NewWorkflow{
 			Name:          "Test Workflow",
@@ -177,8 +382,13 @@ func TestCreateWorkflow(test *testing.T) {
 
 		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
 			Return(user, nil).Once()
+		mockActionRepo.On("FindActionById", "1").
+			Return(entities.Action{}, nil).Once()
 
-		mockWorkflowRepo.On("CreateWorkflow", "Test Workflow", "1", "1", "2", map[string]interface{}{"key": "value"}, map[string]interface{}{"key": "value"}, map[string]interface{}{"key": "value"}).
+		mockWorkflowRepo.On("CreateWorkflow", "Test Workflow", "1", "1", "2", "", "once", 0, map[string]interface{}{"key": "value"}, map[string]interface{}{"key": "value"}, map[string]interface{}{"key": "value"}).
+			Return("workflow", nil).Once()
+
+		mockWorkflowReactionRepo.On("CreateWorkflowReaction", "workflow", "2", 0, false, map[string]interface{}{"key": "value"}).
 			Return(nil).Once()
 
 		newWorkflow := entities.NewWorkflow{
@@ -193,14 +403,83 @@ func TestCreateWorkflow(test *testing.T) {
 		err := service.CreateWorkflow("test@test.com", "basic", newWorkflow)
 		require.NoError(test, err)
 	})
+
+	test.Run("Successful incoming webhook", func(test *testing.T) {
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+		mockActionRepo.On("FindActionById", "1").
+			Return(entities.Action{Name: incomingWebhookActionName}, nil).Once()
+
+		// A token chosen by the client is replaced by a generated one
+		mockWorkflowRepo.On("CreateWorkflow", "Test Workflow", "1", "1", "2", "", "once", 0, map[string]interface{}(nil), map[string]interface{}(nil),
+			mock.MatchedBy(func(actionData map[string]interface{}) bool {
+				token, _ := actionData[webhookTokenKey].(string)
+				return len(token) == 2*webhookTokenBytes
+			})).
+			Return("workflow", nil).Once()
+
+		mockWorkflowReactionRepo.On("CreateWorkflowReaction", "workflow", "2", 0, false, map[string]interface{}(nil)).
+			Return(nil).Once()
+
+		newWorkflow := entities.NewWorkflow{
+			Name:       "Test Workflow",
+			ActionId:   "1",
+			ReactionId: "2",
+			ActionData: map[string]interface{}{webhookTokenKey: "guessable"},
+		}
+
+		err := service.CreateWorkflow("test@test.com", "basic", newWorkflow)
+		require.NoError(test, err)
+	})
+
+	test.Run("Successful with several reactions", func(test *testing.T) {
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+		mockActionRepo.On("FindActionById", "1").
+			Return(entities.Action{}, nil).Once()
+
+		mockWorkflowRepo.On("CreateWorkflow", "Test Workflow", "1", "1", "2", "", "once", 0, map[string]interface{}(nil), map[string]interface{}{"message": "first"}, map[string]interface{}(nil)).
+			Return("workflow", nil).Once()
+
+		mockWorkflowReactionRepo.On("CreateWorkflowReaction", "workflow", "2", 0, true, map[string]interface{}{"message": "first"}).
+			Return(nil).Once()
+		mockWorkflowReactionRepo.On("CreateWorkflowReaction", "workflow", "3", 1, false, map[string]interface{}{"message": "second"}).
+			Return(nil).Once()
+
+		newWorkflow := entities.NewWorkflow{
+			Name:     "Test Workflow",
+			ActionId: "1",
+			Reactions: []entities.NewWorkflowReaction{
+				{ReactionId: "2", ReactionParam: map[string]interface{}{"message": "first"}, ContinueOnError: true},
+				{ReactionId: "3", ReactionParam: map[string]interface{}{"message": "second"}},
+			},
+		}
+
+		err := service.CreateWorkflow("test@test.com", "basic", newWorkflow)
+		require.NoError(test, err)
+		mockWorkflowReactionRepo.AssertExpectations(test)
+	})
+}
+
+func TestCheckPollInterval(test *testing.T) {
+	polledAction := entities.Action{MinimumInterval: 300, DefaultInterval: 900}
+
+	require.NoError(test, checkPollInterval(polledAction, 0))
+	require.NoError(test, checkPollInterval(polledAction, 300))
+	require.NoError(test, checkPollInterval(entities.Action{}, 0))
+	require.EqualError(test, checkPollInterval(polledAction, -1), errorInvalidPollInterval)
+	require.EqualError(test, checkPollInterval(polledAction, 299), "Poll interval below the minimum of this action, expected at least 300 seconds")
+	require.EqualError(test, checkPollInterval(entities.Action{}, 300), errorActionNotPolled)
 }
 
 func TestGetUserWorkflows(test *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockWorkflowRepo := new(MockWorkflowRepository)
+	mockWorkflowReactionRepo := new(MockWorkflowReactionRepository)
 	service := &WorkflowService{
-		UserRepository:     mockUserRepo,
-		WorkflowRepository: mockWorkflowRepo,
+		UserRepository:             mockUserRepo,
+		WorkflowRepository:         mockWorkflowRepo,
+		WorkflowReactionRepository: mockWorkflowReactionRepo,
 	}
 
 	test.Run("User not found", func(test *testing.T) {
@@ -228,26 +507,44 @@ func TestGetUserWorkflows(test *testing.T) {
 	test.Run("Successful", func(test *testing.T) {
 		var user entities.User
 		user.Id = "1"
+		workflowReactions := []entities.WorkflowReaction{{Id: "step", WorkflowId: "workflow", ReactionId: "2"}}
 
 		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
 			Return(user, nil).Once()
 
 		mockWorkflowRepo.On("FindWorkflowsByOwnerId", "1").
-			Return([]entities.Workflow{}, nil).Once()
+			Return([]entities.Workflow{{Id: "workflow"}}, nil).Once()
 
-		_, err := service.GetUserWorkflows("test@test.com", "basic")
+		mockWorkflowReactionRepo.On("FindWorkflowReactionsByWorkflowId", "workflow").
+			Return(workflowReactions, nil).Once()
+
+		workflows, err := service.GetUserWorkflows("test@test.com", "basic")
 		require.NoError(test, err)
+		require.Equal(test, workflowReactions, workflows[0].Reactions)
 	})
 }
 
 func TestUpdateWorkflow(test *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockWorkflowRepo := new(MockWorkflowRepository)
+	mockWorkflowReactionRepo := new(MockWorkflowReactionRepository)
+	mockActionRepo := new(MockActionRepository)
 	service := &WorkflowService{
-		UserRepository:     mockUserRepo,
-		WorkflowRepository: mockWorkflowRepo,
+		UserRepository:             mockUserRepo,
+		WorkflowRepository:         mockWorkflowRepo,
+		WorkflowReactionRepository: mockWorkflowReactionRepo,
+		ActionRepository:           mockActionRepo,
 	}
 
+	mockActionRepo.On("FindActionById", "5").
+		Return(entities.Action{Id: "5", Name: "New push", MinimumInterval: 300, DefaultInterval: 900}, nil)
+	mockActionRepo.On("FindActionById", "6").
+		Return(entities.Action{Id: "6", Name: "At date and time"}, nil)
+	mockUserRepo.On("FindUserById", "owner").
+		Return(entities.User{Id: "owner", Timezone: "America/New_York"}, nil)
+	mockUserRepo.On("FindUserById", "").
+		Return(entities.User{}, errors.New("User not found"))
+
 	test.Run("User not found", func(test *testing.T) {
 		var updateWorkflow entities.UpdatedWorkflow
 
@@ -271,6 +568,62 @@ func TestUpdateWorkflow(test *testing.T) {
 		require.EqualError(test, err, "Fail update workflow")
 	})
 
+	test.Run("Catch-up policy", func(test *testing.T) {
+		catchUpPolicy := entities.CatchUpPolicySkip
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{CatchUpPolicy: entities.CatchUpPolicyOnce}, nil).Once()
+
+		mockWorkflowRepo.On("UpdateWorkflow", "1", entities.Workflow{CatchUpPolicy: entities.CatchUpPolicySkip}).
+			Return(nil).Once()
+
+		err := service.UpdateWorkflow("1", entities.UpdatedWorkflow{CatchUpPolicy: &catchUpPolicy})
+		require.NoError(test, err)
+	})
+
+	test.Run("Invalid catch-up policy", func(test *testing.T) {
+		catchUpPolicy := "twice"
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{}, nil).Once()
+
+		err := service.UpdateWorkflow("1", entities.UpdatedWorkflow{CatchUpPolicy: &catchUpPolicy})
+		require.EqualError(test, err, errorInvalidCatchUpPolicy)
+	})
+
+	test.Run("Poll interval", func(test *testing.T) {
+		pollInterval := 600
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{ActionId: "5"}, nil).Once()
+
+		mockWorkflowRepo.On("UpdateWorkflow", "1", entities.Workflow{ActionId: "5", PollInterval: 600}).
+			Return(nil).Once()
+
+		err := service.UpdateWorkflow("1", entities.UpdatedWorkflow{PollInterval: &pollInterval})
+		require.NoError(test, err)
+	})
+
+	test.Run("Date in the past", func(test *testing.T) {
+		actionParam := map[string]interface{}{"date": "2024-03-01 09:30"}
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{ActionId: "6", OwnerId: "owner"}, nil).Once()
+
+		err := service.UpdateWorkflow("1", entities.UpdatedWorkflow{ActionParam: &actionParam})
+		require.EqualError(test, err, errorDateAndTimeInPast)
+	})
+
+	test.Run("Poll interval below minimum", func(test *testing.T) {
+		pollInterval := 60
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{ActionId: "5"}, nil).Once()
+
+		err := service.UpdateWorkflow("1", entities.UpdatedWorkflow{PollInterval: &pollInterval})
+		require.EqualError(test, err, "Poll interval below the minimum of this action, expected at least 300 seconds")
+	})
+
 	test.Run("Successful", func(test *testing.T) {
 		var updateWorkflow entities.UpdatedWorkflow
 
@@ -282,6 +635,43 @@ func TestUpdateWorkflow(test *testing.T) {
 
 		err := service.UpdateWorkflow("1", updateWorkflow)
 		require.NoError(test, err)
+		mockWorkflowReactionRepo.AssertNotCalled(test, "DeleteWorkflowReactionsByWorkflowId", "1")
+	})
+
+	test.Run("Empty reactions", func(test *testing.T) {
+		updateWorkflow := entities.UpdatedWorkflow{Reactions: &[]entities.NewWorkflowReaction{}}
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{}, nil).Once()
+
+		err := service.UpdateWorkflow("1", updateWorkflow)
+		require.EqualError(test, err, errorMissingReaction)
+	})
+
+	test.Run("Successful with reactions", func(test *testing.T) {
+		updateWorkflow := entities.UpdatedWorkflow{
+			Reactions: &[]entities.NewWorkflowReaction{
+				{ReactionId: "3", ReactionParam: map[string]interface{}{"message": "first"}},
+				{ReactionId: "4", ContinueOnError: true},
+			},
+		}
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{ReactionId: "2"}, nil).Once()
+
+		mockWorkflowRepo.On("UpdateWorkflow", "1", entities.Workflow{ReactionId: "3", ReactionParam: map[string]interface{}{"message": "first"}}).
+			Return(nil).Once()
+
+		mockWorkflowReactionRepo.On("DeleteWorkflowReactionsByWorkflowId", "1").
+			Return(nil).Once()
+		mockWorkflowReactionRepo.On("CreateWorkflowReaction", "1", "3", 0, false, map[string]interface{}{"message": "first"}).
+			Return(nil).Once()
+		mockWorkflowReactionRepo.On("CreateWorkflowReaction", "1", "4", 1, true, map[string]interface{}(nil)).
+			Return(nil).Once()
+
+		err := service.UpdateWorkflow("1", updateWorkflow)
+		require.NoError(test, err)
+		mockWorkflowReactionRepo.AssertExpectations(test)
 	})
 }
 
@@ -330,6 +720,111 @@ func TestDeleteWorkflow(test *testing.T) {
 	})
 }
 
+func TestGetWorkflowRuns(test *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockWorkflowRepo := new(MockWorkflowRepository)
+	mockWorkflowRunRepo := new(MockWorkflowRunRepository)
+	service := &WorkflowService{
+		UserRepository:        mockUserRepo,
+		WorkflowRepository:    mockWorkflowRepo,
+		WorkflowRunRepository: mockWorkflowRunRepo,
+	}
+
+	test.Run("Workflow not owned", func(test *testing.T) {
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{Id: "1", OwnerId: "2"}, nil).Once()
+
+		_, err := service.GetWorkflowRuns("test@test.com", "basic", "1", "", 1, 20)
+		require.ErrorIs(test, err, entities.WorkflowNotFoundError{})
+	})
+
+	test.Run("Unknown workflow", func(test *testing.T) {
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+
+		mockWorkflowRepo.On("FindWorkflowById", "unknown").
+			Return(entities.Workflow{}, entities.WorkflowNotFoundError{}).Once()
+
+		_, err := service.GetWorkflowRuns("test@test.com", "basic", "unknown", "", 1, 20)
+		require.ErrorIs(test, err, entities.WorkflowNotFoundError{})
+	})
+
+	test.Run("Successful", func(test *testing.T) {
+		workflowRuns := []entities.WorkflowRun{{Id: "run", Status: entities.WorkflowRunFailure}}
+
+		mockUserRepo.On("FindUserByEmail", "test@test.com", "basic").
+			Return(entities.User{Id: "1"}, nil).Once()
+
+		mockWorkflowRepo.On("FindWorkflowById", "1").
+			Return(entities.Workflow{Id: "1", OwnerId: "1"}, nil).Once()
+
+		mockWorkflowRunRepo.On("CountWorkflowRunsByWorkflowId", "1", "failure").
+			Return(21, nil).Once()
+
+		mockWorkflowRunRepo.On("FindWorkflowRunsByWorkflowId", "1", "failure", 20, 20).
+			Return(workflowRuns, nil).Once()
+
+		workflowRunsPage, err := service.GetWorkflowRuns("test@test.com", "basic", "1", "failure", 2, 20)
+		require.NoError(test, err)
+		require.Equal(test, 21, workflowRunsPage.Total)
+		require.Equal(test, workflowRuns, workflowRunsPage.Runs)
+	})
+}
+
+func TestRecordWorkflowRun(test *testing.T) {
+	mockWorkflowRunRepo := new(MockWorkflowRunRepository)
+	mockWorkflowRepo := new(MockWorkflowRepository)
+	service := &WorkflowService{
+		WorkflowRunRepository: mockWorkflowRunRepo,
+		WorkflowRepository:    mockWorkflowRepo,
+	}
+	workflow := entities.Workflow{Id: "workflow", ReactionId: "reaction"}
+	event := entities.ActionEvent{"post": "title"}
+
+	test.Run("Failed API call", func(test *testing.T) {
+		result := entities.ReactionResult{
+			ReactionId:   "reaction",
+			Status:       entities.WorkflowRunFailure,
+			HttpStatus:   401,
+			ErrorMessage: "API call failed",
+		}
+
+		mockWorkflowRunRepo.On("CreateWorkflowRun", "workflow", "reaction", entities.WorkflowRunFailure, "API call failed", 401, map[string]interface{}(event)).
+			Return(nil).Once()
+		mockWorkflowRepo.On("IncrementWorkflowFailures", "workflow").
+			Return(1, nil).Once()
+
+		err := service.recordWorkflowRun(workflow, event, result, false)
+		require.NoError(test, err)
+		mockWorkflowRepo.AssertExpectations(test)
+	})
+
+	test.Run("Run Already Failed", func(test *testing.T) {
+		result := entities.ReactionResult{ReactionId: "reaction", Status: entities.WorkflowRunFailure, ErrorMessage: "API call failed"}
+
+		mockWorkflowRunRepo.On("CreateWorkflowRun", "workflow", "reaction", entities.WorkflowRunFailure, "API call failed", 0, map[string]interface{}(event)).
+			Return(nil).Once()
+
+		err := service.recordWorkflowRun(workflow, event, result, true)
+		require.NoError(test, err)
+		mockWorkflowRepo.AssertNumberOfCalls(test, "IncrementWorkflowFailures", 1)
+	})
+
+	test.Run("Successful run", func(test *testing.T) {
+		result := entities.ReactionResult{ReactionId: "reaction", Status: entities.WorkflowRunSuccess}
+
+		mockWorkflowRunRepo.On("CreateWorkflowRun", "workflow", "reaction", entities.WorkflowRunSuccess, "", 0, map[string]interface{}(event)).
+			Return(nil).Once()
+
+		err := service.recordWorkflowRun(workflow, event, result, false)
+		require.NoError(test, err)
+		mockWorkflowRepo.AssertNumberOfCalls(test, "IncrementWorkflowFailures", 1)
+	})
+}
+
 func TestGetAccessToken(test *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	MockUserServiceRepo := new(MockUserServiceRepository)
@@ -429,3 +924,209 @@ func TestGetWorkflowStringActionParam(test *testing.T) {
 		require.EqualError(test, err, "Missing required field")
 	})
 }
+
+func TestCheckWebhookWorkflow(test *testing.T) {
+	webhookEvent := entities.WebhookEvent{
+		ActionName: "New star",
+		ParamKey:   "repository",
+		ParamValue: "repo",
+	}
+
+	test.Run("Fail Get Action Param", func(test *testing.T) {
+		service := &WorkflowService{}
+
+		err := service.checkWebhookWorkflow(entities.Workflow{}, webhookEvent)
+
+		require.EqualError(test, err, errorMissingField)
+	})
+
+	test.Run("Other Repository", func(test *testing.T) {
+		service := &WorkflowService{}
+
+		workflow := entities.Workflow{
+			ActionParam: map[string]interface{}{
+				"repository": "other",
+			},
+		}
+
+		err := service.checkWebhookWorkflow(workflow, webhookEvent)
+
+		require.NoError(test, err)
+	})
+}
+
+func TestCheckWebhooksWorkflows(test *testing.T) {
+	registry := connector.NewRegistry()
+	githubConnector, _ := registry.FindConnector("Github")
+	spotifyConnector, _ := registry.FindConnector("Spotify")
+
+	newWebhookRequest := func(event, body string) *http.Request {
+		request, _ := http.NewRequest("POST", "/webhooks/Github", io.NopCloser(strings.NewReader(body)))
+		request.Header.Set("X-GitHub-Event", event)
+		request.Header.Set("X-Hub-Signature-256", signWebhookBody("secret", body))
+		request.RemoteAddr = "203.0.113.7:443"
+		return request
+	}
+
+	test.Run("Unsupported Service", func(test *testing.T) {
+		mockServiceService := new(MockServiceServiceRepository)
+
+		service := &WorkflowService{
+			ServiceService: mockServiceService,
+		}
+
+		mockServiceService.On("FindServiceByName", "Spotify").
+			Return(entities.Service{Id: "1", Name: "Spotify"}, nil)
+		mockServiceService.On("FindConnector", "Spotify").
+			Return(spotifyConnector, nil)
+
+		err := service.CheckWebhooksWorkflows("Spotify", newWebhookRequest("watch", "{}"))
+
+		require.Error(test, err)
+	})
+
+	test.Run("Ping", func(test *testing.T) {
+		mockServiceService := new(MockServiceServiceRepository)
+		mockActionRepo := new(MockActionRepository)
+
+		service := &WorkflowService{
+			ServiceService:   mockServiceService,
+			ActionRepository: mockActionRepo,
+		}
+
+		mockServiceService.On("FindServiceByName", "Github").
+			Return(entities.Service{Id: "1", Name: "Github"}, nil)
+		mockServiceService.On("FindConnector", "Github").
+			Return(githubConnector, nil)
+
+		err := service.CheckWebhooksWorkflows("Github", newWebhookRequest("ping", `{"repository": {"full_name": "owner/repo"}}`))
+
+		require.NoError(test, err)
+		mockActionRepo.AssertNotCalled(test, "FindActionByNameAndServiceId", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Invalid Signature", func(test *testing.T) {
+		mockServiceService := new(MockServiceServiceRepository)
+		mockActionRepo := new(MockActionRepository)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+
+		service := &WorkflowService{
+			ServiceService:           mockServiceService,
+			ActionRepository:         mockActionRepo,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+		}
+
+		mockServiceService.On("FindServiceByName", "Github").
+			Return(entities.Service{Id: "1", Name: "Github"}, nil)
+		mockServiceService.On("FindConnector", "Github").
+			Return(githubConnector, nil)
+		mockServiceWebhookRepo.On("FindServiceWebhookSecret", "Github", "owner/repo").
+			Return("other", nil)
+		mockServiceWebhookRepo.On("CreateWebhookRejection", "Github", "owner/repo", errorInvalidWebhookSignature, "203.0.113.7:443").
+			Return(nil).Once()
+
+		err := service.CheckWebhooksWorkflows("Github", newWebhookRequest("watch", `{"repository": {"full_name": "owner/repo"}}`))
+
+		require.EqualError(test, err, errorInvalidWebhookSignature)
+		mockServiceWebhookRepo.AssertExpectations(test)
+		mockActionRepo.AssertNotCalled(test, "FindActionByNameAndServiceId", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Unregistered Repository", func(test *testing.T) {
+		mockServiceService := new(MockServiceServiceRepository)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+
+		service := &WorkflowService{
+			ServiceService:           mockServiceService,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+		}
+
+		mockServiceService.On("FindServiceByName", "Github").
+			Return(entities.Service{Id: "1", Name: "Github"}, nil)
+		mockServiceService.On("FindConnector", "Github").
+			Return(githubConnector, nil)
+		mockServiceWebhookRepo.On("FindServiceWebhookSecret", "Github", "owner/repo").
+			Return("", errors.New("no rows"))
+		mockServiceWebhookRepo.On("CreateWebhookRejection", "Github", "owner/repo", errorMissingWebhookSecret, "203.0.113.7:443").
+			Return(nil).Once()
+
+		err := service.CheckWebhooksWorkflows("Github", newWebhookRequest("watch", `{"repository": {"full_name": "owner/repo"}}`))
+
+		require.EqualError(test, err, errorInvalidWebhookSignature)
+		mockServiceWebhookRepo.AssertExpectations(test)
+	})
+
+	test.Run("Success", func(test *testing.T) {
+		mockServiceService := new(MockServiceServiceRepository)
+		mockActionRepo := new(MockActionRepository)
+		mockWorkflowRepo := new(MockWorkflowRepository)
+		mockServiceWebhookRepo := new(MockServiceWebhookRepository)
+
+		service := &WorkflowService{
+			ServiceService:           mockServiceService,
+			ActionRepository:         mockActionRepo,
+			WorkflowRepository:       mockWorkflowRepo,
+			ServiceWebhookRepository: mockServiceWebhookRepo,
+		}
+
+		mockServiceService.On("FindServiceByName", "Github").
+			Return(entities.Service{Id: "1", Name: "Github"}, nil)
+		mockServiceService.On("FindConnector", "Github").
+			Return(githubConnector, nil)
+		mockServiceWebhookRepo.On("FindServiceWebhookSecret", "Github", "owner/repo").
+			Return("secret", nil)
+		mockActionRepo.On("FindActionByNameAndServiceId", "New star", "1").
+			Return(entities.Action{Id: "2"}, nil)
+		mockWorkflowRepo.On("FindWorkflowsByActionId", "2").
+			Return([]entities.Workflow{{IsActivated: false}}, nil)
+
+		err := service.CheckWebhooksWorkflows("Github", newWebhookRequest("watch", `{"repository": {"full_name": "owner/repo"}}`))
+
+		require.NoError(test, err)
+		mockServiceWebhookRepo.AssertNotCalled(test, "CreateWebhookRejection", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCheckReactions(test *testing.T) {
+	workflow := entities.Workflow{Id: "workflow", ReactionId: "1"}
+	event := entities.ActionEvent{"post": map[string]interface{}{"title": "title"}}
+
+	test.Run("Single Reaction Workflow", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService([]entities.WorkflowReaction{})
+
+		mockJobRepo.On("EnqueueJob", mock.MatchedBy(func(job entities.ReactionJob) bool {
+			return job.WorkflowId == "workflow" && job.ServiceName == "Discord" && len(job.Steps) == 1 && job.Steps[0].ReactionId == "1"
+		})).Return(nil).Once()
+
+		service.checkReactions(workflow, event)
+
+		mockJobRepo.AssertExpectations(test)
+		mockWorkflowRunRepo.AssertNotCalled(test, "CreateWorkflowRun", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Steps Enqueued Together", func(test *testing.T) {
+		service, mockJobRepo, _ := newReactionJobService([]entities.WorkflowReaction{
+			{Position: 0, ReactionId: "1"},
+			{Position: 1, ReactionId: "2"},
+		})
+
+		mockJobRepo.On("EnqueueJob", mock.MatchedBy(func(job entities.ReactionJob) bool {
+			return job.ServiceName == "Discord" && len(job.Steps) == 2 && job.Event["post"] != nil
+		})).Return(nil).Once()
+
+		service.checkReactions(workflow, event)
+
+		mockJobRepo.AssertNumberOfCalls(test, "EnqueueJob", 1)
+	})
+
+	test.Run("Enqueue Error", func(test *testing.T) {
+		service, mockJobRepo, mockWorkflowRunRepo := newReactionJobService([]entities.WorkflowReaction{})
+
+		mockJobRepo.On("EnqueueJob", mock.Anything).
+			Return(errors.New("Fail enqueue")).Once()
+
+		service.checkReactions(workflow, event)
+
+		mockWorkflowRunRepo.AssertCalled(test, "CreateWorkflowRun", "workflow", "1", entities.WorkflowRunFailure, errorEnqueuingJob, 0, mock.Anything)
+	})
+}
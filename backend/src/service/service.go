@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"io"
 	"net/http"
 
@@ -10,9 +11,10 @@ import (
 type UserService interface {
 	CreateUser(userEmail, userPassword, userConnectionType string) error
 	LoginAuthentication(userEmail, userPassword, userConnectionType string) (string, error)
-	LoginWithService(code, serviceName, appType string) (string, error)
+	LoginWithService(code, state, session, serviceName, appType string) (string, error)
 	GetUser(userEmail, userConnectionType string) (entities.UserInfos, error)
 	ModifyPassword(userEmail, userConnectionType string, newPassword entities.UserModifyPassword) error
+	ModifyTimezone(userEmail, userConnectionType, timezone string) error
 	DeleteAccount(userEmail, userConnectionType string) error
 	FindUserById(userId string) (entities.User, error)
 }
@@ -27,20 +29,18 @@ type ServiceService interface {
 	FindAllServices() ([]entities.Service, error)
 	RetrieveActionsFromService(serviceName string) ([]entities.Action, error)
 	RetrieveReactionsFromService(serviceName string) ([]entities.Reaction, error)
-	GetGoogleRefreshTokenRequest(refreshToken string) (*http.Request, error)
-	GetSpotifyRefreshTokenRequest(refreshToken string) (*http.Request, error)
-	GetDiscordRefreshTokenRequest(refreshToken string) (*http.Request, error)
-	GetRedditRefreshTokenRequest(refreshToken string) (*http.Request, error)
-	GetAsanaRefreshTokenRequest(refreshToken string) (*http.Request, error)
-	GetDropboxRefreshTokenRequest(refreshToken string) (*http.Request, error)
-	GetGitlabRefreshTokenRequest(refreshToken string) (*http.Request, error)
+	FindConnector(serviceName string) (Connector, error)
+	RetrieveConnectors() []Connector
+	SeedServices() error
 	ExecuteRequest(request *http.Request) (*http.Response, error)
+	ExecuteRequestWithClient(client *http.Client, request *http.Request) (*http.Response, error)
 	ExecuteApiRequest(url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error)
-	GetResultTokenFromCode(code, serviceName, callbackType, appType string) (entities.ResultToken, error)
+	ExecuteApiRequestWithContext(ctx context.Context, url, method, typeToken, accessToken string, body io.Reader) (*http.Response, error)
+	GetResultTokenFromCode(code, serviceName, callbackType, appType, codeVerifier string) (entities.ResultToken, error)
 	GetUserInfoFromService(accessToken, serviceName string) (entities.UserInfo, error)
-	RequestToTimeApi() (entities.TimeResponse, error)
-	OAuth2Service(serviceName, callbackType, appType string) (string, error)
-	RequestGithubUserRepositories(accessToken string) ([]entities.GithubRepository, error)
+	OAuth2Service(serviceName, callbackType, appType, session string) (string, error)
+	ConsumeOAuthState(state, session, serviceName, callbackType, appType string) (string, error)
+	RequestGithubUserRepositories(ctx context.Context, accessToken string) ([]entities.GithubRepository, error)
 	RequestGitlabUserProjects(accessToken string) ([]entities.GitlabProject, error)
 	RetrieveDiscordGuildChannels(guildId string) ([]map[string]interface{}, error)
 }
@@ -48,7 +48,11 @@ type ServiceService interface {
 type UserServiceService interface {
 	RetrieveUserServiceAuthenticationStatus(email, connectionType, serviceName string) (bool, error)
 	CallApiAndRefresh(email, connectionType, serviceName string) (string, error)
-	UpdateTokenForService(code, serviceName, appType, email, connectionType string) error
+	UpdateTokenForService(code, state, session, serviceName, appType, email, connectionType string) error
+	DisconnectService(email, connectionType, serviceName string) (entities.ServiceDisconnection, error)
+	RefreshExpiringTokens(ctx context.Context) (int, error)
+	RecordServiceCall(userId, serviceName string, callErr error) error
+	RetrieveServiceConnections(email, connectionType string) ([]entities.ServiceConnection, error)
 	RetrieveGithubUserRepositories(email, connectionType string) ([]entities.GithubRepository, error)
 	RetrieveGitlabUserProjects(email, connectionType string) ([]entities.GitlabProject, error)
 	RetrieveDiscordUserServers(email, connectionType string) ([]map[string]interface{}, error)
@@ -63,23 +67,48 @@ type WorkflowService interface {
 	GetUserWorkflows(email, connectionType string) ([]entities.Workflow, error)
 	UpdateWorkflow(workflowId string, workflow entities.UpdatedWorkflow) error
 	DeleteWorkflow(email, connectionType, workflowId string) error
-	CheckTimeAndDateActions() error
-	CheckGithubActions() error
-	CheckRedditActions() error
-	CheckWeatherActions() error
-	CheckNewGitlabWorkflows() error
-	CheckNewGithubWorkflows() error
+	GetWorkflowRuns(email, connectionType, workflowId, status string, page, pageSize int) (entities.WorkflowRunsPage, error)
+	CheckTimeAndDateActions(ctx context.Context) error
+	CheckGithubActions(ctx context.Context) error
+	CheckRedditActions(ctx context.Context) error
+	CheckWeatherActions(ctx context.Context) error
+	CheckNewGitlabWorkflows(ctx context.Context) error
+	CheckNewGithubWorkflows(ctx context.Context) error
 	CheckWebhooksWorkflows(serviceName string, request *http.Request) error
+	CheckIncomingWebhook(token string, request *http.Request) error
+	ReplayWebhookDelivery(email, connectionType, deliveryId string) error
+	StartJobWorkers(ctx context.Context)
+	WaitJobWorkers(ctx context.Context) error
+	IsSchedulerLeader(ctx context.Context) bool
+	ReleaseSchedulerLock() error
+	GetSchedulerState() entities.SchedulerState
+	GetWorkflowDeadLetters(email, connectionType, workflowId string) ([]entities.ReactionJob, error)
+	RetriggerWorkflowDeadLetters(email, connectionType, workflowId string) (int, error)
+	CheckSpotifyReactions(workflow entities.Workflow, reaction entities.Reaction) error
+	CheckDiscordReactions(workflow entities.Workflow, reaction entities.Reaction) error
+	CheckLinkedinReactions(workflow entities.Workflow, reaction entities.Reaction) error
+	CheckAsanaReactions(workflow entities.Workflow, reaction entities.Reaction) error
+	CheckSMSReactions(workflow entities.Workflow, reaction entities.Reaction) error
+	CheckSendEmailReactions(workflow entities.Workflow, reaction entities.Reaction) error
+	CheckDropboxReactions(workflow entities.Workflow, reaction entities.Reaction) error
+	CheckRedditReactions(workflow entities.Workflow, reaction entities.Reaction) error
+	CheckHttpRequestReactions(workflow entities.Workflow, reaction entities.Reaction) error
 }
 
 type AboutService interface {
 	GetAboutServer(about entities.About) (entities.About, error)
 }
 
+type HealthService interface {
+	GetHealth(ctx context.Context) entities.Health
+	MarkShuttingDown()
+}
+
 type Service struct {
 	UserService        UserService
 	ServiceService     ServiceService
 	UserServiceService UserServiceService
 	WorkflowService    WorkflowService
 	AboutService       AboutService
+	HealthService      HealthService
 }
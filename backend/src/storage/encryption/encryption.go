@@ -0,0 +1,170 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const keysEnv = "TOKEN_ENCRYPTION_KEYS"
+const currentKeyIdEnv = "TOKEN_ENCRYPTION_KEY_ID"
+
+const keySize = 32
+
+const errorMissingKeys = "Missing " + keysEnv
+const errorInvalidKey = "Invalid encryption key %s"
+const errorUnknownKeyId = "Unknown encryption key %s"
+const errorInvalidCiphertext = "Invalid ciphertext"
+
+// Master keys indexed by their id, new data keys are wrapped with the current one
+type Keyring struct {
+	currentKeyId string
+	keys         map[string][]byte
+}
+
+// Secret encrypted with its own data key, the data key is wrapped with the master key KeyId
+type Envelope struct {
+	KeyId   string
+	DataKey string
+}
+
+// Reads the comma separated "id:base64 key" pairs of TOKEN_ENCRYPTION_KEYS, TOKEN_ENCRYPTION_KEY_ID names the current key
+// and defaults to the first one
+func NewKeyringFromEnv() (*Keyring, error) {
+	pairs := strings.Split(os.Getenv(keysEnv), ",")
+	keyring := &Keyring{keys: map[string][]byte{}}
+
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		keyId, encodedKey, found := strings.Cut(pair, ":")
+		if !found || keyId == "" {
+			return nil, fmt.Errorf(errorInvalidKey, pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf(errorInvalidKey, keyId)
+		}
+		keyring.keys[keyId] = key
+		if keyring.currentKeyId == "" {
+			keyring.currentKeyId = keyId
+		}
+	}
+	if len(keyring.keys) == 0 {
+		return nil, fmt.Errorf(errorMissingKeys)
+	}
+
+	currentKeyId := os.Getenv(currentKeyIdEnv)
+	if currentKeyId != "" {
+		if _, found := keyring.keys[currentKeyId]; !found {
+			return nil, fmt.Errorf(errorUnknownKeyId, currentKeyId)
+		}
+		keyring.currentKeyId = currentKeyId
+	}
+	return keyring, nil
+}
+
+func NewKeyring(currentKeyId string, keys map[string][]byte) *Keyring {
+	return &Keyring{currentKeyId: currentKeyId, keys: keys}
+}
+
+func (self *Keyring) CurrentKeyId() string {
+	return self.currentKeyId
+}
+
+// Generates a data key wrapped with the current master key, the secrets of one row share its data key
+// The additional data identifies where the envelope is stored, it must be given again to open it
+func (self *Keyring) NewEnvelope(additionalData string) (Envelope, []byte, error) {
+	dataKey := make([]byte, keySize)
+	_, err := rand.Read(dataKey)
+	if err != nil {
+		return Envelope{}, nil, err
+	}
+
+	wrappedKey, err := seal(self.keys[self.currentKeyId], dataKey, []byte(additionalData))
+	if err != nil {
+		return Envelope{}, nil, err
+	}
+	return Envelope{KeyId: self.currentKeyId, DataKey: wrappedKey}, dataKey, nil
+}
+
+func (self *Keyring) OpenEnvelope(envelope Envelope, additionalData string) ([]byte, error) {
+	key, found := self.keys[envelope.KeyId]
+	if !found {
+		return nil, fmt.Errorf(errorUnknownKeyId, envelope.KeyId)
+	}
+	return open(key, envelope.DataKey, []byte(additionalData))
+}
+
+// Wraps the data key of the envelope with the current master key, the secrets it encrypts are left untouched
+func (self *Keyring) RewrapEnvelope(envelope Envelope, additionalData string) (Envelope, error) {
+	dataKey, err := self.OpenEnvelope(envelope, additionalData)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	wrappedKey, err := seal(self.keys[self.currentKeyId], dataKey, []byte(additionalData))
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{KeyId: self.currentKeyId, DataKey: wrappedKey}, nil
+}
+
+func Encrypt(dataKey []byte, plaintext, additionalData string) (string, error) {
+	return seal(dataKey, []byte(plaintext), []byte(additionalData))
+}
+
+func Decrypt(dataKey []byte, ciphertext, additionalData string) (string, error) {
+	plaintext, err := open(dataKey, ciphertext, []byte(additionalData))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// AES-GCM with a random nonce prepended to the ciphertext, encoded in base64
+// The additional data is authenticated but not stored, opening with another one fails
+func seal(key, plaintext, additionalData []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, additionalData)), nil
+}
+
+func open(key []byte, ciphertext string, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf(errorInvalidCiphertext)
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf(errorInvalidCiphertext)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
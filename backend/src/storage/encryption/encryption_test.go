@@ -0,0 +1,114 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodedKey(fill byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, keySize))
+}
+
+func TestNewKeyringFromEnv(test *testing.T) {
+	test.Run("Missing Keys", func(test *testing.T) {
+		test.Setenv(keysEnv, "")
+
+		_, err := NewKeyringFromEnv()
+
+		require.EqualError(test, err, errorMissingKeys)
+	})
+
+	test.Run("Invalid Key", func(test *testing.T) {
+		test.Setenv(keysEnv, "old:"+base64.StdEncoding.EncodeToString([]byte("short")))
+
+		_, err := NewKeyringFromEnv()
+
+		require.EqualError(test, err, fmt.Sprintf(errorInvalidKey, "old"))
+	})
+
+	test.Run("Unknown Current Key", func(test *testing.T) {
+		test.Setenv(keysEnv, "old:"+encodedKey(1))
+		test.Setenv(currentKeyIdEnv, "new")
+
+		_, err := NewKeyringFromEnv()
+
+		require.EqualError(test, err, fmt.Sprintf(errorUnknownKeyId, "new"))
+	})
+
+	test.Run("Current Key", func(test *testing.T) {
+		test.Setenv(keysEnv, "old:"+encodedKey(1)+", new:"+encodedKey(2))
+
+		keyring, err := NewKeyringFromEnv()
+		require.NoError(test, err)
+		require.Equal(test, "old", keyring.CurrentKeyId())
+
+		test.Setenv(currentKeyIdEnv, "new")
+
+		keyring, err = NewKeyringFromEnv()
+		require.NoError(test, err)
+		require.Equal(test, "new", keyring.CurrentKeyId())
+	})
+}
+
+func TestEnvelope(test *testing.T) {
+	keys := map[string][]byte{
+		"old": bytes.Repeat([]byte{1}, keySize),
+		"new": bytes.Repeat([]byte{2}, keySize),
+	}
+	oldKeyring := NewKeyring("old", keys)
+
+	envelope, dataKey, err := oldKeyring.NewEnvelope("row/datakey")
+	require.NoError(test, err)
+	require.Equal(test, "old", envelope.KeyId)
+
+	ciphertext, err := Encrypt(dataKey, "token", "row/token")
+	require.NoError(test, err)
+	require.NotContains(test, ciphertext, "token")
+
+	test.Run("Open", func(test *testing.T) {
+		openedKey, err := oldKeyring.OpenEnvelope(envelope, "row/datakey")
+		require.NoError(test, err)
+
+		plaintext, err := Decrypt(openedKey, ciphertext, "row/token")
+		require.NoError(test, err)
+		require.Equal(test, "token", plaintext)
+	})
+
+	test.Run("Rewrap", func(test *testing.T) {
+		newKeyring := NewKeyring("new", keys)
+
+		rewrapped, err := newKeyring.RewrapEnvelope(envelope, "row/datakey")
+		require.NoError(test, err)
+		require.Equal(test, "new", rewrapped.KeyId)
+
+		_, err = NewKeyring("new", map[string][]byte{"new": keys["new"]}).OpenEnvelope(envelope, "row/datakey")
+		require.EqualError(test, err, fmt.Sprintf(errorUnknownKeyId, "old"))
+
+		openedKey, err := newKeyring.OpenEnvelope(rewrapped, "row/datakey")
+		require.NoError(test, err)
+
+		plaintext, err := Decrypt(openedKey, ciphertext, "row/token")
+		require.NoError(test, err)
+		require.Equal(test, "token", plaintext)
+	})
+
+	test.Run("Tampered Ciphertext", func(test *testing.T) {
+		sealed, _ := base64.StdEncoding.DecodeString(ciphertext)
+		sealed[len(sealed)-1] ^= 1
+
+		_, err := Decrypt(dataKey, base64.StdEncoding.EncodeToString(sealed), "row/token")
+		require.EqualError(test, err, errorInvalidCiphertext)
+	})
+
+	test.Run("Other Additional Data", func(test *testing.T) {
+		_, err := oldKeyring.OpenEnvelope(envelope, "other/datakey")
+		require.EqualError(test, err, errorInvalidCiphertext)
+
+		_, err = Decrypt(dataKey, ciphertext, "row/tokenrefresh")
+		require.EqualError(test, err, errorInvalidCiphertext)
+	})
+}
@@ -16,25 +16,62 @@ func NewActionRepository(db *sql.DB) *ActionRepository {
 	return &ActionRepository{db: db}
 }
 
-func unmarshalParameters(parametersBytes []byte, action entities.Action) (entities.Action, error) {
+func unmarshalParameters(parametersBytes, variablesBytes []byte, action entities.Action) (entities.Action, error) {
 	if len(parametersBytes) > 0 {
 		err := json.Unmarshal(parametersBytes, &action.Parameters)
 		if err != nil {
 			return action, err
 		}
 	}
+	if len(variablesBytes) > 0 {
+		err := json.Unmarshal(variablesBytes, &action.Variables)
+		if err != nil {
+			return action, err
+		}
+	}
 	return action, nil
 }
 
-func (self *ActionRepository) CreateAction(name, description, serviceId string, nbParam int) error {
-	sqlStatement := `INSERT INTO actions (name, description, serviceid, nbparam) VALUES ($1, $2, $3, $4)`
+func (self *ActionRepository) CreateAction(action entities.Action) error {
+	sqlStatement := `INSERT INTO actions (name, description, serviceid, nbparam, parameters, variables, minimuminterval, defaultinterval) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
-	_, err := self.FindActionByName(name)
+	_, err := self.FindActionByNameAndServiceId(action.Name, action.ServiceId)
 	if err == nil {
 		return fmt.Errorf("Action already exist")
 	}
 
-	_, err = self.db.Exec(sqlStatement, name, description, serviceId, nbParam)
+	parametersBytes, err := json.Marshal(action.Parameters)
+	if err != nil {
+		return err
+	}
+	variablesBytes, err := json.Marshal(action.Variables)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, action.Name, action.Description, action.ServiceId, action.NbParam,
+		parametersBytes, variablesBytes, action.MinimumInterval, action.DefaultInterval)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Writes the definition of an existing action, its name and service are left as is
+func (self *ActionRepository) UpdateAction(id string, action entities.Action) error {
+	sqlStatement := `UPDATE actions SET description = ($2), nbparam = ($3), parameters = ($4), variables = ($5), minimuminterval = ($6), defaultinterval = ($7) WHERE id = ($1)`
+
+	parametersBytes, err := json.Marshal(action.Parameters)
+	if err != nil {
+		return err
+	}
+	variablesBytes, err := json.Marshal(action.Variables)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, id, action.Description, action.NbParam,
+		parametersBytes, variablesBytes, action.MinimumInterval, action.DefaultInterval)
 	if err != nil {
 		return err
 	}
@@ -44,15 +81,16 @@ func (self *ActionRepository) CreateAction(name, description, serviceId string,
 func (self *ActionRepository) FindActionById(id string) (entities.Action, error) {
 	sqlStatement := `SELECT * FROM actions WHERE id = ($1)`
 	var action entities.Action
-	var parametersBytes []byte
+	var parametersBytes, variablesBytes []byte
 
 	row := self.db.QueryRow(sqlStatement, id)
-	err := row.Scan(&action.Id, &action.ServiceId, &action.Name, &action.Description, &action.NbParam, &parametersBytes)
+	err := row.Scan(&action.Id, &action.ServiceId, &action.Name, &action.Description, &action.NbParam, &parametersBytes, &variablesBytes,
+		&action.MinimumInterval, &action.DefaultInterval)
 	if err != nil {
 		return action, err
 	}
 
-	action, err = unmarshalParameters(parametersBytes, action)
+	action, err = unmarshalParameters(parametersBytes, variablesBytes, action)
 	if err != nil {
 		return action, err
 	}
@@ -62,15 +100,16 @@ func (self *ActionRepository) FindActionById(id string) (entities.Action, error)
 func (self *ActionRepository) FindActionByName(name string) (entities.Action, error) {
 	sqlStatement := `SELECT * FROM actions WHERE name = ($1)`
 	var action entities.Action
-	var parametersBytes []byte
+	var parametersBytes, variablesBytes []byte
 
 	row := self.db.QueryRow(sqlStatement, name)
-	err := row.Scan(&action.Id, &action.ServiceId, &action.Name, &action.Description, &action.NbParam, &parametersBytes)
+	err := row.Scan(&action.Id, &action.ServiceId, &action.Name, &action.Description, &action.NbParam, &parametersBytes, &variablesBytes,
+		&action.MinimumInterval, &action.DefaultInterval)
 	if err != nil {
 		return action, err
 	}
 
-	action, err = unmarshalParameters(parametersBytes, action)
+	action, err = unmarshalParameters(parametersBytes, variablesBytes, action)
 	if err != nil {
 		return action, err
 	}
@@ -89,14 +128,15 @@ func (self *ActionRepository) FindActionsByServiceId(serviceId string) ([]entiti
 
 	for rows.Next() {
 		var action entities.Action
-		var parametersBytes []byte
+		var parametersBytes, variablesBytes []byte
 
-		err := rows.Scan(&action.Id, &action.ServiceId, &action.Name, &action.Description, &action.NbParam, &parametersBytes)
+		err := rows.Scan(&action.Id, &action.ServiceId, &action.Name, &action.Description, &action.NbParam, &parametersBytes, &variablesBytes,
+			&action.MinimumInterval, &action.DefaultInterval)
 		if err != nil {
 			return nil, err
 		}
 
-		action, err = unmarshalParameters(parametersBytes, action)
+		action, err = unmarshalParameters(parametersBytes, variablesBytes, action)
 		if err != nil {
 			return actions, err
 		}
@@ -108,15 +148,16 @@ func (self *ActionRepository) FindActionsByServiceId(serviceId string) ([]entiti
 func (self *ActionRepository) FindActionByNameAndServiceId(name, serviceId string) (entities.Action, error) {
 	sqlStatement := `SELECT * FROM actions WHERE name = ($1) AND serviceid = ($2)`
 	var action entities.Action
-	var parametersBytes []byte
+	var parametersBytes, variablesBytes []byte
 
 	row := self.db.QueryRow(sqlStatement, name, serviceId)
-	err := row.Scan(&action.Id, &action.ServiceId, &action.Name, &action.Description, &action.NbParam, &parametersBytes)
+	err := row.Scan(&action.Id, &action.ServiceId, &action.Name, &action.Description, &action.NbParam, &parametersBytes, &variablesBytes,
+		&action.MinimumInterval, &action.DefaultInterval)
 	if err != nil {
 		return action, err
 	}
 
-	action, err = unmarshalParameters(parametersBytes, action)
+	action, err = unmarshalParameters(parametersBytes, variablesBytes, action)
 	if err != nil {
 		return action, err
 	}
@@ -31,13 +31,28 @@ func TestCreateAction(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
+	action := entities.Action{
+		Name:            "name",
+		Description:     "description",
+		ServiceId:       "serviceid",
+		NbParam:         1,
+		Parameters:      []map[string]interface{}{{"name": "minutes", "type": "int"}},
+		Variables:       []string{"time.hour"},
+		MinimumInterval: 300,
+		DefaultInterval: 900,
+	}
+	findSqlStatement := `SELECT \* FROM actions WHERE name = \(\$1\) AND serviceid = \(\$2\)`
+	sqlStatement := `INSERT INTO actions \(name, description, serviceid, nbparam, parameters, variables, minimuminterval, defaultinterval\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\)`
+
 	test.Run("Successful", func(test *testing.T) {
-		sqlStatement := `INSERT INTO actions \(name, description, serviceid, nbparam\) VALUES \(\$1, \$2, \$3, \$4\)`
+		mock.ExpectQuery(findSqlStatement).
+			WithArgs("name", "serviceid").
+			WillReturnError(sql.ErrNoRows)
 		mock.ExpectExec(sqlStatement).
-			WithArgs("name", "description", "serviceid", 3).
+			WithArgs("name", "description", "serviceid", 1, []byte(`[{"name":"minutes","type":"int"}]`), []byte(`["time.hour"]`), 300, 900).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err := repo.CreateAction("name", "description", "serviceid", 3)
+		err := repo.CreateAction(action)
 
 		assert.NoError(test, err)
 
@@ -47,37 +62,61 @@ func TestCreateAction(test *testing.T) {
 		}
 	})
 
-	test.Run("Reaction already exist", func(test *testing.T) {
-		findSqlStatement := `SELECT \* FROM actions WHERE name = \(\$1\)`
-		mockRow := sqlmock.NewRows([]string{"id", "name", "description", "serviceid", "nbparam", "parameters"}).
-			AddRow("id", "name", "description", "serviceid", 3, nil)
+	test.Run("Action already exist", func(test *testing.T) {
+		mockRow := sqlmock.NewRows([]string{"id", "serviceid", "name", "description", "nbparam", "parameters", "variables", "minimuminterval", "defaultinterval"}).
+			AddRow("id", "serviceid", "name", "description", 1, nil, nil, 0, 0)
 
 		mock.ExpectQuery(findSqlStatement).
-			WithArgs("name").
+			WithArgs("name", "serviceid").
 			WillReturnRows(mockRow)
 
-		sqlStatement := `INSERT INTO actions \(name, description, serviceid, nbparam\) VALUES \(\$1, \$2, \$3, \$4\)`
-		mock.ExpectExec(sqlStatement).
-			WithArgs("name", "description", "serviceid", 3).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		err := repo.CreateAction(action)
 
-		err := repo.CreateAction("name", "description", "serviceid", 3)
+		assert.EqualError(test, err, "Action already exist")
 
 		err = mock.ExpectationsWereMet()
-		if err == nil {
+		if err != nil {
 			test.Errorf("Expectation fail")
 		}
 	})
 }
 
+func TestUpdateAction(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	action := entities.Action{
+		Description:     "description",
+		NbParam:         1,
+		Parameters:      []map[string]interface{}{{"name": "minutes", "type": "int"}},
+		Variables:       []string{"time.hour"},
+		MinimumInterval: 300,
+		DefaultInterval: 900,
+	}
+	sqlStatement := `UPDATE actions SET description = \(\$2\), nbparam = \(\$3\), parameters = \(\$4\), variables = \(\$5\), minimuminterval = \(\$6\), defaultinterval = \(\$7\) WHERE id = \(\$1\)`
+
+	mock.ExpectExec(sqlStatement).
+		WithArgs("id", "description", 1, []byte(`[{"name":"minutes","type":"int"}]`), []byte(`["time.hour"]`), 300, 900).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdateAction("id", action)
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
 func TestFindActionById(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
 	test.Run("Successful", func(test *testing.T) {
 		sqlStatement := `SELECT \* FROM actions WHERE id = \(\$1\)`
-		mockRow := sqlmock.NewRows([]string{"id", "serviceid", "name", "description", "nbparam", "parameters"}).
-			AddRow("id", "serviceid", "name", "description", 3, nil)
+		mockRow := sqlmock.NewRows([]string{"id", "serviceid", "name", "description", "nbparam", "parameters", "variables", "minimuminterval", "defaultinterval"}).
+			AddRow("id", "serviceid", "name", "description", 3, nil, nil, 0, 0)
 
 		mock.ExpectQuery(sqlStatement).
 			WithArgs("id").
@@ -94,6 +133,28 @@ func TestFindActionById(test *testing.T) {
 		}
 	})
 
+	test.Run("Successful with variables", func(test *testing.T) {
+		sqlStatement := `SELECT \* FROM actions WHERE id = \(\$1\)`
+		mockRow := sqlmock.NewRows([]string{"id", "serviceid", "name", "description", "nbparam", "parameters", "variables", "minimuminterval", "defaultinterval"}).
+			AddRow("id", "serviceid", "name", "description", 3, nil, []byte(`["post.title","post.url"]`), 300, 900)
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("id").
+			WillReturnRows(mockRow)
+
+		action, err := repo.FindActionById("id")
+
+		assert.NoError(test, err)
+		assert.Equal(test, []string{"post.title", "post.url"}, action.Variables)
+		assert.Equal(test, 300, action.MinimumInterval)
+		assert.Equal(test, 900, action.DefaultInterval)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
 	test.Run("Action not found", func(test *testing.T) {
 
 		sqlStatement := `SELECT \* FROM actions WHERE id = \(\$1\)`
@@ -116,8 +177,8 @@ func TestFindActionByServiceId(test *testing.T) {
 
 	test.Run("Successful", func(test *testing.T) {
 		sqlStatement := `SELECT \* FROM actions WHERE serviceid = \(\$1\)`
-		mockRow := sqlmock.NewRows([]string{"id", "serviceid", "name", "description", "nbparam", "parameters"}).
-			AddRow("id", "serviceid", "name", "description", 3, nil)
+		mockRow := sqlmock.NewRows([]string{"id", "serviceid", "name", "description", "nbparam", "parameters", "variables", "minimuminterval", "defaultinterval"}).
+			AddRow("id", "serviceid", "name", "description", 3, nil, nil, 0, 0)
 
 		mock.ExpectQuery(sqlStatement).
 			WithArgs("serviceid").
@@ -0,0 +1,23 @@
+package database_repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type DatabaseRepository struct {
+	db *sql.DB
+}
+
+func NewDatabaseRepository(db *sql.DB) *DatabaseRepository {
+	return &DatabaseRepository{db: db}
+}
+
+func (self *DatabaseRepository) Ping(ctx context.Context) error {
+	return self.db.PingContext(ctx)
+}
+
+// Waits for the queries in progress, the connections are closed once released
+func (self *DatabaseRepository) Close() error {
+	return self.db.Close()
+}
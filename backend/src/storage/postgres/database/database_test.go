@@ -0,0 +1,67 @@
+package database_repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *DatabaseRepository) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		test.Fatalf("Mock DB fail")
+	}
+	repo := NewDatabaseRepository(db)
+	return db, mock, repo
+}
+
+func TestPing(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectPing()
+
+		err := repo.Ping(context.Background())
+
+		assert.NoError(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Database unreachable", func(test *testing.T) {
+		mock.ExpectPing().
+			WillReturnError(errors.New("connection refused"))
+
+		err := repo.Ping(context.Background())
+
+		assert.EqualError(test, err, "connection refused")
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestClose(test *testing.T) {
+	_, mock, repo := createMockDb(test)
+
+	mock.ExpectClose()
+
+	err := repo.Close()
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
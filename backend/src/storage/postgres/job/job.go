@@ -0,0 +1,158 @@
+package job_repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"backend/src/entities"
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func (self *JobRepository) EnqueueJob(job entities.ReactionJob) error {
+	sqlStatement := `INSERT INTO jobs (workflowid, servicename, workflow, event, steps, runfailed) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	workflowJson, err := json.Marshal(job.Workflow)
+	if err != nil {
+		return err
+	}
+	eventJson, err := json.Marshal(job.Event)
+	if err != nil {
+		return err
+	}
+	stepsJson, err := json.Marshal(job.Steps)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, job.WorkflowId, job.ServiceName, workflowJson, eventJson, stepsJson, job.RunFailed)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+const jobColumns = `id, workflowid, servicename, workflow, event, steps, status, attempts, lasterror, runat, createdat, runfailed`
+
+type jobScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row jobScanner) (entities.ReactionJob, error) {
+	var job entities.ReactionJob
+	var workflowBytes, eventBytes, stepsBytes []byte
+
+	err := row.Scan(&job.Id, &job.WorkflowId, &job.ServiceName, &workflowBytes, &eventBytes, &stepsBytes,
+		&job.Status, &job.Attempts, &job.LastError, &job.RunAt, &job.CreatedAt, &job.RunFailed)
+	if err != nil {
+		return job, err
+	}
+
+	err = json.Unmarshal(workflowBytes, &job.Workflow)
+	if err != nil {
+		return job, err
+	}
+	err = json.Unmarshal(eventBytes, &job.Event)
+	if err != nil {
+		return job, err
+	}
+	err = json.Unmarshal(stepsBytes, &job.Steps)
+	if err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+// Claims the oldest due job of the service, skipping the jobs already locked by another worker.
+// Returns false when there is no job to run.
+func (self *JobRepository) ClaimJob(serviceName string, staleBefore time.Time) (entities.ReactionJob, bool, error) {
+	sqlStatement := `UPDATE jobs SET status = 'running', attempts = attempts + 1, lockedat = now()
+	WHERE id = (SELECT id FROM jobs WHERE servicename = ($1) AND runat <= now()
+		AND (status = 'pending' OR (status = 'running' AND lockedat < ($2)))
+		ORDER BY runat LIMIT 1 FOR UPDATE SKIP LOCKED)
+	RETURNING ` + jobColumns
+
+	job, err := scanJob(self.db.QueryRow(sqlStatement, serviceName, staleBefore))
+	if errors.Is(err, sql.ErrNoRows) {
+		return job, false, nil
+	}
+	if err != nil {
+		return job, false, err
+	}
+	return job, true, nil
+}
+
+func (self *JobRepository) CompleteJob(id string) error {
+	sqlStatement := `DELETE FROM jobs WHERE id = ($1)`
+
+	_, err := self.db.Exec(sqlStatement, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (self *JobRepository) FailJob(id, lastError string) error {
+	sqlStatement := `UPDATE jobs SET status = 'failed', lasterror = ($2), lockedat = NULL WHERE id = ($1)`
+
+	_, err := self.db.Exec(sqlStatement, id, lastError)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// The job is claimed again once runAt is reached
+func (self *JobRepository) RetryJob(id, lastError string, runAt time.Time) error {
+	sqlStatement := `UPDATE jobs SET status = 'pending', lasterror = ($2), runat = ($3), lockedat = NULL WHERE id = ($1)`
+
+	_, err := self.db.Exec(sqlStatement, id, lastError, runAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (self *JobRepository) FindFailedJobsByWorkflowId(workflowId string) ([]entities.ReactionJob, error) {
+	sqlStatement := `SELECT ` + jobColumns + ` FROM jobs WHERE workflowid = ($1) AND status = 'failed' ORDER BY createdat DESC`
+	var jobs []entities.ReactionJob
+
+	rows, err := self.db.Query(sqlStatement, workflowId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Failed jobs are enqueued again with their attempts reset, returns the number of jobs enqueued
+func (self *JobRepository) RequeueFailedJobs(workflowId string) (int, error) {
+	sqlStatement := `UPDATE jobs SET status = 'pending', attempts = 0, runat = now() WHERE workflowid = ($1) AND status = 'failed'`
+
+	result, err := self.db.Exec(sqlStatement, workflowId)
+	if err != nil {
+		return 0, err
+	}
+
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(requeued), nil
+}
@@ -0,0 +1,253 @@
+package job_repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"backend/src/entities"
+)
+
+func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *JobRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		test.Fatalf("Mock DB fail")
+	}
+	repo := NewJobRepository(db)
+	return db, mock, repo
+}
+
+func TestEnqueueJob(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `INSERT INTO jobs \(workflowid, servicename, workflow, event, steps, runfailed\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)`
+	job := entities.ReactionJob{
+		WorkflowId:  "1",
+		ServiceName: "Discord",
+		Workflow:    entities.Workflow{Id: "1"},
+		Event:       entities.ActionEvent{"post": "title"},
+		Steps:       []entities.WorkflowReaction{{ReactionId: "2"}},
+	}
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("1", "Discord", sqlmock.AnyArg(), []byte(`{"post":"title"}`), sqlmock.AnyArg(), false).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.EnqueueJob(job)
+
+		assert.NoError(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Exec error", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("1", "Discord", sqlmock.AnyArg(), []byte(`{"post":"title"}`), sqlmock.AnyArg(), false).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.EnqueueJob(job)
+
+		assert.Error(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestClaimJob(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `UPDATE jobs SET status = 'running', attempts = attempts \+ 1, lockedat = now\(\)
+	WHERE id = \(SELECT id FROM jobs WHERE servicename = \(\$1\) AND runat <= now\(\)
+		AND \(status = 'pending' OR \(status = 'running' AND lockedat < \(\$2\)\)\)
+		ORDER BY runat LIMIT 1 FOR UPDATE SKIP LOCKED\)
+	RETURNING id, workflowid, servicename, workflow, event, steps, status, attempts, lasterror, runat, createdat, runfailed`
+	staleBefore := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	columns := []string{"id", "workflowid", "servicename", "workflow", "event", "steps", "status", "attempts", "lasterror", "runat", "createdat", "runfailed"}
+
+	test.Run("Successful", func(test *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow("job", "1", "Discord", []byte(`{"id":"1"}`), []byte(`{"post":"title"}`), []byte(`[{"reactionid":"2"}]`),
+				"running", 1, "", staleBefore, staleBefore, true)
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Discord", staleBefore).
+			WillReturnRows(rows)
+
+		job, found, err := repo.ClaimJob("Discord", staleBefore)
+
+		assert.NoError(test, err)
+		assert.True(test, found)
+		assert.Equal(test, "job", job.Id)
+		assert.Equal(test, "1", job.Workflow.Id)
+		assert.Equal(test, entities.ActionEvent{"post": "title"}, job.Event)
+		assert.Equal(test, "2", job.Steps[0].ReactionId)
+		assert.Equal(test, 1, job.Attempts)
+		assert.True(test, job.RunFailed)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("No job", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Discord", staleBefore).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, found, err := repo.ClaimJob("Discord", staleBefore)
+
+		assert.NoError(test, err)
+		assert.False(test, found)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Query error", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Discord", staleBefore).
+			WillReturnError(sql.ErrConnDone)
+
+		_, found, err := repo.ClaimJob("Discord", staleBefore)
+
+		assert.Error(test, err)
+		assert.False(test, found)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestCompleteJob(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM jobs WHERE id = \(\$1\)`).
+		WithArgs("job").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.CompleteJob("job")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestFailJob(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE jobs SET status = 'failed', lasterror = \(\$2\), lockedat = NULL WHERE id = \(\$1\)`).
+		WithArgs("job", "Fail").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.FailJob("job", "Fail")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestRetryJob(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	runAt := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE jobs SET status = 'pending', lasterror = \(\$2\), runat = \(\$3\), lockedat = NULL WHERE id = \(\$1\)`).
+		WithArgs("job", "API call failed", runAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.RetryJob("job", "API call failed", runAt)
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestFindFailedJobsByWorkflowId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT id, workflowid, servicename, workflow, event, steps, status, attempts, lasterror, runat, createdat, runfailed FROM jobs WHERE workflowid = \(\$1\) AND status = 'failed' ORDER BY createdat DESC`
+	createdAt := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	test.Run("Successful", func(test *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "workflowid", "servicename", "workflow", "event", "steps", "status", "attempts", "lasterror", "runat", "createdat", "runfailed"}).
+			AddRow("job", "1", "Dropbox", []byte(`{"id":"1"}`), []byte(`{}`), []byte(`[{"reactionid":"2"}]`),
+				"failed", 5, "API call failed", createdAt, createdAt, false)
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("1").
+			WillReturnRows(rows)
+
+		jobs, err := repo.FindFailedJobsByWorkflowId("1")
+
+		assert.NoError(test, err)
+		assert.Len(test, jobs, 1)
+		assert.Equal(test, "API call failed", jobs[0].LastError)
+		assert.Equal(test, 5, jobs[0].Attempts)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Query error", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("1").
+			WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.FindFailedJobsByWorkflowId("1")
+
+		assert.Error(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestRequeueFailedJobs(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE jobs SET status = 'pending', attempts = 0, runat = now\(\) WHERE workflowid = \(\$1\) AND status = 'failed'`).
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	requeued, err := repo.RequeueFailedJobs("1")
+
+	assert.NoError(test, err)
+	assert.Equal(test, 2, requeued)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
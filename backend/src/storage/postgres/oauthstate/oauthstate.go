@@ -0,0 +1,48 @@
+package oauth_state_repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type OAuthStateRepository struct {
+	db *sql.DB
+}
+
+func NewOAuthStateRepository(db *sql.DB) *OAuthStateRepository {
+	return &OAuthStateRepository{db: db}
+}
+
+// The expired states are deleted along the way, their callback can no longer be received
+func (self *OAuthStateRepository) CreateOAuthState(codeVerifier string, expiresAt time.Time) (string, error) {
+	deleteSqlStatement := `DELETE FROM oauth_states WHERE expiresat <= now()`
+	sqlStatement := `INSERT INTO oauth_states (codeverifier, expiresat) VALUES ($1, $2) RETURNING id`
+	var id string
+
+	_, err := self.db.Exec(deleteSqlStatement)
+	if err != nil {
+		return "", err
+	}
+
+	err = self.db.QueryRow(sqlStatement, codeVerifier, expiresAt).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Deletes the state and returns its code verifier, a state already used or expired is not found
+func (self *OAuthStateRepository) ConsumeOAuthState(id string, now time.Time) (string, bool, error) {
+	sqlStatement := `DELETE FROM oauth_states WHERE id = ($1) AND expiresat > ($2) RETURNING codeverifier`
+	var codeVerifier string
+
+	err := self.db.QueryRow(sqlStatement, id, now).Scan(&codeVerifier)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return codeVerifier, true, nil
+}
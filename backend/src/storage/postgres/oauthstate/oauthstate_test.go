@@ -0,0 +1,102 @@
+package oauth_state_repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *OAuthStateRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		test.Fatalf("Mock DB fail")
+	}
+	repo := NewOAuthStateRepository(db)
+	return db, mock, repo
+}
+
+func TestCreateOAuthState(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	expiresAt := time.Date(2026, 1, 1, 0, 10, 0, 0, time.UTC)
+	deleteSqlStatement := `DELETE FROM oauth_states WHERE expiresat <= now\(\)`
+	sqlStatement := `INSERT INTO oauth_states \(codeverifier, expiresat\) VALUES \(\$1, \$2\) RETURNING id`
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectExec(deleteSqlStatement).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("verifier", expiresAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+
+		id, err := repo.CreateOAuthState("verifier", expiresAt)
+
+		assert.NoError(test, err)
+		assert.Equal(test, "1", id)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Fail Delete Expired", func(test *testing.T) {
+		mock.ExpectExec(deleteSqlStatement).
+			WillReturnError(errors.New("Fail delete"))
+
+		_, err := repo.CreateOAuthState("verifier", expiresAt)
+
+		assert.EqualError(test, err, "Fail delete")
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestConsumeOAuthState(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sqlStatement := `DELETE FROM oauth_states WHERE id = \(\$1\) AND expiresat > \(\$2\) RETURNING codeverifier`
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("1", now).
+			WillReturnRows(sqlmock.NewRows([]string{"codeverifier"}).AddRow("verifier"))
+
+		codeVerifier, found, err := repo.ConsumeOAuthState("1", now)
+
+		assert.NoError(test, err)
+		assert.True(test, found)
+		assert.Equal(test, "verifier", codeVerifier)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Already Used", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("1", now).
+			WillReturnError(sql.ErrNoRows)
+
+		_, found, err := repo.ConsumeOAuthState("1", now)
+
+		assert.NoError(test, err)
+		assert.False(test, found)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
@@ -26,15 +26,20 @@ func unmarshalParameters(parametersBytes []byte, reaction entities.Reaction) (en
 	return reaction, nil
 }
 
-func (self *ReactionRepository) CreateReaction(name, description, serviceId string, nbParam int) error {
-	sqlStatement := `INSERT INTO reactions (name, description, serviceid, nbparam) VALUES ($1, $2, $3, $4)`
+func (self *ReactionRepository) CreateReaction(reaction entities.Reaction) error {
+	sqlStatement := `INSERT INTO reactions (name, description, serviceid, nbparam, parameters) VALUES ($1, $2, $3, $4, $5)`
 
-	_, err := self.FindReactionByName(name)
+	_, err := self.FindReactionByNameAndServiceId(reaction.Name, reaction.ServiceId)
 	if err == nil {
 		return fmt.Errorf("Reaction already exist")
 	}
 
-	_, err = self.db.Exec(sqlStatement, name, description, serviceId, nbParam)
+	parametersBytes, err := json.Marshal(reaction.Parameters)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, reaction.Name, reaction.Description, reaction.ServiceId, reaction.NbParam, parametersBytes)
 	if err != nil {
 		return err
 	}
@@ -77,6 +82,24 @@ func (self *ReactionRepository) FindReactionByName(name string) (entities.Reacti
 	return reaction, nil
 }
 
+func (self *ReactionRepository) FindReactionByNameAndServiceId(name, serviceId string) (entities.Reaction, error) {
+	sqlStatement := `SELECT * FROM reactions WHERE name = ($1) AND serviceid = ($2)`
+	var reaction entities.Reaction
+	var parametersBytes []byte
+
+	row := self.db.QueryRow(sqlStatement, name, serviceId)
+	err := row.Scan(&reaction.Id, &reaction.ServiceId, &reaction.Name, &reaction.Description, &reaction.NbParam, &parametersBytes)
+	if err != nil {
+		return reaction, err
+	}
+
+	reaction, err = unmarshalParameters(parametersBytes, reaction)
+	if err != nil {
+		return reaction, err
+	}
+	return reaction, nil
+}
+
 func (self *ReactionRepository) FindReactionsByServiceId(serviceId string) ([]entities.Reaction, error) {
 	sqlStatement := `SELECT * FROM reactions WHERE serviceid = ($1)`
 	var reactions []entities.Reaction
@@ -31,13 +31,25 @@ func TestCreateReaction(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
+	reaction := entities.Reaction{
+		Name:        "name",
+		Description: "description",
+		ServiceId:   "serviceid",
+		NbParam:     1,
+		Parameters:  []map[string]interface{}{{"name": "url", "type": "string"}},
+	}
+	findSqlStatement := `SELECT \* FROM reactions WHERE name = \(\$1\) AND serviceid = \(\$2\)`
+	sqlStatement := `INSERT INTO reactions \(name, description, serviceid, nbparam, parameters\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`
+
 	test.Run("Successful", func(test *testing.T) {
-		sqlStatement := `INSERT INTO reactions \(name, description, serviceid, nbparam\) VALUES \(\$1, \$2, \$3, \$4\)`
+		mock.ExpectQuery(findSqlStatement).
+			WithArgs("name", "serviceid").
+			WillReturnError(sql.ErrNoRows)
 		mock.ExpectExec(sqlStatement).
-			WithArgs("name", "description", "serviceid", 3).
+			WithArgs("name", "description", "serviceid", 1, []byte(`[{"name":"url","type":"string"}]`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err := repo.CreateReaction("name", "description", "serviceid", 3)
+		err := repo.CreateReaction(reaction)
 
 		assert.NoError(test, err)
 
@@ -48,28 +60,48 @@ func TestCreateReaction(test *testing.T) {
 	})
 
 	test.Run("Reaction already exist", func(test *testing.T) {
-		findSqlStatement := `SELECT \* FROM reactions WHERE name = \(\$1\)`
-		mockRow := sqlmock.NewRows([]string{"id", "name", "description", "serviceid", "nbparam", "parameters"}).
-			AddRow("id", "name", "description", "serviceid", 3, nil)
+		mockRow := sqlmock.NewRows([]string{"id", "serviceid", "name", "description", "nbparam", "parameters"}).
+			AddRow("id", "serviceid", "name", "description", 1, nil)
 
 		mock.ExpectQuery(findSqlStatement).
-			WithArgs("name").
+			WithArgs("name", "serviceid").
 			WillReturnRows(mockRow)
 
-		sqlStatement := `INSERT INTO reactions \(name, description, serviceid, nbparam\) VALUES \(\$1, \$2, \$3, \$4\)`
-		mock.ExpectExec(sqlStatement).
-			WithArgs("name", "description", "serviceid", 3).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		err := repo.CreateReaction(reaction)
 
-		err := repo.CreateReaction("name", "description", "serviceid", 3)
+		assert.EqualError(test, err, "Reaction already exist")
 
 		err = mock.ExpectationsWereMet()
-		if err == nil {
+		if err != nil {
 			test.Errorf("Expectation fail")
 		}
 	})
 }
 
+func TestFindReactionByNameAndServiceId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT \* FROM reactions WHERE name = \(\$1\) AND serviceid = \(\$2\)`
+	mockRow := sqlmock.NewRows([]string{"id", "serviceid", "name", "description", "nbparam", "parameters"}).
+		AddRow("id", "serviceid", "name", "description", 1, []byte(`[{"name":"url"}]`))
+
+	mock.ExpectQuery(sqlStatement).
+		WithArgs("name", "serviceid").
+		WillReturnRows(mockRow)
+
+	reaction, err := repo.FindReactionByNameAndServiceId("name", "serviceid")
+
+	assert.NoError(test, err)
+	assert.Equal(test, "id", reaction.Id)
+	assert.Equal(test, "url", reaction.Parameters[0]["name"])
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
 func TestFindReactionById(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
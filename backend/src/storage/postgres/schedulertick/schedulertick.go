@@ -0,0 +1,90 @@
+package scheduler_tick_repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+const schedulerLockName = "scheduler"
+
+type SchedulerTickRepository struct {
+	db *sql.DB
+
+	// Dedicated connection holding the scheduler lock, the lock is released with its session
+	lockMutex sync.Mutex
+	lockConn  *sql.Conn
+}
+
+func NewSchedulerTickRepository(db *sql.DB) *SchedulerTickRepository {
+	return &SchedulerTickRepository{db: db}
+}
+
+func (self *SchedulerTickRepository) FindLastTick(name string) (time.Time, error) {
+	sqlStatement := `SELECT lasttick FROM scheduler_ticks WHERE name = ($1)`
+	var lastTick time.Time
+
+	err := self.db.QueryRow(sqlStatement, name).Scan(&lastTick)
+	if err != nil {
+		return lastTick, err
+	}
+	return lastTick, nil
+}
+
+func (self *SchedulerTickRepository) UpdateLastTick(name string, tick time.Time) error {
+	sqlStatement := `INSERT INTO scheduler_ticks (name, lasttick) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET lasttick = ($2)`
+
+	_, err := self.db.Exec(sqlStatement, name, tick)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// The scheduler lock is a session advisory lock, kept while its connection stays alive
+func (self *SchedulerTickRepository) TryAcquireSchedulerLock(ctx context.Context) (bool, error) {
+	sqlStatement := `SELECT pg_try_advisory_lock(hashtext($1))`
+	var acquired bool
+
+	self.lockMutex.Lock()
+	defer self.lockMutex.Unlock()
+
+	if self.lockConn != nil {
+		err := self.lockConn.PingContext(ctx)
+		if err == nil {
+			return true, nil
+		}
+		self.lockConn.Close()
+		self.lockConn = nil
+	}
+
+	conn, err := self.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	err = conn.QueryRowContext(ctx, sqlStatement, schedulerLockName).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Close()
+		return false, err
+	}
+	self.lockConn = conn
+	return true, nil
+}
+
+func (self *SchedulerTickRepository) ReleaseSchedulerLock() error {
+	sqlStatement := `SELECT pg_advisory_unlock(hashtext($1))`
+
+	self.lockMutex.Lock()
+	defer self.lockMutex.Unlock()
+
+	if self.lockConn == nil {
+		return nil
+	}
+
+	_, err := self.lockConn.ExecContext(context.Background(), sqlStatement, schedulerLockName)
+	self.lockConn.Close()
+	self.lockConn = nil
+	return err
+}
@@ -0,0 +1,168 @@
+package scheduler_tick_repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *SchedulerTickRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		test.Fatalf("Mock DB fail")
+	}
+	repo := NewSchedulerTickRepository(db)
+	return db, mock, repo
+}
+
+func TestFindLastTick(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT lasttick FROM scheduler_ticks WHERE name = \(\$1\)`
+
+	test.Run("Successful", func(test *testing.T) {
+		lastTick := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Time & Date").
+			WillReturnRows(sqlmock.NewRows([]string{"lasttick"}).AddRow(lastTick))
+
+		tick, err := repo.FindLastTick("Time & Date")
+
+		assert.NoError(test, err)
+		assert.Equal(test, lastTick, tick)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("No tick", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Time & Date").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FindLastTick("Time & Date")
+
+		assert.ErrorIs(test, err, sql.ErrNoRows)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestUpdateLastTick(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	tick := time.Date(2024, time.March, 1, 9, 1, 0, 0, time.UTC)
+	sqlStatement := `INSERT INTO scheduler_ticks \(name, lasttick\) VALUES \(\$1, \$2\) ON CONFLICT \(name\) DO UPDATE SET lasttick = \(\$2\)`
+	mock.ExpectExec(sqlStatement).
+		WithArgs("Time & Date", tick).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdateLastTick("Time & Date", tick)
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestSchedulerLock(test *testing.T) {
+	lockStatement := `SELECT pg_try_advisory_lock\(hashtext\(\$1\)\)`
+	unlockStatement := `SELECT pg_advisory_unlock\(hashtext\(\$1\)\)`
+
+	test.Run("Held By Another Replica", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		mock.ExpectQuery(lockStatement).
+			WithArgs("scheduler").
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+
+		acquired, err := repo.TryAcquireSchedulerLock(context.Background())
+
+		assert.NoError(test, err)
+		assert.False(test, acquired)
+		assert.NoError(test, repo.ReleaseSchedulerLock())
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Acquired And Kept", func(test *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		if err != nil {
+			test.Fatalf("Mock DB fail")
+		}
+		defer db.Close()
+		repo := NewSchedulerTickRepository(db)
+
+		mock.ExpectQuery(lockStatement).
+			WithArgs("scheduler").
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+		mock.ExpectPing()
+		mock.ExpectExec(unlockStatement).
+			WithArgs("scheduler").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		acquired, err := repo.TryAcquireSchedulerLock(context.Background())
+		assert.NoError(test, err)
+		assert.True(test, acquired)
+
+		acquired, err = repo.TryAcquireSchedulerLock(context.Background())
+		assert.NoError(test, err)
+		assert.True(test, acquired)
+
+		assert.NoError(test, repo.ReleaseSchedulerLock())
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Lost Connection", func(test *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		if err != nil {
+			test.Fatalf("Mock DB fail")
+		}
+		defer db.Close()
+		repo := NewSchedulerTickRepository(db)
+
+		mock.ExpectQuery(lockStatement).
+			WithArgs("scheduler").
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+		mock.ExpectPing().
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectQuery(lockStatement).
+			WithArgs("scheduler").
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+
+		acquired, err := repo.TryAcquireSchedulerLock(context.Background())
+		assert.NoError(test, err)
+		assert.True(test, acquired)
+
+		acquired, err = repo.TryAcquireSchedulerLock(context.Background())
+		assert.NoError(test, err)
+		assert.False(test, acquired)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
@@ -15,15 +15,15 @@ func NewServiceRepository(db *sql.DB) *ServiceRepository {
 	return &ServiceRepository{db: db}
 }
 
-func (self *ServiceRepository) CreateService(name, color, logo string) error {
-	sqlStatement := `INSERT INTO services (name, color, logo) VALUES ($1, $2, $3)`
+func (self *ServiceRepository) CreateService(service entities.Service) error {
+	sqlStatement := `INSERT INTO services (name, color, logo, hasactions, hasreactions, isauthneeded, description) VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := self.FindServiceByName(name)
+	_, err := self.FindServiceByName(service.Name)
 	if err == nil {
 		return fmt.Errorf("Service already exist")
 	}
 
-	_, err = self.db.Exec(sqlStatement, name, color, logo)
+	_, err = self.db.Exec(sqlStatement, service.Name, service.Color, service.Logo, service.HasActions, service.HasReactions, service.IsAuthNeeded, service.Description)
 	if err != nil {
 		return err
 	}
@@ -34,13 +34,22 @@ func TestCreateService(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
+	newService := entities.Service{
+		Name:         "name",
+		Color:        "color",
+		Logo:         "logo",
+		HasActions:   true,
+		IsAuthNeeded: true,
+		Description:  "description",
+	}
+
 	test.Run("Successful", func(test *testing.T) {
-		sqlStatement := `INSERT INTO services \(name, color, logo\) VALUES \(\$1, \$2, \$3\)`
+		sqlStatement := `INSERT INTO services \(name, color, logo, hasactions, hasreactions, isauthneeded, description\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`
 		mock.ExpectExec(sqlStatement).
-			WithArgs("name", "color", "logo").
+			WithArgs("name", "color", "logo", true, false, true, "description").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err := repo.CreateService("name", "color", "logo")
+		err := repo.CreateService(newService)
 
 		assert.NoError(test, err)
 
@@ -59,12 +68,12 @@ func TestCreateService(test *testing.T) {
 			WithArgs("name").
 			WillReturnRows(mockRow)
 
-		sqlStatement := `INSERT INTO services \(name, color, logo\) VALUES \(\$1, \$2, \$3\)`
+		sqlStatement := `INSERT INTO services \(name, color, logo, hasactions, hasreactions, isauthneeded, description\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`
 		mock.ExpectExec(sqlStatement).
-			WithArgs("name", "color", "logo").
+			WithArgs("name", "color", "logo", true, false, true, "description").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err := repo.CreateService("name", "color", "logo")
+		err := repo.CreateService(newService)
 
 		err = mock.ExpectationsWereMet()
 		if err == nil {
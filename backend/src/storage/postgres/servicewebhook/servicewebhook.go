@@ -0,0 +1,44 @@
+package service_webhook_repository
+
+import (
+	"database/sql"
+)
+
+type ServiceWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewServiceWebhookRepository(db *sql.DB) *ServiceWebhookRepository {
+	return &ServiceWebhookRepository{db: db}
+}
+
+func (self *ServiceWebhookRepository) FindServiceWebhookSecret(serviceName, resource string) (string, error) {
+	sqlStatement := `SELECT secret FROM service_webhooks WHERE servicename = ($1) AND resource = ($2)`
+	var secret string
+
+	err := self.db.QueryRow(sqlStatement, serviceName, resource).Scan(&secret)
+	if err != nil {
+		return secret, err
+	}
+	return secret, nil
+}
+
+func (self *ServiceWebhookRepository) SaveServiceWebhookSecret(serviceName, resource, secret string) error {
+	sqlStatement := `INSERT INTO service_webhooks (servicename, resource, secret) VALUES ($1, $2, $3) ON CONFLICT (servicename, resource) DO UPDATE SET secret = ($3)`
+
+	_, err := self.db.Exec(sqlStatement, serviceName, resource, secret)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (self *ServiceWebhookRepository) CreateWebhookRejection(serviceName, resource, reason, remoteAddr string) error {
+	sqlStatement := `INSERT INTO webhook_rejections (servicename, resource, reason, remoteaddr) VALUES ($1, $2, $3, $4)`
+
+	_, err := self.db.Exec(sqlStatement, serviceName, resource, reason, remoteAddr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package service_webhook_repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *ServiceWebhookRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		test.Fatalf("Mock DB fail")
+	}
+	repo := NewServiceWebhookRepository(db)
+	return db, mock, repo
+}
+
+func TestFindServiceWebhookSecret(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT secret FROM service_webhooks WHERE servicename = \(\$1\) AND resource = \(\$2\)`
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Github", "owner/repo").
+			WillReturnRows(sqlmock.NewRows([]string{"secret"}).AddRow("secret"))
+
+		secret, err := repo.FindServiceWebhookSecret("Github", "owner/repo")
+
+		assert.NoError(test, err)
+		assert.Equal(test, "secret", secret)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("No secret", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Github", "owner/repo").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FindServiceWebhookSecret("Github", "owner/repo")
+
+		assert.ErrorIs(test, err, sql.ErrNoRows)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestSaveServiceWebhookSecret(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `INSERT INTO service_webhooks \(servicename, resource, secret\) VALUES \(\$1, \$2, \$3\) ON CONFLICT \(servicename, resource\) DO UPDATE SET secret = \(\$3\)`
+	mock.ExpectExec(sqlStatement).
+		WithArgs("Gitlab", "42", "secret").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.SaveServiceWebhookSecret("Gitlab", "42", "secret")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestCreateWebhookRejection(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `INSERT INTO webhook_rejections \(servicename, resource, reason, remoteaddr\) VALUES \(\$1, \$2, \$3, \$4\)`
+	mock.ExpectExec(sqlStatement).
+		WithArgs("Github", "owner/repo", "Invalid webhook signature", "203.0.113.7:443").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.CreateWebhookRejection("Github", "owner/repo", "Invalid webhook signature", "203.0.113.7:443")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
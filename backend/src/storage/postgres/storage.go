@@ -5,32 +5,66 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 
 	"backend/src/storage"
+	"backend/src/storage/encryption"
 	action_repository "backend/src/storage/postgres/action"
+	database_repository "backend/src/storage/postgres/database"
+	job_repository "backend/src/storage/postgres/job"
+	oauth_state_repository "backend/src/storage/postgres/oauthstate"
 	reaction_repository "backend/src/storage/postgres/reaction"
+	scheduler_tick_repository "backend/src/storage/postgres/schedulertick"
 	service_repository "backend/src/storage/postgres/service"
+	service_webhook_repository "backend/src/storage/postgres/servicewebhook"
 	user_repository "backend/src/storage/postgres/user"
 	user_service_repository "backend/src/storage/postgres/userservice"
+	webhook_delivery_repository "backend/src/storage/postgres/webhookdelivery"
 	workflow_repository "backend/src/storage/postgres/workflow"
+	workflow_reaction_repository "backend/src/storage/postgres/workflowreaction"
+	workflow_run_repository "backend/src/storage/postgres/workflowrun"
 )
 
+const connectAttempts = 10
+const connectRetryDelay = 3 * time.Second
+
 func retrieveDatabaseInfos() (string, int, string, string, string, error) {
 	port, err := strconv.Atoi(os.Getenv("PGPORT"))
 	if err != nil {
-		panic(err)
+		return "", 0, "", "", "", fmt.Errorf("Invalid PGPORT: %s", err.Error())
+	}
+	return os.Getenv("PGHOST"), port, os.Getenv("PGUSER"), os.Getenv("PGPASSWORD"), os.Getenv("PGDATABASE"), nil
+}
+
+// The database may start after the server, it is given a few attempts to accept connections
+func connect(db *sql.DB) error {
+	var err error
+
+	for attempt := 1; attempt <= connectAttempts; attempt++ {
+		err = db.Ping()
+		if err == nil {
+			return nil
+		}
+		if attempt < connectAttempts {
+			time.Sleep(connectRetryDelay)
+		}
 	}
-	return os.Getenv("PGHOST"), port, os.Getenv("PGUSER"), os.Getenv("PGPASSWORD"), os.Getenv("PGDATABASE"), err
+	return err
 }
 
-func New() *storage.Repository {
+func New() (*storage.Repository, error) {
 	godotenv.Load()
 
+	keyring, err := encryption.NewKeyringFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	host, port, user, password, dbname, err := retrieveDatabaseInfos()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s "+
@@ -39,21 +73,30 @@ func New() *storage.Repository {
 
 	db, err := sql.Open("postgres", psqlInfo)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	err = db.Ping()
+	err = connect(db)
 	if err != nil {
-		panic(err)
+		db.Close()
+		return nil, err
 	}
 	fmt.Println("Successfully connected!")
 
 	return &storage.Repository{
-		UserRepository:        user_repository.NewUserRepository(db),
-		ServiceRepository:     service_repository.NewServiceRepository(db),
-		UserServiceRepository: user_service_repository.NewUserServiceRepository(db),
-		ReactionRepository:    reaction_repository.NewReactionRepository(db),
-		ActionRepository:      action_repository.NewActionRepository(db),
-		WorkflowRepository:    workflow_repository.NewWorkflowRepository(db),
-	}
+		DatabaseRepository:         database_repository.NewDatabaseRepository(db),
+		UserRepository:             user_repository.NewUserRepository(db),
+		ServiceRepository:          service_repository.NewServiceRepository(db),
+		UserServiceRepository:      user_service_repository.NewUserServiceRepository(db, keyring),
+		ReactionRepository:         reaction_repository.NewReactionRepository(db),
+		ActionRepository:           action_repository.NewActionRepository(db),
+		WorkflowRepository:         workflow_repository.NewWorkflowRepository(db),
+		WorkflowReactionRepository: workflow_reaction_repository.NewWorkflowReactionRepository(db),
+		WorkflowRunRepository:      workflow_run_repository.NewWorkflowRunRepository(db),
+		SchedulerTickRepository:    scheduler_tick_repository.NewSchedulerTickRepository(db),
+		ServiceWebhookRepository:   service_webhook_repository.NewServiceWebhookRepository(db),
+		WebhookDeliveryRepository:  webhook_delivery_repository.NewWebhookDeliveryRepository(db),
+		JobRepository:              job_repository.NewJobRepository(db),
+		OAuthStateRepository:       oauth_state_repository.NewOAuthStateRepository(db),
+	}, nil
 }
@@ -69,6 +69,24 @@ func (self *UserRepository) UpdateUser(email, password, connectionType string) e
 	return nil
 }
 
+func (self *UserRepository) UpdateUserTimezone(email, connectionType, timezone string) error {
+	sqlStatement := `UPDATE users SET timezone = ($1) WHERE email = ($2) AND connectiontype = ($3)`
+
+	res, err := self.db.Exec(sqlStatement, timezone, email, connectionType)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("User doesn't exist")
+	}
+	return nil
+}
+
 func (self *UserRepository) DeleteUser(email, connectionType string) error {
 	sqlStatement := `DELETE FROM users WHERE email = ($1)`
 
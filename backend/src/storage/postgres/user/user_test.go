@@ -160,6 +160,43 @@ func TestUpdateUser(test *testing.T) {
 	})
 }
 
+func TestUpdateUserTimezone(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `UPDATE users SET timezone = \(\$1\) WHERE email = \(\$2\) AND connectiontype = \(\$3\)`
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("America/New_York", "email", "connectiontype").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdateUserTimezone("email", "connectiontype", "America/New_York")
+
+		assert.NoError(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("User doesn't exist", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("America/New_York", "email", "connectiontype").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdateUserTimezone("email", "connectiontype", "America/New_York")
+
+		assert.EqualError(test, err, "User doesn't exist")
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
 func TestDeleteUser(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
@@ -3,55 +3,190 @@ package userservice_repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"backend/src/entities"
+	"backend/src/storage/encryption"
 )
 
 type UserServiceRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	keyring *encryption.Keyring
 }
 
-func NewUserServiceRepository(db *sql.DB) *UserServiceRepository {
-	return &UserServiceRepository{db: db}
+type storedTokens struct {
+	id           string
+	userId       string
+	serviceId    string
+	accessToken  string
+	refreshToken string
+	envelope     encryption.Envelope
+}
+
+func NewUserServiceRepository(db *sql.DB, keyring *encryption.Keyring) *UserServiceRepository {
+	return &UserServiceRepository{db: db, keyring: keyring}
 }
 
 func (self *UserServiceRepository) CreateUserService(userId, token, tokenRefresh, expiryDate, serviceId string) error {
-	sqlStatement := `INSERT INTO userservices (userid, token, tokenrefresh, expiry, serviceid) VALUES ($1, $2, $3, $4, $5)`
+	sqlStatement := `INSERT INTO userservices (userid, token, tokenrefresh, expiry, serviceid, keyid, datakey) VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	_, err := self.FindUserServiceByServiceIdandUserId(userId, serviceId)
 	if err == nil {
 		return fmt.Errorf("Service for user already exist")
 	}
 
-	_, err = self.db.Exec(sqlStatement, userId, token, tokenRefresh, expiryDate, serviceId)
+	tokens, err := self.encryptTokens(userId, serviceId, token, tokenRefresh)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, userId, tokens.accessToken, tokens.refreshToken, expiryDate, serviceId,
+		tokens.envelope.KeyId, tokens.envelope.DataKey)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (self *UserServiceRepository) FindUserServiceByServiceIdandUserId(userId, serviceId string) (entities.UserService, error) {
-	sqlStatement := `SELECT * FROM userservices WHERE userid = ($1) AND serviceid = ($2)`
+type userServiceScanner interface {
+	Scan(dest ...any) error
+}
+
+func (self *UserServiceRepository) scanUserService(row userServiceScanner) (entities.UserService, error) {
 	var userService entities.UserService
+	var envelope encryption.Envelope
+	var lastSuccessAt, lastErrorAt sql.NullTime
 
-	row := self.db.QueryRow(sqlStatement, userId, serviceId)
 	err := row.Scan(&userService.Id, &userService.UserId, &userService.AccessToken,
-		&userService.RefreshToken, &userService.ExpiryDate, &userService.ServiceId)
+		&userService.RefreshToken, &userService.ExpiryDate, &userService.ServiceId, &envelope.KeyId, &envelope.DataKey,
+		&userService.NeedsReauth, &userService.Scopes, &lastSuccessAt, &userService.LastError, &lastErrorAt)
 	if err != nil {
 		return userService, err
 	}
+	userService.LastSuccessAt = lastSuccessAt.Time
+	userService.LastErrorAt = lastErrorAt.Time
+
+	userService.AccessToken, userService.RefreshToken, err = self.decryptTokens(userService.UserId, userService.ServiceId,
+		userService.AccessToken, userService.RefreshToken, envelope)
+	if err != nil {
+		return entities.UserService{}, err
+	}
 	return userService, nil
 }
 
+func (self *UserServiceRepository) FindUserServiceByServiceIdandUserId(userId, serviceId string) (entities.UserService, error) {
+	sqlStatement := `SELECT * FROM userservices WHERE userid = ($1) AND serviceid = ($2)`
+
+	row := self.db.QueryRow(sqlStatement, userId, serviceId)
+	return self.scanUserService(row)
+}
+
+// Connections of the user whose tokens can be decrypted
+func (self *UserServiceRepository) FindUserServicesByUserId(userId string) ([]entities.UserService, error) {
+	sqlStatement := `SELECT * FROM userservices WHERE userid = ($1)`
+
+	rows, err := self.db.Query(sqlStatement, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userServices := []entities.UserService{}
+	for rows.Next() {
+		userService, err := self.scanUserService(rows)
+		if err != nil {
+			continue
+		}
+		userServices = append(userServices, userService)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return userServices, nil
+}
+
+// Connections whose token expires before the deadline and which can still be refreshed
+// A row whose tokens cannot be decrypted is skipped so that it does not hold back the others
+func (self *UserServiceRepository) FindUserServicesExpiringBefore(deadline time.Time) ([]entities.UserService, error) {
+	sqlStatement := `SELECT * FROM userservices WHERE NOT needsreauth AND CAST(expiry AS timestamptz) <= ($1)`
+
+	rows, err := self.db.Query(sqlStatement, deadline)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userServices := []entities.UserService{}
+	for rows.Next() {
+		userService, err := self.scanUserService(rows)
+		if err != nil {
+			continue
+		}
+		userServices = append(userServices, userService)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return userServices, nil
+}
+
+// Storing a new token clears the reauthorization flag
 func (self *UserServiceRepository) UpdateUserServiceByServiceIdAndUserId(userId, accessToken, refreshToken, expiryDate, serviceId string) error {
-	sqlStatement := `UPDATE userservices SET token = ($1), tokenrefresh = ($2), expiry = ($3) WHERE userid = ($4) AND serviceid = ($5)`
+	sqlStatement := `UPDATE userservices SET token = ($1), tokenrefresh = ($2), expiry = ($3), keyid = ($4), datakey = ($5), needsreauth = false WHERE userid = ($6) AND serviceid = ($7)`
 
 	_, err := self.FindUserServiceByServiceIdandUserId(userId, serviceId)
 	if err != nil {
 		return fmt.Errorf("Service doesn't exist for user")
 	}
 
-	_, err = self.db.Exec(sqlStatement, accessToken, refreshToken, expiryDate, userId, serviceId)
+	tokens, err := self.encryptTokens(userId, serviceId, accessToken, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, tokens.accessToken, tokens.refreshToken, expiryDate,
+		tokens.envelope.KeyId, tokens.envelope.DataKey, userId, serviceId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (self *UserServiceRepository) MarkUserServiceNeedsReauth(userId, serviceId string) error {
+	sqlStatement := `UPDATE userservices SET needsreauth = true WHERE userid = ($1) AND serviceid = ($2)`
+
+	_, err := self.db.Exec(sqlStatement, userId, serviceId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (self *UserServiceRepository) UpdateUserServiceScopes(userId, serviceId, scopes string) error {
+	sqlStatement := `UPDATE userservices SET scopes = ($1) WHERE userid = ($2) AND serviceid = ($3)`
+
+	_, err := self.db.Exec(sqlStatement, scopes, userId, serviceId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (self *UserServiceRepository) RecordUserServiceSuccess(userId, serviceId string, at time.Time) error {
+	sqlStatement := `UPDATE userservices SET lastsuccessat = ($1) WHERE userid = ($2) AND serviceid = ($3)`
+
+	_, err := self.db.Exec(sqlStatement, at, userId, serviceId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// The last error is kept after a success, its date tells whether it is still relevant
+func (self *UserServiceRepository) RecordUserServiceError(userId, serviceId, lastError string, at time.Time) error {
+	sqlStatement := `UPDATE userservices SET lasterror = ($1), lasterrorat = ($2) WHERE userid = ($3) AND serviceid = ($4)`
+
+	_, err := self.db.Exec(sqlStatement, lastError, at, userId, serviceId)
 	if err != nil {
 		return err
 	}
@@ -67,3 +202,124 @@ func (self *UserServiceRepository) DeleteUserServiceByUserId(userId string) erro
 	}
 	return nil
 }
+
+func (self *UserServiceRepository) DeleteUserServiceByServiceIdAndUserId(userId, serviceId string) error {
+	sqlStatement := `DELETE FROM userservices WHERE userid = ($1) AND serviceid = ($2)`
+
+	res, err := self.db.Exec(sqlStatement, userId, serviceId)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("Could not delete user service")
+	}
+	return nil
+}
+
+// Wraps the data key of every row with the current master key, the rows still in plaintext are encrypted
+// A row updated meanwhile is already under the current key and is left as is
+func (self *UserServiceRepository) RotateUserServiceKeys() (int, error) {
+	sqlStatement := `SELECT id, userid, serviceid, token, tokenrefresh, keyid, datakey FROM userservices WHERE keyid <> ($1)`
+	updateSqlStatement := `UPDATE userservices SET token = ($1), tokenrefresh = ($2), keyid = ($3), datakey = ($4) WHERE id = ($5) AND keyid = ($6)`
+
+	rows, err := self.db.Query(sqlStatement, self.keyring.CurrentKeyId())
+	if err != nil {
+		return 0, err
+	}
+
+	staleTokens := []storedTokens{}
+	for rows.Next() {
+		var tokens storedTokens
+		err = rows.Scan(&tokens.id, &tokens.userId, &tokens.serviceId, &tokens.accessToken, &tokens.refreshToken, &tokens.envelope.KeyId, &tokens.envelope.DataKey)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		staleTokens = append(staleTokens, tokens)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return 0, rows.Err()
+	}
+
+	rotated := 0
+	for _, tokens := range staleTokens {
+		rotatedTokens, err := self.rotateTokens(tokens)
+		if err != nil {
+			return rotated, fmt.Errorf("userservice %s: %s", tokens.id, err.Error())
+		}
+
+		result, err := self.db.Exec(updateSqlStatement, rotatedTokens.accessToken, rotatedTokens.refreshToken,
+			rotatedTokens.envelope.KeyId, rotatedTokens.envelope.DataKey, tokens.id, tokens.envelope.KeyId)
+		if err != nil {
+			return rotated, err
+		}
+		count, err := result.RowsAffected()
+		if err == nil && count > 0 {
+			rotated++
+		}
+	}
+	return rotated, nil
+}
+
+func (self *UserServiceRepository) rotateTokens(tokens storedTokens) (storedTokens, error) {
+	if tokens.envelope.KeyId == "" {
+		return self.encryptTokens(tokens.userId, tokens.serviceId, tokens.accessToken, tokens.refreshToken)
+	}
+
+	envelope, err := self.keyring.RewrapEnvelope(tokens.envelope, columnAdditionalData(tokens.userId, tokens.serviceId, "datakey"))
+	if err != nil {
+		return storedTokens{}, err
+	}
+	tokens.envelope = envelope
+	return tokens, nil
+}
+
+// Binds a ciphertext to the column of the row storing it, a value copied to another row or column cannot be decrypted
+func columnAdditionalData(userId, serviceId, column string) string {
+	return "userservices/" + userId + "/" + serviceId + "/" + column
+}
+
+func (self *UserServiceRepository) encryptTokens(userId, serviceId, accessToken, refreshToken string) (storedTokens, error) {
+	envelope, dataKey, err := self.keyring.NewEnvelope(columnAdditionalData(userId, serviceId, "datakey"))
+	if err != nil {
+		return storedTokens{}, err
+	}
+
+	tokens := storedTokens{userId: userId, serviceId: serviceId, envelope: envelope}
+	tokens.accessToken, err = encryption.Encrypt(dataKey, accessToken, columnAdditionalData(userId, serviceId, "token"))
+	if err != nil {
+		return storedTokens{}, err
+	}
+	tokens.refreshToken, err = encryption.Encrypt(dataKey, refreshToken, columnAdditionalData(userId, serviceId, "tokenrefresh"))
+	if err != nil {
+		return storedTokens{}, err
+	}
+	return tokens, nil
+}
+
+// The tokens of a row without key id were stored before encryption and are returned as is
+func (self *UserServiceRepository) decryptTokens(userId, serviceId, accessToken, refreshToken string, envelope encryption.Envelope) (string, string, error) {
+	if envelope.KeyId == "" {
+		return accessToken, refreshToken, nil
+	}
+
+	dataKey, err := self.keyring.OpenEnvelope(envelope, columnAdditionalData(userId, serviceId, "datakey"))
+	if err != nil {
+		return "", "", err
+	}
+	accessToken, err = encryption.Decrypt(dataKey, accessToken, columnAdditionalData(userId, serviceId, "token"))
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err = encryption.Decrypt(dataKey, refreshToken, columnAdditionalData(userId, serviceId, "tokenrefresh"))
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
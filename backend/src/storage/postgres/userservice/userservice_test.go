@@ -1,34 +1,101 @@
 package userservice_repository
 
 import (
+	"bytes"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+
+	"backend/src/storage/encryption"
 )
 
+var testKeys = map[string][]byte{
+	"old": bytes.Repeat([]byte{1}, 32),
+	"new": bytes.Repeat([]byte{2}, 32),
+}
+
 func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *UserServiceRepository) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		test.Fatalf("Mock DB fail")
 	}
-	repo := NewUserServiceRepository(db)
+	repo := NewUserServiceRepository(db, encryption.NewKeyring("new", testKeys))
 	return db, mock, repo
 }
 
+// Tokens of the row of "userid" and "serviceid" as stored by a repository using the master key keyId
+func encryptedTokens(test *testing.T, keyId, accessToken, refreshToken string) storedTokens {
+	repo := NewUserServiceRepository(nil, encryption.NewKeyring(keyId, testKeys))
+	tokens, err := repo.encryptTokens("userid", "serviceid", accessToken, refreshToken)
+	if err != nil {
+		test.Fatalf("Encryption fail")
+	}
+	return tokens
+}
+
+// Records the arguments of a statement so that its ciphertexts can be checked once it ran
+type capturedArguments []driver.Value
+
+func (self *capturedArguments) arguments(count int) []driver.Value {
+	*self = make(capturedArguments, count)
+	arguments := []driver.Value{}
+	for index := 0; index < count; index++ {
+		arguments = append(arguments, capturingArgument{index: index, args: (*[]driver.Value)(self)})
+	}
+	return arguments
+}
+
+type capturingArgument struct {
+	index int
+	args  *[]driver.Value
+}
+
+func (self capturingArgument) Match(value driver.Value) bool {
+	(*self.args)[self.index] = value
+	return true
+}
+
+func openDataKey(test *testing.T, repo *UserServiceRepository, keyId, wrappedKey driver.Value) []byte {
+	dataKey, err := repo.keyring.OpenEnvelope(encryption.Envelope{KeyId: keyId.(string), DataKey: wrappedKey.(string)},
+		columnAdditionalData("userid", "serviceid", "datakey"))
+	if err != nil {
+		test.Fatalf("Data key fail")
+	}
+	return dataKey
+}
+
+func decrypt(test *testing.T, dataKey []byte, column string, ciphertext driver.Value) string {
+	plaintext, err := encryption.Decrypt(dataKey, ciphertext.(string), columnAdditionalData("userid", "serviceid", column))
+	if err != nil {
+		test.Fatalf("Decryption fail")
+	}
+	return plaintext
+}
+
 func TestCreateUserService(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
-	sqlStatement := `INSERT INTO userservices \(userid, token, tokenrefresh, expiry, serviceid\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`
+	var captured capturedArguments
+
+	sqlStatement := `INSERT INTO userservices \(userid, token, tokenrefresh, expiry, serviceid, keyid, datakey\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`
 	mock.ExpectExec(sqlStatement).
-		WithArgs("userid", "token", "tokenrefresh", "expiry", "serviceid").
+		WithArgs(captured.arguments(7)...).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.CreateUserService("userid", "token", "tokenrefresh", "expiry", "serviceid")
 
 	assert.NoError(test, err)
+	assert.Equal(test, []driver.Value{"userid", "expiry", "serviceid", "new"}, []driver.Value{captured[0], captured[3], captured[4], captured[5]})
+	assert.NotEqual(test, "token", captured[1])
+	dataKey := openDataKey(test, repo, captured[5], captured[6])
+	assert.Equal(test, "token", decrypt(test, dataKey, "token", captured[1]))
+	assert.Equal(test, "tokenrefresh", decrypt(test, dataKey, "tokenrefresh", captured[2]))
 
 	err = mock.ExpectationsWereMet()
 	if err != nil {
@@ -37,26 +104,250 @@ func TestCreateUserService(test *testing.T) {
 }
 
 func TestFindUserServiceByServiceIdandUserId(test *testing.T) {
+	columns := []string{"id", "userid", "accesstoken", "refreshtoken", "expirydate", "serviceid", "keyid", "datakey", "needsreauth", "scopes", "lastsuccessat", "lasterror", "lasterrorat"}
+	sqlStatement := `SELECT \* FROM userservices WHERE userid = \(\$1\) AND serviceid = \(\$2\)`
+	lastSuccessAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	test.Run("Encrypted Tokens", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		tokens := encryptedTokens(test, "old", "accesstoken", "refreshtoken")
+		mockRow := sqlmock.NewRows(columns).
+			AddRow("id", "userid", tokens.accessToken, tokens.refreshToken, "expirydate", "serviceid", tokens.envelope.KeyId, tokens.envelope.DataKey, false, "", nil, "", nil)
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("userid", "serviceid").
+			WillReturnRows(mockRow)
+
+		userService, err := repo.FindUserServiceByServiceIdandUserId("userid", "serviceid")
+
+		assert.NoError(test, err)
+		assert.Equal(test, "id", userService.Id)
+		assert.Equal(test, "userid", userService.UserId)
+		assert.Equal(test, "accesstoken", userService.AccessToken)
+		assert.Equal(test, "refreshtoken", userService.RefreshToken)
+		assert.Equal(test, "expirydate", userService.ExpiryDate)
+		assert.Equal(test, "serviceid", userService.ServiceId)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Plaintext Tokens", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		mockRow := sqlmock.NewRows(columns).
+			AddRow("id", "userid", "accesstoken", "refreshtoken", "expirydate", "serviceid", "", "", false, "repo user", lastSuccessAt, "API call failed (HTTP 401)", nil)
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("userid", "serviceid").
+			WillReturnRows(mockRow)
+
+		userService, err := repo.FindUserServiceByServiceIdandUserId("userid", "serviceid")
+
+		assert.NoError(test, err)
+		assert.Equal(test, "accesstoken", userService.AccessToken)
+		assert.Equal(test, "refreshtoken", userService.RefreshToken)
+		assert.Equal(test, "repo user", userService.Scopes)
+		assert.Equal(test, lastSuccessAt, userService.LastSuccessAt)
+		assert.Equal(test, "API call failed (HTTP 401)", userService.LastError)
+		assert.True(test, userService.LastErrorAt.IsZero())
+	})
+
+	test.Run("Unknown Key", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		tokens := encryptedTokens(test, "old", "accesstoken", "refreshtoken")
+		mockRow := sqlmock.NewRows(columns).
+			AddRow("id", "userid", tokens.accessToken, tokens.refreshToken, "expirydate", "serviceid", "retired", tokens.envelope.DataKey, false, "", nil, "", nil)
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("userid", "serviceid").
+			WillReturnRows(mockRow)
+
+		userService, err := repo.FindUserServiceByServiceIdandUserId("userid", "serviceid")
+
+		assert.Error(test, err)
+		assert.Empty(test, userService.AccessToken)
+	})
+	test.Run("Tokens Of Another Row", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		tokens := encryptedTokens(test, "old", "accesstoken", "refreshtoken")
+		mockRow := sqlmock.NewRows(columns).
+			AddRow("id", "userid", tokens.accessToken, tokens.refreshToken, "expirydate", "otherservice", tokens.envelope.KeyId, tokens.envelope.DataKey, false, "", nil, "", nil)
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("userid", "otherservice").
+			WillReturnRows(mockRow)
+
+		userService, err := repo.FindUserServiceByServiceIdandUserId("userid", "otherservice")
+
+		assert.Error(test, err)
+		assert.Empty(test, userService.AccessToken)
+	})
+
+	test.Run("Swapped Columns", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		tokens := encryptedTokens(test, "old", "accesstoken", "refreshtoken")
+		mockRow := sqlmock.NewRows(columns).
+			AddRow("id", "userid", tokens.refreshToken, tokens.accessToken, "expirydate", "serviceid", tokens.envelope.KeyId, tokens.envelope.DataKey, false, "", nil, "", nil)
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("userid", "serviceid").
+			WillReturnRows(mockRow)
+
+		_, err := repo.FindUserServiceByServiceIdandUserId("userid", "serviceid")
+
+		assert.Error(test, err)
+	})
+}
+
+func TestFindUserServicesByUserId(test *testing.T) {
+	columns := []string{"id", "userid", "accesstoken", "refreshtoken", "expirydate", "serviceid", "keyid", "datakey", "needsreauth", "scopes", "lastsuccessat", "lasterror", "lasterrorat"}
+	sqlStatement := `SELECT \* FROM userservices WHERE userid = \(\$1\)`
+
+	test.Run("Successful", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		tokens := encryptedTokens(test, "old", "accesstoken", "refreshtoken")
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("userid").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "userid", tokens.accessToken, tokens.refreshToken, "expirydate", "serviceid", tokens.envelope.KeyId, tokens.envelope.DataKey, true, "", nil, "", nil).
+				AddRow("2", "userid", tokens.accessToken, tokens.refreshToken, "expirydate", "serviceid", "retired", tokens.envelope.DataKey, false, "", nil, "", nil))
+
+		userServices, err := repo.FindUserServicesByUserId("userid")
+
+		assert.NoError(test, err)
+		assert.Len(test, userServices, 1)
+		assert.Equal(test, "1", userServices[0].Id)
+		assert.True(test, userServices[0].NeedsReauth)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Query error", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("userid").
+			WillReturnError(errors.New("Query error"))
+
+		_, err := repo.FindUserServicesByUserId("userid")
+
+		assert.EqualError(test, err, "Query error")
+	})
+}
+
+func TestFindUserServicesExpiringBefore(test *testing.T) {
+	columns := []string{"id", "userid", "accesstoken", "refreshtoken", "expirydate", "serviceid", "keyid", "datakey", "needsreauth", "scopes", "lastsuccessat", "lasterror", "lasterrorat"}
+	sqlStatement := `SELECT \* FROM userservices WHERE NOT needsreauth AND CAST\(expiry AS timestamptz\) <= \(\$1\)`
+	deadline := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	test.Run("Successful", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		tokens := encryptedTokens(test, "old", "accesstoken", "refreshtoken")
+		mock.ExpectQuery(sqlStatement).
+			WithArgs(deadline).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "userid", tokens.accessToken, tokens.refreshToken, "expirydate", "serviceid", tokens.envelope.KeyId, tokens.envelope.DataKey, false, "", nil, "", nil).
+				AddRow("2", "userid", tokens.accessToken, tokens.refreshToken, "expirydate", "serviceid", "retired", tokens.envelope.DataKey, false, "", nil, "", nil).
+				AddRow("3", "userid", "plaintoken", "plainrefresh", "expirydate", "serviceid", "", "", false, "", nil, "", nil))
+
+		userServices, err := repo.FindUserServicesExpiringBefore(deadline)
+
+		assert.NoError(test, err)
+		assert.Len(test, userServices, 2)
+		assert.Equal(test, "refreshtoken", userServices[0].RefreshToken)
+		assert.Equal(test, "plainrefresh", userServices[1].RefreshToken)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Query error", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs(deadline).
+			WillReturnError(errors.New("Query error"))
+
+		_, err := repo.FindUserServicesExpiringBefore(deadline)
+
+		assert.EqualError(test, err, "Query error")
+	})
+}
+
+func TestMarkUserServiceNeedsReauth(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
-	sqlStatement := `SELECT \* FROM userservices WHERE userid = \(\$1\) AND serviceid = \(\$2\)`
-	mockRow := sqlmock.NewRows([]string{"id", "userid", "accesstoken", "refreshtoken", "expirydate", "serviceid"}).
-		AddRow("id", "userid", "accesstoken", "refreshtoken", "expirydate", "serviceid")
-
-	mock.ExpectQuery(sqlStatement).
+	sqlStatement := `UPDATE userservices SET needsreauth = true WHERE userid = \(\$1\) AND serviceid = \(\$2\)`
+	mock.ExpectExec(sqlStatement).
 		WithArgs("userid", "serviceid").
-		WillReturnRows(mockRow)
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.MarkUserServiceNeedsReauth("userid", "serviceid")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestUpdateUserServiceScopes(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `UPDATE userservices SET scopes = \(\$1\) WHERE userid = \(\$2\) AND serviceid = \(\$3\)`
+	mock.ExpectExec(sqlStatement).
+		WithArgs("repo user", "userid", "serviceid").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdateUserServiceScopes("userid", "serviceid", "repo user")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestRecordUserServiceSuccess(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
 
-	userService, err := repo.FindUserServiceByServiceIdandUserId("userid", "serviceid")
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sqlStatement := `UPDATE userservices SET lastsuccessat = \(\$1\) WHERE userid = \(\$2\) AND serviceid = \(\$3\)`
+	mock.ExpectExec(sqlStatement).
+		WithArgs(at, "userid", "serviceid").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.RecordUserServiceSuccess("userid", "serviceid", at)
 
 	assert.NoError(test, err)
-	assert.Equal(test, "id", userService.Id)
-	assert.Equal(test, "userid", userService.UserId)
-	assert.Equal(test, "accesstoken", userService.AccessToken)
-	assert.Equal(test, "refreshtoken", userService.RefreshToken)
-	assert.Equal(test, "expirydate", userService.ExpiryDate)
-	assert.Equal(test, "serviceid", userService.ServiceId)
 
 	err = mock.ExpectationsWereMet()
 	if err != nil {
@@ -64,27 +355,66 @@ func TestFindUserServiceByServiceIdandUserId(test *testing.T) {
 	}
 }
 
+func TestRecordUserServiceError(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sqlStatement := `UPDATE userservices SET lasterror = \(\$1\), lasterrorat = \(\$2\) WHERE userid = \(\$3\) AND serviceid = \(\$4\)`
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("API call failed (HTTP 401)", at, "userid", "serviceid").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.RecordUserServiceError("userid", "serviceid", "API call failed (HTTP 401)", at)
+
+		assert.NoError(test, err)
+	})
+
+	test.Run("Exec error", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("API call failed (HTTP 401)", at, "userid", "serviceid").
+			WillReturnError(errors.New("Exec error"))
+
+		err := repo.RecordUserServiceError("userid", "serviceid", "API call failed (HTTP 401)", at)
+
+		assert.EqualError(test, err, "Exec error")
+	})
+
+	err := mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
 func TestUpdateUserServiceByServiceIdAndUserId(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
 	test.Run("Successful", func(test *testing.T) {
+		var captured capturedArguments
+
 		findSqlStatement := `SELECT \* FROM userservices WHERE userid = \(\$1\) AND serviceid = \(\$2\)`
-		mockRow := sqlmock.NewRows([]string{"id", "userid", "accesstoken", "refreshtoken", "expirydate", "serviceid"}).
-			AddRow("id", "userid", "oldtoken", "oldtokenrefresh", "expirydate", "serviceid")
+		mockRow := sqlmock.NewRows([]string{"id", "userid", "accesstoken", "refreshtoken", "expirydate", "serviceid", "keyid", "datakey", "needsreauth", "scopes", "lastsuccessat", "lasterror", "lasterrorat"}).
+			AddRow("id", "userid", "oldtoken", "oldtokenrefresh", "expirydate", "serviceid", "", "", false, "", nil, "", nil)
 
 		mock.ExpectQuery(findSqlStatement).
 			WithArgs("userid", "serviceid").
 			WillReturnRows(mockRow)
 
-		updateSqlStatement := `UPDATE userservices SET token = \(\$1\), tokenrefresh = \(\$2\), expiry = \(\$3\) WHERE userid = \(\$4\) AND serviceid = \(\$5\)`
+		updateSqlStatement := `UPDATE userservices SET token = \(\$1\), tokenrefresh = \(\$2\), expiry = \(\$3\), keyid = \(\$4\), datakey = \(\$5\), needsreauth = false WHERE userid = \(\$6\) AND serviceid = \(\$7\)`
 		mock.ExpectExec(updateSqlStatement).
-			WithArgs("token", "tokenrefresh", "expiry", "userid", "serviceid").
+			WithArgs(captured.arguments(7)...).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		err := repo.UpdateUserServiceByServiceIdAndUserId("userid", "token", "tokenrefresh", "expiry", "serviceid")
 
 		assert.NoError(test, err)
+		assert.Equal(test, []driver.Value{"expiry", "new", "userid", "serviceid"}, []driver.Value{captured[2], captured[3], captured[5], captured[6]})
+		dataKey := openDataKey(test, repo, captured[3], captured[4])
+		assert.Equal(test, "token", decrypt(test, dataKey, "token", captured[0]))
+		assert.Equal(test, "tokenrefresh", decrypt(test, dataKey, "tokenrefresh", captured[1]))
 
 		err = mock.ExpectationsWereMet()
 		if err != nil {
@@ -93,9 +423,9 @@ func TestUpdateUserServiceByServiceIdAndUserId(test *testing.T) {
 	})
 
 	test.Run("Service doesn't exist for user", func(test *testing.T) {
-		updateSqlStatement := `UPDATE userservices SET token = \(\$1\), tokenrefresh = \(\$2\), expiry = \(\$3\) WHERE userid = \(\$4\) AND serviceid = \(\$5\)`
+		updateSqlStatement := `UPDATE userservices SET token = \(\$1\), tokenrefresh = \(\$2\), expiry = \(\$3\), keyid = \(\$4\), datakey = \(\$5\), needsreauth = false WHERE userid = \(\$6\) AND serviceid = \(\$7\)`
 		mock.ExpectExec(updateSqlStatement).
-			WithArgs("token", "tokenrefresh", "expiry", "userid", "serviceid").
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "expiry", "new", sqlmock.AnyArg(), "userid", "serviceid").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		err := repo.UpdateUserServiceByServiceIdAndUserId("userid", "token", "tokenrefresh", "expiry", "serviceid")
@@ -125,3 +455,123 @@ func TestDeleteUserServiceByUserId(test *testing.T) {
 		test.Errorf("Expectation fail")
 	}
 }
+
+func TestDeleteUserServiceByServiceIdAndUserId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `DELETE FROM userservices WHERE userid = \(\$1\) AND serviceid = \(\$2\)`
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("userid", "serviceid").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.DeleteUserServiceByServiceIdAndUserId("userid", "serviceid")
+
+		assert.NoError(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Not found", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("userid", "serviceid").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteUserServiceByServiceIdAndUserId("userid", "serviceid")
+
+		assert.EqualError(test, err, "Could not delete user service")
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestRotateUserServiceKeys(test *testing.T) {
+	sqlStatement := `SELECT id, userid, serviceid, token, tokenrefresh, keyid, datakey FROM userservices WHERE keyid <> \(\$1\)`
+	updateSqlStatement := `UPDATE userservices SET token = \(\$1\), tokenrefresh = \(\$2\), keyid = \(\$3\), datakey = \(\$4\) WHERE id = \(\$5\) AND keyid = \(\$6\)`
+	columns := []string{"id", "userid", "serviceid", "token", "tokenrefresh", "keyid", "datakey"}
+
+	test.Run("Successful", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		tokens := encryptedTokens(test, "old", "accesstoken", "refreshtoken")
+		var rewrapped, encrypted capturedArguments
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("new").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "userid", "serviceid", tokens.accessToken, tokens.refreshToken, tokens.envelope.KeyId, tokens.envelope.DataKey).
+				AddRow("2", "userid", "serviceid", "plaintoken", "plainrefresh", "", "").
+				AddRow("3", "userid", "serviceid", tokens.accessToken, tokens.refreshToken, tokens.envelope.KeyId, tokens.envelope.DataKey))
+		mock.ExpectExec(updateSqlStatement).
+			WithArgs(rewrapped.arguments(6)...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(updateSqlStatement).
+			WithArgs(encrypted.arguments(6)...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(updateSqlStatement).
+			WithArgs(tokens.accessToken, tokens.refreshToken, "new", sqlmock.AnyArg(), "3", "old").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		rotated, err := repo.RotateUserServiceKeys()
+
+		assert.NoError(test, err)
+		assert.Equal(test, 2, rotated)
+		assert.Equal(test, []driver.Value{tokens.accessToken, tokens.refreshToken, "new", "1", "old"},
+			[]driver.Value{rewrapped[0], rewrapped[1], rewrapped[2], rewrapped[4], rewrapped[5]})
+		rewrappedKey := openDataKey(test, repo, rewrapped[2], rewrapped[3])
+		assert.Equal(test, "accesstoken", decrypt(test, rewrappedKey, "token", tokens.accessToken))
+		assert.Equal(test, []driver.Value{"new", "2", ""}, []driver.Value{encrypted[2], encrypted[4], encrypted[5]})
+		dataKey := openDataKey(test, repo, encrypted[2], encrypted[3])
+		assert.Equal(test, "plaintoken", decrypt(test, dataKey, "token", encrypted[0]))
+		assert.Equal(test, "plainrefresh", decrypt(test, dataKey, "tokenrefresh", encrypted[1]))
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Unknown Key", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		tokens := encryptedTokens(test, "old", "accesstoken", "refreshtoken")
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("new").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "userid", "serviceid", tokens.accessToken, tokens.refreshToken, "retired", tokens.envelope.DataKey))
+
+		rotated, err := repo.RotateUserServiceKeys()
+
+		assert.Error(test, err)
+		assert.Equal(test, 0, rotated)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Fail Find", func(test *testing.T) {
+		db, mock, repo := createMockDb(test)
+		defer db.Close()
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("new").
+			WillReturnError(errors.New("Fail find"))
+
+		_, err := repo.RotateUserServiceKeys()
+
+		assert.EqualError(test, err, "Fail find")
+	})
+}
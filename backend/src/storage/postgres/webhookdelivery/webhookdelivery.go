@@ -0,0 +1,60 @@
+package webhook_delivery_repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/src/entities"
+)
+
+type WebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+// Returns an empty id when the delivery id was already received since duplicateSince, an older delivery is replaced
+// The unique index on the delivery id makes concurrent receptions of the same delivery wait for each other
+func (self *WebhookDeliveryRepository) CreateWebhookDelivery(serviceName, deliveryId string, headers http.Header, body []byte, duplicateSince time.Time) (string, error) {
+	sqlStatement := `INSERT INTO webhook_deliveries (servicename, deliveryid, headers, body) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (servicename, deliveryid) DO UPDATE SET id = gen_random_uuid(), headers = EXCLUDED.headers, body = EXCLUDED.body, receivedat = now()
+	WHERE webhook_deliveries.receivedat < ($5) RETURNING id`
+	var id string
+
+	headersJson, err := json.Marshal(headers)
+	if err != nil {
+		return "", err
+	}
+
+	err = self.db.QueryRow(sqlStatement, serviceName, deliveryId, headersJson, body, duplicateSince).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (self *WebhookDeliveryRepository) FindWebhookDeliveryById(id string) (entities.WebhookDelivery, error) {
+	sqlStatement := `SELECT * FROM webhook_deliveries WHERE id = ($1)`
+	var delivery entities.WebhookDelivery
+	var headersBytes []byte
+
+	row := self.db.QueryRow(sqlStatement, id)
+	err := row.Scan(&delivery.Id, &delivery.ServiceName, &delivery.DeliveryId, &headersBytes, &delivery.Body, &delivery.ReceivedAt)
+	if err != nil {
+		return delivery, err
+	}
+
+	err = json.Unmarshal(headersBytes, &delivery.Headers)
+	if err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
@@ -0,0 +1,123 @@
+package webhook_delivery_repository
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *WebhookDeliveryRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		test.Fatalf("Mock DB fail")
+	}
+	repo := NewWebhookDeliveryRepository(db)
+	return db, mock, repo
+}
+
+func TestCreateWebhookDelivery(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	since := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	headers := http.Header{"X-Github-Event": []string{"watch"}}
+	sqlStatement := `INSERT INTO webhook_deliveries \(servicename, deliveryid, headers, body\) VALUES \(\$1, \$2, \$3, \$4\)
+	ON CONFLICT \(servicename, deliveryid\) DO UPDATE SET id = gen_random_uuid\(\), headers = EXCLUDED.headers, body = EXCLUDED.body, receivedat = now\(\)
+	WHERE webhook_deliveries.receivedat < \(\$5\) RETURNING id`
+
+	test.Run("New delivery", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Github", "delivery", []byte(`{"X-Github-Event":["watch"]}`), []byte(`{}`), since).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+
+		id, err := repo.CreateWebhookDelivery("Github", "delivery", headers, []byte(`{}`), since)
+
+		assert.NoError(test, err)
+		assert.Equal(test, "1", id)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Duplicate delivery", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Github", "delivery", []byte(`{"X-Github-Event":["watch"]}`), []byte(`{}`), since).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		id, err := repo.CreateWebhookDelivery("Github", "delivery", headers, []byte(`{}`), since)
+
+		assert.NoError(test, err)
+		assert.Empty(test, id)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Query error", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("Github", "delivery", []byte(`{"X-Github-Event":["watch"]}`), []byte(`{}`), since).
+			WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.CreateWebhookDelivery("Github", "delivery", headers, []byte(`{}`), since)
+
+		assert.Error(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestFindWebhookDeliveryById(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT \* FROM webhook_deliveries WHERE id = \(\$1\)`
+	receivedAt := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	test.Run("Successful", func(test *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "servicename", "deliveryid", "headers", "body", "receivedat"}).
+			AddRow("1", "Github", "delivery", []byte(`{"X-Github-Event":["watch"]}`), []byte(`{}`), receivedAt)
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("1").
+			WillReturnRows(rows)
+
+		delivery, err := repo.FindWebhookDeliveryById("1")
+
+		assert.NoError(test, err)
+		assert.Equal(test, "Github", delivery.ServiceName)
+		assert.Equal(test, "delivery", delivery.DeliveryId)
+		assert.Equal(test, "watch", delivery.Headers.Get("X-GitHub-Event"))
+		assert.Equal(test, []byte(`{}`), delivery.Body)
+		assert.Equal(test, receivedAt, delivery.ReceivedAt)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Not found", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("2").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FindWebhookDeliveryById("2")
+
+		assert.ErrorIs(test, err, sql.ErrNoRows)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
@@ -3,7 +3,9 @@ package workflow_repository
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"backend/src/entities"
 )
@@ -66,7 +68,9 @@ func appendWorkflowsSlices(rows *sql.Rows) ([]entities.Workflow, error) {
 		var actionDataBytes []byte
 
 		err := rows.Scan(&workflow.Id, &workflow.Name, &workflow.OwnerId, &workflow.ActionId,
-			&workflow.ReactionId, &workflow.IsActivated, &workflow.CreatedAt, &actionParamBytes, &reactionParamBytes, &actionDataBytes)
+			&workflow.ReactionId, &workflow.IsActivated, &workflow.CreatedAt, &actionParamBytes, &reactionParamBytes, &actionDataBytes, &workflow.Filter, &workflow.CatchUpPolicy,
+			&workflow.ConsecutiveFailures, &workflow.SuspendedReason, &workflow.PollInterval, &workflow.NextCheckAt,
+			&workflow.ConsecutivePollFailures)
 		if err != nil {
 			return nil, err
 		}
@@ -81,30 +85,30 @@ func appendWorkflowsSlices(rows *sql.Rows) ([]entities.Workflow, error) {
 	return workflows, nil
 }
 
-func (self *WorkflowRepository) CreateWorkflow(name, ownerId, actionId, reactionId string, actionParam, reactionParam, actionData map[string]interface{}) error {
-	sqlStatement := `INSERT INTO workflows (name, ownerid, actionid, reactionid, isactivated, actionparam, reactionparam, actiondata) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+func (self *WorkflowRepository) CreateWorkflow(name, ownerId, actionId, reactionId, filter, catchUpPolicy string, pollInterval int, actionParam, reactionParam, actionData map[string]interface{}) (string, error) {
+	sqlStatement := `INSERT INTO workflows (name, ownerid, actionid, reactionid, isactivated, actionparam, reactionparam, actiondata, filter, catchuppolicy, pollinterval) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`
+	var workflowId string
 
 	actionParamJson, reactionParamJson, actionDataJson, err := marshalWorkflowParameters(actionParam, reactionParam, actionData)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	_, err = self.db.Exec(sqlStatement, name, ownerId, actionId, reactionId, true, actionParamJson, reactionParamJson, actionDataJson)
+	err = self.db.QueryRow(sqlStatement, name, ownerId, actionId, reactionId, true, actionParamJson, reactionParamJson, actionDataJson, filter, catchUpPolicy, pollInterval).Scan(&workflowId)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return workflowId, nil
 }
 
-func (self *WorkflowRepository) FindWorkflowById(id string) (entities.Workflow, error) {
-	sqlStatement := `SELECT * FROM workflows WHERE id = ($1)`
+func scanWorkflow(row *sql.Row) (entities.Workflow, error) {
 	var workflow entities.Workflow
 	var actionParamBytes, reactionParamBytes, actionDataBytes []byte
 
-	row := self.db.QueryRow(sqlStatement, id)
-
 	err := row.Scan(&workflow.Id, &workflow.Name, &workflow.OwnerId, &workflow.ActionId,
-		&workflow.ReactionId, &workflow.IsActivated, &workflow.CreatedAt, &actionParamBytes, &reactionParamBytes, &actionDataBytes)
+		&workflow.ReactionId, &workflow.IsActivated, &workflow.CreatedAt, &actionParamBytes, &reactionParamBytes, &actionDataBytes, &workflow.Filter, &workflow.CatchUpPolicy,
+		&workflow.ConsecutiveFailures, &workflow.SuspendedReason, &workflow.PollInterval, &workflow.NextCheckAt,
+		&workflow.ConsecutivePollFailures)
 	if err != nil {
 		return workflow, err
 	}
@@ -116,6 +120,23 @@ func (self *WorkflowRepository) FindWorkflowById(id string) (entities.Workflow,
 	return workflow, nil
 }
 
+func (self *WorkflowRepository) FindWorkflowById(id string) (entities.Workflow, error) {
+	sqlStatement := `SELECT * FROM workflows WHERE id = ($1)`
+
+	workflow, err := scanWorkflow(self.db.QueryRow(sqlStatement, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return workflow, entities.WorkflowNotFoundError{}
+	}
+	return workflow, err
+}
+
+// The token of an incoming webhook is kept in the action data of its workflow
+func (self *WorkflowRepository) FindWorkflowByWebhookToken(token string) (entities.Workflow, error) {
+	sqlStatement := `SELECT * FROM workflows WHERE actiondata->>'webhooktoken' = ($1)`
+
+	return scanWorkflow(self.db.QueryRow(sqlStatement, token))
+}
+
 func (self *WorkflowRepository) FindWorkflowsByActionId(actionId string) ([]entities.Workflow, error) {
 	sqlStatement := `SELECT * FROM workflows WHERE actionid = ($1)`
 
@@ -132,6 +153,23 @@ func (self *WorkflowRepository) FindWorkflowsByActionId(actionId string) ([]enti
 	return workflows, nil
 }
 
+// The activated workflows of the action which next check is due at now
+func (self *WorkflowRepository) FindDueWorkflowsByActionId(actionId string, now time.Time) ([]entities.Workflow, error) {
+	sqlStatement := `SELECT * FROM workflows WHERE actionid = ($1) AND isactivated AND nextcheckat <= ($2)`
+
+	rows, errQuery := self.db.Query(sqlStatement, actionId, now)
+	if errQuery != nil {
+		return nil, errQuery
+	}
+	defer rows.Close()
+
+	workflows, err := appendWorkflowsSlices(rows)
+	if err != nil {
+		return nil, err
+	}
+	return workflows, nil
+}
+
 func (self *WorkflowRepository) FindWorkflowsByOwnerId(userId string) ([]entities.Workflow, error) {
 	sqlStatement := `SELECT * FROM workflows WHERE ownerid = ($1)`
 
@@ -150,20 +188,136 @@ func (self *WorkflowRepository) FindWorkflowsByOwnerId(userId string) ([]entitie
 }
 
 func (self *WorkflowRepository) UpdateWorkflow(id string, updatedWorkflow entities.Workflow) error {
-	sqlStatement := `UPDATE workflows SET name = ($1), actionid = ($2), reactionid = ($3), isactivated = ($4), actionparam = ($5), reactionparam = ($6), actiondata = ($7) WHERE id = ($8)`
+	// Activating a suspended workflow clears its suspension and its failures, the other updates keep them
+	// Changing the action or the poll interval makes the workflow due to be checked
+	sqlStatement := `UPDATE workflows SET name = ($1), actionid = ($2), reactionid = ($3), isactivated = ($4), actionparam = ($5), reactionparam = ($6), actiondata = ($7), filter = ($8), catchuppolicy = ($9),
+	consecutivefailures = CASE WHEN ($4) AND NOT isactivated THEN 0 ELSE consecutivefailures END,
+	consecutivepollfailures = CASE WHEN ($4) AND NOT isactivated THEN 0 ELSE consecutivepollfailures END, suspendedreason = CASE WHEN ($4) THEN '' ELSE suspendedreason END,
+	pollinterval = ($11), nextcheckat = CASE WHEN actionid <> ($2) OR pollinterval <> ($11) THEN now() ELSE nextcheckat END WHERE id = ($10)`
 
 	actionParamJson, reactionParamJson, actionDataJson, err := marshalWorkflowParameters(updatedWorkflow.ActionParam, updatedWorkflow.ReactionParam, updatedWorkflow.ActionData)
 	if err != nil {
 		return err
 	}
 
-	_, err = self.db.Exec(sqlStatement, updatedWorkflow.Name, updatedWorkflow.ActionId, updatedWorkflow.ReactionId, updatedWorkflow.IsActivated, actionParamJson, reactionParamJson, actionDataJson, id)
+	_, err = self.db.Exec(sqlStatement, updatedWorkflow.Name, updatedWorkflow.ActionId, updatedWorkflow.ReactionId, updatedWorkflow.IsActivated, actionParamJson, reactionParamJson, actionDataJson, updatedWorkflow.Filter, updatedWorkflow.CatchUpPolicy, id, updatedWorkflow.PollInterval)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// Saves the state kept by the action between two checks, the other fields edited by the user are left as is
+func (self *WorkflowRepository) UpdateWorkflowActionData(id string, actionData map[string]interface{}) error {
+	sqlStatement := `UPDATE workflows SET actiondata = ($2) WHERE id = ($1)`
+
+	actionDataJson, err := json.Marshal(actionData)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, id, actionDataJson)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Returns the number of consecutive failures including this one
+func (self *WorkflowRepository) IncrementWorkflowFailures(id string) (int, error) {
+	sqlStatement := `UPDATE workflows SET consecutivefailures = consecutivefailures + 1 WHERE id = ($1) RETURNING consecutivefailures`
+	var failures int
+
+	err := self.db.QueryRow(sqlStatement, id).Scan(&failures)
+	if err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (self *WorkflowRepository) ResetWorkflowFailures(id string) error {
+	sqlStatement := `UPDATE workflows SET consecutivefailures = 0 WHERE id = ($1) AND consecutivefailures > 0`
+
+	_, err := self.db.Exec(sqlStatement, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Returns the number of consecutive failed checks of the action including this one
+func (self *WorkflowRepository) IncrementWorkflowPollFailures(id string) (int, error) {
+	sqlStatement := `UPDATE workflows SET consecutivepollfailures = consecutivepollfailures + 1 WHERE id = ($1) RETURNING consecutivepollfailures`
+	var failures int
+
+	err := self.db.QueryRow(sqlStatement, id).Scan(&failures)
+	if err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (self *WorkflowRepository) ResetWorkflowPollFailures(id string) error {
+	sqlStatement := `UPDATE workflows SET consecutivepollfailures = 0 WHERE id = ($1) AND consecutivepollfailures > 0`
+
+	_, err := self.db.Exec(sqlStatement, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Moves the next check of a due workflow to nextCheckAt, returns false when the workflow is not due anymore
+func (self *WorkflowRepository) ClaimWorkflowCheck(id string, now, nextCheckAt time.Time) (bool, error) {
+	sqlStatement := `UPDATE workflows SET nextcheckat = ($3) WHERE id = ($1) AND isactivated AND nextcheckat <= ($2)`
+
+	res, err := self.db.Exec(sqlStatement, id, now, nextCheckAt)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Returns false when the workflow was already deactivated
+func (self *WorkflowRepository) SuspendWorkflow(id, reason string) (bool, error) {
+	sqlStatement := `UPDATE workflows SET isactivated = false, suspendedreason = ($2) WHERE id = ($1) AND isactivated`
+
+	res, err := self.db.Exec(sqlStatement, id, reason)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Deactivates the active workflows of the owner whose action or one of whose reactions belongs to the service
+func (self *WorkflowRepository) SuspendWorkflowsByOwnerIdAndServiceId(ownerId, serviceId, reason string) (int, error) {
+	sqlStatement := `UPDATE workflows SET isactivated = false, suspendedreason = ($3) WHERE ownerid = ($1) AND isactivated AND (
+	actionid IN (SELECT id FROM actions WHERE serviceid = ($2))
+	OR reactionid IN (SELECT id FROM reactions WHERE serviceid = ($2))
+	OR id IN (SELECT workflowid FROM workflow_reactions WHERE reactionid IN (SELECT id FROM reactions WHERE serviceid = ($2))))`
+
+	res, err := self.db.Exec(sqlStatement, ownerId, serviceId, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
 func (self *WorkflowRepository) DeleteWorkflow(id, ownerId string) error {
 	sqlStatement := `DELETE FROM workflows WHERE id = ($1) AND ownerid = ($2)`
 
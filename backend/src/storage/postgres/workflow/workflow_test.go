@@ -3,6 +3,7 @@ package workflow_repository
 import (
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -32,18 +33,19 @@ func TestCreateWorkflow(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
-	sqlStatement := `INSERT INTO workflows \(name, ownerid, actionid, reactionid, isactivated, actionparam, reactionparam, actiondata\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\)`
-	mock.ExpectExec(sqlStatement).
-		WithArgs("workflow", "owner", "action", "reaction", true, []byte("{\"key\":\"value\"}"), []byte("{\"key\":\"value\"}"), []byte("{\"key\":\"value\"}")).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlStatement := `INSERT INTO workflows \(name, ownerid, actionid, reactionid, isactivated, actionparam, reactionparam, actiondata, filter, catchuppolicy, pollinterval\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9, \$10, \$11\) RETURNING id`
+	mock.ExpectQuery(sqlStatement).
+		WithArgs("workflow", "owner", "action", "reaction", true, []byte("{\"key\":\"value\"}"), []byte("{\"key\":\"value\"}"), []byte("{\"key\":\"value\"}"), "title contains \"release\"", "all", 300).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1234"))
 
 	actionParam := map[string]interface{}{"key": "value"}
 	reactionParam := map[string]interface{}{"key": "value"}
 	actionData := map[string]interface{}{"key": "value"}
 
-	err := repo.CreateWorkflow("workflow", "owner", "action", "reaction", actionParam, reactionParam, actionData)
+	workflowId, err := repo.CreateWorkflow("workflow", "owner", "action", "reaction", "title contains \"release\"", "all", 300, actionParam, reactionParam, actionData)
 
 	assert.NoError(test, err)
+	assert.Equal(test, "1234", workflowId)
 
 	err = mock.ExpectationsWereMet()
 	if err != nil {
@@ -60,9 +62,9 @@ func TestFindWorkflowById(test *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"id", "name", "ownerid", "actionid", "reactionid", "isactivated", "createdat",
-		"actionparam", "reactionparam", "actiondata",
+		"actionparam", "reactionparam", "actiondata", "filter", "catchuppolicy", "consecutivefailures", "suspendedreason", "pollinterval", "nextcheckat", "consecutivepollfailures",
 	}).AddRow(id, "workflow", "owner", "action", "reaction", true, "createdat",
-		[]byte(`{"key":"value"}`), []byte(`{"key":"value"}`), []byte(`{"key":"value"}`),
+		[]byte(`{"key":"value"}`), []byte(`{"key":"value"}`), []byte(`{"key":"value"}`), "", "once", 10, "Suspended after 10 consecutive failures", 3600, "nextcheckat", 2,
 	)
 
 	mock.ExpectQuery(sqlStatement).
@@ -73,6 +75,30 @@ func TestFindWorkflowById(test *testing.T) {
 
 	assert.NoError(test, err)
 	assertWorkflow(test, workflow, id, "workflow", "owner", "action", "reaction", true)
+	assert.Equal(test, "once", workflow.CatchUpPolicy)
+	assert.Equal(test, 10, workflow.ConsecutiveFailures)
+	assert.Equal(test, "Suspended after 10 consecutive failures", workflow.SuspendedReason)
+	assert.Equal(test, 3600, workflow.PollInterval)
+	assert.Equal(test, 2, workflow.ConsecutivePollFailures)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestFindWorkflowByIdNotFound(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT \* FROM workflows WHERE id = \(\$1\)`
+	mock.ExpectQuery(sqlStatement).
+		WithArgs("1234").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.FindWorkflowById("1234")
+
+	assert.ErrorIs(test, err, entities.WorkflowNotFoundError{})
 
 	err = mock.ExpectationsWereMet()
 	if err != nil {
@@ -80,6 +106,52 @@ func TestFindWorkflowById(test *testing.T) {
 	}
 }
 
+func TestFindWorkflowByWebhookToken(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT \* FROM workflows WHERE actiondata->>'webhooktoken' = \(\$1\)`
+
+	test.Run("Successful", func(test *testing.T) {
+		rows := sqlmock.NewRows([]string{
+			"id", "name", "ownerid", "actionid", "reactionid", "isactivated", "createdat",
+			"actionparam", "reactionparam", "actiondata", "filter", "catchuppolicy", "consecutivefailures", "suspendedreason", "pollinterval", "nextcheckat", "consecutivepollfailures",
+		}).AddRow("1234", "workflow", "owner", "action", "reaction", true, "createdat",
+			[]byte(`{"secret":""}`), []byte(`{"key":"value"}`), []byte(`{"webhooktoken":"token"}`), "", "once", 0, "", 0, "nextcheckat", 0,
+		)
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("token").
+			WillReturnRows(rows)
+
+		workflow, err := repo.FindWorkflowByWebhookToken("token")
+
+		assert.NoError(test, err)
+		assertWorkflow(test, workflow, "1234", "workflow", "owner", "action", "reaction", true)
+		assert.Equal(test, "token", workflow.ActionData["webhooktoken"])
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Unknown token", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("unknown").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FindWorkflowByWebhookToken("unknown")
+
+		assert.ErrorIs(test, err, sql.ErrNoRows)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
 func TestFindWorkflowsByActionId(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
@@ -89,9 +161,9 @@ func TestFindWorkflowsByActionId(test *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"id", "name", "ownerid", "actionid", "reactionid", "isactivated", "createdat",
-		"actionparam", "reactionparam", "actiondata",
+		"actionparam", "reactionparam", "actiondata", "filter", "catchuppolicy", "consecutivefailures", "suspendedreason", "pollinterval", "nextcheckat", "consecutivepollfailures",
 	}).AddRow(idAction, "workflow", "owner", "action", "reaction", true, "createdat",
-		[]byte(`{"key":"value"}`), []byte(`{"key":"value"}`), []byte(`{"key":"value"}`),
+		[]byte(`{"key":"value"}`), []byte(`{"key":"value"}`), []byte(`{"key":"value"}`), "", "once", 0, "", 0, "nextcheckat", 0,
 	)
 
 	mock.ExpectQuery(sqlStatement).
@@ -109,11 +181,80 @@ func TestFindWorkflowsByActionId(test *testing.T) {
 	}
 }
 
+func TestFindDueWorkflowsByActionId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	now := time.Now()
+	sqlStatement := `SELECT \* FROM workflows WHERE actionid = \(\$1\) AND isactivated AND nextcheckat <= \(\$2\)`
+
+	rows := sqlmock.NewRows([]string{
+		"id", "name", "ownerid", "actionid", "reactionid", "isactivated", "createdat",
+		"actionparam", "reactionparam", "actiondata", "filter", "catchuppolicy", "consecutivefailures", "suspendedreason", "pollinterval", "nextcheckat", "consecutivepollfailures",
+	}).AddRow("1", "workflow", "owner", "action", "reaction", true, "createdat",
+		[]byte(`{"key":"value"}`), []byte(`{"key":"value"}`), []byte(`{"key":"value"}`), "", "once", 0, "", 300, "nextcheckat", 0,
+	)
+
+	mock.ExpectQuery(sqlStatement).
+		WithArgs("action", now).
+		WillReturnRows(rows)
+
+	workflows, err := repo.FindDueWorkflowsByActionId("action", now)
+
+	assert.NoError(test, err)
+	assertWorkflow(test, workflows[0], "1", "workflow", "owner", "action", "reaction", true)
+	assert.Equal(test, 300, workflows[0].PollInterval)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestClaimWorkflowCheck(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	now := time.Now()
+	nextCheckAt := now.Add(time.Hour)
+	sqlStatement := `UPDATE workflows SET nextcheckat = \(\$3\) WHERE id = \(\$1\) AND isactivated AND nextcheckat <= \(\$2\)`
+
+	test.Run("Claimed", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("1", now, nextCheckAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		claimed, err := repo.ClaimWorkflowCheck("1", now, nextCheckAt)
+
+		assert.NoError(test, err)
+		assert.True(test, claimed)
+	})
+
+	test.Run("Not Due Anymore", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("1", now, nextCheckAt).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		claimed, err := repo.ClaimWorkflowCheck("1", now, nextCheckAt)
+
+		assert.NoError(test, err)
+		assert.False(test, claimed)
+	})
+
+	err := mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
 func TestUpdateWorkflow(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
 
-	sqlStatement := `UPDATE workflows SET name = \(\$1\), actionid = \(\$2\), reactionid = \(\$3\), isactivated = \(\$4\), actionparam = \(\$5\), reactionparam = \(\$6\), actiondata = \(\$7\) WHERE id = \(\$8\)`
+	sqlStatement := `UPDATE workflows SET name = \(\$1\), actionid = \(\$2\), reactionid = \(\$3\), isactivated = \(\$4\), actionparam = \(\$5\), reactionparam = \(\$6\), actiondata = \(\$7\), filter = \(\$8\), catchuppolicy = \(\$9\),
+	consecutivefailures = CASE WHEN \(\$4\) AND NOT isactivated THEN 0 ELSE consecutivefailures END,
+	consecutivepollfailures = CASE WHEN \(\$4\) AND NOT isactivated THEN 0 ELSE consecutivepollfailures END, suspendedreason = CASE WHEN \(\$4\) THEN '' ELSE suspendedreason END,
+	pollinterval = \(\$11\), nextcheckat = CASE WHEN actionid <> \(\$2\) OR pollinterval <> \(\$11\) THEN now\(\) ELSE nextcheckat END WHERE id = \(\$10\)`
 
 	var workflowToUpdate entities.Workflow
 	workflowToUpdate.Id = "1234"
@@ -125,6 +266,9 @@ func TestUpdateWorkflow(test *testing.T) {
 	workflowToUpdate.ActionParam = map[string]interface{}{"key": "value"}
 	workflowToUpdate.ReactionParam = map[string]interface{}{"key": "value"}
 	workflowToUpdate.ActionData = map[string]interface{}{"key": "value"}
+	workflowToUpdate.Filter = "temp_c > 30"
+	workflowToUpdate.CatchUpPolicy = "skip"
+	workflowToUpdate.PollInterval = 600
 
 	actionParamJson, reactionParamJson, actionDataJson, err := marshalWorkflowParameters(workflowToUpdate.ActionParam, workflowToUpdate.ReactionParam, workflowToUpdate.ActionData)
 	if err != nil {
@@ -132,7 +276,7 @@ func TestUpdateWorkflow(test *testing.T) {
 	}
 
 	mock.ExpectExec(sqlStatement).
-		WithArgs("name", "action", "reaction", false, actionParamJson, reactionParamJson, actionDataJson, "1234").
+		WithArgs("name", "action", "reaction", false, actionParamJson, reactionParamJson, actionDataJson, "temp_c > 30", "skip", "1234", 600).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err = repo.UpdateWorkflow("1234", workflowToUpdate)
@@ -145,6 +289,178 @@ func TestUpdateWorkflow(test *testing.T) {
 	}
 }
 
+func TestUpdateWorkflowActionData(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `UPDATE workflows SET actiondata = \(\$2\) WHERE id = \(\$1\)`
+
+	mock.ExpectExec(sqlStatement).
+		WithArgs("1234", []byte(`{"key":"value"}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdateWorkflowActionData("1234", map[string]interface{}{"key": "value"})
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestIncrementWorkflowFailures(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `UPDATE workflows SET consecutivefailures = consecutivefailures \+ 1 WHERE id = \(\$1\) RETURNING consecutivefailures`
+
+	test.Run("Successful", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("1234").
+			WillReturnRows(sqlmock.NewRows([]string{"consecutivefailures"}).AddRow(3))
+
+		failures, err := repo.IncrementWorkflowFailures("1234")
+
+		assert.NoError(test, err)
+		assert.Equal(test, 3, failures)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Unknown workflow", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("unknown").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.IncrementWorkflowFailures("unknown")
+
+		assert.ErrorIs(test, err, sql.ErrNoRows)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestResetWorkflowFailures(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE workflows SET consecutivefailures = 0 WHERE id = \(\$1\) AND consecutivefailures > 0`).
+		WithArgs("1234").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.ResetWorkflowFailures("1234")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestIncrementWorkflowPollFailures(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	mock.ExpectQuery(`UPDATE workflows SET consecutivepollfailures = consecutivepollfailures \+ 1 WHERE id = \(\$1\) RETURNING consecutivepollfailures`).
+		WithArgs("1234").
+		WillReturnRows(sqlmock.NewRows([]string{"consecutivepollfailures"}).AddRow(2))
+
+	failures, err := repo.IncrementWorkflowPollFailures("1234")
+
+	assert.NoError(test, err)
+	assert.Equal(test, 2, failures)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestResetWorkflowPollFailures(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE workflows SET consecutivepollfailures = 0 WHERE id = \(\$1\) AND consecutivepollfailures > 0`).
+		WithArgs("1234").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.ResetWorkflowPollFailures("1234")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestSuspendWorkflow(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `UPDATE workflows SET isactivated = false, suspendedreason = \(\$2\) WHERE id = \(\$1\) AND isactivated`
+
+	test.Run("Suspended", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("1234", "reason").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		suspended, err := repo.SuspendWorkflow("1234", "reason")
+
+		assert.NoError(test, err)
+		assert.True(test, suspended)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Already deactivated", func(test *testing.T) {
+		mock.ExpectExec(sqlStatement).
+			WithArgs("1234", "reason").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		suspended, err := repo.SuspendWorkflow("1234", "reason")
+
+		assert.NoError(test, err)
+		assert.False(test, suspended)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestSuspendWorkflowsByOwnerIdAndServiceId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `UPDATE workflows SET isactivated = false, suspendedreason = \(\$3\) WHERE ownerid = \(\$1\) AND isactivated AND`
+	mock.ExpectExec(sqlStatement).
+		WithArgs("owner", "service", "reason").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	count, err := repo.SuspendWorkflowsByOwnerIdAndServiceId("owner", "service", "reason")
+
+	assert.NoError(test, err)
+	assert.Equal(test, 2, count)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
 func TestDeleteWorkflow(test *testing.T) {
 	db, mock, repo := createMockDb(test)
 	defer db.Close()
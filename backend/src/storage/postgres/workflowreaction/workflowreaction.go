@@ -0,0 +1,82 @@
+package workflow_reaction_repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"backend/src/entities"
+)
+
+type WorkflowReactionRepository struct {
+	db *sql.DB
+}
+
+func NewWorkflowReactionRepository(db *sql.DB) *WorkflowReactionRepository {
+	return &WorkflowReactionRepository{db: db}
+}
+
+func appendWorkflowReactionsSlices(rows *sql.Rows) ([]entities.WorkflowReaction, error) {
+	var workflowReactions []entities.WorkflowReaction
+
+	for rows.Next() {
+		var workflowReaction entities.WorkflowReaction
+		var reactionParamBytes []byte
+
+		err := rows.Scan(&workflowReaction.Id, &workflowReaction.WorkflowId, &workflowReaction.Position,
+			&workflowReaction.ReactionId, &reactionParamBytes, &workflowReaction.ContinueOnError)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(reactionParamBytes) > 0 {
+			err = json.Unmarshal(reactionParamBytes, &workflowReaction.ReactionParam)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		workflowReactions = append(workflowReactions, workflowReaction)
+	}
+	return workflowReactions, nil
+}
+
+func (self *WorkflowReactionRepository) CreateWorkflowReaction(workflowId, reactionId string, position int, continueOnError bool, reactionParam map[string]interface{}) error {
+	sqlStatement := `INSERT INTO workflow_reactions (workflowid, position, reactionid, reactionparam, continueonerror) VALUES ($1, $2, $3, $4, $5)`
+
+	reactionParamJson, err := json.Marshal(reactionParam)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, workflowId, position, reactionId, reactionParamJson, continueOnError)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (self *WorkflowReactionRepository) FindWorkflowReactionsByWorkflowId(workflowId string) ([]entities.WorkflowReaction, error) {
+	sqlStatement := `SELECT * FROM workflow_reactions WHERE workflowid = ($1) ORDER BY position`
+
+	rows, errQuery := self.db.Query(sqlStatement, workflowId)
+	if errQuery != nil {
+		return nil, errQuery
+	}
+	defer rows.Close()
+
+	workflowReactions, err := appendWorkflowReactionsSlices(rows)
+	if err != nil {
+		return nil, err
+	}
+	return workflowReactions, nil
+}
+
+func (self *WorkflowReactionRepository) DeleteWorkflowReactionsByWorkflowId(workflowId string) error {
+	sqlStatement := `DELETE FROM workflow_reactions WHERE workflowid = ($1)`
+
+	_, err := self.db.Exec(sqlStatement, workflowId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package workflow_reaction_repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *WorkflowReactionRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		test.Fatalf("Mock DB fail")
+	}
+	repo := NewWorkflowReactionRepository(db)
+	return db, mock, repo
+}
+
+func TestCreateWorkflowReaction(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `INSERT INTO workflow_reactions \(workflowid, position, reactionid, reactionparam, continueonerror\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`
+	mock.ExpectExec(sqlStatement).
+		WithArgs("workflow", 1, "reaction", []byte("{\"key\":\"value\"}"), true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.CreateWorkflowReaction("workflow", "reaction", 1, true, map[string]interface{}{"key": "value"})
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestFindWorkflowReactionsByWorkflowId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT \* FROM workflow_reactions WHERE workflowid = \(\$1\) ORDER BY position`
+
+	test.Run("Successful", func(test *testing.T) {
+		rows := sqlmock.NewRows([]string{
+			"id", "workflowid", "position", "reactionid", "reactionparam", "continueonerror",
+		}).AddRow("first", "workflow", 0, "reaction", []byte(`{"message":"hello"}`), true).
+			AddRow("second", "workflow", 1, "reaction", nil, false)
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("workflow").
+			WillReturnRows(rows)
+
+		workflowReactions, err := repo.FindWorkflowReactionsByWorkflowId("workflow")
+
+		assert.NoError(test, err)
+		assert.Len(test, workflowReactions, 2)
+		assert.Equal(test, "first", workflowReactions[0].Id)
+		assert.Equal(test, 0, workflowReactions[0].Position)
+		assert.True(test, workflowReactions[0].ContinueOnError)
+		assert.Equal(test, "hello", workflowReactions[0].ReactionParam["message"])
+		assert.Equal(test, 1, workflowReactions[1].Position)
+		assert.Nil(test, workflowReactions[1].ReactionParam)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Query error", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("workflow").
+			WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.FindWorkflowReactionsByWorkflowId("workflow")
+
+		assert.Error(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestDeleteWorkflowReactionsByWorkflowId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `DELETE FROM workflow_reactions WHERE workflowid = \(\$1\)`
+	mock.ExpectExec(sqlStatement).
+		WithArgs("workflow").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.DeleteWorkflowReactionsByWorkflowId("workflow")
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
@@ -0,0 +1,83 @@
+package workflow_run_repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"backend/src/entities"
+)
+
+type WorkflowRunRepository struct {
+	db *sql.DB
+}
+
+func NewWorkflowRunRepository(db *sql.DB) *WorkflowRunRepository {
+	return &WorkflowRunRepository{db: db}
+}
+
+func appendWorkflowRunsSlices(rows *sql.Rows) ([]entities.WorkflowRun, error) {
+	var workflowRuns []entities.WorkflowRun
+
+	for rows.Next() {
+		var workflowRun entities.WorkflowRun
+		var actionPayloadBytes []byte
+
+		err := rows.Scan(&workflowRun.Id, &workflowRun.WorkflowId, &workflowRun.ReactionId, &workflowRun.TriggeredAt,
+			&actionPayloadBytes, &workflowRun.Status, &workflowRun.HttpStatus, &workflowRun.ErrorMessage)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(actionPayloadBytes) > 0 {
+			err = json.Unmarshal(actionPayloadBytes, &workflowRun.ActionPayload)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		workflowRuns = append(workflowRuns, workflowRun)
+	}
+	return workflowRuns, nil
+}
+
+func (self *WorkflowRunRepository) CreateWorkflowRun(workflowId, reactionId, status, errorMessage string, httpStatus int, actionPayload map[string]interface{}) error {
+	sqlStatement := `INSERT INTO workflow_runs (workflowid, reactionid, actionpayload, status, httpstatus, errormessage) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	actionPayloadJson, err := json.Marshal(actionPayload)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.db.Exec(sqlStatement, workflowId, reactionId, actionPayloadJson, status, httpStatus, errorMessage)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (self *WorkflowRunRepository) FindWorkflowRunsByWorkflowId(workflowId, status string, limit, offset int) ([]entities.WorkflowRun, error) {
+	sqlStatement := `SELECT * FROM workflow_runs WHERE workflowid = ($1) AND (($2) = '' OR status = ($2)) ORDER BY triggeredat DESC LIMIT ($3) OFFSET ($4)`
+
+	rows, errQuery := self.db.Query(sqlStatement, workflowId, status, limit, offset)
+	if errQuery != nil {
+		return nil, errQuery
+	}
+	defer rows.Close()
+
+	workflowRuns, err := appendWorkflowRunsSlices(rows)
+	if err != nil {
+		return nil, err
+	}
+	return workflowRuns, nil
+}
+
+func (self *WorkflowRunRepository) CountWorkflowRunsByWorkflowId(workflowId, status string) (int, error) {
+	sqlStatement := `SELECT COUNT(*) FROM workflow_runs WHERE workflowid = ($1) AND (($2) = '' OR status = ($2))`
+	var count int
+
+	err := self.db.QueryRow(sqlStatement, workflowId, status).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,103 @@
+package workflow_run_repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockDb(test *testing.T) (*sql.DB, sqlmock.Sqlmock, *WorkflowRunRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		test.Fatalf("Mock DB fail")
+	}
+	repo := NewWorkflowRunRepository(db)
+	return db, mock, repo
+}
+
+func TestCreateWorkflowRun(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `INSERT INTO workflow_runs \(workflowid, reactionid, actionpayload, status, httpstatus, errormessage\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)`
+	mock.ExpectExec(sqlStatement).
+		WithArgs("workflow", "reaction", []byte("{\"key\":\"value\"}"), "failure", 401, "API call failed").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.CreateWorkflowRun("workflow", "reaction", "failure", "API call failed", 401, map[string]interface{}{"key": "value"})
+
+	assert.NoError(test, err)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
+
+func TestFindWorkflowRunsByWorkflowId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT \* FROM workflow_runs WHERE workflowid = \(\$1\) AND \(\(\$2\) = '' OR status = \(\$2\)\) ORDER BY triggeredat DESC LIMIT \(\$3\) OFFSET \(\$4\)`
+
+	test.Run("Successful", func(test *testing.T) {
+		rows := sqlmock.NewRows([]string{
+			"id", "workflowid", "reactionid", "triggeredat", "actionpayload", "status", "httpstatus", "errormessage",
+		}).AddRow("id", "workflow", "reaction", "triggeredat", []byte(`{"post":{"title":"title"}}`), "success", 200, "")
+
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("workflow", "success", 20, 0).
+			WillReturnRows(rows)
+
+		workflowRuns, err := repo.FindWorkflowRunsByWorkflowId("workflow", "success", 20, 0)
+
+		assert.NoError(test, err)
+		assert.Len(test, workflowRuns, 1)
+		assert.Equal(test, "id", workflowRuns[0].Id)
+		assert.Equal(test, "success", workflowRuns[0].Status)
+		assert.Equal(test, 200, workflowRuns[0].HttpStatus)
+		assert.Equal(test, map[string]interface{}{"title": "title"}, workflowRuns[0].ActionPayload["post"])
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+
+	test.Run("Query error", func(test *testing.T) {
+		mock.ExpectQuery(sqlStatement).
+			WithArgs("workflow", "", 20, 0).
+			WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.FindWorkflowRunsByWorkflowId("workflow", "", 20, 0)
+
+		assert.Error(test, err)
+
+		err = mock.ExpectationsWereMet()
+		if err != nil {
+			test.Errorf("Expectation fail")
+		}
+	})
+}
+
+func TestCountWorkflowRunsByWorkflowId(test *testing.T) {
+	db, mock, repo := createMockDb(test)
+	defer db.Close()
+
+	sqlStatement := `SELECT COUNT\(\*\) FROM workflow_runs WHERE workflowid = \(\$1\) AND \(\(\$2\) = '' OR status = \(\$2\)\)`
+	mock.ExpectQuery(sqlStatement).
+		WithArgs("workflow", "failure").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := repo.CountWorkflowRunsByWorkflowId("workflow", "failure")
+
+	assert.NoError(test, err)
+	assert.Equal(test, 3, count)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		test.Errorf("Expectation fail")
+	}
+}
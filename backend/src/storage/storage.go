@@ -1,6 +1,10 @@
 package storage
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"backend/src/entities"
 )
 
@@ -9,11 +13,12 @@ type UserRepository interface {
 	FindUserByEmail(email, connectionType string) (entities.User, error)
 	FindUserById(userId string) (entities.User, error)
 	UpdateUser(email, password, connectionType string) error
+	UpdateUserTimezone(email, connectionType, timezone string) error
 	DeleteUser(email, connectionType string) error
 }
 
 type ServiceRepository interface {
-	CreateService(name, color, logo string) error
+	CreateService(service entities.Service) error
 	FindServiceById(id string) (entities.Service, error)
 	FindServiceByName(name string) (entities.Service, error)
 	FindAllServices() ([]entities.Service, error)
@@ -25,18 +30,28 @@ type UserServiceRepository interface {
 	CreateUserService(userId, token, tokenRefresh, expiryDate, serviceId string) error
 	FindUserServiceByServiceIdandUserId(userId, serviceId string) (entities.UserService, error)
 	UpdateUserServiceByServiceIdAndUserId(userId, accessToken, refreshToken, expiryDate, serviceId string) error
+	FindUserServicesByUserId(userId string) ([]entities.UserService, error)
+	FindUserServicesExpiringBefore(deadline time.Time) ([]entities.UserService, error)
+	MarkUserServiceNeedsReauth(userId, serviceId string) error
+	UpdateUserServiceScopes(userId, serviceId, scopes string) error
+	RecordUserServiceSuccess(userId, serviceId string, at time.Time) error
+	RecordUserServiceError(userId, serviceId, lastError string, at time.Time) error
 	DeleteUserServiceByUserId(userId string) error
+	DeleteUserServiceByServiceIdAndUserId(userId, serviceId string) error
+	RotateUserServiceKeys() (int, error)
 }
 
 type ReactionRepository interface {
-	CreateReaction(name, description, serviceId string, nbParam int) error
+	CreateReaction(reaction entities.Reaction) error
 	FindReactionById(id string) (entities.Reaction, error)
 	FindReactionByName(name string) (entities.Reaction, error)
+	FindReactionByNameAndServiceId(name, serviceId string) (entities.Reaction, error)
 	FindReactionsByServiceId(serviceId string) ([]entities.Reaction, error)
 }
 
 type ActionRepository interface {
-	CreateAction(name, description, serviceId string, nbParam int) error
+	CreateAction(action entities.Action) error
+	UpdateAction(id string, action entities.Action) error
 	FindActionById(id string) (entities.Action, error)
 	FindActionByName(name string) (entities.Action, error)
 	FindActionsByServiceId(serviceId string) ([]entities.Action, error)
@@ -44,20 +59,88 @@ type ActionRepository interface {
 }
 
 type WorkflowRepository interface {
-	CreateWorkflow(name, ownerId, actionId, reactionId string, actionParam, reactionParam, actionData map[string]interface{}) error
+	CreateWorkflow(name, ownerId, actionId, reactionId, filter, catchUpPolicy string, pollInterval int, actionParam, reactionParam, actionData map[string]interface{}) (string, error)
 	FindWorkflowById(id string) (entities.Workflow, error)
+	FindWorkflowByWebhookToken(token string) (entities.Workflow, error)
 	FindWorkflowsByActionId(actionId string) ([]entities.Workflow, error)
+	FindDueWorkflowsByActionId(actionId string, now time.Time) ([]entities.Workflow, error)
 	FindWorkflowsByOwnerId(ownerId string) ([]entities.Workflow, error)
 	UpdateWorkflow(id string, updatedWorkflow entities.Workflow) error
+	UpdateWorkflowActionData(id string, actionData map[string]interface{}) error
+	IncrementWorkflowFailures(id string) (int, error)
+	ResetWorkflowFailures(id string) error
+	IncrementWorkflowPollFailures(id string) (int, error)
+	ResetWorkflowPollFailures(id string) error
+	ClaimWorkflowCheck(id string, now, nextCheckAt time.Time) (bool, error)
+	SuspendWorkflow(id, reason string) (bool, error)
+	SuspendWorkflowsByOwnerIdAndServiceId(ownerId, serviceId, reason string) (int, error)
 	DeleteWorkflow(id, ownerId string) error
 	DeleteWorkflowByOwnerId(ownerId string) error
 }
 
+type WorkflowReactionRepository interface {
+	CreateWorkflowReaction(workflowId, reactionId string, position int, continueOnError bool, reactionParam map[string]interface{}) error
+	FindWorkflowReactionsByWorkflowId(workflowId string) ([]entities.WorkflowReaction, error)
+	DeleteWorkflowReactionsByWorkflowId(workflowId string) error
+}
+
+type WorkflowRunRepository interface {
+	CreateWorkflowRun(workflowId, reactionId, status, errorMessage string, httpStatus int, actionPayload map[string]interface{}) error
+	FindWorkflowRunsByWorkflowId(workflowId, status string, limit, offset int) ([]entities.WorkflowRun, error)
+	CountWorkflowRunsByWorkflowId(workflowId, status string) (int, error)
+}
+
+type SchedulerTickRepository interface {
+	FindLastTick(name string) (time.Time, error)
+	UpdateLastTick(name string, tick time.Time) error
+	TryAcquireSchedulerLock(ctx context.Context) (bool, error)
+	ReleaseSchedulerLock() error
+}
+
+type ServiceWebhookRepository interface {
+	FindServiceWebhookSecret(serviceName, resource string) (string, error)
+	SaveServiceWebhookSecret(serviceName, resource, secret string) error
+	CreateWebhookRejection(serviceName, resource, reason, remoteAddr string) error
+}
+
+type WebhookDeliveryRepository interface {
+	CreateWebhookDelivery(serviceName, deliveryId string, headers http.Header, body []byte, duplicateSince time.Time) (string, error)
+	FindWebhookDeliveryById(id string) (entities.WebhookDelivery, error)
+}
+
+type JobRepository interface {
+	EnqueueJob(job entities.ReactionJob) error
+	ClaimJob(serviceName string, staleBefore time.Time) (entities.ReactionJob, bool, error)
+	CompleteJob(id string) error
+	FailJob(id, lastError string) error
+	RetryJob(id, lastError string, runAt time.Time) error
+	FindFailedJobsByWorkflowId(workflowId string) ([]entities.ReactionJob, error)
+	RequeueFailedJobs(workflowId string) (int, error)
+}
+
+type OAuthStateRepository interface {
+	CreateOAuthState(codeVerifier string, expiresAt time.Time) (string, error)
+	ConsumeOAuthState(id string, now time.Time) (string, bool, error)
+}
+
+type DatabaseRepository interface {
+	Ping(ctx context.Context) error
+	Close() error
+}
+
 type Repository struct {
-	UserRepository        UserRepository
-	ServiceRepository     ServiceRepository
-	UserServiceRepository UserServiceRepository
-	ReactionRepository    ReactionRepository
-	ActionRepository      ActionRepository
-	WorkflowRepository    WorkflowRepository
+	DatabaseRepository         DatabaseRepository
+	UserRepository             UserRepository
+	ServiceRepository          ServiceRepository
+	UserServiceRepository      UserServiceRepository
+	ReactionRepository         ReactionRepository
+	ActionRepository           ActionRepository
+	WorkflowRepository         WorkflowRepository
+	WorkflowReactionRepository WorkflowReactionRepository
+	WorkflowRunRepository      WorkflowRunRepository
+	SchedulerTickRepository    SchedulerTickRepository
+	ServiceWebhookRepository   ServiceWebhookRepository
+	WebhookDeliveryRepository  WebhookDeliveryRepository
+	JobRepository              JobRepository
+	OAuthStateRepository       OAuthStateRepository
 }
@@ -406,8 +406,8 @@ const WorkflowBuilder = () => {
 
     let isCallbackSent = false;
 
-    const handleServiceCallback = async (codeAuth: string, serviceName: string) => {
-        if (!codeAuth || !serviceName) return;
+    const handleServiceCallback = async (codeAuth: string, stateAuth: string, serviceName: string) => {
+        if (!codeAuth || !stateAuth || !serviceName) return;
 
         if (isCallbackSent) {
             console.warn("Callback already sent");
@@ -418,12 +418,13 @@ const WorkflowBuilder = () => {
 
         try {
             const result = await axios.post(
-                `${import.meta.env.VITE_API_URL}service-callback/?code=${codeAuth}`,
+                `${import.meta.env.VITE_API_URL}service-callback/`,
                 {
                     apptype: "web",
                     service: serviceName
                 },
                 {
+                    params: { code: codeAuth, state: stateAuth },
                     withCredentials: true
                 }
             );
@@ -464,6 +465,7 @@ const WorkflowBuilder = () => {
         const getSavedData = async () => {
             const params = new URLSearchParams(window.location.search);
             const codeAuth = params.get("code");
+            const stateAuth = params.get("state");
 
             const state = sessionStorage.getItem("state-data");
 
@@ -478,11 +480,11 @@ const WorkflowBuilder = () => {
 
             updateStateFromSession(jsonState);
 
-            if (!codeAuth) {
+            if (!codeAuth || !stateAuth) {
                 return;
             }
 
-            await handleServiceCallback(codeAuth, serviceAuth.name);
+            await handleServiceCallback(codeAuth, stateAuth, serviceAuth.name);
 
             if (jsonState.type === "action" && jsonState.selectedAction?.parameters != null) {
                 const isconnected = await isServiceConnectedStatus(jsonState.selectedActionService?.name)
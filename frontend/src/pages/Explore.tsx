@@ -26,19 +26,21 @@ const ExplorePage = () => {
 
     const getAuthCode = async () => {
         const codeAuth = searchParams.get("code");
+        const stateAuth = searchParams.get("state");
         const serviceName = sessionStorage.getItem("oauth2-login")
 
-        if (!codeAuth || !serviceName) {
+        if (!codeAuth || !stateAuth || !serviceName) {
             return;
         }
 
         await axios.post(
-            `${import.meta.env.VITE_API_URL}login-callback/?code=${codeAuth}`,
+            `${import.meta.env.VITE_API_URL}login-callback/`,
             {
                 service: serviceName,
                 apptype: "web"
             },
             {
+                params: { code: codeAuth, state: stateAuth },
                 withCredentials: true
             }
         );
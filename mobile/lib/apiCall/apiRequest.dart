@@ -80,7 +80,7 @@ class ApiRequest {
     }
   }
 
-  static Future<http.Response> post(String apiRoute, Object? body) async {
+  static Future<http.Response> post(String apiRoute, Object? body, {Map<String, String> headers = const {}}) async {
     developer.log('PostApi route : ${ApiData.apiUrl}$apiRoute');
     developer.log("api domaine : ${ApiData.apiUrl}");
     try {
@@ -89,6 +89,7 @@ class ApiRequest {
         headers: <String, String>{
           'Content-Type': 'application/json; charset=UTF-8',
           'Cookie': Globaldata.JWToken,
+          ...headers,
         },
         body: body
       );
@@ -1,4 +1,5 @@
 
 class AuthData {
   static String serviceConnectionLink = "";
+  static String oauthSession = "";
 }
\ No newline at end of file
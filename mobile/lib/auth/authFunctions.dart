@@ -4,6 +4,7 @@ import 'dart:convert';
 
 import "../../globalData.dart";
 import "../../apiCall/apiRequest.dart";
+import "authData.dart";
 import 'package:flutter_inappwebview/flutter_inappwebview.dart';
 
 class AuthFunctions {
@@ -21,6 +22,7 @@ class AuthFunctions {
       return authLink;
     }
     authLink = responseData["auth-url"];
+    AuthData.oauthSession = responseData["session"] ?? "";
     developer.log(
       "getAuthLink, service : $service, response : ${authLink}");
     return authLink;
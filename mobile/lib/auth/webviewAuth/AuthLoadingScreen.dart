@@ -10,6 +10,7 @@ import "../../apiCall/apiErrorHandling.dart";
 import "../../globalData.dart";
 import "../../login/loginNavigator.dart";
 import "../../login/loadData/loadServicesData.dart";
+import "../authData.dart";
 
 String callBackRedirect = "";
 String authServiceName = "";
@@ -72,27 +73,23 @@ class AuthLoadingScreen extends State<APICall> {
     }
   }
 
-  String removeUrlState(String url) {
-    final splitState = url.split("?state=");
-    if (splitState.length == 1) {
-      return url.split("&state=").first;
-    }
-    final splitCode = splitState.last.split("&code=");
-    return "${splitState.first}?code=${splitCode.last}";
+  // The state is checked by the API against the OAuth session given with the authorization URL
+  String callbackQuery(WebUri uri) {
+    final code = uri.queryParameters["code"] ?? "";
+    final state = uri.queryParameters["state"] ?? "";
+    return "?code=${Uri.encodeQueryComponent(code)}&state=${Uri.encodeQueryComponent(state)}";
   }
 
   Future<void> handleCallbackUrl() async {
-    List<String> urlSplit = webUri.toString().split(callBackRedirect);
-    if (webUri.toString().contains("/$authServiceName?")) {
-      urlSplit = webUri.toString().split(authServiceName);
-    }
-    String callBackUrl = removeUrlState(urlSplit.last);
-    String postUrl = "${apiRoute}$callBackUrl";
+    String postUrl = "${apiRoute}${callbackQuery(webUri)}";
     final Response response = await ApiRequest.post(postUrl,
       jsonEncode(<String, dynamic>{
         "apptype" : Globaldata.appType,
         "service" : authServiceName,
       }),
+      headers: <String, String>{
+        "X-OAuth-Session": AuthData.oauthSession,
+      },
     );
 
     if (response.statusCode == 200) {